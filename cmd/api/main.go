@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -9,8 +10,11 @@ import (
 	"cctv-monitoring-backend/internal/database"
 	"cctv-monitoring-backend/internal/handler"
 	"cctv-monitoring-backend/internal/middleware"
+	"cctv-monitoring-backend/internal/models"
 	"cctv-monitoring-backend/internal/repository"
+	"cctv-monitoring-backend/internal/rtsp"
 	"cctv-monitoring-backend/internal/service"
+	"cctv-monitoring-backend/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -42,19 +46,97 @@ func main() {
 	userRepo := repository.NewUserRepository(db)
 	cameraRepo := repository.NewCameraRepository(db)
 	tokenRepo := repository.NewTokenRepository(db)
+	channelRepo := repository.NewChannelRepository(db)
+	mediaServerRepo := repository.NewMediaServerRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	probeRepo := repository.NewProbeRepository(db)
+	activityRepo := repository.NewActivityRepository(db)
+	revisionRepo := repository.NewRevisionRepository(db)
+	locationRepo := repository.NewLocationRepository(db)
+	transactor := repository.NewTransactor(db)
+
+	// Key enkripsi credential RTSP camera dan password node RTSPtoWeb
+	credentialCipher, err := utils.NewCredentialCipher(cfg.Credentials.Keys)
+	if err != nil {
+		log.Fatalf("Invalid CREDENTIAL_KEYS (generate a key with `openssl rand -base64 32`): %v", err)
+	}
 
 	// Initialize services
 	authService := service.NewAuthService(userRepo, tokenRepo)
-	rtspService := service.NewRTSPService(cfg.RTSP.APIURL, cfg.RTSP.PublicBaseURL, cfg.RTSP.Username, cfg.RTSP.Password)
-	cameraService := service.NewCameraService(cameraRepo, rtspService)
+	credentialService := service.NewCredentialService(credentialCipher, cameraRepo, channelRepo, mediaServerRepo, revisionRepo, transactor)
+
+	// Enkripsi credential yang masih tersimpan di URL dan rotasi ke key aktif
+	if report, err := credentialService.RotateAll(); err != nil {
+		log.Printf("Credential rotation failed: %v", err)
+	} else if report.Total > 0 {
+		log.Printf("Credential rotation to key %s: %d rotated, %d skipped, %d failed", report.ActiveKeyID, report.Rotated, report.Skipped, report.Failed)
+	}
+
+	mediaServerService := service.NewMediaServerService(mediaServerRepo, cameraRepo, channelRepo, credentialService, service.RTSPClientOptions{
+		Timeout:          cfg.RTSP.Timeout,
+		MaxRetries:       cfg.RTSP.MaxRetries,
+		RetryBackoff:     cfg.RTSP.RetryBackoff,
+		BreakerThreshold: cfg.RTSP.BreakerThreshold,
+		BreakerCooldown:  cfg.RTSP.BreakerCooldown,
+	})
+
+	// Node RTSPtoWeb dari .env didaftarkan sebagai node default jika registry masih kosong
+	if err := mediaServerService.EnsureDefault(cfg.RTSP.APIURL, cfg.RTSP.PublicBaseURL, cfg.RTSP.Username, cfg.RTSP.Password); err != nil {
+		log.Fatalf("Failed to register default media server: %v", err)
+	}
+
+	viewerTokenService := service.NewViewerTokenService(cfg.Media.Enabled, cfg.Media.Secret, cfg.Media.TTL)
+	outboxService := service.NewStreamOutboxService(outboxRepo, cameraRepo, channelRepo, mediaServerService, credentialService, cfg.Outbox.MaxAttempts)
+	cameraService := service.NewCameraService(cameraRepo, channelRepo, mediaServerService, viewerTokenService, outboxService, credentialService, transactor, service.PlaybackConfig{
+		ProxyEnabled: cfg.RTSP.ProxyEnabled,
+		APIBaseURL:   cfg.App.PublicURL,
+	}, activityRepo, revisionRepo, locationRepo, cfg.Trash.Retention)
+
+	locationService := service.NewLocationService(locationRepo, revisionRepo, transactor)
+
+	rtspConfigService := service.NewRTSPConfigService(cameraRepo, channelRepo, mediaServerService, credentialService, transactor, revisionRepo, service.RTSPConfigDefaults{
+		HTTPLogin:    cfg.RTSP.Username,
+		HTTPPassword: cfg.RTSP.Password,
+		HTTPDemo:     cfg.RTSP.HTTPDemo,
+		TokenEnabled: cfg.Media.Enabled,
+		TokenBackend: cfg.Media.GetBackendEndpoint(),
+	})
+
+	cameraProbeService := service.NewCameraProbeService(cameraRepo, probeRepo, transactor, credentialService, rtsp.NewProber(cfg.RTSP.ProbeTimeout))
 
-	// Start cleanup job for expired tokens (run every 1 hour)
-	cleanupService := service.NewCleanupService(tokenRepo)
+	// Start cleanup job for expired tokens and camera trash (run every 1 hour)
+	cleanupService := service.NewCleanupService(tokenRepo, cameraService)
 	cleanupService.StartCleanupJob(1 * time.Hour)
 
+	// Start outbox worker (kirim ulang operasi stream yang gagal ke RTSPtoWeb)
+	outboxService.StartWorker(cfg.Outbox.Interval)
+
+	// Start health check node RTSPtoWeb (failover stream jika node down)
+	mediaServerService.StartHealthJob(cfg.Monitor.NodeHealthInterval, cfg.Monitor.FailoverThreshold)
+
+	// Start stream health monitor (update cameras.status dan last_seen)
+	streamMonitorService := service.NewStreamMonitorService(cameraRepo, mediaServerService)
+	streamMonitorService.StartMonitorJob(cfg.Monitor.Interval)
+
+	// Samakan stream RTSPtoWeb dengan tabel cameras saat startup
+	reconcileService := service.NewReconcileService(cameraRepo, channelRepo, mediaServerService, credentialService)
+	if cfg.Monitor.ReconcileOnStartup {
+		go func() {
+			if _, err := reconcileService.Reconcile(context.Background(), false); err != nil {
+				log.Printf("Startup stream reconcile failed: %v", err)
+			}
+		}()
+	}
+
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService, cfg.JWT.Secret, cfg.JWT.Expiration.String())
 	cameraHandler := handler.NewCameraHandler(cameraService)
+	adminHandler := handler.NewAdminHandler(reconcileService)
+	mediaHandler := handler.NewMediaHandler(viewerTokenService, cfg.Media.BackendSecret)
+	mediaServerHandler := handler.NewMediaServerHandler(mediaServerService)
+	rtspConfigHandler := handler.NewRTSPConfigHandler(rtspConfigService)
+	cameraProbeHandler := handler.NewCameraProbeHandler(cameraProbeService)
+	locationHandler := handler.NewLocationHandler(locationService)
 
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
@@ -75,7 +157,7 @@ func main() {
 	})
 
 	// Routes
-	setupRoutes(app, authHandler, cameraHandler, authService)
+	setupRoutes(app, authHandler, cameraHandler, adminHandler, mediaHandler, mediaServerHandler, rtspConfigHandler, cameraProbeHandler, locationHandler, authService, viewerTokenService)
 
 	// Start server
 	addr := fmt.Sprintf(":%s", cfg.App.Port)
@@ -84,7 +166,7 @@ func main() {
 }
 
 // setupRoutes mengatur semua routing aplikasi
-func setupRoutes(app *fiber.App, authHandler *handler.AuthHandler, cameraHandler *handler.CameraHandler, authService service.AuthService) {
+func setupRoutes(app *fiber.App, authHandler *handler.AuthHandler, cameraHandler *handler.CameraHandler, adminHandler *handler.AdminHandler, mediaHandler *handler.MediaHandler, mediaServerHandler *handler.MediaServerHandler, rtspConfigHandler *handler.RTSPConfigHandler, cameraProbeHandler *handler.CameraProbeHandler, locationHandler *handler.LocationHandler, authService service.AuthService, viewerTokenService service.ViewerTokenService) {
 	// Health check
 	app.Get("/health", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
@@ -108,12 +190,28 @@ func setupRoutes(app *fiber.App, authHandler *handler.AuthHandler, cameraHandler
 	auth.Get("/me", authMiddleware, authHandler.Me)
 	auth.Post("/logout", authMiddleware, authHandler.Logout)
 
+	// Token backend untuk RTSPtoWeb (dipanggil oleh media server, bukan browser)
+	api.Post("/media/authorize", mediaHandler.Authorize)
+
+	// Media proxy routes (HLS dan JPEG dari RTSPtoWeb). Didaftarkan sebelum group
+	// /cameras agar viewer token bisa dipakai sebagai pengganti Authorization header.
+	mediaAuthMiddleware := middleware.MediaAuthMiddleware(authService, viewerTokenService)
+	api.Get("/cameras/:id/hls/*", mediaAuthMiddleware, cameraHandler.ProxyHLS)
+	api.Get("/cameras/:id/snapshot", mediaAuthMiddleware, cameraHandler.ProxySnapshot)
+
 	// Camera routes
 	cameras := api.Group("/cameras", authMiddleware)
 	cameras.Get("/", cameraHandler.GetAll)
+	cameras.Get("/export", cameraHandler.Export)
+	cameras.Get("/trash", middleware.RoleMiddleware("admin", "operator"), cameraHandler.GetTrash)
+	cameras.Post("/trash/:id/restore", middleware.RoleMiddleware("admin", "operator"), cameraHandler.Restore)
+	cameras.Delete("/trash/:id", middleware.RoleMiddleware("admin"), cameraHandler.Purge)
 	cameras.Get("/:id", cameraHandler.GetByID)
 	cameras.Post("/", cameraHandler.Create)
+	cameras.Post("/import", middleware.RoleMiddleware("admin", "operator"), cameraHandler.Import)
+	cameras.Post("/bulk", middleware.RoleMiddleware("admin", "operator"), cameraHandler.Bulk)
 	cameras.Put("/:id", cameraHandler.Update)
+	cameras.Patch("/:id", cameraHandler.Patch)
 	cameras.Delete("/:id", cameraHandler.Delete)
 
 	// Camera filter routes
@@ -123,6 +221,58 @@ func setupRoutes(app *fiber.App, authHandler *handler.AuthHandler, cameraHandler
 	// Stream routes
 	cameras.Post("/:id/stream/start", cameraHandler.StartStream)
 	cameras.Post("/:id/stream/stop", cameraHandler.StopStream)
+	cameras.Get("/:id/stream/deliveries", cameraHandler.GetStreamDeliveries)
+	cameras.Get("/:id/history", cameraHandler.GetHistory)
+	cameras.Get("/:id/history/:revision", cameraHandler.GetRevision)
+	cameras.Post("/:id/history/:revision/revert", cameraHandler.Revert)
+	cameras.Post("/:id/webrtc", cameraHandler.WebRTCOffer)
+
+	// Probe RTSP (codec, resolusi, fps dari DESCRIBE)
+	cameras.Post("/:id/probe", cameraProbeHandler.Probe)
+	cameras.Get("/:id/probe", cameraProbeHandler.GetProbe)
+
+	// Location routes (site > building > floor > zone), hanya admin yang bisa mengubah
+	locations := api.Group("/locations", authMiddleware)
+	locations.Get("/tree", locationHandler.GetTree)
+	locations.Get("/sites", locationHandler.GetSites)
+	locations.Get("/sites/:id", locationHandler.GetSite)
+	locations.Post("/sites", middleware.RoleMiddleware("admin"), locationHandler.CreateSite)
+	locations.Put("/sites/:id", middleware.RoleMiddleware("admin"), locationHandler.UpdateSite)
+	locations.Delete("/sites/:id", middleware.RoleMiddleware("admin"), locationHandler.DeleteSite)
+	locations.Get("/buildings", locationHandler.GetBuildings)
+	locations.Get("/buildings/:id", locationHandler.GetBuilding)
+	locations.Post("/buildings", middleware.RoleMiddleware("admin"), locationHandler.CreateBuilding)
+	locations.Put("/buildings/:id", middleware.RoleMiddleware("admin"), locationHandler.UpdateBuilding)
+	locations.Delete("/buildings/:id", middleware.RoleMiddleware("admin"), locationHandler.DeleteBuilding)
+	locations.Get("/floors", locationHandler.GetFloors)
+	locations.Get("/floors/:id", locationHandler.GetFloor)
+	locations.Post("/floors", middleware.RoleMiddleware("admin"), locationHandler.CreateFloor)
+	locations.Put("/floors/:id", middleware.RoleMiddleware("admin"), locationHandler.UpdateFloor)
+	locations.Delete("/floors/:id", middleware.RoleMiddleware("admin"), locationHandler.DeleteFloor)
+	locations.Get("/zones", locationHandler.GetZones)
+	locations.Get("/zones/:id", locationHandler.GetZone)
+	locations.Post("/zones", middleware.RoleMiddleware("admin"), locationHandler.CreateZone)
+	locations.Put("/zones/:id", middleware.RoleMiddleware("admin"), locationHandler.UpdateZone)
+	locations.Delete("/zones/:id", middleware.RoleMiddleware("admin"), locationHandler.DeleteZone)
+
+	// Admin routes
+	admin := api.Group("/admin", authMiddleware, middleware.RoleMiddleware("admin"))
+	admin.Post("/streams/reconcile", adminHandler.ReconcileStreams)
+
+	// Media server (node RTSPtoWeb) routes
+	admin.Get("/media-servers", mediaServerHandler.GetAll)
+	admin.Get("/media-servers/:id", mediaServerHandler.GetByID)
+	admin.Post("/media-servers", mediaServerHandler.Create)
+	admin.Put("/media-servers/:id", mediaServerHandler.Update)
+	admin.Delete("/media-servers/:id", mediaServerHandler.Delete)
+
+	// Import config.json RTSPtoWeb ke tabel cameras
+	admin.Post("/cameras/import-config", rtspConfigHandler.Import)
+
+	// Generate config.json RTSPtoWeb dari tabel cameras (per node dengan ?media_server_id=).
+	// Config berisi credential RTSP camera, butuh permission camera:credentials.
+	admin.Get("/cameras/export-config", middleware.PermissionMiddleware(models.PermissionCameraCredentials), rtspConfigHandler.Export)
+	admin.Get("/cameras/export-config/diff", rtspConfigHandler.Diff)
 }
 
 // customErrorHandler adalah custom error handler untuk Fiber
@@ -0,0 +1,383 @@
+// Command cctvctl adalah CLI administrasi CCTV Monitoring Backend yang bekerja
+// langsung ke database (memakai konfigurasi .env yang sama dengan API).
+//
+// Usage:
+//
+//	cctvctl import-config -file rtsptoweb-config.json [-mode skip|update] [-dry-run] [-media-server <id>] [-user admin]
+//	cctvctl generate-config [-media-server <id>] [-out config.json]
+//	cctvctl diff-config [-media-server <id>]
+//	cctvctl rotate-credentials
+//	cctvctl grant-permission -user <username> -permission camera:credentials
+//	cctvctl revoke-permission -user <username> -permission camera:credentials
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"cctv-monitoring-backend/internal/config"
+	"cctv-monitoring-backend/internal/database"
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/repository"
+	"cctv-monitoring-backend/internal/service"
+	"cctv-monitoring-backend/internal/utils"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "import-config":
+		err = runImportConfig(os.Args[2:])
+	case "generate-config":
+		err = runGenerateConfig(os.Args[2:])
+	case "diff-config":
+		err = runDiffConfig(os.Args[2:])
+	case "rotate-credentials":
+		err = runRotateCredentials(os.Args[2:])
+	case "grant-permission":
+		err = runSetPermission("grant-permission", os.Args[2:], true)
+	case "revoke-permission":
+		err = runSetPermission("revoke-permission", os.Args[2:], false)
+	case "help", "-h", "--help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: cctvctl <command> [flags]
+
+Commands:
+  import-config     Import cameras from an RTSPtoWeb config.json
+  generate-config   Render an RTSPtoWeb config.json from the cameras table
+  diff-config       Compare the cameras table with streams on a running node
+  rotate-credentials
+                    Re-encrypt camera RTSP credentials with the active key
+  grant-permission  Grant a permission to a user
+  revoke-permission Revoke a permission from a user
+
+Run "cctvctl <command> -h" for command flags.`)
+}
+
+// app berisi dependency yang dipakai command CLI
+type app struct {
+	userRepo    repository.UserRepository
+	rtspConfig  service.RTSPConfigService
+	credentials service.CredentialService
+}
+
+// newApp membuka koneksi database dan menyiapkan repository dan service
+func newApp() (*app, func(), error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	db, err := database.Connect(cfg.Database.GetDSN())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := database.RunMigrations(db); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	credentialCipher, err := utils.NewCredentialCipher(cfg.Credentials.Keys)
+	if err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("invalid CREDENTIAL_KEYS: %w", err)
+	}
+
+	cameraRepo := repository.NewCameraRepository(db)
+	channelRepo := repository.NewChannelRepository(db)
+	mediaServerRepo := repository.NewMediaServerRepository(db)
+	revisionRepo := repository.NewRevisionRepository(db)
+	transactor := repository.NewTransactor(db)
+	credentials := service.NewCredentialService(credentialCipher, cameraRepo, channelRepo, mediaServerRepo, revisionRepo, transactor)
+	mediaServers := service.NewMediaServerService(mediaServerRepo, cameraRepo, channelRepo, credentials, service.RTSPClientOptions{
+		Timeout:          cfg.RTSP.Timeout,
+		MaxRetries:       cfg.RTSP.MaxRetries,
+		RetryBackoff:     cfg.RTSP.RetryBackoff,
+		BreakerThreshold: cfg.RTSP.BreakerThreshold,
+		BreakerCooldown:  cfg.RTSP.BreakerCooldown,
+	})
+
+	// Memuat registry node (sama seperti saat API start)
+	if err := mediaServers.EnsureDefault(cfg.RTSP.APIURL, cfg.RTSP.PublicBaseURL, cfg.RTSP.Username, cfg.RTSP.Password); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	a := &app{
+		userRepo: repository.NewUserRepository(db),
+		rtspConfig: service.NewRTSPConfigService(cameraRepo, channelRepo, mediaServers, credentials, transactor, revisionRepo, service.RTSPConfigDefaults{
+			HTTPLogin:    cfg.RTSP.Username,
+			HTTPPassword: cfg.RTSP.Password,
+			HTTPDemo:     cfg.RTSP.HTTPDemo,
+			TokenEnabled: cfg.Media.Enabled,
+			TokenBackend: cfg.Media.GetBackendEndpoint(),
+		}),
+		credentials: credentials,
+	}
+
+	return a, func() { db.Close() }, nil
+}
+
+// runImportConfig membuat camera dari stream di config.json RTSPtoWeb
+func runImportConfig(args []string) error {
+	fs := flag.NewFlagSet("import-config", flag.ExitOnError)
+	file := fs.String("file", "rtsptoweb-config.json", "path to RTSPtoWeb config.json")
+	mode := fs.String("mode", models.ConfigImportSkip, "existing cameras: skip or update")
+	dryRun := fs.Bool("dry-run", false, "only report what would change")
+	mediaServerID := fs.String("media-server", "", "media server ID the streams are running on")
+	username := fs.String("user", "admin", "username recorded as created_by")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	fs.Parse(args)
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	rtspConfig, err := service.ParseRTSPtoWebConfig(data)
+	if err != nil {
+		return err
+	}
+
+	a, closeDB, err := newApp()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	user, err := a.userRepo.GetByUsername(*username)
+	if err != nil {
+		return fmt.Errorf("user %q not found: %w", *username, err)
+	}
+
+	report, err := a.rtspConfig.Import(rtspConfig, models.ConfigImportOptions{
+		Mode:          *mode,
+		DryRun:        *dryRun,
+		MediaServerID: *mediaServerID,
+		UserID:        user.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return printJSON(report)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STREAM ID\tNAME\tACTION\tHOST\tPORT\tCHANNELS\tLOCATION\tMESSAGE")
+	for _, item := range report.Items {
+		location := "ok"
+		if item.NeedsLocation {
+			location = "NEEDS LOCATION"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			item.StreamID, item.Name, item.Action, item.IPAddress, item.Port, item.Channels, location, item.Message)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d streams: %d created, %d updated, %d skipped, %d failed, %d need location",
+		report.TotalStreams, report.Created, report.Updated, report.Skipped, report.Failed, report.NeedsLocation)
+	if report.DryRun {
+		fmt.Print(" (dry run, nothing saved)")
+	}
+	fmt.Println()
+
+	if report.Failed > 0 {
+		log.Printf("%d streams failed to import", report.Failed)
+	}
+
+	return nil
+}
+
+// runGenerateConfig menulis config.json RTSPtoWeb dari tabel cameras
+func runGenerateConfig(args []string) error {
+	fs := flag.NewFlagSet("generate-config", flag.ExitOnError)
+	mediaServerID := fs.String("media-server", "", "only cameras placed on this media server")
+	out := fs.String("out", "", "output file (default stdout)")
+	fs.Parse(args)
+
+	a, closeDB, err := newApp()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	rtspConfig, err := a.rtspConfig.Generate(*mediaServerID)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return printJSON(rtspConfig)
+	}
+
+	data, err := json.MarshalIndent(rtspConfig, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// File berisi credential camera dan login RTSPtoWeb
+	if err := os.WriteFile(*out, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	log.Printf("✓ Wrote %d streams to %s", len(rtspConfig.Streams), *out)
+	return nil
+}
+
+// runDiffConfig membandingkan stream di database dengan stream di node
+func runDiffConfig(args []string) error {
+	fs := flag.NewFlagSet("diff-config", flag.ExitOnError)
+	mediaServerID := fs.String("media-server", "", "media server to compare (optional with a single node)")
+	asJSON := fs.Bool("json", false, "print the diff as JSON")
+	fs.Parse(args)
+
+	a, closeDB, err := newApp()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	diff, err := a.rtspConfig.Diff(context.Background(), *mediaServerID)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return printJSON(diff)
+	}
+
+	fmt.Printf("Media server %s (%s): %d streams in database, %d on node\n",
+		diff.MediaServerName, diff.MediaServerID, diff.DatabaseStreams, diff.NodeStreams)
+	if diff.InSync {
+		fmt.Println("In sync.")
+		return nil
+	}
+
+	for _, item := range diff.Items {
+		sign := map[string]string{
+			models.ConfigDiffAdded:   "+",
+			models.ConfigDiffRemoved: "-",
+			models.ConfigDiffChanged: "~",
+		}[item.Type]
+		fmt.Printf("%s %s %s\n", sign, item.StreamID, item.Name)
+		for _, change := range item.Changes {
+			fmt.Printf("    %s\n", change)
+		}
+	}
+
+	return nil
+}
+
+// runRotateCredentials mengenkripsi ulang credential camera dengan key aktif.
+// Jalankan setelah key baru ditambahkan di depan CREDENTIAL_KEYS, sebelum key
+// lama dihapus.
+func runRotateCredentials(args []string) error {
+	fs := flag.NewFlagSet("rotate-credentials", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	fs.Parse(args)
+
+	a, closeDB, err := newApp()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	report, err := a.credentials.RotateAll()
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return printJSON(report)
+	}
+
+	fmt.Printf("Active key %s: %d credentials to rotate, %d rotated, %d skipped, %d failed\n",
+		report.ActiveKeyID, report.Total, report.Rotated, report.Skipped, report.Failed)
+	if report.Skipped > 0 {
+		log.Printf("%d cameras have an RTSP URL that cannot be parsed, update their rtsp_url and channels", report.Skipped)
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d credentials could not be rotated, keep the old keys in CREDENTIAL_KEYS", report.Failed)
+	}
+
+	return nil
+}
+
+// runSetPermission memberi atau mencabut permission user. Berlaku setelah user
+// login ulang karena permission dibawa di JWT.
+func runSetPermission(name string, args []string, grant bool) error {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	username := fs.String("user", "", "username")
+	permission := fs.String("permission", models.PermissionCameraCredentials, "permission ("+strings.Join(models.KnownPermissions, ", ")+")")
+	fs.Parse(args)
+
+	if *username == "" {
+		return fmt.Errorf("-user is required")
+	}
+	if !models.HasPermission(models.KnownPermissions, *permission) {
+		return fmt.Errorf("unknown permission %q", *permission)
+	}
+
+	a, closeDB, err := newApp()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	user, err := a.userRepo.GetByUsername(*username)
+	if err != nil {
+		return fmt.Errorf("user %q not found: %w", *username, err)
+	}
+
+	permissions := []string{}
+	for _, p := range user.Permissions {
+		if p != *permission {
+			permissions = append(permissions, p)
+		}
+	}
+	if grant {
+		permissions = append(permissions, *permission)
+	}
+
+	if err := a.userRepo.UpdatePermissions(user.ID, permissions); err != nil {
+		return err
+	}
+
+	log.Printf("✓ %s now has permissions [%s] (effective on next login)", user.Username, strings.Join(permissions, ", "))
+	return nil
+}
+
+// printJSON menulis v ke stdout sebagai JSON
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
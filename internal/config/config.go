@@ -2,24 +2,32 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	App      AppConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	RTSP     RTSPConfig
-	CORS     CORSConfig
+	App         AppConfig
+	Database    DatabaseConfig
+	JWT         JWTConfig
+	RTSP        RTSPConfig
+	CORS        CORSConfig
+	Monitor     MonitorConfig
+	Media       MediaTokenConfig
+	Outbox      OutboxConfig
+	Credentials CredentialConfig
+	Trash       TrashConfig
 }
 
 type AppConfig struct {
-	Name string
-	Env  string
-	Port string
+	Name      string
+	Env       string
+	Port      string
+	PublicURL string
 }
 
 type DatabaseConfig struct {
@@ -43,12 +51,59 @@ type RTSPConfig struct {
 	PublicBaseURL string
 	Username      string
 	Password      string
+	ProxyEnabled  bool
+	HTTPDemo      bool // http_demo di config.json yang di-generate
+
+	// Resiliensi client RTSPtoWeb
+	Timeout          time.Duration
+	MaxRetries       int
+	RetryBackoff     time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// Timeout probe RTSP (OPTIONS + DESCRIBE) langsung ke kamera
+	ProbeTimeout time.Duration
 }
 
 type CORSConfig struct {
 	AllowedOrigins string
 }
 
+type MediaTokenConfig struct {
+	Enabled       bool
+	Secret        string
+	TTL           time.Duration
+	BackendSecret string
+	// URL token backend yang dipanggil RTSPtoWeb (dipakai saat generate config.json)
+	BackendURL string
+}
+
+type MonitorConfig struct {
+	Interval           time.Duration
+	ReconcileOnStartup bool
+	NodeHealthInterval time.Duration
+	FailoverThreshold  int
+}
+
+// OutboxConfig mengatur worker pengiriman operasi stream ke RTSPtoWeb
+type OutboxConfig struct {
+	Interval    time.Duration
+	MaxAttempts int
+}
+
+// CredentialConfig adalah keyring enkripsi credential RTSP camera dan password
+// node RTSPtoWeb (AES-GCM). Format "id:base64key[,id:base64key...]", key pertama
+// dipakai untuk enkripsi, key berikutnya hanya untuk membaca data lama selama rotasi.
+type CredentialConfig struct {
+	Keys string
+}
+
+// TrashConfig mengatur berapa lama camera yang dihapus disimpan di trash
+// sebelum di-purge otomatis. Retention 0 = tidak pernah di-purge.
+type TrashConfig struct {
+	Retention time.Duration
+}
+
 // Load membaca konfigurasi dari environment variables
 func Load() (*Config, error) {
 	// Load .env file jika ada
@@ -60,11 +115,33 @@ func Load() (*Config, error) {
 		jwtExp = 24 * time.Hour
 	}
 
+	// Parse stream monitor interval
+	monitorInterval, err := time.ParseDuration(getEnv("STREAM_MONITOR_INTERVAL", "30s"))
+	if err != nil || monitorInterval <= 0 {
+		monitorInterval = 30 * time.Second
+	}
+
+	// Parse media server health check interval
+	nodeHealthInterval, err := time.ParseDuration(getEnv("MEDIA_HEALTH_INTERVAL", "15s"))
+	if err != nil || nodeHealthInterval <= 0 {
+		nodeHealthInterval = 15 * time.Second
+	}
+
+	// Parse viewer token TTL
+	viewerTokenTTL, err := time.ParseDuration(getEnv("MEDIA_TOKEN_TTL", "15m"))
+	if err != nil || viewerTokenTTL <= 0 {
+		viewerTokenTTL = 15 * time.Minute
+	}
+
+	jwtSecret := getEnv("JWT_SECRET", "your-secret-key")
+
 	config := &Config{
 		App: AppConfig{
 			Name: getEnv("APP_NAME", "CCTV Monitoring API"),
 			Env:  getEnv("APP_ENV", "development"),
 			Port: getEnv("APP_PORT", "8080"),
+			// Base URL publik backend untuk URL playback, kosong = path relatif
+			PublicURL: getEnv("APP_PUBLIC_URL", ""),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -75,7 +152,7 @@ func Load() (*Config, error) {
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET", "your-secret-key"),
+			Secret:     jwtSecret,
 			Expiration: jwtExp,
 		},
 		RTSP: RTSPConfig{
@@ -85,10 +162,46 @@ func Load() (*Config, error) {
 			PublicBaseURL: getEnv("RTSP_TO_WEB_PUBLIC_URL", "http://localhost:8083"), // ← Pastikan ini
 			Username:      getEnv("RTSP_TO_WEB_USERNAME", ""),
 			Password:      getEnv("RTSP_TO_WEB_PASSWORD", ""),
+			ProxyEnabled:  getEnv("RTSP_TO_WEB_PROXY_ENABLED", "true") == "true",
+			HTTPDemo:      getEnv("RTSP_TO_WEB_HTTP_DEMO", "false") == "true",
+
+			Timeout:          getEnvAsDuration("RTSP_TO_WEB_TIMEOUT", 10*time.Second),
+			MaxRetries:       getEnvAsInt("RTSP_TO_WEB_MAX_RETRIES", 2),
+			RetryBackoff:     getEnvAsDuration("RTSP_TO_WEB_RETRY_BACKOFF", 200*time.Millisecond),
+			BreakerThreshold: getEnvAsInt("RTSP_TO_WEB_BREAKER_THRESHOLD", 5),
+			BreakerCooldown:  getEnvAsDuration("RTSP_TO_WEB_BREAKER_COOLDOWN", 30*time.Second),
+
+			ProbeTimeout: getEnvAsDuration("RTSP_PROBE_TIMEOUT", 5*time.Second),
 		},
 		CORS: CORSConfig{
 			AllowedOrigins: getEnv("CORS_ALLOWED_ORIGINS", "*"),
 		},
+		Media: MediaTokenConfig{
+			Enabled: getEnv("MEDIA_TOKEN_ENABLED", "false") == "true",
+			// Secret terpisah agar viewer token tidak bisa dipakai sebagai token login
+			Secret:        getEnv("MEDIA_TOKEN_SECRET", jwtSecret+".media"),
+			TTL:           viewerTokenTTL,
+			BackendSecret: getEnv("MEDIA_TOKEN_BACKEND_SECRET", ""),
+			BackendURL:    getEnv("MEDIA_TOKEN_BACKEND_URL", "http://backend:8080/api/v1/media/authorize"),
+		},
+		Outbox: OutboxConfig{
+			Interval: getEnvAsDuration("OUTBOX_INTERVAL", 5*time.Second),
+			// Setelah batas ini operasi ditandai FAILED dan camera OUT_OF_SYNC
+			MaxAttempts: getEnvAsInt("OUTBOX_MAX_ATTEMPTS", 10),
+		},
+		Trash: TrashConfig{
+			Retention: time.Duration(getEnvAsInt("CAMERA_TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour,
+		},
+		Monitor: MonitorConfig{
+			Interval:           monitorInterval,
+			ReconcileOnStartup: getEnv("STREAM_RECONCILE_ON_STARTUP", "true") == "true",
+			NodeHealthInterval: nodeHealthInterval,
+			// Jumlah health check gagal berturut-turut sebelum failover
+			FailoverThreshold: getEnvAsInt("MEDIA_FAILOVER_THRESHOLD", 3),
+		},
+		Credentials: CredentialConfig{
+			Keys: getEnv("CREDENTIAL_KEYS", ""),
+		},
 	}
 
 	return config, nil
@@ -102,6 +215,15 @@ func (c *DatabaseConfig) GetDSN() string {
 	)
 }
 
+// GetBackendEndpoint mengembalikan URL token backend untuk config.json RTSPtoWeb,
+// termasuk secret jika diset
+func (c *MediaTokenConfig) GetBackendEndpoint() string {
+	if c.BackendSecret == "" {
+		return c.BackendURL
+	}
+	return c.BackendURL + "?secret=" + url.QueryEscape(c.BackendSecret)
+}
+
 // getEnv membaca environment variable dengan fallback default value
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
@@ -110,3 +232,21 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvAsInt membaca environment variable sebagai int (tidak boleh negatif)
+func getEnvAsInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvAsDuration membaca environment variable sebagai time.Duration (mis. "10s")
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(getEnv(key, ""))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -145,6 +145,309 @@ func RunMigrations(db *sql.DB) error {
 		return fmt.Errorf("migration 4 failed: %w", err)
 	}
 
+	// Migration 5: Create camera channels table
+	migration5 := `
+		CREATE TABLE IF NOT EXISTS camera_channels (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			camera_id UUID NOT NULL REFERENCES cameras(id) ON DELETE CASCADE,
+			channel_index INTEGER NOT NULL,
+			label VARCHAR(100) NOT NULL,
+			rtsp_url TEXT NOT NULL,
+			role VARCHAR(20) NOT NULL DEFAULT 'MAIN',
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			UNIQUE (camera_id, channel_index)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_camera_channels_camera_id ON camera_channels(camera_id);
+
+		INSERT INTO camera_channels (camera_id, channel_index, label, rtsp_url, role)
+		SELECT c.id, 0, 'Main', c.rtsp_url, 'MAIN'
+		FROM cameras c
+		WHERE NOT EXISTS (SELECT 1 FROM camera_channels cc WHERE cc.camera_id = c.id);
+	`
+
+	if _, err := db.Exec(migration5); err != nil {
+		return fmt.Errorf("migration 5 failed: %w", err)
+	}
+
+	// Migration 6: Create media servers table
+	migration6 := `
+		CREATE TABLE IF NOT EXISTS media_servers (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			name VARCHAR(100) UNIQUE NOT NULL,
+			api_url TEXT NOT NULL,
+			public_url TEXT NOT NULL,
+			username VARCHAR(100),
+			password_enc TEXT,
+			max_streams INTEGER NOT NULL DEFAULT 100,
+			status VARCHAR(20) NOT NULL DEFAULT 'UNKNOWN',
+			last_checked TIMESTAMPTZ,
+			is_active BOOLEAN DEFAULT true,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW()
+		);
+
+		ALTER TABLE cameras ADD COLUMN IF NOT EXISTS media_server_id UUID REFERENCES media_servers(id) ON DELETE SET NULL;
+
+		CREATE INDEX IF NOT EXISTS idx_media_servers_is_active ON media_servers(is_active);
+		CREATE INDEX IF NOT EXISTS idx_cameras_media_server_id ON cameras(media_server_id);
+	`
+
+	if _, err := db.Exec(migration6); err != nil {
+		return fmt.Errorf("migration 6 failed: %w", err)
+	}
+
+	// Migration 7: Add stream sync status to cameras
+	migration7 := `
+		ALTER TABLE cameras ADD COLUMN IF NOT EXISTS sync_status VARCHAR(20) NOT NULL DEFAULT 'SYNCED';
+		ALTER TABLE cameras ADD COLUMN IF NOT EXISTS sync_error TEXT;
+
+		CREATE INDEX IF NOT EXISTS idx_cameras_sync_status ON cameras(sync_status);
+	`
+
+	if _, err := db.Exec(migration7); err != nil {
+		return fmt.Errorf("migration 7 failed: %w", err)
+	}
+
+	// Migration 8: Create stream outbox table
+	migration8 := `
+		CREATE TABLE IF NOT EXISTS stream_outbox (
+			id BIGSERIAL PRIMARY KEY,
+			camera_id UUID NOT NULL REFERENCES cameras(id) ON DELETE CASCADE,
+			operation VARCHAR(20) NOT NULL,
+			stream_id VARCHAR(255),
+			media_server_id UUID,
+			status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT,
+			next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			locked_until TIMESTAMPTZ,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			delivered_at TIMESTAMPTZ
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_stream_outbox_pending ON stream_outbox(next_attempt_at) WHERE status = 'PENDING';
+		CREATE INDEX IF NOT EXISTS idx_stream_outbox_camera_id ON stream_outbox(camera_id, id);
+	`
+
+	if _, err := db.Exec(migration8); err != nil {
+		return fmt.Errorf("migration 8 failed: %w", err)
+	}
+
+	// Migration 9: Add stream options to cameras
+	migration9 := `
+		ALTER TABLE cameras ADD COLUMN IF NOT EXISTS on_demand BOOLEAN NOT NULL DEFAULT true;
+		ALTER TABLE cameras ADD COLUMN IF NOT EXISTS audio BOOLEAN NOT NULL DEFAULT false;
+		ALTER TABLE cameras ADD COLUMN IF NOT EXISTS debug BOOLEAN NOT NULL DEFAULT false;
+		ALTER TABLE cameras ADD COLUMN IF NOT EXISTS insecure_skip_verify BOOLEAN NOT NULL DEFAULT false;
+	`
+
+	if _, err := db.Exec(migration9); err != nil {
+		return fmt.Errorf("migration 9 failed: %w", err)
+	}
+
+	// Migration 10: Create camera probes table
+	migration10 := `
+		CREATE TABLE IF NOT EXISTS camera_probes (
+			camera_id UUID PRIMARY KEY REFERENCES cameras(id) ON DELETE CASCADE,
+			success BOOLEAN NOT NULL,
+			error TEXT NOT NULL DEFAULT '',
+			server VARCHAR(255) NOT NULL DEFAULT '',
+			session_name VARCHAR(255) NOT NULL DEFAULT '',
+			video_codec VARCHAR(50) NOT NULL DEFAULT '',
+			video_profile VARCHAR(50) NOT NULL DEFAULT '',
+			width INTEGER NOT NULL DEFAULT 0,
+			height INTEGER NOT NULL DEFAULT 0,
+			fps DOUBLE PRECISION NOT NULL DEFAULT 0,
+			audio_codec VARCHAR(50) NOT NULL DEFAULT '',
+			audio_sample_rate INTEGER NOT NULL DEFAULT 0,
+			track_count INTEGER NOT NULL DEFAULT 0,
+			tracks JSONB NOT NULL DEFAULT '[]',
+			sdp TEXT NOT NULL DEFAULT '',
+			duration_ms BIGINT NOT NULL DEFAULT 0,
+			probed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+	`
+
+	if _, err := db.Exec(migration10); err != nil {
+		return fmt.Errorf("migration 10 failed: %w", err)
+	}
+
+	// Migration 11: Add camera credentials and user permissions
+	migration11 := `
+		ALTER TABLE cameras ADD COLUMN IF NOT EXISTS rtsp_username VARCHAR(255);
+		ALTER TABLE cameras ADD COLUMN IF NOT EXISTS rtsp_password_enc TEXT;
+
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS permissions TEXT[] NOT NULL DEFAULT '{}';
+	`
+
+	if _, err := db.Exec(migration11); err != nil {
+		return fmt.Errorf("migration 11 failed: %w", err)
+	}
+
+	// Migration 12: Index keyset pagination list camera
+	migration12 := `
+		CREATE INDEX IF NOT EXISTS idx_cameras_created_at_id ON cameras(created_at, id);
+	`
+
+	if _, err := db.Exec(migration12); err != nil {
+		return fmt.Errorf("migration 12 failed: %w", err)
+	}
+
+	// Migration 13: Versi camera untuk optimistic concurrency (ETag / If-Match)
+	migration13 := `
+		ALTER TABLE cameras ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+	`
+
+	if _, err := db.Exec(migration13); err != nil {
+		return fmt.Errorf("migration 13 failed: %w", err)
+	}
+
+	// Migration 14: Trash bin camera (siapa dan kapan dihapus). Log activity
+	// camera tetap disimpan setelah camera di-purge.
+	migration14 := `
+		ALTER TABLE cameras ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+		ALTER TABLE cameras ADD COLUMN IF NOT EXISTS deleted_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+		UPDATE cameras SET deleted_at = updated_at WHERE is_active = false AND deleted_at IS NULL;
+
+		CREATE INDEX IF NOT EXISTS idx_cameras_deleted_at ON cameras(deleted_at) WHERE is_active = false;
+
+		DO $$
+		BEGIN
+			IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'activity_logs_camera_id_fkey' AND confdeltype = 'c') THEN
+				ALTER TABLE activity_logs DROP CONSTRAINT activity_logs_camera_id_fkey;
+				ALTER TABLE activity_logs ADD CONSTRAINT activity_logs_camera_id_fkey
+					FOREIGN KEY (camera_id) REFERENCES cameras(id) ON DELETE SET NULL;
+			END IF;
+		END $$;
+	`
+
+	if _, err := db.Exec(migration14); err != nil {
+		return fmt.Errorf("migration 14 failed: %w", err)
+	}
+
+	// Migration 15: Riwayat perubahan camera per field (history dan revert)
+	migration15 := `
+		CREATE TABLE IF NOT EXISTS camera_revisions (
+			id BIGSERIAL PRIMARY KEY,
+			camera_id UUID NOT NULL REFERENCES cameras(id) ON DELETE CASCADE,
+			version INTEGER NOT NULL,
+			user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+			action VARCHAR(50) NOT NULL,
+			changes JSONB NOT NULL,
+			reverted_to BIGINT REFERENCES camera_revisions(id) ON DELETE SET NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_camera_revisions_camera_id ON camera_revisions(camera_id, id);
+	`
+
+	if _, err := db.Exec(migration15); err != nil {
+		return fmt.Errorf("migration 15 failed: %w", err)
+	}
+
+	// Migration 16: Hierarki lokasi site > building > floor > zone. Camera
+	// menunjuk ke zone, kolom building dan zone tetap diisi nama zone-nya.
+	migration16 := `
+		CREATE TABLE IF NOT EXISTS sites (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			name VARCHAR(255) NOT NULL,
+			address TEXT,
+			geometry JSONB,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS buildings (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			site_id UUID NOT NULL REFERENCES sites(id),
+			name VARCHAR(255) NOT NULL,
+			address TEXT,
+			geometry JSONB,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS floors (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			building_id UUID NOT NULL REFERENCES buildings(id),
+			name VARCHAR(100) NOT NULL,
+			level INTEGER NOT NULL DEFAULT 0,
+			geometry JSONB,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS zones (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			building_id UUID NOT NULL REFERENCES buildings(id),
+			floor_id UUID REFERENCES floors(id),
+			name VARCHAR(255) NOT NULL,
+			geometry JSONB,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW()
+		);
+
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_sites_name ON sites(LOWER(name));
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_buildings_site_name ON buildings(site_id, LOWER(name));
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_floors_building_name ON floors(building_id, LOWER(name));
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_zones_building_name ON zones(building_id, LOWER(name));
+		CREATE INDEX IF NOT EXISTS idx_zones_floor_id ON zones(floor_id);
+
+		ALTER TABLE cameras ADD COLUMN IF NOT EXISTS zone_id UUID REFERENCES zones(id) ON DELETE SET NULL;
+		CREATE INDEX IF NOT EXISTS idx_cameras_zone_id ON cameras(zone_id);
+
+		-- Data lama: building/zone teks dijadikan entity di bawah satu site default
+		DO $$
+		DECLARE
+			default_site UUID;
+		BEGIN
+			IF EXISTS (SELECT 1 FROM sites) THEN
+				RETURN;
+			END IF;
+
+			CREATE TEMP TABLE camera_locations ON COMMIT DROP AS
+			SELECT
+				id,
+				COALESCE(NULLIF(regexp_replace(TRIM(building), '\s+', ' ', 'g'), ''), 'Unassigned') AS building,
+				COALESCE(NULLIF(regexp_replace(TRIM(zone), '\s+', ' ', 'g'), ''), 'Unassigned') AS zone
+			FROM cameras
+			WHERE NULLIF(TRIM(building), '') IS NOT NULL OR NULLIF(TRIM(zone), '') IS NOT NULL;
+
+			IF NOT EXISTS (SELECT 1 FROM camera_locations) THEN
+				RETURN;
+			END IF;
+
+			INSERT INTO sites (name) VALUES ('Default Site') RETURNING id INTO default_site;
+
+			INSERT INTO buildings (site_id, name)
+			SELECT DISTINCT ON (LOWER(building)) default_site, building
+			FROM camera_locations
+			GROUP BY building
+			ORDER BY LOWER(building), COUNT(*) DESC, building;
+
+			INSERT INTO zones (building_id, name)
+			SELECT DISTINCT ON (b.id, LOWER(l.zone)) b.id, l.zone
+			FROM camera_locations l
+			JOIN buildings b ON LOWER(b.name) = LOWER(l.building)
+			GROUP BY b.id, l.zone
+			ORDER BY b.id, LOWER(l.zone), COUNT(*) DESC, l.zone;
+
+			UPDATE cameras c
+			SET zone_id = z.id, building = b.name, zone = z.name
+			FROM camera_locations l
+			JOIN buildings b ON LOWER(b.name) = LOWER(l.building)
+			JOIN zones z ON z.building_id = b.id AND LOWER(z.name) = LOWER(l.zone)
+			WHERE c.id = l.id;
+		END $$;
+	`
+
+	if _, err := db.Exec(migration16); err != nil {
+		return fmt.Errorf("migration 16 failed: %w", err)
+	}
+
 	log.Println("✓ Database migrations completed successfully")
 	return nil
 }
@@ -0,0 +1,118 @@
+package database_test
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"cctv-monitoring-backend/internal/database"
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/repository"
+)
+
+// testDB membuka TEST_DATABASE_URL dengan schema sementara yang dihapus di akhir
+// test. Test dilewati jika TEST_DATABASE_URL tidak diisi.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := database.Connect(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Satu koneksi agar search_path berlaku untuk semua query
+	db.SetMaxOpenConns(1)
+	schema := fmt.Sprintf("migration_test_%d", time.Now().UnixNano())
+	if _, err := db.Exec("CREATE SCHEMA " + schema + "; SET search_path TO " + schema + ", public"); err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		db.Exec("SET search_path TO public; DROP SCHEMA " + schema + " CASCADE")
+		db.Close()
+	})
+	return db
+}
+
+func insertCamera(t *testing.T, db *sql.DB, name, building, zone, status string) string {
+	t.Helper()
+
+	var id string
+	err := db.QueryRow(`
+		INSERT INTO cameras (name, rtsp_url, latitude, longitude, building, zone, status)
+		VALUES ($1, $2, -6.2, 106.8, NULLIF($3, ''), NULLIF($4, ''), $5)
+		RETURNING id
+	`, name, "rtsp://10.0.0.1/"+name, building, zone, status).Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// TestLocationMigration memastikan building/zone teks camera lama dijadikan
+// entity lokasi sekali saja, dan jumlah camera ONLINE di tree memakai status
+// yang ditulis stream monitor
+func TestLocationMigration(t *testing.T) {
+	db := testDB(t)
+
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ejaan berbeda untuk building yang sama, ejaan terbanyak menjadi nama entity
+	lobby := insertCamera(t, db, "lobby", "Gedung A", "Lobby", models.CameraStatusOnline)
+	insertCamera(t, db, "lobby-2", "Gedung A", "lobby", models.CameraStatusOffline)
+	insertCamera(t, db, "lobby-3", "  gedung   a ", "Lobby", models.CameraStatusOnline)
+	gate := insertCamera(t, db, "gate", "", "Gerbang", models.CameraStatusUnknown)
+	insertCamera(t, db, "spare", "", "", models.CameraStatusOnline)
+
+	// Data lama diproses saat migration berikutnya berjalan, lalu tidak lagi
+	for i := 0; i < 2; i++ {
+		if err := database.RunMigrations(db); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var sites, buildings, zones int
+	if err := db.QueryRow(`SELECT (SELECT COUNT(*) FROM sites), (SELECT COUNT(*) FROM buildings), (SELECT COUNT(*) FROM zones)`).Scan(&sites, &buildings, &zones); err != nil {
+		t.Fatal(err)
+	}
+	// Gedung A (Lobby) dan Unassigned (Gerbang)
+	if sites != 1 || buildings != 2 || zones != 2 {
+		t.Errorf("sites = %d, buildings = %d, zones = %d, want 1, 2, 2", sites, buildings, zones)
+	}
+
+	location := func(id string) (building, zone string, zoneID sql.NullString) {
+		t.Helper()
+		if err := db.QueryRow(`SELECT building, zone, zone_id FROM cameras WHERE id = $1`, id).Scan(&building, &zone, &zoneID); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	if building, zone, zoneID := location(lobby); building != "Gedung A" || zone != "Lobby" || !zoneID.Valid {
+		t.Errorf("lobby camera = %q, %q, %v", building, zone, zoneID)
+	}
+	if building, zone, zoneID := location(gate); building != "Unassigned" || zone != "Gerbang" || !zoneID.Valid {
+		t.Errorf("gate camera = %q, %q, %v", building, zone, zoneID)
+	}
+
+	counts, unassigned, err := repository.NewLocationRepository(db).CountCamerasByZone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, lobbyZone := location(lobby)
+	if counts[lobbyZone.String] != (models.LocationCounts{CameraCount: 3, OnlineCount: 2}) {
+		t.Errorf("lobby counts = %+v, want 3 cameras, 2 online", counts[lobbyZone.String])
+	}
+	if unassigned != (models.LocationCounts{CameraCount: 1, OnlineCount: 1}) {
+		t.Errorf("unassigned = %+v, want 1 camera, 1 online", unassigned)
+	}
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminHandler menangani HTTP requests untuk operasi administrasi
+type AdminHandler struct {
+	reconcileService service.ReconcileService
+}
+
+// NewAdminHandler membuat instance baru dari AdminHandler
+func NewAdminHandler(reconcileService service.ReconcileService) *AdminHandler {
+	return &AdminHandler{
+		reconcileService: reconcileService,
+	}
+}
+
+// ReconcileStreams handler untuk menyamakan stream RTSPtoWeb dengan tabel cameras
+func (h *AdminHandler) ReconcileStreams(c *fiber.Ctx) error {
+	dryRun := c.QueryBool("dry_run", false)
+
+	report, err := h.reconcileService.Reconcile(c.UserContext(), dryRun)
+	if err != nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeServiceUnavailable,
+				"Failed to reconcile streams",
+				err.Error(),
+			),
+		)
+	}
+
+	message := "Streams reconciled successfully"
+	if dryRun {
+		message = "Stream reconcile dry run completed"
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    report,
+	})
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"cctv-monitoring-backend/internal/models"
 	"cctv-monitoring-backend/internal/service"
+	"cctv-monitoring-backend/internal/validation"
 	"errors"
 	"strings"
 
@@ -147,13 +148,9 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	}
 
 	// Validasi input
-	if req.Username == "" || req.Email == "" || req.Password == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(
-			models.NewErrorResponse(
-				models.ErrCodeMissingFields,
-				"Username, email, and password are required",
-			),
-		)
+	req.Role = strings.ToLower(req.Role)
+	if errs := validation.Struct(&req); len(errs) > 0 {
+		return validationErrorResponse(c, errs)
 	}
 
 	// Set default role jika tidak diisi
@@ -1,10 +1,19 @@
 package handler
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"strconv"
+	"strings"
+	"time"
 
 	"cctv-monitoring-backend/internal/models"
 	"cctv-monitoring-backend/internal/service"
+	"cctv-monitoring-backend/internal/utils"
+	"cctv-monitoring-backend/internal/validation"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -35,22 +44,25 @@ func (h *CameraHandler) Create(c *fiber.Ctx) error {
 		)
 	}
 
-	// Validasi input
-	if req.Name == "" || req.RTSPUrl == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(
-			models.NewErrorResponse(
-				models.ErrCodeMissingFields,
-				"Camera name and RTSP URL are required",
-			),
-		)
+	// Validasi input (rtsp_url boleh kosong jika channels diisi)
+	req.Normalize()
+	if errs := validation.Struct(&req); len(errs) > 0 {
+		return validationErrorResponse(c, errs)
 	}
 
 	// Ambil user ID dari context
 	userID := c.Locals("user_id").(string)
 
 	// Proses create camera
-	camera, err := h.cameraService.Create(&req, userID)
+	camera, err := h.cameraService.Create(c.UserContext(), &req, userID)
 	if err != nil {
+		if errors.Is(err, service.ErrCredentialConflict) || errors.Is(err, service.ErrUnparseableRTSPURL) {
+			return credentialConflictResponse(c, err)
+		}
+		if errors.Is(err, service.ErrZoneNotFound) {
+			return zoneNotFoundResponse(c)
+		}
+
 		return c.Status(fiber.StatusBadRequest).JSON(
 			models.NewErrorResponse(
 				models.ErrCodeInternalError,
@@ -60,66 +72,146 @@ func (h *CameraHandler) Create(c *fiber.Ctx) error {
 		)
 	}
 
+	h.revealCredentials(c, camera)
+
+	// Camera tersimpan, stream didaftarkan ulang oleh outbox worker
+	message := "Camera created successfully"
+	if camera.StreamSync != nil && !camera.StreamSync.Applied {
+		message = "Camera created, stream registration queued for retry"
+	}
+
+	c.Set(fiber.HeaderETag, camera.ETag())
 	return c.Status(fiber.StatusCreated).JSON(models.APIResponse{
 		Success: true,
-		Message: "Camera created successfully",
+		Message: message,
 		Data:    camera,
 	})
 }
 
-// GetByID handler untuk mengambil camera berdasarkan ID
-func (h *CameraHandler) GetByID(c *fiber.Ctx) error {
-	id := c.Params("id")
-
-	camera, err := h.cameraService.GetByID(id)
+// Import handler untuk bulk import camera dari CSV atau array JSON. Format
+// diambil dari ?format=, Content-Type atau ekstensi file upload (field "file").
+// Semua baris divalidasi dulu; camera hanya dibuat jika semua baris valid.
+func (h *CameraHandler) Import(c *fiber.Ctx) error {
+	data, format, err := readImportBody(c)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(
+		return c.Status(fiber.StatusBadRequest).JSON(
 			models.NewErrorResponse(
-				models.ErrCodeNotFound,
-				"Camera not found",
+				models.ErrCodeValidationFailed,
+				"Invalid import file",
 				err.Error(),
 			),
 		)
 	}
 
-	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
-		Success: true,
-		Message: "Camera retrieved successfully",
-		Data:    camera,
-	})
-}
-
-// GetAll handler untuk mengambil semua camera dengan pagination
-func (h *CameraHandler) GetAll(c *fiber.Ctx) error {
-	// Parse query parameters
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	pageSize, _ := strconv.Atoi(c.Query("page_size", "10"))
+	var inputs []models.CameraImportInput
+	if format == models.CameraImportCSV {
+		inputs, err = service.ParseCameraImportCSV(data)
+	} else {
+		inputs, err = service.ParseCameraImportJSON(data)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeValidationFailed,
+				"Invalid import file",
+				err.Error(),
+			),
+		)
+	}
 
-	cameras, meta, err := h.cameraService.GetAll(page, pageSize)
+	dryRun := c.QueryBool("dry_run", false)
+	report, err := h.cameraService.Import(c.UserContext(), inputs, c.Locals("user_id").(string), dryRun)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(
 			models.NewErrorResponse(
 				models.ErrCodeInternalError,
-				"Failed to retrieve cameras",
+				"Failed to import cameras",
 				err.Error(),
 			),
 		)
 	}
 
-	return c.Status(fiber.StatusOK).JSON(models.PaginatedResponse{
-		Success:    true,
-		Message:    "Cameras retrieved successfully",
-		Data:       cameras,
-		Pagination: *meta,
+	switch {
+	case report.Total == 0:
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeValidationFailed,
+				"Import file contains no cameras",
+			),
+		)
+	case dryRun:
+		message := "Camera import dry run completed, all rows are valid"
+		if report.Invalid > 0 {
+			message = fmt.Sprintf("Camera import dry run completed, %d of %d rows are invalid", report.Invalid, report.Total)
+		}
+		return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+			Success: report.Invalid == 0,
+			Message: message,
+			Data:    report,
+		})
+	case report.Invalid > 0:
+		// Tidak ada yang disimpan, report berisi error per baris
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(models.APIResponse{
+			Success: false,
+			Message: fmt.Sprintf("%d of %d rows are invalid, no cameras were created", report.Invalid, report.Total),
+			Data:    report,
+		})
+	}
+
+	message := fmt.Sprintf("%d cameras imported successfully", report.Created)
+	if report.StreamsQueued > 0 {
+		message = fmt.Sprintf("%d cameras imported, %d stream registrations queued for retry", report.Created, report.StreamsQueued)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    report,
 	})
 }
 
-// Update handler untuk mengupdate camera
-func (h *CameraHandler) Update(c *fiber.Ctx) error {
-	id := c.Params("id")
+// readImportBody mengambil isi file import dari upload multipart atau body
+// request, beserta formatnya (csv atau json)
+func readImportBody(c *fiber.Ctx) ([]byte, string, error) {
+	format := strings.ToLower(c.Query("format"))
+	data := c.Body()
 
-	// Parse request body
-	var req models.UpdateCameraRequest
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		defer f.Close()
+
+		if data, err = io.ReadAll(f); err != nil {
+			return nil, "", err
+		}
+		if format == "" && strings.HasSuffix(strings.ToLower(file.Filename), ".csv") {
+			format = models.CameraImportCSV
+		}
+	} else if format == "" && strings.Contains(strings.ToLower(c.Get(fiber.HeaderContentType)), "csv") {
+		format = models.CameraImportCSV
+	}
+
+	switch format {
+	case "":
+		format = models.CameraImportJSON
+	case models.CameraImportCSV, models.CameraImportJSON:
+	default:
+		return nil, "", fmt.Errorf("format must be csv or json")
+	}
+
+	if len(data) == 0 {
+		return nil, "", fmt.Errorf("request body is empty")
+	}
+
+	return data, format, nil
+}
+
+// Bulk handler untuk menjalankan satu aksi ke banyak camera (berdasarkan ids
+// atau filter) dengan hasil per camera
+func (h *CameraHandler) Bulk(c *fiber.Ctx) error {
+	var req models.BulkCameraRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(
 			models.NewErrorResponse(
@@ -130,80 +222,79 @@ func (h *CameraHandler) Update(c *fiber.Ctx) error {
 		)
 	}
 
-	// Proses update
-	camera, err := h.cameraService.Update(id, &req)
+	report, err := h.cameraService.Bulk(c.UserContext(), &req, c.Locals("user_id").(string))
 	if err != nil {
-		// Check if camera not found
-		if err.Error() == "camera not found" {
-			return c.Status(fiber.StatusNotFound).JSON(
+		if errors.Is(err, service.ErrInvalidBulkRequest) || errors.Is(err, service.ErrBulkTooManyCameras) {
+			return c.Status(fiber.StatusBadRequest).JSON(
 				models.NewErrorResponse(
-					models.ErrCodeNotFound,
-					"Camera not found",
+					models.ErrCodeValidationFailed,
+					"Invalid bulk request",
 					err.Error(),
 				),
 			)
 		}
 
-		return c.Status(fiber.StatusBadRequest).JSON(
+		return c.Status(fiber.StatusInternalServerError).JSON(
 			models.NewErrorResponse(
 				models.ErrCodeInternalError,
-				"Failed to update camera",
+				"Failed to apply bulk action",
 				err.Error(),
 			),
 		)
 	}
 
+	message := fmt.Sprintf("Bulk %s applied to %d cameras", report.Action, report.Succeeded)
+	if report.Failed > 0 {
+		message = fmt.Sprintf("Bulk %s applied to %d of %d cameras", report.Action, report.Succeeded, report.Total)
+	}
+	if report.StreamsQueued > 0 {
+		message += fmt.Sprintf(", %d stream operations queued for retry", report.StreamsQueued)
+	}
+
 	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
-		Success: true,
-		Message: "Camera updated successfully",
-		Data:    camera,
+		Success: report.Failed == 0,
+		Message: message,
+		Data:    report,
 	})
 }
 
-// Delete handler untuk menghapus camera
-func (h *CameraHandler) Delete(c *fiber.Ctx) error {
+// GetByID handler untuk mengambil camera berdasarkan ID
+func (h *CameraHandler) GetByID(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	if err := h.cameraService.Delete(id); err != nil {
-		// Check if camera not found
-		if err.Error() == "camera not found" {
-			return c.Status(fiber.StatusNotFound).JSON(
-				models.NewErrorResponse(
-					models.ErrCodeNotFound,
-					"Camera not found",
-					err.Error(),
-				),
-			)
-		}
-
-		return c.Status(fiber.StatusBadRequest).JSON(
+	camera, err := h.cameraService.GetByID(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
 			models.NewErrorResponse(
-				models.ErrCodeInternalError,
-				"Failed to delete camera",
+				models.ErrCodeNotFound,
+				"Camera not found",
 				err.Error(),
 			),
 		)
 	}
 
+	h.revealCredentials(c, camera)
+
+	c.Set(fiber.HeaderETag, camera.ETag())
 	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
 		Success: true,
-		Message: "Camera deleted successfully",
+		Message: "Camera retrieved successfully",
+		Data:    camera,
 	})
 }
 
-// GetByZone handler untuk mengambil camera berdasarkan zone
-func (h *CameraHandler) GetByZone(c *fiber.Ctx) error {
-	zone := c.Query("zone")
-	if zone == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(
-			models.NewErrorResponse(
-				models.ErrCodeMissingFields,
-				"Zone parameter is required",
-			),
-		)
+// GetAll handler untuk mengambil camera dengan filter, sort dan pagination
+func (h *CameraHandler) GetAll(c *fiber.Ctx) error {
+	filter, errs := parseCameraFilter(c)
+	if len(errs) > 0 {
+		return validationErrorResponse(c, errs)
 	}
 
-	cameras, err := h.cameraService.GetByZone(zone)
+	if filter.Keyset {
+		return h.getByCursor(c, filter)
+	}
+
+	cameras, meta, err := h.cameraService.GetAll(filter)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(
 			models.NewErrorResponse(
@@ -214,75 +305,205 @@ func (h *CameraHandler) GetByZone(c *fiber.Ctx) error {
 		)
 	}
 
-	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
-		Success: true,
-		Message: "Cameras retrieved successfully",
-		Data:    cameras,
+	h.revealCredentials(c, cameras...)
+
+	data, err := models.ProjectCameras(cameras, filter.Fields)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.PaginatedResponse{
+		Success:    true,
+		Message:    "Cameras retrieved successfully",
+		Data:       data,
+		Pagination: *meta,
 	})
 }
 
-// GetNearby handler untuk mengambil camera dalam radius tertentu
-func (h *CameraHandler) GetNearby(c *fiber.Ctx) error {
-	// Parse query parameters
-	lat, err := strconv.ParseFloat(c.Query("lat"), 64)
+// getByCursor mengambil list camera dengan keyset pagination. next_cursor
+// dikirim kembali sebagai ?cursor= untuk halaman berikutnya.
+func (h *CameraHandler) getByCursor(c *fiber.Ctx, filter *models.CameraFilter) error {
+	cameras, meta, err := h.cameraService.GetByCursor(filter)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(
+		return c.Status(fiber.StatusInternalServerError).JSON(
 			models.NewErrorResponse(
-				models.ErrCodeValidationFailed,
-				"Invalid latitude parameter",
+				models.ErrCodeInternalError,
+				"Failed to retrieve cameras",
 				err.Error(),
 			),
 		)
 	}
 
-	lng, err := strconv.ParseFloat(c.Query("lng"), 64)
+	h.revealCredentials(c, cameras...)
+
+	data, err := models.ProjectCameras(cameras, filter.Fields)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.CursorPaginatedResponse{
+		Success:    true,
+		Message:    "Cameras retrieved successfully",
+		Data:       data,
+		Pagination: *meta,
+	})
+}
+
+// Export handler untuk export camera ke CSV, GeoJSON atau KML dengan filter
+// dan sort yang sama seperti GET /cameras. Camera di-stream langsung dari
+// database tanpa pagination; credential RTSP tidak ikut diexport.
+func (h *CameraHandler) Export(c *fiber.Ctx) error {
+	filter, errs := parseCameraFilter(c)
+	if len(errs) > 0 {
+		return validationErrorResponse(c, errs)
+	}
+
+	format := strings.ToLower(c.Query("format", models.CameraExportCSV))
+	export, err := h.cameraService.Export(filter, format)
+	if err != nil {
+		if errors.Is(err, service.ErrUnsupportedExportFormat) {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeValidationFailed,
+					"Invalid query parameter",
+					err.Error(),
+				),
+			)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(
 			models.NewErrorResponse(
-				models.ErrCodeValidationFailed,
-				"Invalid longitude parameter",
+				models.ErrCodeInternalError,
+				"Failed to export cameras",
 				err.Error(),
 			),
 		)
 	}
 
-	radius, err := strconv.ParseFloat(c.Query("radius", "5"), 64)
-	if err != nil {
+	c.Attachment(export.Filename(time.Now()))
+	c.Set(fiber.HeaderContentType, export.ContentType)
+
+	// Status sudah terkirim saat stream ditulis, error di tengah export hanya
+	// bisa dicatat (file di sisi client terpotong)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := export.Write(w); err != nil {
+			log.Printf("Error exporting cameras: %v", err)
+		}
+	})
+
+	return nil
+}
+
+// Update handler untuk mengupdate camera
+func (h *CameraHandler) Update(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// Parse request body
+	var req models.UpdateCameraRequest
+	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(
 			models.NewErrorResponse(
 				models.ErrCodeValidationFailed,
-				"Invalid radius parameter",
+				"Invalid request body",
 				err.Error(),
 			),
 		)
 	}
 
-	cameras, err := h.cameraService.GetNearby(lat, lng, radius)
+	req.Normalize()
+	if errs := validation.Struct(&req); len(errs) > 0 {
+		return validationErrorResponse(c, errs)
+	}
+
+	// If-Match opsional untuk PUT
+	version, err := models.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(
+		return versionConflictResponse(c, err)
+	}
+
+	// Proses update
+	camera, err := h.cameraService.Update(c.UserContext(), id, &req, version, c.Locals("user_id").(string))
+	if err != nil {
+		// Check if camera not found
+		if errors.Is(err, service.ErrCameraNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeNotFound,
+					"Camera not found",
+					err.Error(),
+				),
+			)
+		}
+		if errors.Is(err, service.ErrCredentialConflict) || errors.Is(err, service.ErrUnparseableRTSPURL) {
+			return credentialConflictResponse(c, err)
+		}
+		if errors.Is(err, service.ErrVersionConflict) {
+			return versionConflictResponse(c, err)
+		}
+		if errors.Is(err, service.ErrZoneNotFound) {
+			return zoneNotFoundResponse(c)
+		}
+
+		return c.Status(fiber.StatusBadRequest).JSON(
 			models.NewErrorResponse(
 				models.ErrCodeInternalError,
-				"Failed to retrieve nearby cameras",
+				"Failed to update camera",
 				err.Error(),
 			),
 		)
 	}
 
+	h.revealCredentials(c, camera)
+
+	// Data tetap tersimpan, tapi stream di RTSPtoWeb masih memakai konfigurasi lama
+	message := "Camera updated successfully"
+	if camera.StreamSync != nil && !camera.StreamSync.Applied {
+		message = "Camera updated, stream change queued for retry"
+	}
+
+	c.Set(fiber.HeaderETag, camera.ETag())
 	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
 		Success: true,
-		Message: "Nearby cameras retrieved successfully",
-		Data:    cameras,
+		Message: message,
+		Data:    camera,
 	})
 }
 
-// StartStream handler untuk memulai streaming camera
-func (h *CameraHandler) StartStream(c *fiber.Ctx) error {
+// Patch handler untuk update camera dengan JSON Merge Patch (RFC 7396): key
+// yang tidak ada tidak diubah dan null menghapus field. If-Match wajib diisi
+// dengan ETag dari GET /cameras/:id agar perubahan operator lain tidak tertimpa.
+func (h *CameraHandler) Patch(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	camera, err := h.cameraService.StartStream(id)
+	ifMatch := c.Get(fiber.HeaderIfMatch)
+	if ifMatch == "" {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(
+			models.NewErrorResponse(
+				models.ErrCodePreconditionRequired,
+				"If-Match header is required",
+				"send the ETag from GET /cameras/:id, or * to overwrite unconditionally",
+			),
+		)
+	}
+	version, err := models.ParseIfMatch(ifMatch)
+	if err != nil {
+		return versionConflictResponse(c, err)
+	}
+
+	patch, err := models.ParseCameraPatch(c.Body())
 	if err != nil {
-		// Check if camera not found
-		if err.Error() == "camera not found" {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeValidationFailed,
+				"Invalid request body",
+				err.Error(),
+			),
+		)
+	}
+
+	camera, err := h.cameraService.Patch(c.UserContext(), id, patch, version, c.Locals("user_id").(string))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrCameraNotFound):
 			return c.Status(fiber.StatusNotFound).JSON(
 				models.NewErrorResponse(
 					models.ErrCodeNotFound,
@@ -290,31 +511,47 @@ func (h *CameraHandler) StartStream(c *fiber.Ctx) error {
 					err.Error(),
 				),
 			)
+		case errors.Is(err, service.ErrVersionConflict):
+			return versionConflictResponse(c, err)
+		case errors.Is(err, service.ErrCredentialConflict):
+			return credentialConflictResponse(c, err)
+		}
+		var errs validation.Errors
+		if errors.As(err, &errs) {
+			return validationErrorResponse(c, errs)
 		}
 
-		return c.Status(fiber.StatusBadRequest).JSON(
+		return c.Status(fiber.StatusInternalServerError).JSON(
 			models.NewErrorResponse(
-				models.ErrCodeServiceUnavailable,
-				"Failed to start stream",
+				models.ErrCodeInternalError,
+				"Failed to update camera",
 				err.Error(),
 			),
 		)
 	}
 
+	h.revealCredentials(c, camera)
+
+	message := "Camera updated successfully"
+	if camera.StreamSync != nil && !camera.StreamSync.Applied {
+		message = "Camera updated, stream change queued for retry"
+	}
+
+	c.Set(fiber.HeaderETag, camera.ETag())
 	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
 		Success: true,
-		Message: "Stream started successfully",
+		Message: message,
 		Data:    camera,
 	})
 }
 
-// StopStream handler untuk menghentikan streaming camera
-func (h *CameraHandler) StopStream(c *fiber.Ctx) error {
+// Delete handler untuk memindahkan camera ke trash
+func (h *CameraHandler) Delete(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	if err := h.cameraService.StopStream(id); err != nil {
+	if err := h.cameraService.Delete(c.UserContext(), id, c.Locals("user_id").(string)); err != nil {
 		// Check if camera not found
-		if err.Error() == "camera not found" {
+		if errors.Is(err, service.ErrCameraNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(
 				models.NewErrorResponse(
 					models.ErrCodeNotFound,
@@ -326,8 +563,8 @@ func (h *CameraHandler) StopStream(c *fiber.Ctx) error {
 
 		return c.Status(fiber.StatusBadRequest).JSON(
 			models.NewErrorResponse(
-				models.ErrCodeServiceUnavailable,
-				"Failed to stop stream",
+				models.ErrCodeInternalError,
+				"Failed to delete camera",
 				err.Error(),
 			),
 		)
@@ -335,6 +572,873 @@ func (h *CameraHandler) StopStream(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
 		Success: true,
-		Message: "Stream stopped successfully",
+		Message: "Camera deleted successfully",
 	})
 }
+
+// GetTrash handler untuk mengambil camera yang dihapus beserta waktu purge-nya
+func (h *CameraHandler) GetTrash(c *fiber.Ctx) error {
+	page, pageSize, errs := parsePagination(c)
+	if len(errs) > 0 {
+		return validationErrorResponse(c, errs)
+	}
+
+	cameras, meta, err := h.cameraService.GetTrash(page, pageSize)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeInternalError,
+				"Failed to retrieve deleted cameras",
+				err.Error(),
+			),
+		)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.PaginatedResponse{
+		Success:    true,
+		Message:    "Deleted cameras retrieved successfully",
+		Data:       cameras,
+		Pagination: *meta,
+	})
+}
+
+// Restore handler untuk mengaktifkan kembali camera dari trash. Stream camera
+// didaftarkan ulang ke RTSPtoWeb.
+func (h *CameraHandler) Restore(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	camera, err := h.cameraService.Restore(c.UserContext(), id, c.Locals("user_id").(string))
+	if err != nil {
+		if errors.Is(err, service.ErrCameraNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeNotFound,
+					"Camera not found in trash",
+					err.Error(),
+				),
+			)
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeInternalError,
+				"Failed to restore camera",
+				err.Error(),
+			),
+		)
+	}
+
+	h.revealCredentials(c, camera)
+
+	message := "Camera restored successfully"
+	if camera.StreamSync != nil && !camera.StreamSync.Applied {
+		message = "Camera restored, stream registration queued for retry"
+	}
+
+	c.Set(fiber.HeaderETag, camera.ETag())
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    camera,
+	})
+}
+
+// Purge handler untuk menghapus permanen camera dari trash
+func (h *CameraHandler) Purge(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if err := h.cameraService.Purge(id, c.Locals("user_id").(string)); err != nil {
+		switch {
+		case errors.Is(err, service.ErrCameraNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeNotFound,
+					"Camera not found in trash",
+					err.Error(),
+				),
+			)
+		case errors.Is(err, service.ErrStreamRemovalPending):
+			return c.Status(fiber.StatusConflict).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeStreamRemovalPending,
+					"Camera stream is still being removed",
+					err.Error(),
+				),
+			)
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeInternalError,
+				"Failed to delete camera permanently",
+				err.Error(),
+			),
+		)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: "Camera deleted permanently",
+	})
+}
+
+// GetHistory handler untuk mengambil riwayat perubahan camera per field,
+// yang terbaru lebih dulu
+func (h *CameraHandler) GetHistory(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	page, pageSize, errs := parsePagination(c)
+	if len(errs) > 0 {
+		return validationErrorResponse(c, errs)
+	}
+
+	revisions, meta, err := h.cameraService.GetHistory(id, page, pageSize)
+	if err != nil {
+		if errors.Is(err, service.ErrCameraNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeNotFound,
+					"Camera not found",
+					err.Error(),
+				),
+			)
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeInternalError,
+				"Failed to retrieve camera history",
+				err.Error(),
+			),
+		)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.PaginatedResponse{
+		Success:    true,
+		Message:    "Camera history retrieved successfully",
+		Data:       revisions,
+		Pagination: *meta,
+	})
+}
+
+// GetRevision handler untuk mengambil satu revision beserta diff camera saat
+// ini terhadap revision tersebut (perubahan yang dilakukan jika di-revert)
+func (h *CameraHandler) GetRevision(c *fiber.Ctx) error {
+	revisionID, err := strconv.ParseInt(c.Params("revision"), 10, 64)
+	if err != nil || revisionID < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeValidationFailed,
+				"Invalid revision ID",
+			),
+		)
+	}
+
+	plan, err := h.cameraService.GetRevertPlan(c.Params("id"), revisionID)
+	if err != nil {
+		if errors.Is(err, service.ErrCameraNotFound) || errors.Is(err, service.ErrRevisionNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeNotFound,
+					"Revision not found",
+					err.Error(),
+				),
+			)
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeInternalError,
+				"Failed to retrieve camera revision",
+				err.Error(),
+			),
+		)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: "Camera revision retrieved successfully",
+		Data:    plan,
+	})
+}
+
+// Revert handler untuk mengembalikan camera ke state setelah sebuah revision.
+// If-Match opsional seperti PUT.
+func (h *CameraHandler) Revert(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	revisionID, err := strconv.ParseInt(c.Params("revision"), 10, 64)
+	if err != nil || revisionID < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeValidationFailed,
+				"Invalid revision ID",
+			),
+		)
+	}
+
+	version, err := models.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return versionConflictResponse(c, err)
+	}
+
+	camera, err := h.cameraService.Revert(c.UserContext(), id, revisionID, version, c.Locals("user_id").(string))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrCameraNotFound), errors.Is(err, service.ErrRevisionNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeNotFound,
+					"Revision not found",
+					err.Error(),
+				),
+			)
+		case errors.Is(err, service.ErrVersionConflict):
+			return versionConflictResponse(c, err)
+		case errors.Is(err, service.ErrCredentialConflict):
+			return credentialConflictResponse(c, err)
+		}
+		var errs validation.Errors
+		if errors.As(err, &errs) {
+			// Revision valid saat dibuat tetapi tidak lagi, misalnya zone_id
+			// sudah dihapus
+			response := models.NewValidationErrorResponse(errs)
+			response.Message = "Revision cannot be applied to the current camera"
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(response)
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeInternalError,
+				"Failed to revert camera",
+				err.Error(),
+			),
+		)
+	}
+
+	h.revealCredentials(c, camera)
+
+	message := fmt.Sprintf("Camera reverted to revision %d", revisionID)
+	if camera.StreamSync != nil && !camera.StreamSync.Applied {
+		message += ", stream change queued for retry"
+	}
+
+	c.Set(fiber.HeaderETag, camera.ETag())
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    camera,
+	})
+}
+
+// GetByZone handler untuk mengambil camera berdasarkan zone
+func (h *CameraHandler) GetByZone(c *fiber.Ctx) error {
+	zone := c.Query("zone")
+	if zone == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeMissingFields,
+				"Zone parameter is required",
+			),
+		)
+	}
+
+	cameras, err := h.cameraService.GetByZone(zone)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeInternalError,
+				"Failed to retrieve cameras",
+				err.Error(),
+			),
+		)
+	}
+
+	h.revealCredentials(c, cameras...)
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: "Cameras retrieved successfully",
+		Data:    cameras,
+	})
+}
+
+// GetNearby handler untuk mengambil camera dalam radius tertentu
+func (h *CameraHandler) GetNearby(c *fiber.Ctx) error {
+	// Parse query parameters, radius dalam km
+	var errs validation.Errors
+	lat := floatQuery(c, "lat", 0, -90, 90, true, &errs)
+	lng := floatQuery(c, "lng", 0, -180, 180, true, &errs)
+	radius := floatQuery(c, "radius", 5, 0.01, 1000, false, &errs)
+	if len(errs) > 0 {
+		return validationErrorResponse(c, errs)
+	}
+
+	cameras, err := h.cameraService.GetNearby(lat, lng, radius)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeInternalError,
+				"Failed to retrieve nearby cameras",
+				err.Error(),
+			),
+		)
+	}
+
+	h.revealCredentials(c, cameras...)
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: "Nearby cameras retrieved successfully",
+		Data:    cameras,
+	})
+}
+
+// StartStream handler untuk memulai streaming camera
+func (h *CameraHandler) StartStream(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	camera, err := h.cameraService.StartStream(c.UserContext(), id)
+	if err != nil {
+		return streamErrorResponse(c, err, "Failed to start stream")
+	}
+	h.revealCredentials(c, camera)
+
+	// RTSPtoWeb belum menerima stream, outbox worker akan mencoba lagi
+	if camera.StreamSync != nil && !camera.StreamSync.Applied {
+		return c.Status(fiber.StatusAccepted).JSON(models.APIResponse{
+			Success: true,
+			Message: "Stream start queued for retry",
+			Data:    camera,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: "Stream started successfully",
+		Data:    camera,
+	})
+}
+
+// StopStream handler untuk menghentikan streaming camera
+func (h *CameraHandler) StopStream(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	camera, err := h.cameraService.StopStream(c.UserContext(), id)
+	if err != nil {
+		return streamErrorResponse(c, err, "Failed to stop stream")
+	}
+	h.revealCredentials(c, camera)
+
+	if camera.StreamSync != nil && !camera.StreamSync.Applied {
+		return c.Status(fiber.StatusAccepted).JSON(models.APIResponse{
+			Success: true,
+			Message: "Stream stop queued for retry",
+			Data:    camera,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: "Stream stopped successfully",
+		Data:    camera,
+	})
+}
+
+// GetStreamDeliveries handler untuk melihat status pengiriman operasi stream
+// camera ke RTSPtoWeb (outbox)
+func (h *CameraHandler) GetStreamDeliveries(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	deliveries, err := h.cameraService.GetStreamDeliveries(id)
+	if err != nil {
+		if errors.Is(err, service.ErrCameraNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeNotFound,
+					"Camera not found",
+					err.Error(),
+				),
+			)
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeInternalError,
+				"Failed to retrieve stream deliveries",
+				err.Error(),
+			),
+		)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: "Stream deliveries retrieved successfully",
+		Data:    deliveries,
+	})
+}
+
+// WebRTCOffer handler untuk signaling WebRTC: menerima SDP offer dan mengembalikan SDP answer
+func (h *CameraHandler) WebRTCOffer(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.WebRTCOfferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeValidationFailed,
+				"Invalid request body",
+				err.Error(),
+			),
+		)
+	}
+
+	if req.SDP == "" || (req.Type != "" && req.Type != "offer") {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeMissingFields,
+				"SDP offer is required",
+			),
+		)
+	}
+
+	// Channel bisa dikirim lewat body atau query (?channel=1)
+	if req.Channel == nil {
+		channel, err := parseChannelQuery(c)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeValidationFailed,
+					"Invalid channel parameter",
+					err.Error(),
+				),
+			)
+		}
+		req.Channel = channel
+	}
+
+	answer, err := h.cameraService.WebRTCOffer(c.UserContext(), id, req.Channel, req.SDP)
+	if err != nil {
+		return streamErrorResponse(c, err, "Failed to negotiate WebRTC session")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: "WebRTC answer created successfully",
+		Data:    answer,
+	})
+}
+
+// ProxyHLS handler untuk meneruskan playlist dan segment HLS dari RTSPtoWeb
+func (h *CameraHandler) ProxyHLS(c *fiber.Ctx) error {
+	channel, err := parseChannelQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeValidationFailed,
+				"Invalid channel parameter",
+				err.Error(),
+			),
+		)
+	}
+
+	// viewer_token diisi oleh MediaAuthMiddleware jika client memakai ?token=
+	viewerToken, _ := c.Locals("viewer_token").(string)
+
+	media, err := h.cameraService.OpenHLS(c.UserContext(), c.Params("id"), channel, c.Params("*"), viewerToken)
+	if err != nil {
+		return streamErrorResponse(c, err, "Failed to fetch HLS stream")
+	}
+
+	return sendMedia(c, media)
+}
+
+// ProxySnapshot handler untuk meneruskan snapshot JPEG dari RTSPtoWeb
+func (h *CameraHandler) ProxySnapshot(c *fiber.Ctx) error {
+	channel, err := parseChannelQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeValidationFailed,
+				"Invalid channel parameter",
+				err.Error(),
+			),
+		)
+	}
+
+	media, err := h.cameraService.OpenSnapshot(c.UserContext(), c.Params("id"), channel)
+	if err != nil {
+		return streamErrorResponse(c, err, "Failed to fetch snapshot")
+	}
+
+	return sendMedia(c, media)
+}
+
+// revealCredentials menampilkan password RTSP di response hanya jika caller
+// memiliki permission camera:credentials. Gagal dekripsi tidak menggagalkan
+// request, URL tetap disamarkan.
+func (h *CameraHandler) revealCredentials(c *fiber.Ctx, cameras ...*models.Camera) {
+	permissions, _ := c.Locals("permissions").([]string)
+	if !models.HasPermission(permissions, models.PermissionCameraCredentials) {
+		return
+	}
+
+	if err := h.cameraService.RevealCredentials(cameras...); err != nil {
+		log.Printf("Error revealing camera credentials: %v", err)
+	}
+}
+
+// credentialConflictResponse untuk channel dengan credential RTSP berbeda
+func credentialConflictResponse(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusBadRequest).JSON(
+		models.NewErrorResponse(
+			models.ErrCodeValidationFailed,
+			"Invalid RTSP credentials",
+			err.Error(),
+		),
+	)
+}
+
+// validationErrorResponse mengembalikan 400 berisi semua pelanggaran validasi
+// body atau query request
+func validationErrorResponse(c *fiber.Ctx, errs validation.Errors) error {
+	return c.Status(fiber.StatusBadRequest).JSON(models.NewValidationErrorResponse(errs))
+}
+
+// zoneNotFoundResponse mengembalikan validation error jika zone_id camera
+// tidak ada
+func zoneNotFoundResponse(c *fiber.Ctx) error {
+	var errs validation.Errors
+	errs.Add("zone_id", models.FieldErrNotFound, "does not exist")
+	return validationErrorResponse(c, errs)
+}
+
+// versionConflictResponse mengembalikan 412 jika If-Match tidak cocok dengan
+// version camera saat ini
+func versionConflictResponse(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusPreconditionFailed).JSON(
+		models.NewErrorResponse(
+			models.ErrCodeVersionConflict,
+			"Camera was modified by another request, reload it and retry",
+			err.Error(),
+		),
+	)
+}
+
+// parseCameraFilter membaca filter, sort, pagination dan fields list camera dari
+// query string. Parameter multi-nilai dipisah koma, misalnya ?status=ONLINE,ERROR.
+// Semua parameter yang tidak valid dikembalikan sekaligus.
+func parseCameraFilter(c *fiber.Ctx) (*models.CameraFilter, validation.Errors) {
+	var errs validation.Errors
+
+	// page_size di atas batas dipotong ke MaxCameraPageSize
+	page := positiveQueryInt(c, "page", 1, &errs)
+	pageSize := positiveQueryInt(c, "page_size", models.DefaultCameraPageSize, &errs)
+
+	filter := &models.CameraFilter{
+		Query:         strings.TrimSpace(c.Query("q")),
+		Statuses:      splitQueryList(strings.ToUpper(c.Query("status"))),
+		Buildings:     splitQueryList(c.Query("building")),
+		Zones:         splitQueryList(c.Query("zone")),
+		Manufacturers: splitQueryList(c.Query("manufacturer")),
+		Tags:          splitQueryList(c.Query("tags")),
+		Page:          page,
+		PageSize:      pageSize,
+	}
+
+	switch active := c.Query("active", models.CameraActiveOnly); active {
+	case models.CameraActiveOnly, models.CameraInactiveOnly, models.CameraActiveAll:
+		filter.Active = active
+	default:
+		errs.Add("active", models.FieldErrInvalidChoice, "must be one of true, false, all")
+	}
+
+	switch match := c.Query("tag_match", models.TagMatchAny); match {
+	case models.TagMatchAny, models.TagMatchAll:
+		filter.TagMatch = match
+	default:
+		errs.Add("tag_match", models.FieldErrInvalidChoice, "must be one of any, all")
+	}
+
+	locations := []struct {
+		param string
+		dst   *[]string
+	}{
+		{"site_id", &filter.SiteIDs},
+		{"building_id", &filter.BuildingIDs},
+		{"floor_id", &filter.FloorIDs},
+		{"zone_id", &filter.ZoneIDs},
+	}
+	for _, l := range locations {
+		ids := splitQueryList(c.Query(l.param))
+		for _, id := range ids {
+			if !utils.IsUUID(id) {
+				errs.Add(l.param, models.FieldErrInvalidFormat, "must be a list of IDs")
+				ids = nil
+				break
+			}
+		}
+		*l.dst = ids
+	}
+
+	if createdBy := c.Query("created_by"); createdBy != "" {
+		if utils.IsUUID(createdBy) {
+			filter.CreatedBy = createdBy
+		} else {
+			errs.Add("created_by", models.FieldErrInvalidFormat, "must be a user ID")
+		}
+	}
+
+	dates := []struct {
+		param string
+		end   bool
+		dst   **time.Time
+	}{
+		{"created_from", false, &filter.CreatedFrom},
+		{"created_to", true, &filter.CreatedTo},
+		{"updated_from", false, &filter.UpdatedFrom},
+		{"updated_to", true, &filter.UpdatedTo},
+	}
+	for _, d := range dates {
+		t, err := parseDateQuery(c.Query(d.param), d.end)
+		if err != nil {
+			errs.Add(d.param, models.FieldErrInvalidFormat, err.Error())
+			continue
+		}
+		*d.dst = t
+	}
+
+	sort, err := models.ParseCameraSort(c.Query("sort"))
+	if err != nil {
+		errs.Add("sort", models.FieldErrInvalidChoice, err.Error())
+	}
+	filter.Sort = sort
+
+	fields, err := models.ParseCameraFields(c.Query("fields"))
+	if err != nil {
+		errs.Add("fields", models.FieldErrInvalidChoice, err.Error())
+	}
+	filter.Fields = fields
+
+	// ?cursor= (boleh kosong untuk halaman pertama) memakai keyset pagination.
+	// Sort yang tidak valid sudah dilaporkan, cursor tidak perlu dicek lagi.
+	if c.Context().QueryArgs().Has("cursor") && err == nil {
+		if err := parseCursorQuery(c, filter); err != nil {
+			errs.Add("cursor", models.FieldErrInvalidFormat, err.Error())
+		}
+	}
+
+	return filter, errs
+}
+
+// parseCursorQuery mengisi keyset pagination dari ?cursor= dan ?include_total=
+func parseCursorQuery(c *fiber.Ctx, filter *models.CameraFilter) error {
+	if c.Query("page") != "" {
+		return fmt.Errorf("page cannot be combined with cursor")
+	}
+
+	desc, err := filter.KeysetDesc()
+	if err != nil {
+		return err
+	}
+
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := models.DecodeCameraCursor(raw)
+		if err != nil || !utils.IsUUID(cursor.ID) {
+			return fmt.Errorf("invalid cursor")
+		}
+		if cursor.Desc != desc {
+			return fmt.Errorf("cursor was issued for a different sort order")
+		}
+		filter.After = cursor
+	}
+
+	filter.Keyset = true
+	filter.IncludeTotal = c.QueryBool("include_total", false)
+	return nil
+}
+
+// parsePagination membaca page dan page_size, page_size dipotong ke
+// MaxCameraPageSize
+func parsePagination(c *fiber.Ctx) (page, pageSize int, errs validation.Errors) {
+	page = positiveQueryInt(c, "page", 1, &errs)
+	pageSize = positiveQueryInt(c, "page_size", models.DefaultCameraPageSize, &errs)
+	if pageSize > models.MaxCameraPageSize {
+		pageSize = models.MaxCameraPageSize
+	}
+	return page, pageSize, errs
+}
+
+// positiveQueryInt membaca parameter integer >= 1, def jika tidak diisi. Nilai
+// yang tidak valid dicatat di errs.
+func positiveQueryInt(c *fiber.Ctx, param string, def int, errs *validation.Errors) int {
+	raw := c.Query(param)
+	if raw == "" {
+		return def
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		errs.Add(param, models.FieldErrInvalidFormat, "must be a positive integer")
+		return def
+	}
+	if value < 1 {
+		errs.Add(param, models.FieldErrOutOfRange, "must be a positive integer")
+		return def
+	}
+	return value
+}
+
+// floatQuery membaca parameter angka desimal dalam rentang [min, max], def
+// jika tidak diisi. required=true mewajibkan parameter diisi.
+func floatQuery(c *fiber.Ctx, param string, def, min, max float64, required bool, errs *validation.Errors) float64 {
+	raw := c.Query(param)
+	if raw == "" {
+		if required {
+			errs.Add(param, models.FieldErrRequired, "is required")
+		}
+		return def
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		errs.Add(param, models.FieldErrInvalidFormat, "must be a number")
+		return def
+	}
+	if value < min || value > max {
+		errs.Add(param, models.FieldErrOutOfRange, fmt.Sprintf("must be between %g and %g", min, max))
+		return def
+	}
+	return value
+}
+
+// splitQueryList memecah nilai query yang dipisah koma, nilai kosong dibuang
+func splitQueryList(raw string) []string {
+	var values []string
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// parseDateQuery menerima RFC3339 atau tanggal (YYYY-MM-DD). Tanggal sebagai
+// batas akhir rentang mencakup seluruh hari tersebut.
+func parseDateQuery(raw string, end bool) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return nil, fmt.Errorf("expected RFC3339 timestamp or YYYY-MM-DD date")
+	}
+	if end {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
+
+// parseChannelQuery membaca query ?channel=N, nil jika tidak diisi
+func parseChannelQuery(c *fiber.Ctx) (*int, error) {
+	if c.Query("channel") == "" {
+		return nil, nil
+	}
+
+	channel, err := strconv.Atoi(c.Query("channel"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &channel, nil
+}
+
+// sendMedia meneruskan response media ke client. Body di-stream langsung
+// (fasthttp menutup body setelah selesai dibaca), tidak di-buffer di memory.
+func sendMedia(c *fiber.Ctx, media *service.MediaResponse) error {
+	c.Status(media.StatusCode)
+	if media.ContentType != "" {
+		c.Set(fiber.HeaderContentType, media.ContentType)
+	}
+	if media.CacheControl != "" {
+		c.Set(fiber.HeaderCacheControl, media.CacheControl)
+	} else {
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+	}
+
+	c.Context().SetBodyStream(media.Body, int(media.ContentLength))
+	return nil
+}
+
+// streamErrorResponse memetakan error stream/media ke HTTP response
+func streamErrorResponse(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrCameraNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeNotFound,
+				"Camera not found",
+			),
+		)
+	case errors.Is(err, service.ErrChannelNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeNotFound,
+				"Channel not found",
+			),
+		)
+	case errors.Is(err, service.ErrStreamNotStarted):
+		return c.Status(fiber.StatusConflict).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeStreamNotStarted,
+				"Stream has not been started",
+			),
+		)
+	case errors.Is(err, service.ErrStreamNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeStreamNotFound,
+				"Stream not found on media server",
+				err.Error(),
+			),
+		)
+	case errors.Is(err, service.ErrMediaTimeout):
+		return c.Status(fiber.StatusGatewayTimeout).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeMediaServerTimeout,
+				message,
+				err.Error(),
+			),
+		)
+	case errors.Is(err, service.ErrMediaUnavailable), errors.Is(err, service.ErrNoMediaServer):
+		return c.Status(fiber.StatusServiceUnavailable).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeServiceUnavailable,
+				message,
+				err.Error(),
+			),
+		)
+	case errors.Is(err, service.ErrMediaUnauthorized):
+		// Credential RTSPtoWeb di backend salah, bukan kesalahan client
+		return c.Status(fiber.StatusBadGateway).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeMediaServerAuth,
+				message,
+				err.Error(),
+			),
+		)
+	default:
+		return c.Status(fiber.StatusBadGateway).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeServiceUnavailable,
+				message,
+				err.Error(),
+			),
+		)
+	}
+}
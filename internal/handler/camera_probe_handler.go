@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CameraProbeHandler menangani HTTP requests untuk probe RTSP camera
+type CameraProbeHandler struct {
+	probeService service.CameraProbeService
+}
+
+// NewCameraProbeHandler membuat instance baru dari CameraProbeHandler
+func NewCameraProbeHandler(probeService service.CameraProbeService) *CameraProbeHandler {
+	return &CameraProbeHandler{
+		probeService: probeService,
+	}
+}
+
+// Probe handler untuk menjalankan OPTIONS + DESCRIBE ke kamera. Query apply=false
+// hanya menyimpan hasil probe tanpa memperbarui resolution/fps/manufacturer camera.
+func (h *CameraProbeHandler) Probe(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	probe, err := h.probeService.Probe(c.UserContext(), id, c.QueryBool("apply", true))
+	if err != nil {
+		return probeErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: "Camera probed successfully",
+		Data:    probe,
+	})
+}
+
+// GetProbe handler untuk mengambil hasil probe terakhir camera
+func (h *CameraProbeHandler) GetProbe(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	probe, err := h.probeService.GetLatest(id)
+	if err != nil {
+		if errors.Is(err, service.ErrProbeNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeNotFound,
+					"Camera has not been probed",
+				),
+			)
+		}
+
+		return probeErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: "Camera probe retrieved successfully",
+		Data:    probe,
+	})
+}
+
+// probeErrorResponse memetakan error probe ke HTTP status
+func probeErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrCameraNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeNotFound,
+				"Camera not found",
+			),
+		)
+	case errors.Is(err, service.ErrInvalidRTSPURL):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeValidationFailed,
+				"Camera RTSP URL cannot be probed",
+				err.Error(),
+			),
+		)
+	case errors.Is(err, service.ErrCameraTimeout):
+		return c.Status(fiber.StatusGatewayTimeout).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeCameraUnreachable,
+				"Camera did not respond in time",
+				err.Error(),
+			),
+		)
+	case errors.Is(err, service.ErrCameraUnreachable):
+		return c.Status(fiber.StatusBadGateway).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeCameraUnreachable,
+				"Camera unreachable",
+				err.Error(),
+			),
+		)
+	case errors.Is(err, service.ErrCameraUnauthorized):
+		// Credential di rtsp_url salah, bukan kesalahan client API
+		return c.Status(fiber.StatusBadGateway).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeCameraAuth,
+				"Camera rejected RTSP credentials",
+				err.Error(),
+			),
+		)
+	default:
+		return c.Status(fiber.StatusBadGateway).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeServiceUnavailable,
+				"Failed to probe camera",
+				err.Error(),
+			),
+		)
+	}
+}
@@ -0,0 +1,353 @@
+package handler
+
+import (
+	"errors"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/service"
+	"cctv-monitoring-backend/internal/utils"
+	"cctv-monitoring-backend/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LocationHandler menangani HTTP requests untuk hierarki site, building,
+// floor dan zone
+type LocationHandler struct {
+	locationService service.LocationService
+}
+
+// NewLocationHandler membuat instance baru dari LocationHandler
+func NewLocationHandler(locationService service.LocationService) *LocationHandler {
+	return &LocationHandler{
+		locationService: locationService,
+	}
+}
+
+// locationErrorResponse memetakan error location service ke response HTTP
+func locationErrorResponse(c *fiber.Ctx, err error, message string) error {
+	var errs validation.Errors
+	switch {
+	case errors.As(err, &errs):
+		return validationErrorResponse(c, errs)
+
+	case errors.Is(err, service.ErrLocationNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeNotFound,
+				"Location not found",
+			),
+		)
+
+	case errors.Is(err, service.ErrLocationExists):
+		return c.Status(fiber.StatusConflict).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeAlreadyExists,
+				message,
+				err.Error(),
+			),
+		)
+
+	case errors.Is(err, service.ErrLocationInUse):
+		return c.Status(fiber.StatusConflict).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeLocationInUse,
+				message,
+				err.Error(),
+			),
+		)
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(
+		models.NewErrorResponse(
+			models.ErrCodeInternalError,
+			message,
+			err.Error(),
+		),
+	)
+}
+
+// parseLocationBody membaca dan memvalidasi body request lokasi. Response
+// error sudah dikirim jika ok false.
+func parseLocationBody(c *fiber.Ctx, req interface{}) (ok bool, err error) {
+	if err := c.BodyParser(req); err != nil {
+		return false, c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeValidationFailed,
+				"Invalid request body",
+				err.Error(),
+			),
+		)
+	}
+
+	if errs := validation.Struct(req); len(errs) > 0 {
+		return false, validationErrorResponse(c, errs)
+	}
+
+	return true, nil
+}
+
+// locationIDQuery membaca filter ID parent dari query string, kosong jika
+// tidak diisi
+func locationIDQuery(c *fiber.Ctx, param string, errs *validation.Errors) string {
+	id := c.Query(param)
+	if id != "" && !utils.IsUUID(id) {
+		errs.Add(param, models.FieldErrInvalidFormat, "must be an ID")
+		return ""
+	}
+	return id
+}
+
+// locationResponse mengirim response sukses dengan status dan data
+func locationResponse(c *fiber.Ctx, status int, message string, data interface{}) error {
+	return c.Status(status).JSON(models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
+// GetTree handler untuk mengambil hierarki lokasi beserta jumlah camera
+func (h *LocationHandler) GetTree(c *fiber.Ctx) error {
+	tree, err := h.locationService.GetTree()
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to retrieve location tree")
+	}
+	return locationResponse(c, fiber.StatusOK, "Location tree retrieved successfully", tree)
+}
+
+// GetSites handler untuk mengambil semua site
+func (h *LocationHandler) GetSites(c *fiber.Ctx) error {
+	sites, err := h.locationService.ListSites()
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to retrieve sites")
+	}
+	return locationResponse(c, fiber.StatusOK, "Sites retrieved successfully", sites)
+}
+
+// GetSite handler untuk mengambil site berdasarkan ID
+func (h *LocationHandler) GetSite(c *fiber.Ctx) error {
+	site, err := h.locationService.GetSite(c.Params("id"))
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to retrieve site")
+	}
+	return locationResponse(c, fiber.StatusOK, "Site retrieved successfully", site)
+}
+
+// CreateSite handler untuk membuat site
+func (h *LocationHandler) CreateSite(c *fiber.Ctx) error {
+	var req models.CreateSiteRequest
+	if ok, err := parseLocationBody(c, &req); !ok {
+		return err
+	}
+
+	site, err := h.locationService.CreateSite(&req)
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to create site")
+	}
+	return locationResponse(c, fiber.StatusCreated, "Site created successfully", site)
+}
+
+// UpdateSite handler untuk mengupdate site
+func (h *LocationHandler) UpdateSite(c *fiber.Ctx) error {
+	var req models.UpdateSiteRequest
+	if ok, err := parseLocationBody(c, &req); !ok {
+		return err
+	}
+
+	site, err := h.locationService.UpdateSite(c.Params("id"), &req)
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to update site")
+	}
+	return locationResponse(c, fiber.StatusOK, "Site updated successfully", site)
+}
+
+// DeleteSite handler untuk menghapus site
+func (h *LocationHandler) DeleteSite(c *fiber.Ctx) error {
+	if err := h.locationService.DeleteSite(c.Params("id")); err != nil {
+		return locationErrorResponse(c, err, "Failed to delete site")
+	}
+	return locationResponse(c, fiber.StatusOK, "Site deleted successfully", nil)
+}
+
+// GetBuildings handler untuk mengambil building, ?site_id= untuk satu site
+func (h *LocationHandler) GetBuildings(c *fiber.Ctx) error {
+	var errs validation.Errors
+	siteID := locationIDQuery(c, "site_id", &errs)
+	if len(errs) > 0 {
+		return validationErrorResponse(c, errs)
+	}
+
+	buildings, err := h.locationService.ListBuildings(siteID)
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to retrieve buildings")
+	}
+	return locationResponse(c, fiber.StatusOK, "Buildings retrieved successfully", buildings)
+}
+
+// GetBuilding handler untuk mengambil building berdasarkan ID
+func (h *LocationHandler) GetBuilding(c *fiber.Ctx) error {
+	building, err := h.locationService.GetBuilding(c.Params("id"))
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to retrieve building")
+	}
+	return locationResponse(c, fiber.StatusOK, "Building retrieved successfully", building)
+}
+
+// CreateBuilding handler untuk membuat building
+func (h *LocationHandler) CreateBuilding(c *fiber.Ctx) error {
+	var req models.CreateBuildingRequest
+	if ok, err := parseLocationBody(c, &req); !ok {
+		return err
+	}
+
+	building, err := h.locationService.CreateBuilding(&req)
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to create building")
+	}
+	return locationResponse(c, fiber.StatusCreated, "Building created successfully", building)
+}
+
+// UpdateBuilding handler untuk mengupdate building
+func (h *LocationHandler) UpdateBuilding(c *fiber.Ctx) error {
+	var req models.UpdateBuildingRequest
+	if ok, err := parseLocationBody(c, &req); !ok {
+		return err
+	}
+
+	building, err := h.locationService.UpdateBuilding(c.Params("id"), &req, c.Locals("user_id").(string))
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to update building")
+	}
+	return locationResponse(c, fiber.StatusOK, "Building updated successfully", building)
+}
+
+// DeleteBuilding handler untuk menghapus building
+func (h *LocationHandler) DeleteBuilding(c *fiber.Ctx) error {
+	if err := h.locationService.DeleteBuilding(c.Params("id")); err != nil {
+		return locationErrorResponse(c, err, "Failed to delete building")
+	}
+	return locationResponse(c, fiber.StatusOK, "Building deleted successfully", nil)
+}
+
+// GetFloors handler untuk mengambil floor, ?building_id= untuk satu building
+func (h *LocationHandler) GetFloors(c *fiber.Ctx) error {
+	var errs validation.Errors
+	buildingID := locationIDQuery(c, "building_id", &errs)
+	if len(errs) > 0 {
+		return validationErrorResponse(c, errs)
+	}
+
+	floors, err := h.locationService.ListFloors(buildingID)
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to retrieve floors")
+	}
+	return locationResponse(c, fiber.StatusOK, "Floors retrieved successfully", floors)
+}
+
+// GetFloor handler untuk mengambil floor berdasarkan ID
+func (h *LocationHandler) GetFloor(c *fiber.Ctx) error {
+	floor, err := h.locationService.GetFloor(c.Params("id"))
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to retrieve floor")
+	}
+	return locationResponse(c, fiber.StatusOK, "Floor retrieved successfully", floor)
+}
+
+// CreateFloor handler untuk membuat floor
+func (h *LocationHandler) CreateFloor(c *fiber.Ctx) error {
+	var req models.CreateFloorRequest
+	if ok, err := parseLocationBody(c, &req); !ok {
+		return err
+	}
+
+	floor, err := h.locationService.CreateFloor(&req)
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to create floor")
+	}
+	return locationResponse(c, fiber.StatusCreated, "Floor created successfully", floor)
+}
+
+// UpdateFloor handler untuk mengupdate floor
+func (h *LocationHandler) UpdateFloor(c *fiber.Ctx) error {
+	var req models.UpdateFloorRequest
+	if ok, err := parseLocationBody(c, &req); !ok {
+		return err
+	}
+
+	floor, err := h.locationService.UpdateFloor(c.Params("id"), &req)
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to update floor")
+	}
+	return locationResponse(c, fiber.StatusOK, "Floor updated successfully", floor)
+}
+
+// DeleteFloor handler untuk menghapus floor
+func (h *LocationHandler) DeleteFloor(c *fiber.Ctx) error {
+	if err := h.locationService.DeleteFloor(c.Params("id")); err != nil {
+		return locationErrorResponse(c, err, "Failed to delete floor")
+	}
+	return locationResponse(c, fiber.StatusOK, "Floor deleted successfully", nil)
+}
+
+// GetZones handler untuk mengambil zone, ?building_id= dan/atau ?floor_id=
+// untuk membatasi
+func (h *LocationHandler) GetZones(c *fiber.Ctx) error {
+	var errs validation.Errors
+	buildingID := locationIDQuery(c, "building_id", &errs)
+	floorID := locationIDQuery(c, "floor_id", &errs)
+	if len(errs) > 0 {
+		return validationErrorResponse(c, errs)
+	}
+
+	zones, err := h.locationService.ListZones(buildingID, floorID)
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to retrieve zones")
+	}
+	return locationResponse(c, fiber.StatusOK, "Zones retrieved successfully", zones)
+}
+
+// GetZone handler untuk mengambil zone berdasarkan ID
+func (h *LocationHandler) GetZone(c *fiber.Ctx) error {
+	zone, err := h.locationService.GetZone(c.Params("id"))
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to retrieve zone")
+	}
+	return locationResponse(c, fiber.StatusOK, "Zone retrieved successfully", zone)
+}
+
+// CreateZone handler untuk membuat zone
+func (h *LocationHandler) CreateZone(c *fiber.Ctx) error {
+	var req models.CreateZoneRequest
+	if ok, err := parseLocationBody(c, &req); !ok {
+		return err
+	}
+
+	zone, err := h.locationService.CreateZone(&req)
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to create zone")
+	}
+	return locationResponse(c, fiber.StatusCreated, "Zone created successfully", zone)
+}
+
+// UpdateZone handler untuk mengupdate zone
+func (h *LocationHandler) UpdateZone(c *fiber.Ctx) error {
+	var req models.UpdateZoneRequest
+	if ok, err := parseLocationBody(c, &req); !ok {
+		return err
+	}
+
+	zone, err := h.locationService.UpdateZone(c.Params("id"), &req, c.Locals("user_id").(string))
+	if err != nil {
+		return locationErrorResponse(c, err, "Failed to update zone")
+	}
+	return locationResponse(c, fiber.StatusOK, "Zone updated successfully", zone)
+}
+
+// DeleteZone handler untuk menghapus zone
+func (h *LocationHandler) DeleteZone(c *fiber.Ctx) error {
+	if err := h.locationService.DeleteZone(c.Params("id")); err != nil {
+		return locationErrorResponse(c, err, "Failed to delete zone")
+	}
+	return locationResponse(c, fiber.StatusOK, "Zone deleted successfully", nil)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"crypto/subtle"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MediaHandler menangani request dari media server (RTSPtoWeb)
+type MediaHandler struct {
+	viewerTokens  service.ViewerTokenService
+	backendSecret string
+}
+
+// NewMediaHandler membuat instance baru dari MediaHandler
+func NewMediaHandler(viewerTokens service.ViewerTokenService, backendSecret string) *MediaHandler {
+	return &MediaHandler{
+		viewerTokens:  viewerTokens,
+		backendSecret: backendSecret,
+	}
+}
+
+// Authorize adalah token backend untuk RTSPtoWeb (server.token.backend).
+// RTSPtoWeb mengirim token viewer beserta stream dan channel yang diminta,
+// dan hanya mengizinkan akses jika response berisi status "1".
+func (h *MediaHandler) Authorize(c *fiber.Ctx) error {
+	// Shared secret opsional, dikirim RTSPtoWeb lewat query string di URL backend
+	if h.backendSecret != "" && subtle.ConstantTimeCompare([]byte(c.Query("secret")), []byte(h.backendSecret)) != 1 {
+		return c.Status(fiber.StatusForbidden).JSON(models.MediaAuthorizeResponse{
+			Status:  "0",
+			Message: "invalid backend secret",
+		})
+	}
+
+	var req models.MediaAuthorizeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.MediaAuthorizeResponse{
+			Status:  "0",
+			Message: "invalid request body",
+		})
+	}
+
+	if err := h.viewerTokens.Authorize(&req); err != nil {
+		return c.Status(fiber.StatusOK).JSON(models.MediaAuthorizeResponse{
+			Status:  "0",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.MediaAuthorizeResponse{
+		Status: "1",
+	})
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"errors"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MediaServerHandler menangani HTTP requests untuk registry node RTSPtoWeb
+type MediaServerHandler struct {
+	mediaServerService service.MediaServerService
+}
+
+// NewMediaServerHandler membuat instance baru dari MediaServerHandler
+func NewMediaServerHandler(mediaServerService service.MediaServerService) *MediaServerHandler {
+	return &MediaServerHandler{
+		mediaServerService: mediaServerService,
+	}
+}
+
+// GetAll handler untuk mengambil semua media server
+func (h *MediaServerHandler) GetAll(c *fiber.Ctx) error {
+	servers, err := h.mediaServerService.List()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeInternalError,
+				"Failed to retrieve media servers",
+				err.Error(),
+			),
+		)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: "Media servers retrieved successfully",
+		Data:    servers,
+	})
+}
+
+// GetByID handler untuk mengambil media server berdasarkan ID
+func (h *MediaServerHandler) GetByID(c *fiber.Ctx) error {
+	server, err := h.mediaServerService.GetByID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeNotFound,
+				"Media server not found",
+			),
+		)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: "Media server retrieved successfully",
+		Data:    server,
+	})
+}
+
+// Create handler untuk mendaftarkan media server baru
+func (h *MediaServerHandler) Create(c *fiber.Ctx) error {
+	var req models.CreateMediaServerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeValidationFailed,
+				"Invalid request body",
+				err.Error(),
+			),
+		)
+	}
+
+	if req.Name == "" || req.APIURL == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeMissingFields,
+				"Media server name and API URL are required",
+			),
+		)
+	}
+
+	server, err := h.mediaServerService.Create(&req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeInternalError,
+				"Failed to create media server",
+				err.Error(),
+			),
+		)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.APIResponse{
+		Success: true,
+		Message: "Media server created successfully",
+		Data:    server,
+	})
+}
+
+// Update handler untuk mengupdate media server
+func (h *MediaServerHandler) Update(c *fiber.Ctx) error {
+	var req models.UpdateMediaServerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeValidationFailed,
+				"Invalid request body",
+				err.Error(),
+			),
+		)
+	}
+
+	server, err := h.mediaServerService.Update(c.Params("id"), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrMediaServerNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeNotFound,
+					"Media server not found",
+				),
+			)
+		}
+
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeInternalError,
+				"Failed to update media server",
+				err.Error(),
+			),
+		)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: "Media server updated successfully",
+		Data:    server,
+	})
+}
+
+// Delete handler untuk menghapus media server
+func (h *MediaServerHandler) Delete(c *fiber.Ctx) error {
+	if err := h.mediaServerService.Delete(c.Params("id")); err != nil {
+		if errors.Is(err, service.ErrMediaServerNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeNotFound,
+					"Media server not found",
+				),
+			)
+		}
+
+		return c.Status(fiber.StatusConflict).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeInternalError,
+				"Failed to delete media server",
+				err.Error(),
+			),
+		)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: "Media server deleted successfully",
+	})
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RTSPConfigHandler menangani HTTP requests untuk import, generate dan diff config.json RTSPtoWeb
+type RTSPConfigHandler struct {
+	rtspConfigService service.RTSPConfigService
+}
+
+// NewRTSPConfigHandler membuat instance baru dari RTSPConfigHandler
+func NewRTSPConfigHandler(rtspConfigService service.RTSPConfigService) *RTSPConfigHandler {
+	return &RTSPConfigHandler{
+		rtspConfigService: rtspConfigService,
+	}
+}
+
+// Import handler untuk membuat camera dari config.json RTSPtoWeb (body = isi file)
+func (h *RTSPConfigHandler) Import(c *fiber.Ctx) error {
+	config, err := service.ParseRTSPtoWebConfig(c.Body())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeValidationFailed,
+				"Invalid RTSPtoWeb config",
+				err.Error(),
+			),
+		)
+	}
+
+	opts := models.ConfigImportOptions{
+		Mode:          c.Query("mode", models.ConfigImportSkip),
+		DryRun:        c.QueryBool("dry_run", false),
+		MediaServerID: c.Query("media_server_id"),
+		UserID:        c.Locals("user_id").(string),
+	}
+
+	report, err := h.rtspConfigService.Import(config, opts)
+	if err != nil {
+		if errors.Is(err, service.ErrMediaServerNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeNotFound,
+					"Media server not found",
+					err.Error(),
+				),
+			)
+		}
+
+		return c.Status(fiber.StatusBadRequest).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeValidationFailed,
+				"Failed to import RTSPtoWeb config",
+				err.Error(),
+			),
+		)
+	}
+
+	message := "RTSPtoWeb config imported successfully"
+	if opts.DryRun {
+		message = "RTSPtoWeb config import dry run completed"
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    report,
+	})
+}
+
+// Export handler untuk generate config.json RTSPtoWeb dari tabel cameras. Response
+// adalah file config.json apa adanya (tidak dibungkus APIResponse).
+func (h *RTSPConfigHandler) Export(c *fiber.Ctx) error {
+	config, err := h.rtspConfigService.Generate(c.Query("media_server_id"))
+	if err != nil {
+		if errors.Is(err, service.ErrMediaServerNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeNotFound,
+					"Media server not found",
+					err.Error(),
+				),
+			)
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(
+			models.NewErrorResponse(
+				models.ErrCodeInternalError,
+				"Failed to generate RTSPtoWeb config",
+				err.Error(),
+			),
+		)
+	}
+
+	if c.QueryBool("download", false) {
+		c.Attachment("config.json")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(config)
+}
+
+// Diff handler untuk membandingkan tabel cameras dengan stream di node RTSPtoWeb
+func (h *RTSPConfigHandler) Diff(c *fiber.Ctx) error {
+	diff, err := h.rtspConfigService.Diff(c.UserContext(), c.Query("media_server_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMediaServerNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeNotFound,
+					"Media server not found",
+					err.Error(),
+				),
+			)
+		case errors.Is(err, service.ErrMediaServerRequired):
+			return c.Status(fiber.StatusBadRequest).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeMissingFields,
+					"media_server_id is required",
+					err.Error(),
+				),
+			)
+		}
+
+		return streamErrorResponse(c, err, "Failed to diff RTSPtoWeb config")
+	}
+
+	message := "RTSPtoWeb config is in sync"
+	if !diff.InSync {
+		message = "RTSPtoWeb config differs from database"
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    diff,
+	})
+}
@@ -89,6 +89,7 @@ func AuthMiddleware(authService service.AuthService) fiber.Handler {
 		c.Locals("user_id", claims.UserID)
 		c.Locals("username", claims.Username)
 		c.Locals("role", claims.Role)
+		c.Locals("permissions", claims.Permissions)
 
 		return c.Next()
 	}
@@ -125,3 +126,51 @@ func RoleMiddleware(allowedRoles ...string) fiber.Handler {
 		)
 	}
 }
+
+// PermissionMiddleware adalah middleware untuk validasi permission eksplisit user
+// (lihat models.KnownPermissions). Role admin tidak otomatis lolos.
+func PermissionMiddleware(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		permissions, _ := c.Locals("permissions").([]string)
+		if !models.HasPermission(permissions, permission) {
+			return c.Status(fiber.StatusForbidden).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeUnauthorized,
+					"Insufficient permissions",
+					"requires permission "+permission,
+				),
+			)
+		}
+
+		return c.Next()
+	}
+}
+
+// MediaAuthMiddleware adalah middleware untuk route media proxy (HLS/snapshot).
+// Viewer token di query ?token= yang berlaku untuk camera :id diterima sebagai
+// pengganti Authorization header (untuk tag <img>/<video>), selain itu request
+// divalidasi sama seperti AuthMiddleware.
+func MediaAuthMiddleware(authService service.AuthService, viewerTokens service.ViewerTokenService) fiber.Handler {
+	authMiddleware := AuthMiddleware(authService)
+
+	return func(c *fiber.Ctx) error {
+		token := c.Query("token")
+		if token == "" || !viewerTokens.Enabled() {
+			return authMiddleware(c)
+		}
+
+		if _, err := viewerTokens.VerifyForCamera(token, c.Params("id")); err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(
+				models.NewErrorResponse(
+					models.ErrCodeTokenInvalid,
+					"Invalid viewer token",
+					err.Error(),
+				),
+			)
+		}
+
+		c.Locals("viewer_token", token)
+
+		return c.Next()
+	}
+}
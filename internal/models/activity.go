@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Action activity log camera
+const (
+	ActivityCameraDeleted  = "camera.deleted"
+	ActivityCameraRestored = "camera.restored"
+	ActivityCameraPurged   = "camera.purged"
+)
+
+// ActivityLog adalah satu baris audit di tabel activity_logs. Details selalu
+// berisi camera_id dan name agar log tetap terbaca setelah camera di-purge.
+type ActivityLog struct {
+	ID        string                 `json:"id"`
+	UserID    string                 `json:"user_id,omitempty"`
+	CameraID  string                 `json:"camera_id,omitempty"`
+	Action    string                 `json:"action"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// NewCameraActivity membuat activity log untuk camera. userID kosong untuk
+// aksi sistem (misalnya purge terjadwal).
+func NewCameraActivity(action string, camera *Camera, userID string, details map[string]interface{}) *ActivityLog {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	details["camera_id"] = camera.ID
+	details["name"] = camera.Name
+
+	return &ActivityLog{
+		UserID:   userID,
+		CameraID: camera.ID,
+		Action:   action,
+		Details:  details,
+	}
+}
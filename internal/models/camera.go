@@ -3,10 +3,28 @@ package models
 import (
 	"database/sql"
 	"encoding/json"
+	"strings"
 	"time"
 )
 
-// Camera merepresentasikan struktur data kamera CCTV
+// Status camera, ditulis oleh stream monitor dan diterima di request camera
+const (
+	CameraStatusOnline  = "ONLINE"
+	CameraStatusOffline = "OFFLINE"
+	CameraStatusError   = "ERROR"
+	CameraStatusUnknown = "UNKNOWN"
+)
+
+// CameraStatuses adalah semua nilai status camera yang valid
+var CameraStatuses = []string{CameraStatusOnline, CameraStatusOffline, CameraStatusError, CameraStatusUnknown}
+
+// Stream sync status
+const (
+	SyncStatusSynced    = "SYNCED"
+	SyncStatusPending   = "PENDING"
+	SyncStatusOutOfSync = "OUT_OF_SYNC"
+)
+
 // Camera merepresentasikan struktur data kamera CCTV
 type Camera struct {
 	ID           string         `json:"id"`
@@ -18,6 +36,7 @@ type Camera struct {
 	Longitude    float64        `json:"longitude"`
 	Building     sql.NullString `json:"-"`
 	Zone         sql.NullString `json:"-"`
+	ZoneID       sql.NullString `json:"-"` // Zone di hierarki lokasi, building dan zone mengikuti namanya
 	IPAddress    sql.NullString `json:"-"`
 	Port         sql.NullInt64  `json:"-"`
 	Manufacturer sql.NullString `json:"-"`
@@ -32,9 +51,46 @@ type Camera struct {
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 
+	// Version naik setiap update, dikirim sebagai ETag untuk If-Match
+	Version int `json:"version"`
+
+	// Waktu dan user yang menghapus camera (is_active = false), selama camera
+	// ada di trash
+	DeletedAt sql.NullTime   `json:"-"`
+	DeletedBy sql.NullString `json:"-"`
+
+	// Waktu camera di trash akan di-purge (hanya di response list trash)
+	PurgeAt *time.Time `json:"purge_at,omitempty"`
+
+	// Node RTSPtoWeb tempat stream berjalan
+	MediaServerID sql.NullString `json:"-"`
+
+	// Credential RTSP. rtsp_url dan URL channel disimpan tanpa userinfo,
+	// password dienkripsi AES-GCM dan hanya didekripsi untuk payload RTSPtoWeb.
+	RTSPUsername    sql.NullString `json:"-"`
+	RTSPPasswordEnc sql.NullString `json:"-"`
+
+	// Password plaintext, hanya diisi untuk caller dengan permission
+	// camera:credentials. Kosong = password di response disamarkan.
+	RTSPPassword string `json:"-"`
+
+	// Opsi stream RTSPtoWeb untuk semua channel camera
+	StreamOptions StreamOptions `json:"stream_options"`
+
+	// Apakah stream di RTSPtoWeb sudah memakai konfigurasi camera terbaru
+	SyncStatus string         `json:"sync_status"`
+	SyncError  sql.NullString `json:"-"`
+
+	// Hasil propagasi perubahan ke RTSPtoWeb (hanya di response update)
+	StreamSync *StreamSyncResult `json:"stream_sync,omitempty"`
+
 	// Stream URLs (not stored in DB, generated dynamically)
 	HLSUrl      string `json:"hls_url,omitempty"`      // NEW
 	SnapshotUrl string `json:"snapshot_url,omitempty"` // NEW
+	WebRTCUrl   string `json:"webrtc_url,omitempty"`   // Signaling endpoint di backend
+
+	// Channels (main stream / sub stream), dibaca dari tabel camera_channels
+	Channels []CameraChannel `json:"channels,omitempty"`
 }
 
 // MarshalJSON custom JSON marshaling untuk Camera
@@ -46,6 +102,7 @@ func (c Camera) MarshalJSON() ([]byte, error) {
 		StreamID     string `json:"stream_id,omitempty"`
 		Building     string `json:"building,omitempty"`
 		Zone         string `json:"zone,omitempty"`
+		ZoneID       string `json:"zone_id,omitempty"`
 		IPAddress    string `json:"ip_address,omitempty"`
 		Port         *int64 `json:"port,omitempty"`
 		Manufacturer string `json:"manufacturer,omitempty"`
@@ -53,12 +110,23 @@ func (c Camera) MarshalJSON() ([]byte, error) {
 		Resolution   string `json:"resolution,omitempty"`
 		LastSeen     string `json:"last_seen,omitempty"`
 		CreatedBy    string `json:"created_by,omitempty"`
+		MediaServer  string `json:"media_server_id,omitempty"`
+		SyncError    string `json:"sync_error,omitempty"`
+		DeletedAt    string `json:"deleted_at,omitempty"`
+		DeletedBy    string `json:"deleted_by,omitempty"`
+
+		// URL dengan credential disamarkan (atau lengkap jika RTSPPassword diisi)
+		RTSPUrl         string          `json:"rtsp_url"`
+		RTSPUsername    string          `json:"rtsp_username,omitempty"`
+		HasRTSPPassword bool            `json:"has_rtsp_password"`
+		Channels        []CameraChannel `json:"channels,omitempty"`
 	}{
 		Alias:        (*Alias)(&c),
 		Description:  c.Description.String,
 		StreamID:     c.StreamID.String,
 		Building:     c.Building.String,
 		Zone:         c.Zone.String,
+		ZoneID:       c.ZoneID.String,
 		IPAddress:    c.IPAddress.String,
 		Port:         nullInt64ToPtr(c.Port),
 		Manufacturer: c.Manufacturer.String,
@@ -66,9 +134,42 @@ func (c Camera) MarshalJSON() ([]byte, error) {
 		Resolution:   c.Resolution.String,
 		LastSeen:     formatNullTime(c.LastSeen),
 		CreatedBy:    c.CreatedBy.String,
+		MediaServer:  c.MediaServerID.String,
+		SyncError:    c.SyncError.String,
+		DeletedAt:    formatNullTime(c.DeletedAt),
+		DeletedBy:    c.DeletedBy.String,
+
+		RTSPUrl:         c.displayURL(c.RTSPUrl),
+		RTSPUsername:    c.RTSPUsername.String,
+		HasRTSPPassword: c.RTSPPasswordEnc.Valid,
+		Channels:        c.displayChannels(),
 	})
 }
 
+// displayURL menyisipkan credential camera ke URL untuk response. Password
+// disamarkan kecuali sudah didekripsi untuk caller yang berhak.
+func (c Camera) displayURL(rawURL string) string {
+	password := c.RTSPPassword
+	if password == "" && c.RTSPPasswordEnc.Valid {
+		password = PasswordPlaceholder
+	}
+	return WithRTSPCredentials(rawURL, c.RTSPUsername.String, password)
+}
+
+// displayChannels menyalin channels dengan URL untuk response
+func (c Camera) displayChannels() []CameraChannel {
+	if c.Channels == nil {
+		return nil
+	}
+
+	channels := make([]CameraChannel, len(c.Channels))
+	for i, channel := range c.Channels {
+		channel.RTSPUrl = c.displayURL(channel.RTSPUrl)
+		channels[i] = channel
+	}
+	return channels
+}
+
 // Helper functions
 func nullInt64ToPtr(ni sql.NullInt64) *int64 {
 	if ni.Valid {
@@ -77,6 +178,13 @@ func nullInt64ToPtr(ni sql.NullInt64) *int64 {
 	return nil
 }
 
+func nullStringPtr(ns sql.NullString) *string {
+	if ns.Valid {
+		return &ns.String
+	}
+	return nil
+}
+
 func formatNullTime(nt sql.NullTime) string {
 	if nt.Valid {
 		return nt.Time.Format(time.RFC3339)
@@ -84,43 +192,118 @@ func formatNullTime(nt sql.NullTime) string {
 	return ""
 }
 
+// StreamOptions adalah opsi channel RTSPtoWeb yang disimpan per camera
+type StreamOptions struct {
+	OnDemand           bool `json:"on_demand"`            // Stream hanya ditarik saat ada viewer
+	Audio              bool `json:"audio"`                // Teruskan track audio
+	Debug              bool `json:"debug"`                // Log RTSP verbose di RTSPtoWeb
+	InsecureSkipVerify bool `json:"insecure_skip_verify"` // Lewati verifikasi TLS untuk rtsps://
+}
+
+// DefaultStreamOptions adalah opsi camera baru: on-demand agar camera yang jarang
+// dilihat tidak terus menarik stream dari NVR
+func DefaultStreamOptions() StreamOptions {
+	return StreamOptions{OnDemand: true}
+}
+
+// StreamOptionsRequest adalah opsi stream di request, field kosong tidak diubah
+type StreamOptionsRequest struct {
+	OnDemand           *bool `json:"on_demand,omitempty"`
+	Audio              *bool `json:"audio,omitempty"`
+	Debug              *bool `json:"debug,omitempty"`
+	InsecureSkipVerify *bool `json:"insecure_skip_verify,omitempty"`
+}
+
+// Apply mengembalikan opts dengan field yang diisi di request
+func (r *StreamOptionsRequest) Apply(opts StreamOptions) StreamOptions {
+	if r == nil {
+		return opts
+	}
+	if r.OnDemand != nil {
+		opts.OnDemand = *r.OnDemand
+	}
+	if r.Audio != nil {
+		opts.Audio = *r.Audio
+	}
+	if r.Debug != nil {
+		opts.Debug = *r.Debug
+	}
+	if r.InsecureSkipVerify != nil {
+		opts.InsecureSkipVerify = *r.InsecureSkipVerify
+	}
+	return opts
+}
+
 // CreateCameraRequest adalah struktur untuk membuat kamera baru
 type CreateCameraRequest struct {
-	Name         string   `json:"name"`
+	Name         string   `json:"name" validate:"required,max=255"`
 	Description  string   `json:"description,omitempty"`
-	RTSPUrl      string   `json:"rtsp_url"`
-	Latitude     float64  `json:"latitude"`
-	Longitude    float64  `json:"longitude"`
-	Building     string   `json:"building,omitempty"`
-	Zone         string   `json:"zone,omitempty"`
-	IPAddress    string   `json:"ip_address,omitempty"`
-	Port         int      `json:"port,omitempty"`
-	Manufacturer string   `json:"manufacturer,omitempty"`
-	Model        string   `json:"model,omitempty"`
-	Resolution   string   `json:"resolution,omitempty"`
-	FPS          int      `json:"fps,omitempty"`
-	Tags         []string `json:"tags,omitempty"`
-	Status       string   `json:"status,omitempty"` // NEW: tambahkan ini
+	RTSPUrl      string   `json:"rtsp_url" validate:"required_without=channels,omitempty,rtsp_url"`
+	Latitude     float64  `json:"latitude" validate:"min=-90,max=90"`
+	Longitude    float64  `json:"longitude" validate:"min=-180,max=180"`
+	Building     string   `json:"building,omitempty" validate:"max=255"`
+	Zone         string   `json:"zone,omitempty" validate:"max=255"`
+	ZoneID       string   `json:"zone_id,omitempty" validate:"omitempty,uuid"`
+	IPAddress    string   `json:"ip_address,omitempty" validate:"omitempty,ip"`
+	Port         int      `json:"port,omitempty" validate:"omitempty,min=1,max=65535"`
+	Manufacturer string   `json:"manufacturer,omitempty" validate:"max=100"`
+	Model        string   `json:"model,omitempty" validate:"max=100"`
+	Resolution   string   `json:"resolution,omitempty" validate:"max=50"`
+	FPS          int      `json:"fps,omitempty" validate:"omitempty,min=1,max=120"`
+	Tags         []string `json:"tags,omitempty" validate:"dive,required,max=100"`
+	Status       string   `json:"status,omitempty" validate:"omitempty,camera_status"`
+
+	// Channels opsional, jika kosong rtsp_url dipakai sebagai channel utama
+	Channels []CameraChannelRequest `json:"channels,omitempty"`
+
+	// Opsi stream opsional, default on_demand true
+	StreamOptions *StreamOptionsRequest `json:"stream_options,omitempty"`
+
+	// Credential RTSP, menggantikan userinfo di rtsp_url jika diisi
+	RTSPCredentialsRequest
+}
+
+// Normalize menyeragamkan huruf status dan role channel sebelum divalidasi
+func (r *CreateCameraRequest) Normalize() {
+	r.Status = strings.ToUpper(r.Status)
+	normalizeChannelRequests(r.Channels)
 }
 
 // UpdateCameraRequest adalah struktur untuk update kamera
 type UpdateCameraRequest struct {
-	Name         string   `json:"name,omitempty"`
+	Name         string   `json:"name,omitempty" validate:"max=255"`
 	Description  string   `json:"description,omitempty"`
-	RTSPUrl      string   `json:"rtsp_url,omitempty"`
-	Latitude     float64  `json:"latitude,omitempty"`
-	Longitude    float64  `json:"longitude,omitempty"`
-	Building     string   `json:"building,omitempty"`
-	Zone         string   `json:"zone,omitempty"`
-	IPAddress    string   `json:"ip_address,omitempty"`
-	Port         int      `json:"port,omitempty"`
-	Manufacturer string   `json:"manufacturer,omitempty"`
-	Model        string   `json:"model,omitempty"`
-	Resolution   string   `json:"resolution,omitempty"`
-	FPS          int      `json:"fps,omitempty"`
-	Tags         []string `json:"tags,omitempty"`
-	Status       string   `json:"status,omitempty"`
+	RTSPUrl      string   `json:"rtsp_url,omitempty" validate:"omitempty,rtsp_url"`
+	Latitude     float64  `json:"latitude,omitempty" validate:"min=-90,max=90"`
+	Longitude    float64  `json:"longitude,omitempty" validate:"min=-180,max=180"`
+	Building     string   `json:"building,omitempty" validate:"max=255"`
+	Zone         string   `json:"zone,omitempty" validate:"max=255"`
+	ZoneID       string   `json:"zone_id,omitempty" validate:"omitempty,uuid"`
+	IPAddress    string   `json:"ip_address,omitempty" validate:"omitempty,ip"`
+	Port         int      `json:"port,omitempty" validate:"omitempty,min=1,max=65535"`
+	Manufacturer string   `json:"manufacturer,omitempty" validate:"max=100"`
+	Model        string   `json:"model,omitempty" validate:"max=100"`
+	Resolution   string   `json:"resolution,omitempty" validate:"max=50"`
+	FPS          int      `json:"fps,omitempty" validate:"omitempty,min=1,max=120"`
+	Tags         []string `json:"tags,omitempty" validate:"dive,required,max=100"`
+	Status       string   `json:"status,omitempty" validate:"omitempty,camera_status"`
 	IsActive     bool     `json:"is_active,omitempty"`
+
+	// Channels jika diisi akan mengganti seluruh channel camera
+	Channels []CameraChannelRequest `json:"channels,omitempty"`
+
+	// Opsi stream, hanya field yang diisi yang diubah
+	StreamOptions *StreamOptionsRequest `json:"stream_options,omitempty"`
+
+	// Credential RTSP, hanya field yang diisi yang diubah. rtsp_username kosong
+	// menghapus credential.
+	RTSPCredentialsRequest
+}
+
+// Normalize menyeragamkan huruf status dan role channel sebelum divalidasi
+func (r *UpdateCameraRequest) Normalize() {
+	r.Status = strings.ToUpper(r.Status)
+	normalizeChannelRequests(r.Channels)
 }
 
 // CameraWithStream adalah camera dengan informasi stream URL
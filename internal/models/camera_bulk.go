@@ -0,0 +1,101 @@
+package models
+
+// MaxBulkCameras adalah jumlah camera maksimal satu bulk operation
+const MaxBulkCameras = 500
+
+// Aksi bulk operation camera
+const (
+	BulkActionStartStream = "start_stream"
+	BulkActionStopStream  = "stop_stream"
+	BulkActionAddTags     = "add_tags"
+	BulkActionRemoveTags  = "remove_tags"
+	BulkActionMove        = "move"       // Pindah zone dan/atau building
+	BulkActionSetStatus   = "set_status" // Status manual, misalnya maintenance
+	BulkActionDelete      = "delete"     // Soft delete
+)
+
+// BulkCameraActions adalah aksi bulk yang didukung
+var BulkCameraActions = []string{
+	BulkActionStartStream,
+	BulkActionStopStream,
+	BulkActionAddTags,
+	BulkActionRemoveTags,
+	BulkActionMove,
+	BulkActionSetStatus,
+	BulkActionDelete,
+}
+
+// BulkCameraRequest adalah request bulk operation. Target dipilih dengan ids
+// atau filter (tidak boleh keduanya).
+type BulkCameraRequest struct {
+	Action string            `json:"action"`
+	IDs    []string          `json:"ids,omitempty"`
+	Filter *BulkCameraFilter `json:"filter,omitempty"`
+
+	// Parameter aksi
+	Tags     []string `json:"tags,omitempty"`     // add_tags, remove_tags
+	Zone     string   `json:"zone,omitempty"`     // move
+	Building string   `json:"building,omitempty"` // move
+	ZoneID   string   `json:"zone_id,omitempty"`  // move, menggantikan zone dan building
+	Status   string   `json:"status,omitempty"`   // set_status
+}
+
+// BulkCameraFilter memilih camera aktif dengan filter yang sama seperti query
+// GET /cameras. Minimal satu kondisi harus diisi agar bulk operation tidak
+// mengenai semua camera secara tidak sengaja.
+type BulkCameraFilter struct {
+	Query         string   `json:"q,omitempty"`
+	Statuses      []string `json:"status,omitempty"`
+	Buildings     []string `json:"building,omitempty"`
+	Zones         []string `json:"zone,omitempty"`
+	Manufacturers []string `json:"manufacturer,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	TagMatch      string   `json:"tag_match,omitempty"`
+	CreatedBy     string   `json:"created_by,omitempty"`
+}
+
+// IsEmpty mengecek apakah filter tidak berisi kondisi apa pun
+func (f *BulkCameraFilter) IsEmpty() bool {
+	return f.Query == "" && len(f.Statuses) == 0 && len(f.Buildings) == 0 &&
+		len(f.Zones) == 0 && len(f.Manufacturers) == 0 && len(f.Tags) == 0 &&
+		f.CreatedBy == ""
+}
+
+// CameraFilter mengubah filter bulk menjadi filter list camera
+func (f *BulkCameraFilter) CameraFilter() *CameraFilter {
+	return &CameraFilter{
+		Query:         f.Query,
+		Statuses:      f.Statuses,
+		Buildings:     f.Buildings,
+		Zones:         f.Zones,
+		Manufacturers: f.Manufacturers,
+		Active:        CameraActiveOnly,
+		Tags:          f.Tags,
+		TagMatch:      f.TagMatch,
+		CreatedBy:     f.CreatedBy,
+	}
+}
+
+// BulkCameraResult adalah hasil bulk operation untuk satu camera. Changed false
+// jika camera sudah dalam kondisi yang diminta (misalnya stream sudah berjalan).
+type BulkCameraResult struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name,omitempty"`
+	Success    bool              `json:"success"`
+	Changed    bool              `json:"changed"`
+	Error      string            `json:"error,omitempty"`
+	StreamSync *StreamSyncResult `json:"stream_sync,omitempty"`
+}
+
+// BulkCameraReport adalah ringkasan bulk operation. Perubahan database
+// disimpan dalam satu transaksi; operasi stream yang gagal dikirim ke
+// RTSPtoWeb tetap di outbox dan dihitung di StreamsQueued.
+type BulkCameraReport struct {
+	Action        string             `json:"action"`
+	Total         int                `json:"total"`
+	Succeeded     int                `json:"succeeded"`
+	Failed        int                `json:"failed"`
+	Changed       int                `json:"changed"`
+	StreamsQueued int                `json:"streams_queued"`
+	Results       []BulkCameraResult `json:"results"`
+}
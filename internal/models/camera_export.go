@@ -0,0 +1,15 @@
+package models
+
+// Format export camera
+const (
+	CameraExportCSV     = "csv"
+	CameraExportGeoJSON = "geojson"
+	CameraExportKML     = "kml"
+)
+
+// CameraExportContentTypes adalah Content-Type response untuk setiap format export
+var CameraExportContentTypes = map[string]string{
+	CameraExportCSV:     "text/csv; charset=utf-8",
+	CameraExportGeoJSON: "application/geo+json",
+	CameraExportKML:     "application/vnd.google-earth.kml+xml",
+}
@@ -0,0 +1,273 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"cctv-monitoring-backend/internal/utils"
+)
+
+// Nilai filter active pada list camera
+const (
+	CameraActiveOnly   = "true"
+	CameraInactiveOnly = "false"
+	CameraActiveAll    = "all"
+)
+
+// Mode pencocokan filter tags
+const (
+	TagMatchAny = "any" // camera punya minimal satu tag
+	TagMatchAll = "all" // camera punya semua tag
+)
+
+// Batas page_size list camera
+const (
+	DefaultCameraPageSize = 10
+	MaxCameraPageSize     = 100
+)
+
+// CameraSortFields adalah field yang boleh dipakai di parameter sort list camera
+var CameraSortFields = []string{
+	"name",
+	"status",
+	"building",
+	"zone",
+	"manufacturer",
+	"created_at",
+	"updated_at",
+	"last_seen",
+}
+
+// CameraFields adalah field response camera yang bisa dipilih lewat fields=
+var CameraFields = []string{
+	"id", "name", "description", "rtsp_url", "rtsp_username", "has_rtsp_password",
+	"stream_id", "latitude", "longitude", "building", "zone", "zone_id", "ip_address", "port",
+	"manufacturer", "model", "resolution", "fps", "tags", "status", "last_seen",
+	"is_active", "created_by", "created_at", "updated_at", "media_server_id",
+	"stream_options", "sync_status", "sync_error", "hls_url", "snapshot_url",
+	"webrtc_url", "channels", "version", "deleted_at", "deleted_by",
+}
+
+// cameraStreamFields adalah field yang butuh channel dan viewer token
+var cameraStreamFields = []string{"hls_url", "snapshot_url", "webrtc_url", "channels"}
+
+// DefaultCameraSort adalah urutan list camera jika sort tidak diisi
+var DefaultCameraSort = []SortField{{Field: "created_at", Desc: true}}
+
+// SortField adalah satu kolom pengurutan, Desc untuk urutan menurun
+type SortField struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+// CameraFilter adalah filter, sort dan pagination untuk GET /cameras. Filter
+// yang kosong tidak dipakai, semua filter digabung dengan AND dan nilai dalam
+// satu filter digabung dengan OR.
+type CameraFilter struct {
+	Query         string // Dicari di name dan description (case-insensitive)
+	Statuses      []string
+	Buildings     []string
+	Zones         []string
+	Manufacturers []string // Case-insensitive
+	Active        string   // CameraActiveOnly (default), CameraInactiveOnly atau CameraActiveAll
+	Tags          []string
+	TagMatch      string // TagMatchAny (default) atau TagMatchAll
+	CreatedBy     string // ID user
+
+	// ID di hierarki lokasi, camera di zone mana pun di bawah lokasi tersebut
+	SiteIDs     []string
+	BuildingIDs []string
+	FloorIDs    []string
+	ZoneIDs     []string
+
+	// Rentang waktu, inklusif
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+
+	Sort     []SortField
+	Page     int
+	PageSize int
+
+	// Fields adalah sparse fieldset response, kosong = semua field
+	Fields []string
+
+	// Keyset pagination menggantikan page: After kosong untuk halaman pertama.
+	// Total hanya dihitung jika IncludeTotal.
+	Keyset       bool
+	After        *CameraCursor
+	IncludeTotal bool
+}
+
+// NeedsStreamURLs mengecek apakah response butuh channel dan stream URL
+func (f *CameraFilter) NeedsStreamURLs() bool {
+	if len(f.Fields) == 0 {
+		return true
+	}
+	for _, field := range cameraStreamFields {
+		if utils.ContainsString(f.Fields, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// KeysetDesc mengembalikan arah keyset (created_at, id). Keyset hanya bisa
+// dipakai dengan sort created_at (default menurun).
+func (f *CameraFilter) KeysetDesc() (bool, error) {
+	switch {
+	case len(f.Sort) == 0:
+		return true, nil
+	case len(f.Sort) == 1 && f.Sort[0].Field == "created_at":
+		return f.Sort[0].Desc, nil
+	default:
+		return false, fmt.Errorf("cursor pagination only supports sort=created_at or sort=-created_at")
+	}
+}
+
+// CameraCursor adalah posisi keyset (created_at, id) camera terakhir di satu
+// halaman. Desc mencatat arah urutan agar cursor tidak dipakai dengan sort lain.
+type CameraCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        string    `json:"i"`
+	Desc      bool      `json:"d"`
+}
+
+// Encode mengubah cursor menjadi string opaque untuk client
+func (c CameraCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCameraCursor membaca cursor hasil Encode
+func DecodeCameraCursor(raw string) (*CameraCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var cursor CameraCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" || cursor.CreatedAt.IsZero() {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &cursor, nil
+}
+
+// ParseCameraFields membaca parameter fields, misalnya "id,name,latitude".
+// id selalu disertakan. Field yang tidak dikenal ditolak.
+func ParseCameraFields(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	fields := []string{"id"}
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || utils.ContainsString(fields, field) {
+			continue
+		}
+		if !utils.ContainsString(CameraFields, field) {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// ProjectCameras mengembalikan cameras dengan hanya field yang dipilih. Tanpa
+// fields, cameras dikembalikan apa adanya.
+func ProjectCameras(cameras []*Camera, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return cameras, nil
+	}
+
+	result := make([]map[string]json.RawMessage, len(cameras))
+	for i, camera := range cameras {
+		data, err := json.Marshal(camera)
+		if err != nil {
+			return nil, err
+		}
+
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, err
+		}
+
+		// Field omitempty yang kosong tetap tidak ditulis
+		projected := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := all[field]; ok {
+				projected[field] = value
+			}
+		}
+		result[i] = projected
+	}
+
+	return result, nil
+}
+
+// Normalize mengisi default dan membatasi pagination
+func (f *CameraFilter) Normalize() {
+	if f.Active == "" {
+		f.Active = CameraActiveOnly
+	}
+	if f.TagMatch == "" {
+		f.TagMatch = TagMatchAny
+	}
+	if len(f.Sort) == 0 {
+		f.Sort = DefaultCameraSort
+	}
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.PageSize < 1 {
+		f.PageSize = DefaultCameraPageSize
+	}
+	if f.PageSize > MaxCameraPageSize {
+		f.PageSize = MaxCameraPageSize
+	}
+}
+
+// ParseCameraSort membaca parameter sort, misalnya "zone,-created_at". Prefix
+// "-" untuk urutan menurun. Field yang tidak dikenal atau diulang ditolak.
+func ParseCameraSort(raw string) ([]SortField, error) {
+	var fields []SortField
+	seen := map[string]bool{}
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		field := SortField{Field: part}
+		if strings.HasPrefix(part, "-") {
+			field = SortField{Field: part[1:], Desc: true}
+		} else if strings.HasPrefix(part, "+") {
+			field.Field = part[1:]
+		}
+
+		if !isCameraSortField(field.Field) {
+			return nil, fmt.Errorf("unknown sort field %q (allowed: %s)", field.Field, strings.Join(CameraSortFields, ", "))
+		}
+		if seen[field.Field] {
+			return nil, fmt.Errorf("duplicate sort field %q", field.Field)
+		}
+		seen[field.Field] = true
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// isCameraSortField mengecek field ada di CameraSortFields
+func isCameraSortField(field string) bool {
+	return utils.ContainsString(CameraSortFields, field)
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseCameraSort(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    []SortField
+		wantErr bool
+	}{
+		{raw: "", want: nil},
+		{raw: "zone,-created_at", want: []SortField{{Field: "zone"}, {Field: "created_at", Desc: true}}},
+		{raw: " +name , -last_seen ", want: []SortField{{Field: "name"}, {Field: "last_seen", Desc: true}}},
+		{raw: "rtsp_url", wantErr: true},
+		{raw: "name,-name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCameraSort(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCameraSort(%q) error = %v, want error %v", tt.raw, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseCameraSort(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestCameraFilterNormalize(t *testing.T) {
+	filter := CameraFilter{Page: -1, PageSize: MaxCameraPageSize + 1}
+	filter.Normalize()
+
+	if filter.Active != CameraActiveOnly || filter.TagMatch != TagMatchAny {
+		t.Errorf("defaults = %q, %q, want %q, %q", filter.Active, filter.TagMatch, CameraActiveOnly, TagMatchAny)
+	}
+	if filter.Page != 1 || filter.PageSize != MaxCameraPageSize {
+		t.Errorf("page = %d, page_size = %d, want 1, %d", filter.Page, filter.PageSize, MaxCameraPageSize)
+	}
+	if !reflect.DeepEqual(filter.Sort, DefaultCameraSort) {
+		t.Errorf("sort = %v, want %v", filter.Sort, DefaultCameraSort)
+	}
+}
+
+func TestCameraCursorRoundTrip(t *testing.T) {
+	cursor := CameraCursor{
+		CreatedAt: time.Date(2026, 3, 1, 8, 0, 0, 123000, time.UTC),
+		ID:        "5f0c6a56-7c1e-4b43-9c55-2f7d2f6f1a10",
+		Desc:      true,
+	}
+
+	decoded, err := DecodeCameraCursor(cursor.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.CreatedAt.Equal(cursor.CreatedAt) || decoded.ID != cursor.ID || decoded.Desc != cursor.Desc {
+		t.Errorf("decoded cursor = %+v, want %+v", decoded, cursor)
+	}
+
+	for _, raw := range []string{"not-base64!", "e30", CameraCursor{ID: "x"}.Encode()} {
+		if _, err := DecodeCameraCursor(raw); err == nil {
+			t.Errorf("DecodeCameraCursor(%q) accepted an invalid cursor", raw)
+		}
+	}
+}
+
+func TestCameraFilterKeysetDesc(t *testing.T) {
+	tests := []struct {
+		sort     []SortField
+		wantDesc bool
+		wantErr  bool
+	}{
+		{sort: nil, wantDesc: true},
+		{sort: []SortField{{Field: "created_at", Desc: true}}, wantDesc: true},
+		{sort: []SortField{{Field: "created_at"}}, wantDesc: false},
+		{sort: []SortField{{Field: "name"}}, wantErr: true},
+		{sort: []SortField{{Field: "created_at"}, {Field: "name"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		filter := CameraFilter{Sort: tt.sort}
+		desc, err := filter.KeysetDesc()
+		if (err != nil) != tt.wantErr || (err == nil && desc != tt.wantDesc) {
+			t.Errorf("KeysetDesc(%v) = %v, %v, want %v (error %v)", tt.sort, desc, err, tt.wantDesc, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package models
+
+// MaxCameraImportRows adalah jumlah baris maksimal satu bulk import
+const MaxCameraImportRows = 500
+
+// Format file bulk import camera
+const (
+	CameraImportCSV  = "csv"
+	CameraImportJSON = "json"
+)
+
+// CameraImportInput adalah satu baris bulk import sebelum divalidasi. Errors
+// berisi kesalahan parsing baris (mis. latitude bukan angka).
+type CameraImportInput struct {
+	Row     int
+	Request CreateCameraRequest
+	Errors  []string
+}
+
+// CameraImportRow adalah hasil validasi dan import satu baris
+type CameraImportRow struct {
+	Row        int               `json:"row"`
+	Name       string            `json:"name"`
+	RTSPUrl    string            `json:"rtsp_url,omitempty"` // Tanpa credential
+	Valid      bool              `json:"valid"`
+	Errors     []string          `json:"errors,omitempty"`
+	CameraID   string            `json:"camera_id,omitempty"`
+	StreamSync *StreamSyncResult `json:"stream_sync,omitempty"`
+}
+
+// CameraImportReport adalah hasil bulk import camera. Camera hanya dibuat jika
+// semua baris valid dan bukan dry run.
+type CameraImportReport struct {
+	DryRun        bool              `json:"dry_run"`
+	Total         int               `json:"total"`
+	Valid         int               `json:"valid"`
+	Invalid       int               `json:"invalid"`
+	Created       int               `json:"created"`
+	StreamsQueued int               `json:"streams_queued"`
+	Rows          []CameraImportRow `json:"rows"`
+}
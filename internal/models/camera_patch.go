@@ -0,0 +1,64 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// MergePatchContentType adalah Content-Type JSON Merge Patch (RFC 7396)
+const MergePatchContentType = "application/merge-patch+json"
+
+// CameraPatchFields adalah key yang boleh diubah lewat PATCH /cameras/:id
+var CameraPatchFields = []string{
+	"name", "description", "rtsp_url", "channels", "rtsp_username", "rtsp_password",
+	"latitude", "longitude", "building", "zone", "zone_id", "ip_address", "port",
+	"manufacturer", "model", "resolution", "fps", "tags", "status", "is_active",
+	"stream_options",
+}
+
+// CameraPatch adalah body JSON Merge Patch untuk camera. Key yang tidak ada
+// tidak diubah, nilai null menghapus (atau mengembalikan ke default) field.
+type CameraPatch map[string]json.RawMessage
+
+// ParseCameraPatch membaca body merge patch. Body harus berupa object JSON.
+func ParseCameraPatch(data []byte) (CameraPatch, error) {
+	var patch CameraPatch
+	if err := json.Unmarshal(data, &patch); err != nil || patch == nil {
+		return nil, errors.New("body must be a JSON object")
+	}
+	return patch, nil
+}
+
+// IsJSONNull mengecek apakah nilai JSON adalah null
+func IsJSONNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// ETag mengembalikan entity tag camera dari version, misalnya "3"
+func (c *Camera) ETag() string {
+	return `"` + strconv.Itoa(c.Version) + `"`
+}
+
+// ParseIfMatch membaca header If-Match menjadi version camera. 0 berarti
+// header kosong atau "*" (tanpa pengecekan version).
+func ParseIfMatch(header string) (int, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, errors.New(`If-Match must be a single ETag, e.g. "3"`)
+	}
+
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version < 1 {
+		return 0, errors.New("If-Match does not match any camera version")
+	}
+
+	return version, nil
+}
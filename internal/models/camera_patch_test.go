@@ -0,0 +1,51 @@
+package models
+
+import "testing"
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    int
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"*", 0, false},
+		{`"3"`, 3, false},
+		{` W/"7" `, 7, false},
+		{"3", 0, true},
+		{`"0"`, 0, true},
+		{`"abc"`, 0, true},
+		{`"1", "2"`, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseIfMatch(tt.header)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseIfMatch(%q) = %d, %v, want %d, error %v", tt.header, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestETagRoundTrip(t *testing.T) {
+	camera := &Camera{Version: 12}
+	version, err := ParseIfMatch(camera.ETag())
+	if err != nil || version != camera.Version {
+		t.Fatalf("ParseIfMatch(%s) = %d, %v, want %d", camera.ETag(), version, err, camera.Version)
+	}
+}
+
+func TestParseCameraPatch(t *testing.T) {
+	patch, err := ParseCameraPatch([]byte(`{"description": null, "fps": 25}`))
+	if err != nil {
+		t.Fatalf("ParseCameraPatch() error = %v", err)
+	}
+	if !IsJSONNull(patch["description"]) || IsJSONNull(patch["fps"]) {
+		t.Errorf("null detection wrong: description %s, fps %s", patch["description"], patch["fps"])
+	}
+
+	for _, body := range []string{`null`, `[]`, `"name"`, `{`} {
+		if _, err := ParseCameraPatch([]byte(body)); err == nil {
+			t.Errorf("ParseCameraPatch(%s) accepted", body)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package models
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"cctv-monitoring-backend/internal/utils"
+)
+
+// Action revision camera
+const (
+	RevisionActionCreate       = "create"
+	RevisionActionImport       = "import"
+	RevisionActionConfigImport = "config_import"
+	RevisionActionUpdate       = "update"
+	RevisionActionPatch        = "patch"
+	RevisionActionRevert       = "revert"
+	RevisionActionDelete       = "delete"
+	RevisionActionRestore      = "restore"
+	RevisionActionBulk         = "bulk" // ditulis "bulk:<aksi>", misalnya bulk:add_tags
+	RevisionActionLocationSync = "location_sync"
+
+	RevisionActionCredentialRotation = "credential_rotation"
+)
+
+// cameraRevisionReadOnly adalah field revision yang tidak bisa dikembalikan
+// lewat revert (password tidak disimpan di revision)
+var cameraRevisionReadOnly = []string{"has_rtsp_password"}
+
+// FieldChange adalah nilai satu field sebelum dan sesudah perubahan. Before
+// null untuk camera baru.
+type FieldChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// CameraRevision adalah satu perubahan camera di tabel camera_revisions
+type CameraRevision struct {
+	ID       int64                  `json:"id"`
+	CameraID string                 `json:"camera_id"`
+	Version  int                    `json:"version"` // version camera setelah perubahan
+	UserID   sql.NullString         `json:"-"`
+	Username sql.NullString         `json:"-"`
+	Action   string                 `json:"action"`
+	Changes  map[string]FieldChange `json:"changes"`
+	// RevertedTo diisi untuk revision hasil revert
+	RevertedTo sql.NullInt64 `json:"-"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// MarshalJSON menulis field nullable revision sebagai nilai atau null
+func (r CameraRevision) MarshalJSON() ([]byte, error) {
+	type Alias CameraRevision
+	return json.Marshal(&struct {
+		Alias
+		UserID     *string `json:"user_id"`
+		Username   *string `json:"username"`
+		RevertedTo *int64  `json:"reverted_to,omitempty"`
+	}{
+		Alias:      Alias(r),
+		UserID:     nullStringPtr(r.UserID),
+		Username:   nullStringPtr(r.Username),
+		RevertedTo: nullInt64ToPtr(r.RevertedTo),
+	})
+}
+
+// CameraSnapshot adalah nilai JSON field revision camera pada satu waktu.
+// channels hanya ada jika Channels camera sudah dimuat.
+type CameraSnapshot map[string]json.RawMessage
+
+// revisionChannel adalah channel di snapshot, formatnya sama dengan
+// CameraChannelRequest agar bisa dipakai ulang saat revert
+type revisionChannel struct {
+	Label   string `json:"label,omitempty"`
+	RTSPUrl string `json:"rtsp_url"`
+	Role    string `json:"role,omitempty"`
+}
+
+// NewCameraSnapshot mengambil snapshot field revision camera. Status, stream_id
+// dan field runtime lain diubah monitor, bukan user, dan tidak dicatat. URL di
+// snapshot tidak berisi credential karena credential disimpan terpisah.
+func NewCameraSnapshot(c *Camera) CameraSnapshot {
+	tags := c.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	values := map[string]interface{}{
+		"name":              c.Name,
+		"description":       nullStringPtr(c.Description),
+		"rtsp_url":          c.RTSPUrl,
+		"rtsp_username":     nullStringPtr(c.RTSPUsername),
+		"has_rtsp_password": c.RTSPPasswordEnc.Valid,
+		"latitude":          c.Latitude,
+		"longitude":         c.Longitude,
+		"building":          nullStringPtr(c.Building),
+		"zone":              nullStringPtr(c.Zone),
+		"zone_id":           nullStringPtr(c.ZoneID),
+		"ip_address":        nullStringPtr(c.IPAddress),
+		"port":              nullInt64ToPtr(c.Port),
+		"manufacturer":      nullStringPtr(c.Manufacturer),
+		"model":             nullStringPtr(c.Model),
+		"resolution":        nullStringPtr(c.Resolution),
+		"fps":               c.FPS,
+		"tags":              tags,
+		"is_active":         c.IsActive,
+		"stream_options":    c.StreamOptions,
+	}
+
+	if c.Channels != nil {
+		channels := make([]CameraChannel, len(c.Channels))
+		copy(channels, c.Channels)
+		sort.SliceStable(channels, func(i, j int) bool { return channels[i].Index < channels[j].Index })
+
+		snapshot := make([]revisionChannel, len(channels))
+		for i, channel := range channels {
+			snapshot[i] = revisionChannel{Label: channel.Label, RTSPUrl: channel.RTSPUrl, Role: channel.Role}
+		}
+		values["channels"] = snapshot
+	}
+
+	result := make(CameraSnapshot, len(values))
+	for field, value := range values {
+		data, _ := json.Marshal(value)
+		result[field] = data
+	}
+	return result
+}
+
+// Diff mengembalikan field yang berbeda antara snapshot s (sebelum) dan after.
+// Field yang tidak ada di after tidak berubah. s nil berarti camera baru.
+func (s CameraSnapshot) Diff(after CameraSnapshot) map[string]FieldChange {
+	changes := map[string]FieldChange{}
+	for field, value := range after {
+		before, ok := s[field]
+		if !ok {
+			if s != nil {
+				continue
+			}
+			before = json.RawMessage("null")
+		}
+		if !bytes.Equal(canonicalJSON(before), canonicalJSON(value)) {
+			changes[field] = FieldChange{Before: before, After: value}
+		}
+	}
+	return changes
+}
+
+// canonicalJSON menyamakan format nilai JSON (urutan key dan spasi), karena
+// nilai yang dibaca dari kolom JSONB tidak sama persis dengan hasil json.Marshal
+func canonicalJSON(raw json.RawMessage) []byte {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return raw
+	}
+	data, _ := json.Marshal(value)
+	return data
+}
+
+// NewCameraRevision membuat revision dari snapshot sebelum perubahan dan camera
+// sesudahnya. nil jika tidak ada field revision yang berubah.
+func NewCameraRevision(camera *Camera, before CameraSnapshot, userID, action string) *CameraRevision {
+	changes := before.Diff(NewCameraSnapshot(camera))
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return &CameraRevision{
+		CameraID: camera.ID,
+		Version:  camera.Version,
+		UserID:   sql.NullString{String: userID, Valid: userID != ""},
+		Action:   action,
+		Changes:  changes,
+	}
+}
+
+// CameraRevertPlan adalah perubahan yang dilakukan jika camera dikembalikan ke
+// sebuah revision. Changes berisi nilai saat ini (before) dan nilai revision
+// (after); Skipped berisi field yang tidak bisa dikembalikan.
+type CameraRevertPlan struct {
+	Revision *CameraRevision        `json:"revision"`
+	Changes  map[string]FieldChange `json:"changes"`
+	Skipped  []string               `json:"skipped,omitempty"`
+}
+
+// NewCameraRevertPlan menghitung plan revert camera ke state setelah revision.
+// later adalah revision camera setelah revision tersebut, urut dari yang lama.
+func NewCameraRevertPlan(revision *CameraRevision, later []*CameraRevision, current CameraSnapshot) *CameraRevertPlan {
+	// Nilai field setelah revision = nilai before di perubahan pertama sesudahnya
+	target := CameraSnapshot{}
+	for _, rev := range later {
+		for field, change := range rev.Changes {
+			if _, ok := target[field]; !ok {
+				target[field] = change.Before
+			}
+		}
+	}
+
+	plan := &CameraRevertPlan{Revision: revision, Changes: current.Diff(target)}
+	for _, field := range cameraRevisionReadOnly {
+		if _, ok := plan.Changes[field]; ok {
+			plan.Skipped = append(plan.Skipped, field)
+		}
+	}
+	return plan
+}
+
+// Patch mengubah plan menjadi merge patch camera. rtsp_url dibuang jika
+// channels ikut dikembalikan, karena rtsp_url sama dengan URL channel MAIN.
+func (p *CameraRevertPlan) Patch() CameraPatch {
+	patch := CameraPatch{}
+	for field, change := range p.Changes {
+		if utils.ContainsString(cameraRevisionReadOnly, field) {
+			continue
+		}
+		patch[field] = change.After
+	}
+	if _, ok := patch["channels"]; ok {
+		delete(patch, "rtsp_url")
+	}
+	return patch
+}
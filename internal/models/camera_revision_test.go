@@ -0,0 +1,168 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+)
+
+// revisionCamera mengembalikan camera dengan field revision terisi
+func revisionCamera() *Camera {
+	return &Camera{
+		ID:          "cam-1",
+		Name:        "Lobby",
+		RTSPUrl:     "rtsp://10.0.0.1/live",
+		Description: sql.NullString{String: "Pintu utama", Valid: true},
+		Port:        sql.NullInt64{Int64: 554, Valid: true},
+		Tags:        []string{"lobby"},
+		IsActive:    true,
+		Version:     1,
+	}
+}
+
+// change membuat FieldChange dari nilai Go
+func change(before, after interface{}) FieldChange {
+	b, _ := json.Marshal(before)
+	a, _ := json.Marshal(after)
+	return FieldChange{Before: b, After: a}
+}
+
+func TestNewCameraRevisionDiff(t *testing.T) {
+	camera := revisionCamera()
+	before := NewCameraSnapshot(camera)
+
+	camera.Name = "Lobby Timur"
+	camera.Port = sql.NullInt64{}
+	camera.Status = CameraStatusOffline // runtime, tidak dicatat
+	camera.Version = 2
+
+	revision := NewCameraRevision(camera, before, "user-1", RevisionActionPatch)
+	if revision == nil {
+		t.Fatal("NewCameraRevision() = nil")
+	}
+	if len(revision.Changes) != 2 {
+		t.Fatalf("changes = %v, want name and port", revision.Changes)
+	}
+	if string(revision.Changes["port"].Before) != "554" || string(revision.Changes["port"].After) != "null" {
+		t.Errorf("port change = %s -> %s", revision.Changes["port"].Before, revision.Changes["port"].After)
+	}
+	if revision.Version != 2 || revision.UserID.String != "user-1" {
+		t.Errorf("revision = %+v", revision)
+	}
+
+	// Tanpa perubahan field revision tidak ada revision
+	if NewCameraRevision(camera, NewCameraSnapshot(camera), "user-1", RevisionActionPatch) != nil {
+		t.Error("revision created without changes")
+	}
+}
+
+func TestNewCameraRevisionCreate(t *testing.T) {
+	revision := NewCameraRevision(revisionCamera(), nil, "", RevisionActionCreate)
+	if revision == nil || revision.UserID.Valid {
+		t.Fatalf("revision = %+v, want system revision", revision)
+	}
+	if string(revision.Changes["name"].Before) != "null" || string(revision.Changes["name"].After) != `"Lobby"` {
+		t.Errorf("name change = %+v", revision.Changes["name"])
+	}
+}
+
+func TestSnapshotDiffIgnoresJSONFormat(t *testing.T) {
+	before := CameraSnapshot{"stream_options": json.RawMessage(`{"audio": false, "on_demand": true}`)}
+	after := CameraSnapshot{"stream_options": json.RawMessage(`{"on_demand":true,"audio":false}`)}
+
+	if changes := before.Diff(after); len(changes) != 0 {
+		t.Errorf("Diff() = %v, want no changes", changes)
+	}
+}
+
+func TestNewCameraRevertPlan(t *testing.T) {
+	// Revision 1: create. Revision 2: name dan port diubah. Revision 3: name
+	// diubah lagi dan camera diberi password.
+	revision := &CameraRevision{ID: 1, Action: RevisionActionCreate}
+	later := []*CameraRevision{
+		{ID: 2, Changes: map[string]FieldChange{"name": change("Lobby", "Lobby Timur"), "port": change(554, nil)}},
+		{ID: 3, Changes: map[string]FieldChange{"name": change("Lobby Timur", "Lobby Barat"), "has_rtsp_password": change(false, true)}},
+	}
+
+	camera := revisionCamera()
+	camera.Name = "Lobby Barat"
+	camera.Port = sql.NullInt64{}
+	camera.RTSPPasswordEnc = sql.NullString{String: "enc", Valid: true}
+
+	plan := NewCameraRevertPlan(revision, later, NewCameraSnapshot(camera))
+
+	// Nilai target diambil dari before perubahan pertama setelah revision
+	want := map[string]FieldChange{
+		"name":              change("Lobby Barat", "Lobby"),
+		"port":              change(nil, 554),
+		"has_rtsp_password": change(true, false),
+	}
+	if len(plan.Changes) != len(want) {
+		t.Fatalf("plan changes = %v, want %v", plan.Changes, want)
+	}
+	for field, w := range want {
+		got := plan.Changes[field]
+		if string(canonicalJSON(got.Before)) != string(w.Before) || string(canonicalJSON(got.After)) != string(w.After) {
+			t.Errorf("%s: %s -> %s, want %s -> %s", field, got.Before, got.After, w.Before, w.After)
+		}
+	}
+	if len(plan.Skipped) != 1 || plan.Skipped[0] != "has_rtsp_password" {
+		t.Errorf("skipped = %v, want has_rtsp_password", plan.Skipped)
+	}
+
+	// Password tidak bisa dikembalikan lewat patch
+	patch := plan.Patch()
+	if _, ok := patch["has_rtsp_password"]; ok || len(patch) != 2 {
+		t.Errorf("patch = %v, want name and port", patch)
+	}
+}
+
+func TestNewCameraRevertPlanLatestRevision(t *testing.T) {
+	// Revert ke revision terakhir tidak mengubah apa-apa
+	plan := NewCameraRevertPlan(&CameraRevision{ID: 3}, nil, NewCameraSnapshot(revisionCamera()))
+	if len(plan.Changes) != 0 || len(plan.Patch()) != 0 {
+		t.Errorf("plan = %+v, want no changes", plan)
+	}
+}
+
+func TestRevertPlanPatchDropsRTSPURLWithChannels(t *testing.T) {
+	plan := &CameraRevertPlan{Changes: map[string]FieldChange{
+		"rtsp_url": change("rtsp://10.0.0.2/live", "rtsp://10.0.0.1/live"),
+		"channels": change(nil, []revisionChannel{{RTSPUrl: "rtsp://10.0.0.1/live", Role: ChannelRoleMain}}),
+	}}
+
+	patch := plan.Patch()
+	if _, ok := patch["rtsp_url"]; ok {
+		t.Errorf("patch = %v, rtsp_url must be dropped when channels are reverted", patch)
+	}
+	if _, ok := patch["channels"]; !ok {
+		t.Errorf("patch = %v, want channels", patch)
+	}
+}
+
+func TestCameraRevisionMarshalJSON(t *testing.T) {
+	revision := CameraRevision{
+		ID:         4,
+		UserID:     sql.NullString{String: "user-1", Valid: true},
+		RevertedTo: sql.NullInt64{Int64: 2, Valid: true},
+		Changes:    map[string]FieldChange{},
+	}
+
+	var got map[string]interface{}
+	data, _ := json.Marshal(revision)
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["user_id"] != "user-1" || got["username"] != nil || got["reverted_to"] != float64(2) {
+		t.Errorf("MarshalJSON() = %s", data)
+	}
+
+	got = nil
+	data, _ = json.Marshal(CameraRevision{})
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["reverted_to"]; ok {
+		t.Errorf("reverted_to written for normal revision: %s", data)
+	}
+}
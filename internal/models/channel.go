@@ -0,0 +1,43 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Channel roles
+const (
+	ChannelRoleMain = "MAIN" // Main stream, resolusi tinggi (fullscreen)
+	ChannelRoleSub  = "SUB"  // Sub stream, bitrate rendah (grid view)
+)
+
+// CameraChannel merepresentasikan satu channel stream dari kamera
+type CameraChannel struct {
+	ID        string    `json:"id"`
+	CameraID  string    `json:"-"`
+	Index     int       `json:"index"`
+	Label     string    `json:"label"`
+	RTSPUrl   string    `json:"rtsp_url"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Stream URLs (not stored in DB, generated dynamically)
+	HLSUrl      string `json:"hls_url,omitempty"`
+	SnapshotUrl string `json:"snapshot_url,omitempty"`
+	WebRTCUrl   string `json:"webrtc_url,omitempty"`
+}
+
+// CameraChannelRequest adalah struktur channel pada request create/update kamera
+type CameraChannelRequest struct {
+	Label   string `json:"label,omitempty" validate:"max=100"`
+	RTSPUrl string `json:"rtsp_url" validate:"required,rtsp_url"`
+	Role    string `json:"role,omitempty" validate:"omitempty,oneof=MAIN SUB"`
+}
+
+// normalizeChannelRequests mengubah role channel menjadi huruf besar
+func normalizeChannelRequests(channels []CameraChannelRequest) {
+	for i := range channels {
+		channels[i].Role = strings.ToUpper(channels[i].Role)
+	}
+}
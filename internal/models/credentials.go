@@ -0,0 +1,88 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+)
+
+// PasswordPlaceholder menggantikan password RTSP di response API
+const PasswordPlaceholder = "xxxxx"
+
+// SplitRTSPCredentials memisahkan userinfo dari RTSP URL. ok false jika URL tidak
+// berisi credential. URL yang tidak bisa di-parse dikembalikan tanpa userinfo
+// agar password tidak ikut tersimpan atau tampil.
+func SplitRTSPCredentials(rawURL string) (stripped, username, password string, ok bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return redactUserinfo(rawURL), "", "", false
+	}
+	if u.User == nil {
+		return rawURL, "", "", false
+	}
+
+	username = u.User.Username()
+	password, _ = u.User.Password()
+	u.User = nil
+
+	return u.String(), username, password, true
+}
+
+// WithRTSPCredentials menyisipkan username dan password ke RTSP URL. URL yang
+// sudah berisi credential atau username kosong dikembalikan apa adanya, URL yang
+// tidak bisa di-parse dikembalikan tanpa userinfo.
+func WithRTSPCredentials(rawURL, username, password string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return redactUserinfo(rawURL)
+	}
+	if username == "" || u.User != nil {
+		return rawURL
+	}
+
+	if password == "" {
+		u.User = url.User(username)
+	} else {
+		u.User = url.UserPassword(username, password)
+	}
+
+	return u.String()
+}
+
+// HasUnparseableCredentials mengembalikan true jika rawURL tidak bisa di-parse
+// tapi kemungkinan berisi userinfo, sehingga credential-nya tidak bisa dipisahkan
+func HasUnparseableCredentials(rawURL string) bool {
+	_, err := url.Parse(rawURL)
+	return err != nil && strings.Contains(rawURL, "@")
+}
+
+// redactUserinfo membuang semua teks sebelum '@' terakhir setelah scheme, untuk
+// URL yang tidak bisa di-parse. Bagian path bisa ikut terbuang, lebih baik
+// daripada password tampil.
+func redactUserinfo(rawURL string) string {
+	prefix, rest := "", rawURL
+	if i := strings.Index(rawURL, "://"); i >= 0 {
+		prefix, rest = rawURL[:i+3], rawURL[i+3:]
+	}
+	if at := strings.LastIndex(rest, "@"); at >= 0 {
+		return prefix + rest[at+1:]
+	}
+	return rawURL
+}
+
+// RTSPCredentialsRequest adalah credential RTSP di request create/update camera.
+// Field nil tidak diubah; credential di userinfo rtsp_url tetap diterima.
+type RTSPCredentialsRequest struct {
+	Username *string `json:"rtsp_username,omitempty"`
+	Password *string `json:"rtsp_password,omitempty"`
+}
+
+// CredentialRotationReport adalah hasil menyegel ulang credential camera dan
+// password node. Skipped adalah camera yang URL-nya tidak bisa di-parse dan
+// harus diperbaiki lewat update camera.
+type CredentialRotationReport struct {
+	ActiveKeyID string `json:"active_key_id"`
+	Total       int    `json:"total"`
+	Rotated     int    `json:"rotated"`
+	Skipped     int    `json:"skipped"`
+	Failed      int    `json:"failed"`
+}
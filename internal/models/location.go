@@ -0,0 +1,287 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// Site adalah lokasi teratas hierarki (kampus, kantor cabang)
+type Site struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Address   sql.NullString  `json:"-"`
+	Geometry  json.RawMessage `json:"geometry,omitempty"` // GeoJSON geometry
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// MarshalJSON menulis address sebagai string atau null
+func (s Site) MarshalJSON() ([]byte, error) {
+	type Alias Site
+	return json.Marshal(&struct {
+		Alias
+		Address *string `json:"address"`
+	}{
+		Alias:   Alias(s),
+		Address: nullStringPtr(s.Address),
+	})
+}
+
+// Building adalah gedung di dalam site
+type Building struct {
+	ID        string          `json:"id"`
+	SiteID    string          `json:"site_id"`
+	Name      string          `json:"name"`
+	Address   sql.NullString  `json:"-"`
+	Geometry  json.RawMessage `json:"geometry,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// MarshalJSON menulis address sebagai string atau null
+func (b Building) MarshalJSON() ([]byte, error) {
+	type Alias Building
+	return json.Marshal(&struct {
+		Alias
+		Address *string `json:"address"`
+	}{
+		Alias:   Alias(b),
+		Address: nullStringPtr(b.Address),
+	})
+}
+
+// Floor adalah lantai di dalam building. Level dipakai untuk urutan (basement
+// negatif).
+type Floor struct {
+	ID         string          `json:"id"`
+	BuildingID string          `json:"building_id"`
+	Name       string          `json:"name"`
+	Level      int             `json:"level"`
+	Geometry   json.RawMessage `json:"geometry,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// Zone adalah area tempat camera dipasang. Floor opsional, zone tanpa floor
+// langsung berada di bawah building.
+type Zone struct {
+	ID         string          `json:"id"`
+	BuildingID string          `json:"building_id"`
+	FloorID    sql.NullString  `json:"-"`
+	Name       string          `json:"name"`
+	Geometry   json.RawMessage `json:"geometry,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// MarshalJSON menulis floor_id sebagai string atau null
+func (z Zone) MarshalJSON() ([]byte, error) {
+	type Alias Zone
+	return json.Marshal(&struct {
+		Alias
+		FloorID *string `json:"floor_id"`
+	}{
+		Alias:   Alias(z),
+		FloorID: nullStringPtr(z.FloorID),
+	})
+}
+
+// CreateSiteRequest adalah struktur untuk membuat site
+type CreateSiteRequest struct {
+	Name     string          `json:"name" validate:"required,max=255"`
+	Address  string          `json:"address,omitempty"`
+	Geometry json.RawMessage `json:"geometry,omitempty" validate:"omitempty,geojson"`
+}
+
+// UpdateSiteRequest adalah struktur untuk update site. Field kosong tidak
+// diubah, address "" dan geometry null menghapus nilainya.
+type UpdateSiteRequest struct {
+	Name     string          `json:"name,omitempty" validate:"max=255"`
+	Address  *string         `json:"address,omitempty"`
+	Geometry json.RawMessage `json:"geometry,omitempty" validate:"omitempty,geojson"`
+}
+
+// CreateBuildingRequest adalah struktur untuk membuat building
+type CreateBuildingRequest struct {
+	SiteID   string          `json:"site_id" validate:"required,uuid"`
+	Name     string          `json:"name" validate:"required,max=255"`
+	Address  string          `json:"address,omitempty"`
+	Geometry json.RawMessage `json:"geometry,omitempty" validate:"omitempty,geojson"`
+}
+
+// UpdateBuildingRequest adalah struktur untuk update building, site_id
+// memindahkan building ke site lain
+type UpdateBuildingRequest struct {
+	SiteID   string          `json:"site_id,omitempty" validate:"omitempty,uuid"`
+	Name     string          `json:"name,omitempty" validate:"max=255"`
+	Address  *string         `json:"address,omitempty"`
+	Geometry json.RawMessage `json:"geometry,omitempty" validate:"omitempty,geojson"`
+}
+
+// CreateFloorRequest adalah struktur untuk membuat floor
+type CreateFloorRequest struct {
+	BuildingID string          `json:"building_id" validate:"required,uuid"`
+	Name       string          `json:"name" validate:"required,max=100"`
+	Level      int             `json:"level"`
+	Geometry   json.RawMessage `json:"geometry,omitempty" validate:"omitempty,geojson"`
+}
+
+// UpdateFloorRequest adalah struktur untuk update floor. Floor tidak bisa
+// dipindah ke building lain.
+type UpdateFloorRequest struct {
+	Name     string          `json:"name,omitempty" validate:"max=100"`
+	Level    *int            `json:"level,omitempty"`
+	Geometry json.RawMessage `json:"geometry,omitempty" validate:"omitempty,geojson"`
+}
+
+// CreateZoneRequest adalah struktur untuk membuat zone. building_id boleh
+// kosong jika floor_id diisi.
+type CreateZoneRequest struct {
+	BuildingID string          `json:"building_id,omitempty" validate:"required_without=floor_id,omitempty,uuid"`
+	FloorID    string          `json:"floor_id,omitempty" validate:"omitempty,uuid"`
+	Name       string          `json:"name" validate:"required,max=255"`
+	Geometry   json.RawMessage `json:"geometry,omitempty" validate:"omitempty,geojson"`
+}
+
+// UpdateZoneRequest adalah struktur untuk update zone. floor_id memindahkan
+// zone ke floor lain (building ikut floor), floor_id "" melepas zone dari floor.
+type UpdateZoneRequest struct {
+	BuildingID string          `json:"building_id,omitempty" validate:"omitempty,uuid"`
+	FloorID    *string         `json:"floor_id,omitempty" validate:"omitempty,uuid"`
+	Name       string          `json:"name,omitempty" validate:"max=255"`
+	Geometry   json.RawMessage `json:"geometry,omitempty" validate:"omitempty,geojson"`
+}
+
+// CameraLocationSync adalah camera yang kolom building dan zone-nya disalin
+// ulang dari hierarki lokasi, beserta state sebelumnya
+type CameraLocationSync struct {
+	Before *Camera
+	After  *Camera
+}
+
+// LocationCounts adalah jumlah camera aktif di satu lokasi
+type LocationCounts struct {
+	CameraCount int `json:"camera_count"`
+	OnlineCount int `json:"online_count"`
+}
+
+// add menjumlahkan counts lokasi anak
+func (c *LocationCounts) add(other LocationCounts) {
+	c.CameraCount += other.CameraCount
+	c.OnlineCount += other.OnlineCount
+}
+
+// LocationTree adalah hierarki lokasi beserta jumlah camera di setiap level.
+// Unassigned adalah camera aktif yang belum punya zone.
+type LocationTree struct {
+	LocationCounts
+	Sites      []*SiteNode    `json:"sites"`
+	Unassigned LocationCounts `json:"unassigned"`
+}
+
+// SiteNode adalah site di LocationTree
+type SiteNode struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Address *string `json:"address"`
+	LocationCounts
+	Buildings []*BuildingNode `json:"buildings"`
+}
+
+// BuildingNode adalah building di LocationTree. Zones berisi zone yang tidak
+// berada di floor mana pun.
+type BuildingNode struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Address *string `json:"address"`
+	LocationCounts
+	Floors []*FloorNode `json:"floors"`
+	Zones  []*ZoneNode  `json:"zones"`
+}
+
+// FloorNode adalah floor di LocationTree
+type FloorNode struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Level int    `json:"level"`
+	LocationCounts
+	Zones []*ZoneNode `json:"zones"`
+}
+
+// ZoneNode adalah zone di LocationTree
+type ZoneNode struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	LocationCounts
+}
+
+// NewLocationTree menyusun hierarki dari semua entity lokasi. counts adalah
+// jumlah camera per zone ID, jumlah di level atas adalah total level bawahnya.
+// Site, building dan zone diurutkan berdasarkan nama, floor berdasarkan level.
+func NewLocationTree(sites []*Site, buildings []*Building, floors []*Floor, zones []*Zone, counts map[string]LocationCounts, unassigned LocationCounts) *LocationTree {
+	tree := &LocationTree{Sites: []*SiteNode{}, Unassigned: unassigned}
+
+	siteNodes := map[string]*SiteNode{}
+	for _, site := range sites {
+		node := &SiteNode{ID: site.ID, Name: site.Name, Address: nullStringPtr(site.Address), Buildings: []*BuildingNode{}}
+		siteNodes[site.ID] = node
+		tree.Sites = append(tree.Sites, node)
+	}
+
+	buildingNodes := map[string]*BuildingNode{}
+	for _, building := range buildings {
+		node := &BuildingNode{ID: building.ID, Name: building.Name, Address: nullStringPtr(building.Address), Floors: []*FloorNode{}, Zones: []*ZoneNode{}}
+		buildingNodes[building.ID] = node
+		if site, ok := siteNodes[building.SiteID]; ok {
+			site.Buildings = append(site.Buildings, node)
+		}
+	}
+
+	floorNodes := map[string]*FloorNode{}
+	for _, floor := range floors {
+		node := &FloorNode{ID: floor.ID, Name: floor.Name, Level: floor.Level, Zones: []*ZoneNode{}}
+		floorNodes[floor.ID] = node
+		if building, ok := buildingNodes[floor.BuildingID]; ok {
+			building.Floors = append(building.Floors, node)
+		}
+	}
+
+	for _, zone := range zones {
+		node := &ZoneNode{ID: zone.ID, Name: zone.Name, LocationCounts: counts[zone.ID]}
+		if floor, ok := floorNodes[zone.FloorID.String]; ok && zone.FloorID.Valid {
+			floor.Zones = append(floor.Zones, node)
+		} else if building, ok := buildingNodes[zone.BuildingID]; ok {
+			building.Zones = append(building.Zones, node)
+		}
+	}
+
+	// Jumlah camera dihitung dari bawah ke atas
+	sortZones := func(zones []*ZoneNode) {
+		sort.Slice(zones, func(i, j int) bool { return zones[i].Name < zones[j].Name })
+	}
+	sort.Slice(tree.Sites, func(i, j int) bool { return tree.Sites[i].Name < tree.Sites[j].Name })
+	for _, site := range tree.Sites {
+		sort.Slice(site.Buildings, func(i, j int) bool { return site.Buildings[i].Name < site.Buildings[j].Name })
+		for _, building := range site.Buildings {
+			sort.Slice(building.Floors, func(i, j int) bool { return building.Floors[i].Level < building.Floors[j].Level })
+			for _, floor := range building.Floors {
+				sortZones(floor.Zones)
+				for _, zone := range floor.Zones {
+					floor.add(zone.LocationCounts)
+				}
+				building.add(floor.LocationCounts)
+			}
+			sortZones(building.Zones)
+			for _, zone := range building.Zones {
+				building.add(zone.LocationCounts)
+			}
+			site.add(building.LocationCounts)
+		}
+		tree.add(site.LocationCounts)
+	}
+	tree.add(unassigned)
+
+	return tree
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestNewLocationTreeCounts(t *testing.T) {
+	sites := []*Site{{ID: "s1", Name: "HQ"}}
+	buildings := []*Building{{ID: "b1", SiteID: "s1", Name: "Gedung A"}}
+	floors := []*Floor{
+		{ID: "f2", BuildingID: "b1", Name: "Lantai 2", Level: 2},
+		{ID: "f1", BuildingID: "b1", Name: "Basement", Level: -1},
+	}
+	zones := []*Zone{
+		{ID: "z1", BuildingID: "b1", FloorID: sql.NullString{String: "f1", Valid: true}, Name: "Parkir"},
+		{ID: "z2", BuildingID: "b1", FloorID: sql.NullString{String: "f2", Valid: true}, Name: "Lobby"},
+		{ID: "z3", BuildingID: "b1", Name: "Atap"},
+	}
+	counts := map[string]LocationCounts{
+		"z1": {CameraCount: 4, OnlineCount: 3},
+		"z2": {CameraCount: 2, OnlineCount: 0},
+		"z3": {CameraCount: 1, OnlineCount: 1},
+	}
+	unassigned := LocationCounts{CameraCount: 5, OnlineCount: 2}
+
+	tree := NewLocationTree(sites, buildings, floors, zones, counts, unassigned)
+
+	building := tree.Sites[0].Buildings[0]
+	if building.Floors[0].ID != "f1" || building.Floors[1].ID != "f2" {
+		t.Errorf("floors not sorted by level: %s, %s", building.Floors[0].ID, building.Floors[1].ID)
+	}
+	if len(building.Zones) != 1 || building.Zones[0].ID != "z3" {
+		t.Errorf("building zones = %+v, want only zone without floor", building.Zones)
+	}
+	if building.Floors[0].LocationCounts != counts["z1"] {
+		t.Errorf("basement counts = %+v, want %+v", building.Floors[0].LocationCounts, counts["z1"])
+	}
+
+	want := LocationCounts{CameraCount: 7, OnlineCount: 4}
+	if building.LocationCounts != want || tree.Sites[0].LocationCounts != want {
+		t.Errorf("building = %+v, site = %+v, want %+v", building.LocationCounts, tree.Sites[0].LocationCounts, want)
+	}
+	// Total tree termasuk camera tanpa zone
+	if tree.LocationCounts != (LocationCounts{CameraCount: 12, OnlineCount: 6}) || tree.Unassigned != unassigned {
+		t.Errorf("tree = %+v, unassigned = %+v", tree.LocationCounts, tree.Unassigned)
+	}
+}
+
+func TestNewLocationTreeEmpty(t *testing.T) {
+	tree := NewLocationTree(nil, nil, nil, nil, nil, LocationCounts{CameraCount: 3})
+	if tree.Sites == nil || len(tree.Sites) != 0 || tree.CameraCount != 3 {
+		t.Errorf("tree = %+v, want no sites and 3 unassigned cameras", tree)
+	}
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// Media server status
+const (
+	MediaServerOnline  = "ONLINE"
+	MediaServerOffline = "OFFLINE"
+	MediaServerUnknown = "UNKNOWN"
+)
+
+// MediaServer merepresentasikan satu node RTSPtoWeb
+type MediaServer struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	APIURL      string         `json:"api_url"`
+	PublicURL   string         `json:"public_url"`
+	Username    string         `json:"username,omitempty"`
+	Password    string         `json:"-"` // Hasil dekripsi PasswordEnc, tidak di-serialize ke JSON
+	PasswordEnc sql.NullString `json:"-"` // <key-id>:<ciphertext>, lihat CredentialCipher
+	MaxStreams  int            `json:"max_streams"`
+	Status      string         `json:"status"`
+	LastChecked sql.NullTime   `json:"-"`
+	IsActive    bool           `json:"is_active"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+
+	// Jumlah stream camera yang di-assign ke node ini (computed)
+	StreamCount int `json:"stream_count"`
+}
+
+// MarshalJSON custom JSON marshaling untuk MediaServer
+func (m MediaServer) MarshalJSON() ([]byte, error) {
+	type Alias MediaServer
+	return json.Marshal(&struct {
+		Alias
+		LastChecked string `json:"last_checked,omitempty"`
+	}{
+		Alias:       (Alias)(m),
+		LastChecked: formatNullTime(m.LastChecked),
+	})
+}
+
+// HasCapacity mengecek apakah node masih bisa menerima stream baru
+func (m *MediaServer) HasCapacity() bool {
+	return m.MaxStreams <= 0 || m.StreamCount < m.MaxStreams
+}
+
+// CreateMediaServerRequest adalah struktur untuk mendaftarkan node baru
+type CreateMediaServerRequest struct {
+	Name       string `json:"name"`
+	APIURL     string `json:"api_url"`
+	PublicURL  string `json:"public_url"`
+	Username   string `json:"username,omitempty"`
+	Password   string `json:"password,omitempty"`
+	MaxStreams int    `json:"max_streams,omitempty"`
+}
+
+// UpdateMediaServerRequest adalah struktur untuk update node
+type UpdateMediaServerRequest struct {
+	Name       string `json:"name,omitempty"`
+	APIURL     string `json:"api_url,omitempty"`
+	PublicURL  string `json:"public_url,omitempty"`
+	Username   string `json:"username,omitempty"`
+	Password   string `json:"password,omitempty"`
+	MaxStreams *int   `json:"max_streams,omitempty"` // 0 berarti tanpa batas
+	IsActive   *bool  `json:"is_active,omitempty"`
+}
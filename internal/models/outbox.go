@@ -0,0 +1,55 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// Outbox operations (perubahan stream di RTSPtoWeb)
+const (
+	OutboxOpAdd    = "ADD"
+	OutboxOpEdit   = "EDIT"
+	OutboxOpRemove = "REMOVE"
+)
+
+// Outbox delivery status
+const (
+	OutboxPending   = "PENDING"
+	OutboxDelivered = "DELIVERED"
+	OutboxFailed    = "FAILED"
+)
+
+// StreamOutboxEntry adalah satu operasi RTSPtoWeb yang dicatat dalam transaksi
+// yang sama dengan perubahan camera dan dikirim oleh outbox worker
+type StreamOutboxEntry struct {
+	ID            int64          `json:"id"`
+	CameraID      string         `json:"camera_id"`
+	Operation     string         `json:"operation"`
+	StreamID      sql.NullString `json:"-"`
+	MediaServerID sql.NullString `json:"-"`
+	Status        string         `json:"status"`
+	Attempts      int            `json:"attempts"`
+	LastError     sql.NullString `json:"-"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	DeliveredAt   sql.NullTime   `json:"-"`
+}
+
+// MarshalJSON custom JSON marshaling untuk StreamOutboxEntry
+func (e StreamOutboxEntry) MarshalJSON() ([]byte, error) {
+	type Alias StreamOutboxEntry
+	return json.Marshal(&struct {
+		*Alias
+		StreamID      string `json:"stream_id,omitempty"`
+		MediaServerID string `json:"media_server_id,omitempty"`
+		LastError     string `json:"last_error,omitempty"`
+		DeliveredAt   string `json:"delivered_at,omitempty"`
+	}{
+		Alias:         (*Alias)(&e),
+		StreamID:      e.StreamID.String,
+		MediaServerID: e.MediaServerID.String,
+		LastError:     e.LastError.String,
+		DeliveredAt:   formatNullTime(e.DeliveredAt),
+	})
+}
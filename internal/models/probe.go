@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// CameraProbe adalah hasil probe RTSP (OPTIONS + DESCRIBE) terakhir satu camera
+type CameraProbe struct {
+	CameraID        string       `json:"camera_id"`
+	Success         bool         `json:"success"`
+	Error           string       `json:"error,omitempty"`
+	Server          string       `json:"server,omitempty"`
+	SessionName     string       `json:"session_name,omitempty"`
+	VideoCodec      string       `json:"video_codec,omitempty"`
+	VideoProfile    string       `json:"video_profile,omitempty"`
+	Width           int          `json:"width,omitempty"`
+	Height          int          `json:"height,omitempty"`
+	FPS             float64      `json:"fps,omitempty"`
+	AudioCodec      string       `json:"audio_codec,omitempty"`
+	AudioSampleRate int          `json:"audio_sample_rate,omitempty"`
+	TrackCount      int          `json:"track_count"`
+	Tracks          []ProbeTrack `json:"tracks"`
+	SDP             string       `json:"sdp,omitempty"`
+	DurationMs      int64        `json:"duration_ms"`
+	ProbedAt        time.Time    `json:"probed_at"`
+
+	// Field camera yang diperbarui dari hasil probe (hanya di response probe)
+	Applied map[string]string `json:"applied,omitempty"`
+}
+
+// ProbeTrack adalah satu media track dari SDP
+type ProbeTrack struct {
+	Media     string  `json:"media"`
+	Codec     string  `json:"codec,omitempty"`
+	Profile   string  `json:"profile,omitempty"`
+	ClockRate int     `json:"clock_rate,omitempty"`
+	Channels  int     `json:"channels,omitempty"`
+	Width     int     `json:"width,omitempty"`
+	Height    int     `json:"height,omitempty"`
+	FPS       float64 `json:"fps,omitempty"`
+	Control   string  `json:"control,omitempty"`
+}
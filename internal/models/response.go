@@ -13,8 +13,31 @@ type ErrorDetail struct {
 	Code    string `json:"code"`              // Error code untuk handling di FE
 	Message string `json:"message"`           // Human readable message
 	Details string `json:"details,omitempty"` // Technical details (optional)
+
+	// Fields berisi semua pelanggaran validasi per field request
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError adalah satu pelanggaran validasi pada field request. Field memakai
+// nama JSON, misalnya "latitude" atau "channels[0].rtsp_url".
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
+// Field error codes
+const (
+	FieldErrRequired      = "REQUIRED"
+	FieldErrTooShort      = "TOO_SHORT"
+	FieldErrTooLong       = "TOO_LONG"
+	FieldErrOutOfRange    = "OUT_OF_RANGE"
+	FieldErrInvalidChoice = "INVALID_CHOICE"
+	FieldErrInvalidFormat = "INVALID_FORMAT"
+	FieldErrNotFound      = "NOT_FOUND"     // ID yang direferensikan tidak ada
+	FieldErrUnknownField  = "UNKNOWN_FIELD" // Key tidak dikenal atau read-only
+)
+
 // Error codes
 const (
 	// Authentication errors
@@ -30,8 +53,22 @@ const (
 	ErrCodeMissingFields    = "MISSING_FIELDS"
 
 	// Resource errors
-	ErrCodeNotFound      = "NOT_FOUND"
-	ErrCodeAlreadyExists = "ALREADY_EXISTS"
+	ErrCodeNotFound             = "NOT_FOUND"
+	ErrCodeAlreadyExists        = "ALREADY_EXISTS"
+	ErrCodeVersionConflict      = "VERSION_CONFLICT"      // If-Match tidak cocok dengan version
+	ErrCodePreconditionRequired = "PRECONDITION_REQUIRED" // If-Match wajib diisi
+	ErrCodeLocationInUse        = "LOCATION_IN_USE"       // Lokasi masih punya lokasi anak atau camera
+
+	// Stream errors
+	ErrCodeStreamNotStarted     = "STREAM_NOT_STARTED"
+	ErrCodeStreamNotFound       = "STREAM_NOT_FOUND"
+	ErrCodeMediaServerTimeout   = "MEDIA_SERVER_TIMEOUT"
+	ErrCodeMediaServerAuth      = "MEDIA_SERVER_UNAUTHORIZED"
+	ErrCodeStreamRemovalPending = "STREAM_REMOVAL_PENDING" // REMOVE stream belum terkirim
+
+	// Camera probe errors
+	ErrCodeCameraUnreachable = "CAMERA_UNREACHABLE"
+	ErrCodeCameraAuth        = "CAMERA_UNAUTHORIZED"
 
 	// Server errors
 	ErrCodeInternalError      = "INTERNAL_ERROR"
@@ -56,6 +93,14 @@ func NewErrorResponse(code, message string, details ...string) APIResponse {
 	}
 }
 
+// NewValidationErrorResponse membuat error response VALIDATION_FAILED berisi
+// semua pelanggaran validasi
+func NewValidationErrorResponse(fields []FieldError) APIResponse {
+	response := NewErrorResponse(ErrCodeValidationFailed, "Validation failed")
+	response.Error.Fields = fields
+	return response
+}
+
 // PaginationMeta adalah metadata untuk pagination
 type PaginationMeta struct {
 	Page       int   `json:"page"`
@@ -71,3 +116,20 @@ type PaginatedResponse struct {
 	Data       interface{}    `json:"data"`
 	Pagination PaginationMeta `json:"pagination"`
 }
+
+// CursorMeta adalah metadata untuk cursor (keyset) pagination. NextCursor
+// kosong jika sudah halaman terakhir, TotalItems hanya diisi jika diminta.
+type CursorMeta struct {
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	TotalItems *int64 `json:"total_items,omitempty"`
+}
+
+// CursorPaginatedResponse adalah response dengan cursor pagination
+type CursorPaginatedResponse struct {
+	Success    bool        `json:"success"`
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data"`
+	Pagination CursorMeta  `json:"pagination"`
+}
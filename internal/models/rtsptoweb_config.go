@@ -0,0 +1,137 @@
+package models
+
+// RTSPtoWebConfig adalah isi file config.json RTSPtoWeb
+type RTSPtoWebConfig struct {
+	ChannelDefaults RTSPtoWebChannelDefaults   `json:"channel_defaults"`
+	Server          RTSPtoWebServerConfig      `json:"server"`
+	Streams         map[string]RTSPtoWebStream `json:"streams"`
+}
+
+// RTSPtoWebChannelDefaults adalah opsi default untuk semua channel
+type RTSPtoWebChannelDefaults struct {
+	OnDemand bool `json:"on_demand"`
+}
+
+// RTSPtoWebServerConfig adalah blok "server" config.json RTSPtoWeb
+type RTSPtoWebServerConfig struct {
+	Debug            bool                 `json:"debug"`
+	HTTPDebug        bool                 `json:"http_debug"`
+	HTTPDemo         bool                 `json:"http_demo"`
+	HTTPDir          string               `json:"http_dir"`
+	HTTPLogin        string               `json:"http_login"`
+	HTTPPassword     string               `json:"http_password"`
+	HTTPPort         string               `json:"http_port"`
+	HTTPS            bool                 `json:"https"`
+	HTTPSAutoTLS     bool                 `json:"https_auto_tls"`
+	HTTPSAutoTLSName string               `json:"https_auto_tls_name"`
+	HTTPSCert        string               `json:"https_cert"`
+	HTTPSKey         string               `json:"https_key"`
+	HTTPSPort        string               `json:"https_port"`
+	ICECredential    string               `json:"ice_credential"`
+	ICEServers       []string             `json:"ice_servers"`
+	ICEUsername      string               `json:"ice_username"`
+	LogLevel         string               `json:"log_level"`
+	RTSPPort         string               `json:"rtsp_port"`
+	Token            RTSPtoWebTokenConfig `json:"token"`
+	WebRTCPortMax    int                  `json:"webrtc_port_max"`
+	WebRTCPortMin    int                  `json:"webrtc_port_min"`
+}
+
+// RTSPtoWebTokenConfig adalah konfigurasi token backend RTSPtoWeb
+type RTSPtoWebTokenConfig struct {
+	Backend string `json:"backend"`
+	Enable  bool   `json:"enable"`
+}
+
+// RTSPtoWebStream adalah satu stream di config.json, key-nya stream ID (UUID)
+type RTSPtoWebStream struct {
+	Name     string                      `json:"name"`
+	Channels map[string]RTSPtoWebChannel `json:"channels"`
+}
+
+// RTSPtoWebChannel adalah satu channel stream, key-nya index channel ("0", "1", ...)
+type RTSPtoWebChannel struct {
+	URL                string `json:"url"`
+	OnDemand           bool   `json:"on_demand"`
+	Debug              bool   `json:"debug,omitempty"`
+	Audio              bool   `json:"audio,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+}
+
+// Mode import untuk camera yang stream_id-nya sudah ada
+const (
+	ConfigImportSkip   = "skip"
+	ConfigImportUpdate = "update"
+)
+
+// Hasil import per stream
+const (
+	ConfigImportActionCreate = "CREATE"
+	ConfigImportActionUpdate = "UPDATE"
+	ConfigImportActionSkip   = "SKIP"
+	ConfigImportActionError  = "ERROR"
+)
+
+// TagNeedsLocation ditambahkan ke camera hasil import yang koordinatnya masih placeholder
+const TagNeedsLocation = "needs-location"
+
+// ConfigImportOptions adalah opsi import config.json RTSPtoWeb
+type ConfigImportOptions struct {
+	Mode          string // skip (default) atau update
+	DryRun        bool
+	MediaServerID string // Node tempat stream di config berjalan, kosong = ditempatkan reconcile
+	UserID        string
+}
+
+// ConfigImportItem adalah hasil import satu stream
+type ConfigImportItem struct {
+	StreamID      string `json:"stream_id"`
+	Name          string `json:"name"`
+	CameraID      string `json:"camera_id,omitempty"`
+	Action        string `json:"action"`
+	Applied       bool   `json:"applied"`
+	IPAddress     string `json:"ip_address,omitempty"`
+	Port          int    `json:"port,omitempty"`
+	Channels      int    `json:"channels"`
+	NeedsLocation bool   `json:"needs_location"`
+	Message       string `json:"message,omitempty"`
+}
+
+// ConfigImportReport adalah hasil import config.json RTSPtoWeb
+type ConfigImportReport struct {
+	DryRun        bool               `json:"dry_run"`
+	Mode          string             `json:"mode"`
+	TotalStreams  int                `json:"total_streams"`
+	Created       int                `json:"created"`
+	Updated       int                `json:"updated"`
+	Skipped       int                `json:"skipped"`
+	Failed        int                `json:"failed"`
+	NeedsLocation int                `json:"needs_location"`
+	Items         []ConfigImportItem `json:"items"`
+}
+
+// Perbedaan stream antara config yang di-generate dan node yang berjalan
+const (
+	ConfigDiffAdded   = "ADDED"   // Ada di database, belum ada di node
+	ConfigDiffRemoved = "REMOVED" // Ada di node, tidak ada di database
+	ConfigDiffChanged = "CHANGED" // Nama atau channel berbeda
+)
+
+// ConfigDiffItem adalah perbedaan satu stream
+type ConfigDiffItem struct {
+	StreamID string   `json:"stream_id"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Changes  []string `json:"changes,omitempty"`
+}
+
+// ConfigDiff adalah hasil perbandingan config dari database dengan stream yang
+// sedang berjalan di satu node RTSPtoWeb
+type ConfigDiff struct {
+	MediaServerID   string           `json:"media_server_id"`
+	MediaServerName string           `json:"media_server_name"`
+	InSync          bool             `json:"in_sync"`
+	DatabaseStreams int              `json:"database_streams"`
+	NodeStreams     int              `json:"node_streams"`
+	Items           []ConfigDiffItem `json:"items"`
+}
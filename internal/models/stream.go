@@ -0,0 +1,92 @@
+package models
+
+// MediaStream adalah representasi stream seperti yang dikembalikan RTSPtoWeb
+type MediaStream struct {
+	Name     string                  `json:"name"`
+	Channels map[string]MediaChannel `json:"channels"`
+}
+
+// MediaChannel adalah satu channel di dalam stream RTSPtoWeb
+type MediaChannel struct {
+	URL                string `json:"url"`
+	OnDemand           bool   `json:"on_demand"`
+	Debug              bool   `json:"debug"`
+	Audio              bool   `json:"audio"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+	Status             int    `json:"status,omitempty"`
+}
+
+// MediaChannelOnline adalah status channel RTSPtoWeb yang sedang menerima
+// video dari kamera
+const MediaChannelOnline = 1
+
+// Reconcile actions
+const (
+	ReconcileActionAdd    = "ADD"
+	ReconcileActionRemove = "REMOVE"
+	ReconcileActionEdit   = "EDIT"
+)
+
+// ReconcileItem adalah satu perbedaan antara tabel cameras dan RTSPtoWeb
+type ReconcileItem struct {
+	StreamID      string `json:"stream_id"`
+	CameraID      string `json:"camera_id,omitempty"`
+	MediaServerID string `json:"media_server_id,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Action        string `json:"action"`
+	Applied       bool   `json:"applied"`
+	Error         string `json:"error,omitempty"`
+}
+
+// ReconcileReport adalah hasil reconcile stream RTSPtoWeb terhadap tabel cameras
+type ReconcileReport struct {
+	DryRun             bool            `json:"dry_run"`
+	TotalCameras       int             `json:"total_cameras"`
+	TotalStreams       int             `json:"total_streams"`
+	InSync             int             `json:"in_sync"`
+	Missing            []ReconcileItem `json:"missing"`
+	Orphans            []ReconcileItem `json:"orphans"`
+	OutOfSync          []ReconcileItem `json:"out_of_sync"`
+	Pending            int             `json:"pending"` // Camera yang operasinya masih di outbox
+	FailedActions      int             `json:"failed_actions"`
+	UnreachableServers []string        `json:"unreachable_servers"`
+}
+
+// StreamSyncResult melaporkan apakah operasi stream (ADD, EDIT, REMOVE) sudah
+// diterima oleh RTSPtoWeb
+type StreamSyncResult struct {
+	Action  string `json:"action"`
+	Applied bool   `json:"applied"`
+	Queued  bool   `json:"queued,omitempty"` // Akan dicoba lagi oleh outbox worker
+	Error   string `json:"error,omitempty"`
+}
+
+// WebRTCOfferRequest adalah SDP offer dari browser untuk memulai playback WebRTC
+type WebRTCOfferRequest struct {
+	Type    string `json:"type"`
+	SDP     string `json:"sdp"`
+	Channel *int   `json:"channel,omitempty"` // Default: channel MAIN
+}
+
+// WebRTCAnswerResponse adalah SDP answer dari RTSPtoWeb
+type WebRTCAnswerResponse struct {
+	Type    string `json:"type"`
+	SDP     string `json:"sdp"`
+	Channel int    `json:"channel"`
+}
+
+// MediaAuthorizeRequest adalah request dari RTSPtoWeb ke token backend
+type MediaAuthorizeRequest struct {
+	Proto   string `json:"proto,omitempty"`
+	Stream  string `json:"stream,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	Token   string `json:"token,omitempty"`
+	IP      string `json:"ip,omitempty"`
+}
+
+// MediaAuthorizeResponse adalah response yang diharapkan RTSPtoWeb dari token backend.
+// Status "1" berarti diizinkan.
+type MediaAuthorizeResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
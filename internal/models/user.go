@@ -2,6 +2,28 @@ package models
 
 import "time"
 
+// Permission eksplisit di luar role. Admin tidak otomatis memilikinya.
+const (
+	// PermissionCameraCredentials mengizinkan melihat credential RTSP camera
+	// (rtsp_url lengkap) dan export config.json RTSPtoWeb
+	PermissionCameraCredentials = "camera:credentials"
+)
+
+// KnownPermissions adalah daftar permission yang bisa diberikan ke user
+var KnownPermissions = []string{
+	PermissionCameraCredentials,
+}
+
+// HasPermission mengecek apakah daftar permissions berisi permission
+func HasPermission(permissions []string, permission string) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
 // User merepresentasikan struktur data user dalam database
 type User struct {
 	ID           string    `json:"id"`
@@ -9,6 +31,7 @@ type User struct {
 	Email        string    `json:"email"`
 	PasswordHash string    `json:"-"` // Tidak di-serialize ke JSON
 	Role         string    `json:"role"`
+	Permissions  []string  `json:"permissions"`
 	IsActive     bool      `json:"is_active"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
@@ -28,8 +51,8 @@ type LoginResponse struct {
 
 // CreateUserRequest adalah struktur untuk membuat user baru
 type CreateUserRequest struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Role     string `json:"role"`
+	Username string `json:"username" validate:"required,min=3,max=100,username"`
+	Email    string `json:"email" validate:"required,max=255,email"`
+	Password string `json:"password" validate:"required,max=72"` // batas input bcrypt
+	Role     string `json:"role" validate:"omitempty,oneof=admin operator viewer"`
 }
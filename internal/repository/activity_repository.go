@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"cctv-monitoring-backend/internal/models"
+)
+
+// ActivityRepository adalah interface untuk menulis audit log ke activity_logs
+type ActivityRepository interface {
+	Create(entry *models.ActivityLog) error
+	WithTx(tx *sql.Tx) ActivityRepository
+}
+
+type activityRepository struct {
+	db DBTX
+}
+
+// NewActivityRepository membuat instance baru dari ActivityRepository
+func NewActivityRepository(db *sql.DB) ActivityRepository {
+	return &activityRepository{db: db}
+}
+
+// WithTx mengembalikan ActivityRepository yang berjalan di dalam transaksi tx
+func (r *activityRepository) WithTx(tx *sql.Tx) ActivityRepository {
+	return &activityRepository{db: tx}
+}
+
+// Create menyimpan satu activity log. CameraID kosong untuk camera yang sudah
+// tidak ada di tabel cameras (misalnya setelah purge).
+func (r *activityRepository) Create(entry *models.ActivityLog) error {
+	details, err := json.Marshal(entry.Details)
+	if err != nil {
+		return fmt.Errorf("failed to encode activity details: %w", err)
+	}
+
+	query := `
+		INSERT INTO activity_logs (user_id, camera_id, action, details)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	err = r.db.QueryRow(
+		query,
+		sql.NullString{String: entry.UserID, Valid: entry.UserID != ""},
+		sql.NullString{String: entry.CameraID, Valid: entry.CameraID != ""},
+		entry.Action,
+		details,
+	).Scan(&entry.ID, &entry.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create activity log: %w", err)
+	}
+
+	return nil
+}
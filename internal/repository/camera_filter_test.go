@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+
+	"cctv-monitoring-backend/internal/models"
+)
+
+func TestCameraFilterQuery(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		filter    models.CameraFilter
+		wantWhere string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "default active only",
+			filter:    models.CameraFilter{},
+			wantWhere: "WHERE is_active = true",
+		},
+		{
+			name:      "all cameras",
+			filter:    models.CameraFilter{Active: models.CameraActiveAll},
+			wantWhere: "",
+		},
+		{
+			name:      "search escapes wildcards",
+			filter:    models.CameraFilter{Query: "50%_off"},
+			wantWhere: "WHERE is_active = true AND (name ILIKE $1 OR description ILIKE $1)",
+			wantArgs:  []interface{}{`%50\%\_off%`},
+		},
+		{
+			name: "statuses, lower-cased manufacturers and created range",
+			filter: models.CameraFilter{
+				Active:        models.CameraInactiveOnly,
+				Statuses:      []string{models.CameraStatusOnline, models.CameraStatusError},
+				Manufacturers: []string{"Hikvision"},
+				CreatedFrom:   &from,
+			},
+			wantWhere: "WHERE is_active = false AND status = ANY($1) AND LOWER(manufacturer) = ANY($2) AND created_at >= $3",
+			wantArgs: []interface{}{
+				pq.Array([]string{models.CameraStatusOnline, models.CameraStatusError}),
+				pq.Array([]string{"hikvision"}),
+				from,
+			},
+		},
+		{
+			name:      "tags any",
+			filter:    models.CameraFilter{Tags: []string{"lobby", "entrance"}},
+			wantWhere: "WHERE is_active = true AND tags && $1::text[]",
+			wantArgs:  []interface{}{pq.Array([]string{"lobby", "entrance"})},
+		},
+		{
+			name:      "tags all",
+			filter:    models.CameraFilter{Tags: []string{"lobby"}, TagMatch: models.TagMatchAll},
+			wantWhere: "WHERE is_active = true AND tags @> $1::text[]",
+			wantArgs:  []interface{}{pq.Array([]string{"lobby"})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Normalize()
+			b := cameraFilterQuery(&tt.filter)
+
+			if got := b.whereClause(); got != tt.wantWhere {
+				t.Errorf("where = %q, want %q", got, tt.wantWhere)
+			}
+			if !reflect.DeepEqual(b.args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", b.args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestCameraOrderBy(t *testing.T) {
+	tests := []struct {
+		sort []models.SortField
+		want string
+	}{
+		{nil, "id ASC"},
+		{models.DefaultCameraSort, "created_at DESC, id ASC"},
+		{[]models.SortField{{Field: "zone"}, {Field: "name", Desc: true}}, "zone ASC NULLS LAST, name DESC, id ASC"},
+		{[]models.SortField{{Field: "last_seen", Desc: true}}, "last_seen DESC NULLS LAST, id ASC"},
+		// Field di luar whitelist tidak pernah ditulis ke SQL
+		{[]models.SortField{{Field: "name; DROP TABLE cameras"}}, "id ASC"},
+	}
+
+	for _, tt := range tests {
+		if got := cameraOrderBy(tt.sort); got != tt.want {
+			t.Errorf("cameraOrderBy(%v) = %q, want %q", tt.sort, got, tt.want)
+		}
+	}
+}
+
+func TestCameraKeyset(t *testing.T) {
+	after := &models.CameraCursor{
+		CreatedAt: time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC),
+		ID:        "5f0c6a56-7c1e-4b43-9c55-2f7d2f6f1a10",
+	}
+
+	tests := []struct {
+		name        string
+		after       *models.CameraCursor
+		desc        bool
+		wantWhere   string
+		wantOrderBy string
+	}{
+		{"first page desc", nil, true, "WHERE is_active = true", "created_at DESC, id DESC"},
+		{"next page desc", after, true, "WHERE is_active = true AND (created_at, id) < ($1::timestamptz, $2::uuid)", "created_at DESC, id DESC"},
+		{"next page asc", after, false, "WHERE is_active = true AND (created_at, id) > ($1::timestamptz, $2::uuid)", "created_at ASC, id ASC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := models.CameraFilter{}
+			filter.Normalize()
+			b := cameraFilterQuery(&filter)
+
+			if got := cameraKeyset(b, tt.after, tt.desc); got != tt.wantOrderBy {
+				t.Errorf("order by = %q, want %q", got, tt.wantOrderBy)
+			}
+			if got := b.whereClause(); got != tt.wantWhere {
+				t.Errorf("where = %q, want %q", got, tt.wantWhere)
+			}
+			if tt.after != nil && !reflect.DeepEqual(b.args, []interface{}{after.CreatedAt, after.ID}) {
+				t.Errorf("args = %#v, want cursor created_at and id", b.args)
+			}
+		})
+	}
+}
@@ -3,29 +3,149 @@ package repository
 import (
 	"cctv-monitoring-backend/internal/models"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/lib/pq"
 )
 
+// ErrCameraNotFound dikembalikan jika camera tidak ada atau sudah dihapus
+var ErrCameraNotFound = errors.New("camera not found")
+
+// ErrStreamRemovalPending dikembalikan jika camera di trash belum bisa dihapus
+// permanen karena REMOVE stream-nya masih menunggu di outbox
+var ErrStreamRemovalPending = errors.New("stream removal is still pending, retry after it has been delivered")
+
+// ErrCameraVersionConflict dikembalikan jika camera sudah diubah request lain
+// sejak dibaca (version berbeda)
+var ErrCameraVersionConflict = errors.New("camera was modified by another request")
+
 type CameraRepository interface {
 	Create(camera *models.Camera, userID string) error
 	GetByID(id string) (*models.Camera, error)
-	GetAll(page, pageSize int) ([]*models.Camera, *models.PaginationMeta, error)
+	GetByIDWithInactive(id string) (*models.Camera, error)
+	GetByStreamID(streamID string) (*models.Camera, error)
+	GetIDsByRTSPUrls(urls []string) (map[string]string, error)
+	GetByIDs(ids []string) ([]*models.Camera, error)
+	GetAllByFilter(filter *models.CameraFilter, limit int) ([]*models.Camera, error)
+	GetAll(filter *models.CameraFilter) ([]*models.Camera, *models.PaginationMeta, error)
+	GetByCursor(filter *models.CameraFilter) ([]*models.Camera, *models.CursorMeta, error)
+	Export(filter *models.CameraFilter) (*CameraRows, error)
 	Update(id string, camera *models.Camera) error
-	Delete(id string) error
+	Delete(id, userID string) error
+	GetTrash(page, pageSize int) ([]*models.Camera, *models.PaginationMeta, error)
+	GetTrashedByID(id string) (*models.Camera, error)
+	HardDelete(id string) error
+	PurgeDeletedBefore(before time.Time, limit int) ([]*models.Camera, error)
 	GetByZone(zone string) ([]*models.Camera, error)
 	GetNearby(lat, lng, radius float64) ([]*models.Camera, error)
+	GetWithStream() ([]*models.Camera, error)
+	GetForCredentialRotation(activeKeyID string) ([]*models.Camera, error)
+	GetByMediaServer(serverID string) ([]*models.Camera, error)
+	UpdateStatus(id, status string, lastSeen *time.Time) error
+	UpdateMediaServer(id string, serverID sql.NullString) error
+	UpdateSyncStatus(id, status string, syncError sql.NullString) error
+	WithTx(tx *sql.Tx) CameraRepository
 }
 
 type cameraRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 func NewCameraRepository(db *sql.DB) CameraRepository {
 	return &cameraRepository{db: db}
 }
 
+// WithTx mengembalikan CameraRepository yang berjalan di dalam transaksi tx
+func (r *cameraRepository) WithTx(tx *sql.Tx) CameraRepository {
+	return &cameraRepository{db: tx}
+}
+
+// cameraColumns adalah daftar kolom yang dibaca oleh scanCamera (urutan harus sama)
+const cameraColumns = `
+			id, name, description, rtsp_url, stream_id,
+			latitude, longitude, building, zone,
+			ip_address, port, manufacturer, model, resolution, fps,
+			tags, status, last_seen, is_active, created_by,
+			created_at, updated_at, media_server_id,
+			sync_status, sync_error,
+			on_demand, audio, debug, insecure_skip_verify,
+			rtsp_username, rtsp_password_enc, version,
+			deleted_at, deleted_by, zone_id`
+
+// rowScanner dipenuhi oleh *sql.Row dan *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanCamera membaca satu baris hasil query cameraColumns ke struct Camera
+func scanCamera(row rowScanner, extra ...interface{}) (*models.Camera, error) {
+	camera := &models.Camera{}
+	dest := []interface{}{
+		&camera.ID,
+		&camera.Name,
+		&camera.Description,
+		&camera.RTSPUrl,
+		&camera.StreamID,
+		&camera.Latitude,
+		&camera.Longitude,
+		&camera.Building,
+		&camera.Zone,
+		&camera.IPAddress,
+		&camera.Port,
+		&camera.Manufacturer,
+		&camera.Model,
+		&camera.Resolution,
+		&camera.FPS,
+		pq.Array(&camera.Tags),
+		&camera.Status,
+		&camera.LastSeen,
+		&camera.IsActive,
+		&camera.CreatedBy,
+		&camera.CreatedAt,
+		&camera.UpdatedAt,
+		&camera.MediaServerID,
+		&camera.SyncStatus,
+		&camera.SyncError,
+		&camera.StreamOptions.OnDemand,
+		&camera.StreamOptions.Audio,
+		&camera.StreamOptions.Debug,
+		&camera.StreamOptions.InsecureSkipVerify,
+		&camera.RTSPUsername,
+		&camera.RTSPPasswordEnc,
+		&camera.Version,
+		&camera.DeletedAt,
+		&camera.DeletedBy,
+		&camera.ZoneID,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	return camera, nil
+}
+
+// scanCameras membaca semua baris hasil query cameraColumns
+func scanCameras(rows *sql.Rows) ([]*models.Camera, error) {
+	cameras := []*models.Camera{}
+	for rows.Next() {
+		camera, err := scanCamera(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan camera: %w", err)
+		}
+		cameras = append(cameras, camera)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate cameras: %w", err)
+	}
+
+	return cameras, nil
+}
+
 func (r *cameraRepository) Create(camera *models.Camera, userID string) error {
 	query := `
 		INSERT INTO cameras (
@@ -33,14 +153,18 @@ func (r *cameraRepository) Create(camera *models.Camera, userID string) error {
 			latitude, longitude, building, zone,
 			ip_address, port, manufacturer, model, resolution, fps,
 			tags, status, is_active, created_by,
-			created_at, updated_at
+			created_at, updated_at, media_server_id,
+			on_demand, audio, debug, insecure_skip_verify,
+			rtsp_username, rtsp_password_enc, zone_id
 		) VALUES (
 			uuid_generate_v4(), $1, $2, $3, $4,
 			$5, $6, $7, $8,
 			$9, $10, $11, $12, $13, $14,
 			$15, $16, $17, $18,
-			NOW(), NOW()
-		) RETURNING id, created_at, updated_at
+			NOW(), NOW(), $19,
+			$20, $21, $22, $23,
+			$24, $25, $26
+		) RETURNING id, created_at, updated_at, version
 	`
 
 	err := r.db.QueryRow(
@@ -63,7 +187,15 @@ func (r *cameraRepository) Create(camera *models.Camera, userID string) error {
 		camera.Status,
 		camera.IsActive,
 		userID,
-	).Scan(&camera.ID, &camera.CreatedAt, &camera.UpdatedAt)
+		camera.MediaServerID,
+		camera.StreamOptions.OnDemand,
+		camera.StreamOptions.Audio,
+		camera.StreamOptions.Debug,
+		camera.StreamOptions.InsecureSkipVerify,
+		camera.RTSPUsername,
+		camera.RTSPPasswordEnc,
+		camera.ZoneID,
+	).Scan(&camera.ID, &camera.CreatedAt, &camera.UpdatedAt, &camera.Version)
 
 	if err != nil {
 		return fmt.Errorf("failed to create camera: %w", err)
@@ -74,44 +206,37 @@ func (r *cameraRepository) Create(camera *models.Camera, userID string) error {
 
 func (r *cameraRepository) GetByID(id string) (*models.Camera, error) {
 	query := `
-		SELECT 
-			id, name, description, rtsp_url, stream_id,
-			latitude, longitude, building, zone,
-			ip_address, port, manufacturer, model, resolution, fps,
-			tags, status, last_seen, is_active, created_by,
-			created_at, updated_at
+		SELECT ` + cameraColumns + `
 		FROM cameras
 		WHERE id = $1 AND is_active = true
 	`
 
-	camera := &models.Camera{}
-	err := r.db.QueryRow(query, id).Scan(
-		&camera.ID,
-		&camera.Name,
-		&camera.Description,
-		&camera.RTSPUrl,
-		&camera.StreamID,
-		&camera.Latitude,
-		&camera.Longitude,
-		&camera.Building,
-		&camera.Zone,
-		&camera.IPAddress,
-		&camera.Port,
-		&camera.Manufacturer,
-		&camera.Model,
-		&camera.Resolution,
-		&camera.FPS,
-		pq.Array(&camera.Tags),
-		&camera.Status,
-		&camera.LastSeen,
-		&camera.IsActive,
-		&camera.CreatedBy,
-		&camera.CreatedAt,
-		&camera.UpdatedAt,
-	)
+	camera, err := scanCamera(r.db.QueryRow(query, id))
+
+	if err == sql.ErrNoRows {
+		return nil, ErrCameraNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get camera: %w", err)
+	}
+
+	return camera, nil
+}
+
+// GetByIDWithInactive mengambil camera berdasarkan ID termasuk camera yang
+// sudah dinonaktifkan
+func (r *cameraRepository) GetByIDWithInactive(id string) (*models.Camera, error) {
+	query := `
+		SELECT ` + cameraColumns + `
+		FROM cameras
+		WHERE id = $1
+	`
+
+	camera, err := scanCamera(r.db.QueryRow(query, id))
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("camera not found")
+		return nil, ErrCameraNotFound
 	}
 
 	if err != nil {
@@ -121,78 +246,136 @@ func (r *cameraRepository) GetByID(id string) (*models.Camera, error) {
 	return camera, nil
 }
 
-func (r *cameraRepository) GetAll(page, pageSize int) ([]*models.Camera, *models.PaginationMeta, error) {
-	offset := (page - 1) * pageSize
+// GetIDsByRTSPUrls mengambil ID camera aktif yang memakai rtsp_url tersebut,
+// dipetakan per URL
+func (r *cameraRepository) GetIDsByRTSPUrls(urls []string) (map[string]string, error) {
+	query := `
+		SELECT rtsp_url, id
+		FROM cameras
+		WHERE is_active = true AND rtsp_url = ANY($1)
+	`
+
+	rows, err := r.db.Query(query, pq.Array(urls))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cameras by rtsp url: %w", err)
+	}
+	defer rows.Close()
+
+	ids := map[string]string{}
+	for rows.Next() {
+		var url, id string
+		if err := rows.Scan(&url, &id); err != nil {
+			return nil, fmt.Errorf("failed to scan camera: %w", err)
+		}
+		ids[url] = id
+	}
+
+	return ids, rows.Err()
+}
+
+// GetByIDs mengambil camera aktif dengan ID tersebut. ID yang tidak ditemukan
+// tidak ada di hasil.
+func (r *cameraRepository) GetByIDs(ids []string) ([]*models.Camera, error) {
+	query := `
+		SELECT ` + cameraColumns + `
+		FROM cameras
+		WHERE is_active = true AND id = ANY($1::uuid[])
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.Query(query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cameras: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCameras(rows)
+}
+
+// GetAllByFilter mengambil maksimal limit camera yang cocok dengan filter list
+// camera, tanpa pagination
+func (r *cameraRepository) GetAllByFilter(filter *models.CameraFilter, limit int) ([]*models.Camera, error) {
+	filter.Normalize()
+	b := cameraFilterQuery(filter)
+
+	query := `
+		SELECT ` + cameraColumns + `
+		FROM cameras
+		` + b.whereClause() + `
+		ORDER BY ` + cameraOrderBy(filter.Sort) + `
+		LIMIT ` + b.arg(limit)
+
+	rows, err := r.db.Query(query, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cameras: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCameras(rows)
+}
+
+// GetByStreamID mengambil camera berdasarkan stream_id RTSPtoWeb, termasuk camera
+// yang sudah dihapus karena stream_id tetap unik untuk baris yang non-aktif
+func (r *cameraRepository) GetByStreamID(streamID string) (*models.Camera, error) {
+	query := `
+		SELECT ` + cameraColumns + `
+		FROM cameras
+		WHERE stream_id = $1
+	`
+
+	camera, err := scanCamera(r.db.QueryRow(query, streamID))
+
+	if err == sql.ErrNoRows {
+		return nil, ErrCameraNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get camera by stream id: %w", err)
+	}
+
+	return camera, nil
+}
+
+func (r *cameraRepository) GetAll(filter *models.CameraFilter) ([]*models.Camera, *models.PaginationMeta, error) {
+	filter.Normalize()
+	b := cameraFilterQuery(filter)
+	where := b.whereClause()
 
 	// Get total count
 	var totalItems int64
-	countQuery := "SELECT COUNT(*) FROM cameras WHERE is_active = true"
-	if err := r.db.QueryRow(countQuery).Scan(&totalItems); err != nil {
+	countQuery := "SELECT COUNT(*) FROM cameras " + where
+	if err := r.db.QueryRow(countQuery, b.args...).Scan(&totalItems); err != nil {
 		return nil, nil, fmt.Errorf("failed to count cameras: %w", err)
 	}
 
 	// Calculate total pages
-	totalPages := int(totalItems) / pageSize
-	if int(totalItems)%pageSize > 0 {
+	totalPages := int(totalItems) / filter.PageSize
+	if int(totalItems)%filter.PageSize > 0 {
 		totalPages++
 	}
 
 	// Get cameras
 	query := `
-		SELECT 
-			id, name, description, rtsp_url, stream_id,
-			latitude, longitude, building, zone,
-			ip_address, port, manufacturer, model, resolution, fps,
-			tags, status, last_seen, is_active, created_by,
-			created_at, updated_at
+		SELECT ` + cameraColumns + `
 		FROM cameras
-		WHERE is_active = true
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`
+		` + where + `
+		ORDER BY ` + cameraOrderBy(filter.Sort) + `
+		LIMIT ` + b.arg(filter.PageSize) + ` OFFSET ` + b.arg((filter.Page-1)*filter.PageSize)
 
-	rows, err := r.db.Query(query, pageSize, offset)
+	rows, err := r.db.Query(query, b.args...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get cameras: %w", err)
 	}
 	defer rows.Close()
 
-	cameras := []*models.Camera{}
-	for rows.Next() {
-		camera := &models.Camera{}
-		err := rows.Scan(
-			&camera.ID,
-			&camera.Name,
-			&camera.Description,
-			&camera.RTSPUrl,
-			&camera.StreamID,
-			&camera.Latitude,
-			&camera.Longitude,
-			&camera.Building,
-			&camera.Zone,
-			&camera.IPAddress,
-			&camera.Port,
-			&camera.Manufacturer,
-			&camera.Model,
-			&camera.Resolution,
-			&camera.FPS,
-			pq.Array(&camera.Tags),
-			&camera.Status,
-			&camera.LastSeen,
-			&camera.IsActive,
-			&camera.CreatedBy,
-			&camera.CreatedAt,
-			&camera.UpdatedAt,
-		)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to scan camera: %w", err)
-		}
-		cameras = append(cameras, camera)
+	cameras, err := scanCameras(rows)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	meta := &models.PaginationMeta{
-		Page:       page,
-		PageSize:   pageSize,
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
 		TotalItems: totalItems,
 		TotalPages: totalPages,
 	}
@@ -200,6 +383,246 @@ func (r *cameraRepository) GetAll(page, pageSize int) ([]*models.Camera, *models
 	return cameras, meta, nil
 }
 
+// GetByCursor mengambil camera dengan keyset pagination pada (created_at, id),
+// tanpa OFFSET dan tanpa COUNT kecuali filter.IncludeTotal. Satu baris ekstra
+// diambil untuk mengetahui apakah masih ada halaman berikutnya.
+func (r *cameraRepository) GetByCursor(filter *models.CameraFilter) ([]*models.Camera, *models.CursorMeta, error) {
+	filter.Normalize()
+	desc, err := filter.KeysetDesc()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b := cameraFilterQuery(filter)
+	meta := &models.CursorMeta{PageSize: filter.PageSize}
+
+	// Total dihitung sebelum kondisi cursor ditambahkan
+	if filter.IncludeTotal {
+		var totalItems int64
+		countQuery := "SELECT COUNT(*) FROM cameras " + b.whereClause()
+		if err := r.db.QueryRow(countQuery, b.args...).Scan(&totalItems); err != nil {
+			return nil, nil, fmt.Errorf("failed to count cameras: %w", err)
+		}
+		meta.TotalItems = &totalItems
+	}
+
+	orderBy := cameraKeyset(b, filter.After, desc)
+
+	query := `
+		SELECT ` + cameraColumns + `
+		FROM cameras
+		` + b.whereClause() + `
+		ORDER BY ` + orderBy + `
+		LIMIT ` + b.arg(filter.PageSize+1)
+
+	rows, err := r.db.Query(query, b.args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get cameras: %w", err)
+	}
+	defer rows.Close()
+
+	cameras, err := scanCameras(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(cameras) > filter.PageSize {
+		cameras = cameras[:filter.PageSize]
+		last := cameras[len(cameras)-1]
+		meta.HasMore = true
+		meta.NextCursor = models.CameraCursor{CreatedAt: last.CreatedAt, ID: last.ID, Desc: desc}.Encode()
+	}
+
+	return cameras, meta, nil
+}
+
+// cameraKeyset menambahkan kondisi setelah cursor ke b dan mengembalikan
+// ORDER BY keyset (created_at, id) sesuai arah
+func cameraKeyset(b *queryBuilder, after *models.CameraCursor, desc bool) string {
+	direction, comparison := "ASC", ">"
+	if desc {
+		direction, comparison = "DESC", "<"
+	}
+	if after != nil {
+		b.where("(created_at, id) " + comparison + " (" + b.arg(after.CreatedAt) + "::timestamptz, " + b.arg(after.ID) + "::uuid)")
+	}
+
+	return "created_at " + direction + ", id " + direction
+}
+
+// CameraRows membaca hasil query camera satu per satu sehingga hasil besar tidak
+// dimuat ke memori sekaligus. Koneksi database dipakai sampai Close dipanggil.
+type CameraRows struct {
+	rows *sql.Rows
+}
+
+// Next maju ke baris berikutnya, false jika sudah habis atau terjadi error
+func (r *CameraRows) Next() bool {
+	return r.rows.Next()
+}
+
+// Scan membaca camera di baris saat ini beserta RTSP URL channel SUB (kosong
+// jika camera tidak punya sub stream)
+func (r *CameraRows) Scan() (*models.Camera, string, error) {
+	var subURL sql.NullString
+	camera, err := scanCamera(r.rows, &subURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to scan camera: %w", err)
+	}
+	return camera, subURL.String, nil
+}
+
+// Err mengembalikan error yang menghentikan Next
+func (r *CameraRows) Err() error {
+	if err := r.rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate cameras: %w", err)
+	}
+	return nil
+}
+
+// Close melepas koneksi database
+func (r *CameraRows) Close() error {
+	return r.rows.Close()
+}
+
+// Export mengambil semua camera yang cocok dengan filter (tanpa pagination)
+// dengan urutan filter.Sort, untuk di-stream ke file export
+func (r *cameraRepository) Export(filter *models.CameraFilter) (*CameraRows, error) {
+	filter.Normalize()
+	b := cameraFilterQuery(filter)
+
+	query := `
+		SELECT ` + cameraColumns + `,
+			(SELECT cc.rtsp_url FROM camera_channels cc
+			 WHERE cc.camera_id = cameras.id AND cc.role = ` + b.arg(models.ChannelRoleSub) + `
+			 ORDER BY cc.channel_index LIMIT 1) AS sub_rtsp_url
+		FROM cameras
+		` + b.whereClause() + `
+		ORDER BY ` + cameraOrderBy(filter.Sort)
+
+	rows, err := r.db.Query(query, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export cameras: %w", err)
+	}
+
+	return &CameraRows{rows: rows}, nil
+}
+
+// cameraFilterQuery menyusun kondisi WHERE dari filter list camera
+func cameraFilterQuery(filter *models.CameraFilter) *queryBuilder {
+	b := &queryBuilder{}
+
+	switch filter.Active {
+	case models.CameraInactiveOnly:
+		b.where("is_active = false")
+	case models.CameraActiveAll:
+	default:
+		b.where("is_active = true")
+	}
+
+	if filter.Query != "" {
+		pattern := b.arg("%" + likePattern(filter.Query) + "%")
+		b.where("(name ILIKE " + pattern + " OR description ILIKE " + pattern + ")")
+	}
+	if len(filter.Statuses) > 0 {
+		b.where("status = ANY(" + b.arg(pq.Array(filter.Statuses)) + ")")
+	}
+	if len(filter.Buildings) > 0 {
+		b.where("building = ANY(" + b.arg(pq.Array(filter.Buildings)) + ")")
+	}
+	if len(filter.Zones) > 0 {
+		b.where("zone = ANY(" + b.arg(pq.Array(filter.Zones)) + ")")
+	}
+	if len(filter.ZoneIDs) > 0 {
+		b.where("zone_id = ANY(" + b.arg(pq.Array(filter.ZoneIDs)) + ")")
+	}
+	if len(filter.FloorIDs) > 0 {
+		b.where("zone_id IN (SELECT id FROM zones WHERE floor_id = ANY(" + b.arg(pq.Array(filter.FloorIDs)) + "))")
+	}
+	if len(filter.BuildingIDs) > 0 {
+		b.where("zone_id IN (SELECT id FROM zones WHERE building_id = ANY(" + b.arg(pq.Array(filter.BuildingIDs)) + "))")
+	}
+	if len(filter.SiteIDs) > 0 {
+		b.where("zone_id IN (SELECT z.id FROM zones z JOIN buildings lb ON lb.id = z.building_id WHERE lb.site_id = ANY(" + b.arg(pq.Array(filter.SiteIDs)) + "))")
+	}
+	if len(filter.Manufacturers) > 0 {
+		manufacturers := make([]string, len(filter.Manufacturers))
+		for i, m := range filter.Manufacturers {
+			manufacturers[i] = strings.ToLower(m)
+		}
+		b.where("LOWER(manufacturer) = ANY(" + b.arg(pq.Array(manufacturers)) + ")")
+	}
+
+	// && dan @> memakai index GIN idx_cameras_tags
+	if len(filter.Tags) > 0 {
+		operator := "&&"
+		if filter.TagMatch == models.TagMatchAll {
+			operator = "@>"
+		}
+		b.where("tags " + operator + " " + b.arg(pq.Array(filter.Tags)) + "::text[]")
+	}
+
+	if filter.CreatedBy != "" {
+		b.where("created_by = " + b.arg(filter.CreatedBy))
+	}
+	if filter.CreatedFrom != nil {
+		b.where("created_at >= " + b.arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		b.where("created_at <= " + b.arg(*filter.CreatedTo))
+	}
+	if filter.UpdatedFrom != nil {
+		b.where("updated_at >= " + b.arg(*filter.UpdatedFrom))
+	}
+	if filter.UpdatedTo != nil {
+		b.where("updated_at <= " + b.arg(*filter.UpdatedTo))
+	}
+
+	return b
+}
+
+// cameraSortColumns memetakan field sort ke kolom. Kolom nullable diurutkan
+// dengan NULL di akhir untuk kedua arah.
+var cameraSortColumns = map[string]struct {
+	column   string
+	nullable bool
+}{
+	"name":         {"name", false},
+	"status":       {"status", false},
+	"building":     {"building", true},
+	"zone":         {"zone", true},
+	"manufacturer": {"manufacturer", true},
+	"created_at":   {"created_at", false},
+	"updated_at":   {"updated_at", false},
+	"last_seen":    {"last_seen", true},
+}
+
+// cameraOrderBy menyusun ORDER BY dari field yang sudah divalidasi. id selalu
+// ditambahkan di akhir agar urutan stabil antar halaman.
+func cameraOrderBy(sort []models.SortField) string {
+	parts := make([]string, 0, len(sort)+1)
+	for _, field := range sort {
+		col, ok := cameraSortColumns[field.Field]
+		if !ok {
+			continue
+		}
+
+		part := col.column + " ASC"
+		if field.Desc {
+			part = col.column + " DESC"
+		}
+		if col.nullable {
+			part += " NULLS LAST"
+		}
+		parts = append(parts, part)
+	}
+
+	return strings.Join(append(parts, "id ASC"), ", ")
+}
+
+// Update menyimpan camera jika version di database masih sama dengan
+// camera.Version, lalu menaikkan version. ErrCameraVersionConflict jika camera
+// sudah diubah request lain sejak dibaca.
 func (r *cameraRepository) Update(id string, camera *models.Camera) error {
 	query := `
 		UPDATE cameras SET
@@ -220,11 +643,23 @@ func (r *cameraRepository) Update(id string, camera *models.Camera) error {
 			tags = $15,
 			status = $16,
 			is_active = $17,
-			updated_at = NOW()
-		WHERE id = $18
+			media_server_id = $18,
+			on_demand = $19,
+			audio = $20,
+			debug = $21,
+			insecure_skip_verify = $22,
+			rtsp_username = $23,
+			rtsp_password_enc = $24,
+			deleted_at = $25,
+			deleted_by = $26,
+			zone_id = $27,
+			updated_at = NOW(),
+			version = version + 1
+		WHERE id = $28 AND version = $29
+		RETURNING updated_at, version
 	`
 
-	_, err := r.db.Exec(
+	err := r.db.QueryRow(
 		query,
 		camera.Name,
 		camera.Description,
@@ -243,8 +678,24 @@ func (r *cameraRepository) Update(id string, camera *models.Camera) error {
 		pq.Array(camera.Tags),
 		camera.Status,
 		camera.IsActive,
+		camera.MediaServerID,
+		camera.StreamOptions.OnDemand,
+		camera.StreamOptions.Audio,
+		camera.StreamOptions.Debug,
+		camera.StreamOptions.InsecureSkipVerify,
+		camera.RTSPUsername,
+		camera.RTSPPasswordEnc,
+		camera.DeletedAt,
+		camera.DeletedBy,
+		camera.ZoneID,
 		id,
-	)
+		camera.Version,
+	).Scan(&camera.UpdatedAt, &camera.Version)
+
+	// Version yang dibaca caller sudah basi
+	if err == sql.ErrNoRows {
+		return ErrCameraVersionConflict
+	}
 
 	if err != nil {
 		return fmt.Errorf("failed to update camera: %w", err)
@@ -253,10 +704,20 @@ func (r *cameraRepository) Update(id string, camera *models.Camera) error {
 	return nil
 }
 
-func (r *cameraRepository) Delete(id string) error {
-	query := "UPDATE cameras SET is_active = false, updated_at = NOW() WHERE id = $1"
+// Delete memindahkan camera ke trash (soft delete). stream_id tetap disimpan
+// agar import config RTSPtoWeb tidak membuat ulang camera yang dihapus.
+func (r *cameraRepository) Delete(id, userID string) error {
+	query := `
+		UPDATE cameras SET
+			is_active = false,
+			deleted_at = NOW(),
+			deleted_by = $2,
+			updated_at = NOW(),
+			version = version + 1
+		WHERE id = $1 AND is_active = true
+	`
 
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.Exec(query, id, sql.NullString{String: userID, Valid: userID != ""})
 	if err != nil {
 		return fmt.Errorf("failed to delete camera: %w", err)
 	}
@@ -264,14 +725,115 @@ func (r *cameraRepository) Delete(id string) error {
 	return nil
 }
 
+// GetTrash mengambil camera di trash, yang terakhir dihapus lebih dulu
+func (r *cameraRepository) GetTrash(page, pageSize int) ([]*models.Camera, *models.PaginationMeta, error) {
+	var totalItems int64
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM cameras WHERE is_active = false").Scan(&totalItems); err != nil {
+		return nil, nil, fmt.Errorf("failed to count deleted cameras: %w", err)
+	}
+
+	totalPages := int(totalItems) / pageSize
+	if int(totalItems)%pageSize > 0 {
+		totalPages++
+	}
+
+	query := `
+		SELECT ` + cameraColumns + `
+		FROM cameras
+		WHERE is_active = false
+		ORDER BY deleted_at DESC NULLS LAST, id ASC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.Query(query, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get deleted cameras: %w", err)
+	}
+	defer rows.Close()
+
+	cameras, err := scanCameras(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meta := &models.PaginationMeta{
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: totalItems,
+		TotalPages: totalPages,
+	}
+
+	return cameras, meta, nil
+}
+
+// GetTrashedByID mengambil camera yang ada di trash
+func (r *cameraRepository) GetTrashedByID(id string) (*models.Camera, error) {
+	query := `
+		SELECT ` + cameraColumns + `
+		FROM cameras
+		WHERE id = $1 AND is_active = false
+	`
+
+	camera, err := scanCamera(r.db.QueryRow(query, id))
+
+	if err == sql.ErrNoRows {
+		return nil, ErrCameraNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted camera: %w", err)
+	}
+
+	return camera, nil
+}
+
+// trashPurgeable adalah kondisi camera di trash yang boleh dihapus permanen:
+// REMOVE stream-nya sudah terkirim, karena riwayat outbox ikut terhapus
+const trashPurgeable = `is_active = false AND NOT EXISTS (
+			SELECT 1 FROM stream_outbox o WHERE o.camera_id = cameras.id AND o.status = 'PENDING'
+		)`
+
+// HardDelete menghapus permanen camera di trash beserta channel, probe dan
+// riwayat outbox-nya
+func (r *cameraRepository) HardDelete(id string) error {
+	result, err := r.db.Exec("DELETE FROM cameras WHERE id = $1 AND "+trashPurgeable, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete camera permanently: %w", err)
+	}
+
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrStreamRemovalPending
+	}
+
+	return nil
+}
+
+// PurgeDeletedBefore menghapus permanen maksimal limit camera yang masuk trash
+// sebelum waktu tertentu, mengembalikan camera yang dihapus
+func (r *cameraRepository) PurgeDeletedBefore(before time.Time, limit int) ([]*models.Camera, error) {
+	query := `
+		DELETE FROM cameras
+		WHERE id IN (
+			SELECT id FROM cameras
+			WHERE deleted_at < $1 AND ` + trashPurgeable + `
+			ORDER BY deleted_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + cameraColumns
+
+	rows, err := r.db.Query(query, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge deleted cameras: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCameras(rows)
+}
+
 func (r *cameraRepository) GetByZone(zone string) ([]*models.Camera, error) {
 	query := `
-		SELECT 
-			id, name, description, rtsp_url, stream_id,
-			latitude, longitude, building, zone,
-			ip_address, port, manufacturer, model, resolution, fps,
-			tags, status, last_seen, is_active, created_by,
-			created_at, updated_at
+		SELECT ` + cameraColumns + `
 		FROM cameras
 		WHERE zone = $1 AND is_active = true
 		ORDER BY created_at DESC
@@ -283,50 +845,12 @@ func (r *cameraRepository) GetByZone(zone string) ([]*models.Camera, error) {
 	}
 	defer rows.Close()
 
-	cameras := []*models.Camera{}
-	for rows.Next() {
-		camera := &models.Camera{}
-		err := rows.Scan(
-			&camera.ID,
-			&camera.Name,
-			&camera.Description,
-			&camera.RTSPUrl,
-			&camera.StreamID,
-			&camera.Latitude,
-			&camera.Longitude,
-			&camera.Building,
-			&camera.Zone,
-			&camera.IPAddress,
-			&camera.Port,
-			&camera.Manufacturer,
-			&camera.Model,
-			&camera.Resolution,
-			&camera.FPS,
-			pq.Array(&camera.Tags),
-			&camera.Status,
-			&camera.LastSeen,
-			&camera.IsActive,
-			&camera.CreatedBy,
-			&camera.CreatedAt,
-			&camera.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan camera: %w", err)
-		}
-		cameras = append(cameras, camera)
-	}
-
-	return cameras, nil
+	return scanCameras(rows)
 }
 
 func (r *cameraRepository) GetNearby(lat, lng, radius float64) ([]*models.Camera, error) {
 	query := `
-		SELECT 
-			id, name, description, rtsp_url, stream_id,
-			latitude, longitude, building, zone,
-			ip_address, port, manufacturer, model, resolution, fps,
-			tags, status, last_seen, is_active, created_by,
-			created_at, updated_at,
+		SELECT ` + cameraColumns + `,
 			earth_distance(
 				ll_to_earth(latitude, longitude),
 				ll_to_earth($1, $2)
@@ -345,33 +869,8 @@ func (r *cameraRepository) GetNearby(lat, lng, radius float64) ([]*models.Camera
 
 	cameras := []*models.Camera{}
 	for rows.Next() {
-		camera := &models.Camera{}
 		var distance float64
-		err := rows.Scan(
-			&camera.ID,
-			&camera.Name,
-			&camera.Description,
-			&camera.RTSPUrl,
-			&camera.StreamID,
-			&camera.Latitude,
-			&camera.Longitude,
-			&camera.Building,
-			&camera.Zone,
-			&camera.IPAddress,
-			&camera.Port,
-			&camera.Manufacturer,
-			&camera.Model,
-			&camera.Resolution,
-			&camera.FPS,
-			pq.Array(&camera.Tags),
-			&camera.Status,
-			&camera.LastSeen,
-			&camera.IsActive,
-			&camera.CreatedBy,
-			&camera.CreatedAt,
-			&camera.UpdatedAt,
-			&distance,
-		)
+		camera, err := scanCamera(rows, &distance)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan camera: %w", err)
 		}
@@ -380,3 +879,107 @@ func (r *cameraRepository) GetNearby(lat, lng, radius float64) ([]*models.Camera
 
 	return cameras, nil
 }
+
+// GetWithStream mengambil semua camera aktif yang sudah terdaftar di RTSPtoWeb
+func (r *cameraRepository) GetWithStream() ([]*models.Camera, error) {
+	query := `
+		SELECT ` + cameraColumns + `
+		FROM cameras
+		WHERE is_active = true AND stream_id IS NOT NULL AND stream_id <> ''
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get streaming cameras: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCameras(rows)
+}
+
+// GetForCredentialRotation mengambil camera (termasuk yang dihapus) yang
+// credential-nya perlu disegel ulang: password dienkripsi dengan key selain
+// activeKeyID, atau rtsp_url/URL channel masih berisi userinfo (data lama)
+func (r *cameraRepository) GetForCredentialRotation(activeKeyID string) ([]*models.Camera, error) {
+	query := `
+		SELECT ` + cameraColumns + `
+		FROM cameras
+		WHERE (rtsp_password_enc IS NOT NULL AND split_part(rtsp_password_enc, ':', 1) <> $1)
+		OR rtsp_url LIKE '%@%'
+		OR EXISTS (
+			SELECT 1 FROM camera_channels ch
+			WHERE ch.camera_id = cameras.id AND ch.rtsp_url LIKE '%@%'
+		)
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(query, activeKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cameras for credential rotation: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCameras(rows)
+}
+
+// UpdateStatus menyimpan status stream dan last_seen tanpa menyentuh updated_at,
+// karena perubahan ini berasal dari monitor, bukan dari edit user.
+// lastSeen nil berarti last_seen yang lama dipertahankan.
+func (r *cameraRepository) UpdateStatus(id, status string, lastSeen *time.Time) error {
+	query := `
+		UPDATE cameras SET
+			status = $1,
+			last_seen = COALESCE($2, last_seen)
+		WHERE id = $3
+	`
+
+	_, err := r.db.Exec(query, status, lastSeen, id)
+	if err != nil {
+		return fmt.Errorf("failed to update camera status: %w", err)
+	}
+
+	return nil
+}
+
+// GetByMediaServer mengambil camera aktif yang stream-nya berjalan di node tertentu
+func (r *cameraRepository) GetByMediaServer(serverID string) ([]*models.Camera, error) {
+	query := `
+		SELECT ` + cameraColumns + `
+		FROM cameras
+		WHERE is_active = true AND stream_id IS NOT NULL AND media_server_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Query(query, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cameras by media server: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCameras(rows)
+}
+
+// UpdateMediaServer memindahkan assignment node camera (placement / failover)
+func (r *cameraRepository) UpdateMediaServer(id string, serverID sql.NullString) error {
+	query := "UPDATE cameras SET media_server_id = $1 WHERE id = $2"
+
+	_, err := r.db.Exec(query, serverID, id)
+	if err != nil {
+		return fmt.Errorf("failed to update camera media server: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSyncStatus menyimpan status sinkronisasi stream camera dengan RTSPtoWeb
+func (r *cameraRepository) UpdateSyncStatus(id, status string, syncError sql.NullString) error {
+	query := "UPDATE cameras SET sync_status = $1, sync_error = $2 WHERE id = $3"
+
+	_, err := r.db.Exec(query, status, syncError, id)
+	if err != nil {
+		return fmt.Errorf("failed to update camera sync status: %w", err)
+	}
+
+	return nil
+}
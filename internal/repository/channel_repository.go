@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"cctv-monitoring-backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// ChannelRepository adalah interface untuk operasi database camera channels
+type ChannelRepository interface {
+	GetByCameraID(cameraID string) ([]models.CameraChannel, error)
+	GetByCameraIDs(cameraIDs []string) (map[string][]models.CameraChannel, error)
+	ReplaceForCamera(cameraID string, channels []models.CameraChannel) error
+	WithTx(tx *sql.Tx) ChannelRepository
+}
+
+type channelRepository struct {
+	db DBTX
+}
+
+// NewChannelRepository membuat instance baru dari ChannelRepository
+func NewChannelRepository(db *sql.DB) ChannelRepository {
+	return &channelRepository{db: db}
+}
+
+// WithTx mengembalikan ChannelRepository yang berjalan di dalam transaksi tx
+func (r *channelRepository) WithTx(tx *sql.Tx) ChannelRepository {
+	return &channelRepository{db: tx}
+}
+
+const channelColumns = `id, camera_id, channel_index, label, rtsp_url, role, created_at, updated_at`
+
+func scanChannel(row rowScanner) (models.CameraChannel, error) {
+	var channel models.CameraChannel
+	err := row.Scan(
+		&channel.ID,
+		&channel.CameraID,
+		&channel.Index,
+		&channel.Label,
+		&channel.RTSPUrl,
+		&channel.Role,
+		&channel.CreatedAt,
+		&channel.UpdatedAt,
+	)
+	return channel, err
+}
+
+// GetByCameraID mengambil semua channel milik satu camera, urut berdasarkan index
+func (r *channelRepository) GetByCameraID(cameraID string) ([]models.CameraChannel, error) {
+	query := `
+		SELECT ` + channelColumns + `
+		FROM camera_channels
+		WHERE camera_id = $1
+		ORDER BY channel_index ASC
+	`
+
+	rows, err := r.db.Query(query, cameraID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get camera channels: %w", err)
+	}
+	defer rows.Close()
+
+	channels := []models.CameraChannel{}
+	for rows.Next() {
+		channel, err := scanChannel(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan camera channel: %w", err)
+		}
+		channels = append(channels, channel)
+	}
+
+	return channels, rows.Err()
+}
+
+// GetByCameraIDs mengambil channel untuk banyak camera sekaligus (untuk list endpoint)
+func (r *channelRepository) GetByCameraIDs(cameraIDs []string) (map[string][]models.CameraChannel, error) {
+	result := make(map[string][]models.CameraChannel, len(cameraIDs))
+	if len(cameraIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT ` + channelColumns + `
+		FROM camera_channels
+		WHERE camera_id = ANY($1::uuid[])
+		ORDER BY camera_id, channel_index ASC
+	`
+
+	rows, err := r.db.Query(query, pq.Array(cameraIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get camera channels: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		channel, err := scanChannel(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan camera channel: %w", err)
+		}
+		result[channel.CameraID] = append(result[channel.CameraID], channel)
+	}
+
+	return result, rows.Err()
+}
+
+// ReplaceForCamera mengganti seluruh channel milik camera dalam satu transaksi
+func (r *channelRepository) ReplaceForCamera(cameraID string, channels []models.CameraChannel) error {
+	return inTx(r.db, func(tx DBTX) error {
+		return replaceChannels(tx, cameraID, channels)
+	})
+}
+
+func replaceChannels(tx DBTX, cameraID string, channels []models.CameraChannel) error {
+	if _, err := tx.Exec("DELETE FROM camera_channels WHERE camera_id = $1", cameraID); err != nil {
+		return fmt.Errorf("failed to delete camera channels: %w", err)
+	}
+
+	query := `
+		INSERT INTO camera_channels (camera_id, channel_index, label, rtsp_url, role)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	for i := range channels {
+		channels[i].CameraID = cameraID
+		err := tx.QueryRow(
+			query,
+			cameraID,
+			channels[i].Index,
+			channels[i].Label,
+			channels[i].RTSPUrl,
+			channels[i].Role,
+		).Scan(&channels[i].ID, &channels[i].CreatedAt, &channels[i].UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create camera channel: %w", err)
+		}
+	}
+
+	return nil
+}
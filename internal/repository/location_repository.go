@@ -0,0 +1,590 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"cctv-monitoring-backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrLocationNotFound dikembalikan jika site, building, floor atau zone tidak ada
+	ErrLocationNotFound = errors.New("location not found")
+	// ErrLocationExists dikembalikan jika nama sudah dipakai di parent yang sama
+	ErrLocationExists = errors.New("a location with this name already exists")
+)
+
+// LocationRepository adalah interface untuk hierarki site, building, floor
+// dan zone
+type LocationRepository interface {
+	CreateSite(site *models.Site) error
+	GetSite(id string) (*models.Site, error)
+	GetSites() ([]*models.Site, error)
+	UpdateSite(site *models.Site) error
+	DeleteSite(id string) error
+
+	CreateBuilding(building *models.Building) error
+	GetBuilding(id string) (*models.Building, error)
+	GetBuildings(siteID string) ([]*models.Building, error)
+	UpdateBuilding(building *models.Building) error
+	DeleteBuilding(id string) error
+
+	CreateFloor(floor *models.Floor) error
+	GetFloor(id string) (*models.Floor, error)
+	GetFloors(buildingID string) ([]*models.Floor, error)
+	UpdateFloor(floor *models.Floor) error
+	DeleteFloor(id string) error
+
+	CreateZone(zone *models.Zone) error
+	GetZone(id string) (*models.Zone, error)
+	GetZones(buildingID, floorID string) ([]*models.Zone, error)
+	UpdateZone(zone *models.Zone) error
+	DeleteZone(id string) error
+	FindZone(buildingName, zoneName string) (*models.Zone, error)
+
+	CountChildren(table, parentColumn, parentID string) (int, error)
+	CountCamerasByZone() (map[string]models.LocationCounts, models.LocationCounts, error)
+	SyncCameraNames(buildingID string) ([]models.CameraLocationSync, error)
+	WithTx(tx *sql.Tx) LocationRepository
+}
+
+type locationRepository struct {
+	db DBTX
+}
+
+// NewLocationRepository membuat instance baru dari LocationRepository
+func NewLocationRepository(db *sql.DB) LocationRepository {
+	return &locationRepository{db: db}
+}
+
+// WithTx mengembalikan LocationRepository yang berjalan di dalam transaksi tx
+func (r *locationRepository) WithTx(tx *sql.Tx) LocationRepository {
+	return &locationRepository{db: tx}
+}
+
+// locationError menerjemahkan error database: nama duplikat (unique index
+// per parent) menjadi ErrLocationExists
+func locationError(err error, action string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrLocationExists
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
+
+// locationNotFound mengecek apakah query lokasi tidak menemukan baris, termasuk
+// ID yang bukan UUID (invalid_text_representation)
+func locationNotFound(err error) bool {
+	var pqErr *pq.Error
+	return err == sql.ErrNoRows || (errors.As(err, &pqErr) && pqErr.Code == "22P02")
+}
+
+// geometryArg mengubah geometry menjadi argumen query, NULL jika kosong
+func geometryArg(geometry json.RawMessage) interface{} {
+	if len(geometry) == 0 || models.IsJSONNull(geometry) {
+		return nil
+	}
+	return string(geometry)
+}
+
+// execLocation menjalankan UPDATE/DELETE satu lokasi, ErrLocationNotFound jika
+// tidak ada baris yang berubah
+func (r *locationRepository) execLocation(action, query string, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return locationError(err, action)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	if affected == 0 {
+		return ErrLocationNotFound
+	}
+
+	return nil
+}
+
+const siteColumns = `id, name, address, geometry, created_at, updated_at`
+
+func scanSite(row rowScanner) (*models.Site, error) {
+	site := &models.Site{}
+	var geometry []byte
+	if err := row.Scan(&site.ID, &site.Name, &site.Address, &geometry, &site.CreatedAt, &site.UpdatedAt); err != nil {
+		return nil, err
+	}
+	site.Geometry = geometry
+	return site, nil
+}
+
+// CreateSite menyimpan site baru
+func (r *locationRepository) CreateSite(site *models.Site) error {
+	query := `
+		INSERT INTO sites (name, address, geometry)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, site.Name, site.Address, geometryArg(site.Geometry)).
+		Scan(&site.ID, &site.CreatedAt, &site.UpdatedAt)
+	if err != nil {
+		return locationError(err, "create site")
+	}
+
+	return nil
+}
+
+// GetSite mencari site berdasarkan ID
+func (r *locationRepository) GetSite(id string) (*models.Site, error) {
+	site, err := scanSite(r.db.QueryRow("SELECT "+siteColumns+" FROM sites WHERE id = $1", id))
+	if locationNotFound(err) {
+		return nil, ErrLocationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get site: %w", err)
+	}
+	return site, nil
+}
+
+// GetSites mengambil semua site, urut nama
+func (r *locationRepository) GetSites() ([]*models.Site, error) {
+	rows, err := r.db.Query("SELECT " + siteColumns + " FROM sites ORDER BY name ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sites: %w", err)
+	}
+	defer rows.Close()
+
+	sites := []*models.Site{}
+	for rows.Next() {
+		site, err := scanSite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan site: %w", err)
+		}
+		sites = append(sites, site)
+	}
+
+	return sites, rows.Err()
+}
+
+// UpdateSite menyimpan perubahan site
+func (r *locationRepository) UpdateSite(site *models.Site) error {
+	query := `
+		UPDATE sites SET name = $1, address = $2, geometry = $3, updated_at = NOW()
+		WHERE id = $4
+	`
+	return r.execLocation("update site", query, site.Name, site.Address, geometryArg(site.Geometry), site.ID)
+}
+
+// DeleteSite menghapus site
+func (r *locationRepository) DeleteSite(id string) error {
+	return r.execLocation("delete site", "DELETE FROM sites WHERE id = $1", id)
+}
+
+const buildingColumns = `id, site_id, name, address, geometry, created_at, updated_at`
+
+func scanBuilding(row rowScanner) (*models.Building, error) {
+	building := &models.Building{}
+	var geometry []byte
+	err := row.Scan(&building.ID, &building.SiteID, &building.Name, &building.Address, &geometry, &building.CreatedAt, &building.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	building.Geometry = geometry
+	return building, nil
+}
+
+// CreateBuilding menyimpan building baru
+func (r *locationRepository) CreateBuilding(building *models.Building) error {
+	query := `
+		INSERT INTO buildings (site_id, name, address, geometry)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, building.SiteID, building.Name, building.Address, geometryArg(building.Geometry)).
+		Scan(&building.ID, &building.CreatedAt, &building.UpdatedAt)
+	if err != nil {
+		return locationError(err, "create building")
+	}
+
+	return nil
+}
+
+// GetBuilding mencari building berdasarkan ID
+func (r *locationRepository) GetBuilding(id string) (*models.Building, error) {
+	building, err := scanBuilding(r.db.QueryRow("SELECT "+buildingColumns+" FROM buildings WHERE id = $1", id))
+	if locationNotFound(err) {
+		return nil, ErrLocationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get building: %w", err)
+	}
+	return building, nil
+}
+
+// GetBuildings mengambil building, semua site jika siteID kosong
+func (r *locationRepository) GetBuildings(siteID string) ([]*models.Building, error) {
+	b := &queryBuilder{}
+	if siteID != "" {
+		b.where("site_id = " + b.arg(siteID))
+	}
+
+	rows, err := r.db.Query("SELECT "+buildingColumns+" FROM buildings "+b.whereClause()+" ORDER BY name ASC", b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get buildings: %w", err)
+	}
+	defer rows.Close()
+
+	buildings := []*models.Building{}
+	for rows.Next() {
+		building, err := scanBuilding(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan building: %w", err)
+		}
+		buildings = append(buildings, building)
+	}
+
+	return buildings, rows.Err()
+}
+
+// UpdateBuilding menyimpan perubahan building
+func (r *locationRepository) UpdateBuilding(building *models.Building) error {
+	query := `
+		UPDATE buildings SET site_id = $1, name = $2, address = $3, geometry = $4, updated_at = NOW()
+		WHERE id = $5
+	`
+	return r.execLocation("update building", query, building.SiteID, building.Name, building.Address, geometryArg(building.Geometry), building.ID)
+}
+
+// DeleteBuilding menghapus building
+func (r *locationRepository) DeleteBuilding(id string) error {
+	return r.execLocation("delete building", "DELETE FROM buildings WHERE id = $1", id)
+}
+
+const floorColumns = `id, building_id, name, level, geometry, created_at, updated_at`
+
+func scanFloor(row rowScanner) (*models.Floor, error) {
+	floor := &models.Floor{}
+	var geometry []byte
+	err := row.Scan(&floor.ID, &floor.BuildingID, &floor.Name, &floor.Level, &geometry, &floor.CreatedAt, &floor.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	floor.Geometry = geometry
+	return floor, nil
+}
+
+// CreateFloor menyimpan floor baru
+func (r *locationRepository) CreateFloor(floor *models.Floor) error {
+	query := `
+		INSERT INTO floors (building_id, name, level, geometry)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, floor.BuildingID, floor.Name, floor.Level, geometryArg(floor.Geometry)).
+		Scan(&floor.ID, &floor.CreatedAt, &floor.UpdatedAt)
+	if err != nil {
+		return locationError(err, "create floor")
+	}
+
+	return nil
+}
+
+// GetFloor mencari floor berdasarkan ID
+func (r *locationRepository) GetFloor(id string) (*models.Floor, error) {
+	floor, err := scanFloor(r.db.QueryRow("SELECT "+floorColumns+" FROM floors WHERE id = $1", id))
+	if locationNotFound(err) {
+		return nil, ErrLocationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get floor: %w", err)
+	}
+	return floor, nil
+}
+
+// GetFloors mengambil floor, semua building jika buildingID kosong. Urut
+// berdasarkan level.
+func (r *locationRepository) GetFloors(buildingID string) ([]*models.Floor, error) {
+	b := &queryBuilder{}
+	if buildingID != "" {
+		b.where("building_id = " + b.arg(buildingID))
+	}
+
+	rows, err := r.db.Query("SELECT "+floorColumns+" FROM floors "+b.whereClause()+" ORDER BY building_id, level ASC, name ASC", b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get floors: %w", err)
+	}
+	defer rows.Close()
+
+	floors := []*models.Floor{}
+	for rows.Next() {
+		floor, err := scanFloor(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan floor: %w", err)
+		}
+		floors = append(floors, floor)
+	}
+
+	return floors, rows.Err()
+}
+
+// UpdateFloor menyimpan perubahan floor
+func (r *locationRepository) UpdateFloor(floor *models.Floor) error {
+	query := `
+		UPDATE floors SET name = $1, level = $2, geometry = $3, updated_at = NOW()
+		WHERE id = $4
+	`
+	return r.execLocation("update floor", query, floor.Name, floor.Level, geometryArg(floor.Geometry), floor.ID)
+}
+
+// DeleteFloor menghapus floor
+func (r *locationRepository) DeleteFloor(id string) error {
+	return r.execLocation("delete floor", "DELETE FROM floors WHERE id = $1", id)
+}
+
+const zoneColumns = `id, building_id, floor_id, name, geometry, created_at, updated_at`
+
+func scanZone(row rowScanner) (*models.Zone, error) {
+	zone := &models.Zone{}
+	var geometry []byte
+	err := row.Scan(&zone.ID, &zone.BuildingID, &zone.FloorID, &zone.Name, &geometry, &zone.CreatedAt, &zone.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	zone.Geometry = geometry
+	return zone, nil
+}
+
+// CreateZone menyimpan zone baru
+func (r *locationRepository) CreateZone(zone *models.Zone) error {
+	query := `
+		INSERT INTO zones (building_id, floor_id, name, geometry)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, zone.BuildingID, zone.FloorID, zone.Name, geometryArg(zone.Geometry)).
+		Scan(&zone.ID, &zone.CreatedAt, &zone.UpdatedAt)
+	if err != nil {
+		return locationError(err, "create zone")
+	}
+
+	return nil
+}
+
+// GetZone mencari zone berdasarkan ID
+func (r *locationRepository) GetZone(id string) (*models.Zone, error) {
+	zone, err := scanZone(r.db.QueryRow("SELECT "+zoneColumns+" FROM zones WHERE id = $1", id))
+	if locationNotFound(err) {
+		return nil, ErrLocationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zone: %w", err)
+	}
+	return zone, nil
+}
+
+// GetZones mengambil zone, difilter building dan/atau floor jika diisi
+func (r *locationRepository) GetZones(buildingID, floorID string) ([]*models.Zone, error) {
+	b := &queryBuilder{}
+	if buildingID != "" {
+		b.where("building_id = " + b.arg(buildingID))
+	}
+	if floorID != "" {
+		b.where("floor_id = " + b.arg(floorID))
+	}
+
+	rows, err := r.db.Query("SELECT "+zoneColumns+" FROM zones "+b.whereClause()+" ORDER BY name ASC", b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zones: %w", err)
+	}
+	defer rows.Close()
+
+	zones := []*models.Zone{}
+	for rows.Next() {
+		zone, err := scanZone(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan zone: %w", err)
+		}
+		zones = append(zones, zone)
+	}
+
+	return zones, rows.Err()
+}
+
+// UpdateZone menyimpan perubahan zone
+func (r *locationRepository) UpdateZone(zone *models.Zone) error {
+	query := `
+		UPDATE zones SET building_id = $1, floor_id = $2, name = $3, geometry = $4, updated_at = NOW()
+		WHERE id = $5
+	`
+	return r.execLocation("update zone", query, zone.BuildingID, zone.FloorID, zone.Name, geometryArg(zone.Geometry), zone.ID)
+}
+
+// DeleteZone menghapus zone. zone_id camera di trash menjadi NULL.
+func (r *locationRepository) DeleteZone(id string) error {
+	return r.execLocation("delete zone", "DELETE FROM zones WHERE id = $1", id)
+}
+
+// FindZone mencari zone berdasarkan nama building dan nama zone (tidak
+// case-sensitive). ErrLocationNotFound jika tidak ada atau lebih dari satu
+// zone yang cocok (nama building sama di site berbeda).
+func (r *locationRepository) FindZone(buildingName, zoneName string) (*models.Zone, error) {
+	query := `
+		SELECT z.id, z.building_id, z.floor_id, z.name, z.geometry, z.created_at, z.updated_at
+		FROM zones z
+		JOIN buildings b ON b.id = z.building_id
+		WHERE LOWER(b.name) = LOWER($1) AND LOWER(z.name) = LOWER($2)
+		LIMIT 2
+	`
+
+	rows, err := r.db.Query(query, buildingName, zoneName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find zone: %w", err)
+	}
+	defer rows.Close()
+
+	var zones []*models.Zone
+	for rows.Next() {
+		zone, err := scanZone(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan zone: %w", err)
+		}
+		zones = append(zones, zone)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find zone: %w", err)
+	}
+
+	if len(zones) != 1 {
+		return nil, ErrLocationNotFound
+	}
+	return zones[0], nil
+}
+
+// locationChildTables adalah tabel dan kolom parent yang boleh dihitung
+// CountChildren, nama tabel tidak pernah berasal dari request
+var locationChildTables = map[string][]string{
+	"buildings": {"site_id"},
+	"floors":    {"building_id"},
+	"zones":     {"building_id", "floor_id"},
+	"cameras":   {"zone_id"},
+}
+
+// CountChildren menghitung baris table yang menunjuk ke parentID. Untuk
+// cameras hanya camera aktif yang dihitung.
+func (r *locationRepository) CountChildren(table, parentColumn, parentID string) (int, error) {
+	allowed := false
+	for _, column := range locationChildTables[table] {
+		allowed = allowed || column == parentColumn
+	}
+	if !allowed {
+		return 0, fmt.Errorf("cannot count %s by %s", table, parentColumn)
+	}
+
+	query := "SELECT COUNT(*) FROM " + table + " WHERE " + parentColumn + " = $1"
+	if table == "cameras" {
+		query += " AND is_active = true"
+	}
+
+	var count int
+	if err := r.db.QueryRow(query, parentID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count %s: %w", table, err)
+	}
+	return count, nil
+}
+
+// CountCamerasByZone menghitung camera aktif dan yang ONLINE (status yang
+// ditulis stream monitor) per zone. Camera tanpa zone dijumlahkan di unassigned.
+func (r *locationRepository) CountCamerasByZone() (map[string]models.LocationCounts, models.LocationCounts, error) {
+	var unassigned models.LocationCounts
+
+	query := `
+		SELECT zone_id, COUNT(*), COUNT(*) FILTER (WHERE status = $1)
+		FROM cameras
+		WHERE is_active = true
+		GROUP BY zone_id
+	`
+
+	rows, err := r.db.Query(query, models.CameraStatusOnline)
+	if err != nil {
+		return nil, unassigned, fmt.Errorf("failed to count cameras by zone: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]models.LocationCounts{}
+	for rows.Next() {
+		var zoneID sql.NullString
+		var c models.LocationCounts
+		if err := rows.Scan(&zoneID, &c.CameraCount, &c.OnlineCount); err != nil {
+			return nil, unassigned, fmt.Errorf("failed to scan camera counts: %w", err)
+		}
+		if zoneID.Valid {
+			counts[zoneID.String] = c
+		} else {
+			unassigned = c
+		}
+	}
+
+	return counts, unassigned, rows.Err()
+}
+
+// SyncCameraNames menyamakan kolom building dan zone camera di building dengan
+// nama building dan zone-nya, setelah building atau zone diganti nama atau
+// dipindah. Mengembalikan camera yang berubah beserta state sebelumnya untuk
+// dicatat sebagai revision.
+func (r *locationRepository) SyncCameraNames(buildingID string) ([]models.CameraLocationSync, error) {
+	// SELECT utama memakai snapshot yang sama dengan UPDATE di CTE, sehingga
+	// membaca baris camera sebelum diubah
+	query := `
+		WITH synced AS (
+			UPDATE cameras c
+			SET building = b.name, zone = z.name, updated_at = NOW(), version = c.version + 1
+			FROM zones z
+			JOIN buildings b ON b.id = z.building_id
+			WHERE c.zone_id = z.id AND z.building_id = $1
+				AND (c.building IS DISTINCT FROM b.name OR c.zone IS DISTINCT FROM z.name)
+			RETURNING c.id AS synced_id, c.building AS synced_building, c.zone AS synced_zone,
+				c.updated_at AS synced_updated_at, c.version AS synced_version
+		)
+		SELECT ` + cameraColumns + `,
+			synced_building, synced_zone, synced_updated_at, synced_version
+		FROM cameras
+		JOIN synced ON synced_id = id
+		ORDER BY id
+	`
+
+	rows, err := r.db.Query(query, buildingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync camera locations: %w", err)
+	}
+	defer rows.Close()
+
+	synced := []models.CameraLocationSync{}
+	for rows.Next() {
+		var building, zone sql.NullString
+		var updatedAt time.Time
+		var version int
+
+		before, err := scanCamera(rows, &building, &zone, &updatedAt, &version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan synced camera: %w", err)
+		}
+
+		after := *before
+		after.Building = building
+		after.Zone = zone
+		after.UpdatedAt = updatedAt
+		after.Version = version
+		synced = append(synced, models.CameraLocationSync{Before: before, After: &after})
+	}
+
+	return synced, rows.Err()
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"cctv-monitoring-backend/internal/models"
+)
+
+// MediaServerRepository adalah interface untuk operasi database media servers
+type MediaServerRepository interface {
+	Create(server *models.MediaServer) error
+	GetByID(id string) (*models.MediaServer, error)
+	GetAll() ([]*models.MediaServer, error)
+	Update(id string, server *models.MediaServer) error
+	Delete(id string) error
+	UpdateStatus(id, status string) error
+	AssignUnplacedCameras(serverID string) (int64, error)
+	GetForCredentialRotation(activeKeyID string) ([]*models.MediaServer, error)
+	UpdatePassword(id string, passwordEnc sql.NullString) error
+}
+
+type mediaServerRepository struct {
+	db *sql.DB
+}
+
+// NewMediaServerRepository membuat instance baru dari MediaServerRepository
+func NewMediaServerRepository(db *sql.DB) MediaServerRepository {
+	return &mediaServerRepository{db: db}
+}
+
+// mediaServerSelect membaca node beserta jumlah stream camera yang di-assign
+const mediaServerSelect = `
+		SELECT
+			m.id, m.name, m.api_url, m.public_url, COALESCE(m.username, ''), m.password_enc,
+			m.max_streams, m.status, m.last_checked, m.is_active, m.created_at, m.updated_at,
+			COUNT(c.id) AS stream_count
+		FROM media_servers m
+		LEFT JOIN cameras c ON c.media_server_id = m.id
+			AND c.is_active = true AND c.stream_id IS NOT NULL
+	`
+
+func scanMediaServer(row rowScanner) (*models.MediaServer, error) {
+	server := &models.MediaServer{}
+	err := row.Scan(
+		&server.ID,
+		&server.Name,
+		&server.APIURL,
+		&server.PublicURL,
+		&server.Username,
+		&server.PasswordEnc,
+		&server.MaxStreams,
+		&server.Status,
+		&server.LastChecked,
+		&server.IsActive,
+		&server.CreatedAt,
+		&server.UpdatedAt,
+		&server.StreamCount,
+	)
+	return server, err
+}
+
+// Create mendaftarkan node baru. Password disimpan dari PasswordEnc, bukan
+// plaintext.
+func (r *mediaServerRepository) Create(server *models.MediaServer) error {
+	query := `
+		INSERT INTO media_servers (name, api_url, public_url, username, password_enc, max_streams, status, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		server.Name,
+		server.APIURL,
+		server.PublicURL,
+		server.Username,
+		server.PasswordEnc,
+		server.MaxStreams,
+		server.Status,
+		server.IsActive,
+	).Scan(&server.ID, &server.CreatedAt, &server.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create media server: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID mencari node berdasarkan ID
+func (r *mediaServerRepository) GetByID(id string) (*models.MediaServer, error) {
+	query := mediaServerSelect + `
+		WHERE m.id = $1
+		GROUP BY m.id
+	`
+
+	server, err := scanMediaServer(r.db.QueryRow(query, id))
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("media server not found")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media server: %w", err)
+	}
+
+	return server, nil
+}
+
+// GetAll mengambil semua node
+func (r *mediaServerRepository) GetAll() ([]*models.MediaServer, error) {
+	query := mediaServerSelect + `
+		GROUP BY m.id
+		ORDER BY m.created_at ASC
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media servers: %w", err)
+	}
+	defer rows.Close()
+
+	servers := []*models.MediaServer{}
+	for rows.Next() {
+		server, err := scanMediaServer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan media server: %w", err)
+		}
+		servers = append(servers, server)
+	}
+
+	return servers, rows.Err()
+}
+
+// Update mengupdate data node. Password disimpan dari PasswordEnc.
+func (r *mediaServerRepository) Update(id string, server *models.MediaServer) error {
+	query := `
+		UPDATE media_servers SET
+			name = $1,
+			api_url = $2,
+			public_url = $3,
+			username = $4,
+			password_enc = $5,
+			max_streams = $6,
+			is_active = $7,
+			updated_at = NOW()
+		WHERE id = $8
+	`
+
+	_, err := r.db.Exec(
+		query,
+		server.Name,
+		server.APIURL,
+		server.PublicURL,
+		server.Username,
+		server.PasswordEnc,
+		server.MaxStreams,
+		server.IsActive,
+		id,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update media server: %w", err)
+	}
+
+	return nil
+}
+
+// Delete menghapus node, camera yang di-assign otomatis menjadi NULL (ON DELETE SET NULL)
+func (r *mediaServerRepository) Delete(id string) error {
+	_, err := r.db.Exec("DELETE FROM media_servers WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete media server: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateStatus menyimpan hasil health check node
+func (r *mediaServerRepository) UpdateStatus(id, status string) error {
+	query := "UPDATE media_servers SET status = $1, last_checked = NOW() WHERE id = $2"
+
+	_, err := r.db.Exec(query, status, id)
+	if err != nil {
+		return fmt.Errorf("failed to update media server status: %w", err)
+	}
+
+	return nil
+}
+
+// AssignUnplacedCameras meng-assign camera yang sudah punya stream tapi belum punya
+// node (data sebelum multi-node) ke node tertentu
+func (r *mediaServerRepository) AssignUnplacedCameras(serverID string) (int64, error) {
+	query := `
+		UPDATE cameras SET media_server_id = $1
+		WHERE media_server_id IS NULL AND stream_id IS NOT NULL
+	`
+
+	result, err := r.db.Exec(query, serverID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to assign cameras to media server: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// GetForCredentialRotation mengambil node yang password-nya dienkripsi dengan
+// key selain activeKeyID
+func (r *mediaServerRepository) GetForCredentialRotation(activeKeyID string) ([]*models.MediaServer, error) {
+	query := mediaServerSelect + `
+		WHERE m.password_enc IS NOT NULL AND split_part(m.password_enc, ':', 1) <> $1
+		GROUP BY m.id
+		ORDER BY m.created_at ASC
+	`
+
+	rows, err := r.db.Query(query, activeKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media servers for credential rotation: %w", err)
+	}
+	defer rows.Close()
+
+	servers := []*models.MediaServer{}
+	for rows.Next() {
+		server, err := scanMediaServer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan media server: %w", err)
+		}
+		servers = append(servers, server)
+	}
+
+	return servers, rows.Err()
+}
+
+// UpdatePassword menyimpan password node yang dienkripsi ulang. updated_at
+// tidak diubah karena password node tetap sama.
+func (r *mediaServerRepository) UpdatePassword(id string, passwordEnc sql.NullString) error {
+	query := "UPDATE media_servers SET password_enc = $1 WHERE id = $2"
+
+	_, err := r.db.Exec(query, passwordEnc, id)
+	if err != nil {
+		return fmt.Errorf("failed to update media server password: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"cctv-monitoring-backend/internal/models"
+)
+
+// OutboxRepository adalah interface untuk operasi database stream outbox
+type OutboxRepository interface {
+	Enqueue(entry *models.StreamOutboxEntry) error
+	ClaimDue(cameraID string, limit int, lease time.Duration) ([]*models.StreamOutboxEntry, error)
+	MarkDelivered(id int64) error
+	MarkRetry(id int64, lastError string, nextAttemptAt time.Time) error
+	MarkFailed(id int64, lastError string) error
+	CountPending(cameraID string) (int, error)
+	GetByCamera(cameraID string, limit int) ([]*models.StreamOutboxEntry, error)
+	DeleteDeliveredBefore(before time.Time) (int64, error)
+	WithTx(tx *sql.Tx) OutboxRepository
+}
+
+type outboxRepository struct {
+	db DBTX
+}
+
+// NewOutboxRepository membuat instance baru dari OutboxRepository
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// WithTx mengembalikan OutboxRepository yang berjalan di dalam transaksi tx
+func (r *outboxRepository) WithTx(tx *sql.Tx) OutboxRepository {
+	return &outboxRepository{db: tx}
+}
+
+const outboxColumns = `
+		id, camera_id, operation, stream_id, media_server_id, status,
+		attempts, last_error, next_attempt_at, created_at, delivered_at`
+
+func scanOutboxEntry(row rowScanner) (*models.StreamOutboxEntry, error) {
+	entry := &models.StreamOutboxEntry{}
+	err := row.Scan(
+		&entry.ID,
+		&entry.CameraID,
+		&entry.Operation,
+		&entry.StreamID,
+		&entry.MediaServerID,
+		&entry.Status,
+		&entry.Attempts,
+		&entry.LastError,
+		&entry.NextAttemptAt,
+		&entry.CreatedAt,
+		&entry.DeliveredAt,
+	)
+	return entry, err
+}
+
+func scanOutboxEntries(rows *sql.Rows) ([]*models.StreamOutboxEntry, error) {
+	entries := []*models.StreamOutboxEntry{}
+	for rows.Next() {
+		entry, err := scanOutboxEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate outbox entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Enqueue mencatat operasi baru ke outbox
+func (r *outboxRepository) Enqueue(entry *models.StreamOutboxEntry) error {
+	query := `
+		INSERT INTO stream_outbox (camera_id, operation, stream_id, media_server_id, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, next_attempt_at, created_at
+	`
+
+	entry.Status = models.OutboxPending
+	err := r.db.QueryRow(
+		query,
+		entry.CameraID,
+		entry.Operation,
+		entry.StreamID,
+		entry.MediaServerID,
+		entry.Status,
+	).Scan(&entry.ID, &entry.NextAttemptAt, &entry.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to enqueue outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimDue mengambil operasi yang sudah jatuh tempo dan memberi lease agar tidak
+// dikirim dua worker sekaligus. Hanya operasi PENDING tertua per camera yang bisa
+// diambil, sehingga urutan ADD/EDIT/REMOVE satu camera selalu terjaga.
+// cameraID kosong berarti semua camera.
+func (r *outboxRepository) ClaimDue(cameraID string, limit int, lease time.Duration) ([]*models.StreamOutboxEntry, error) {
+	query := `
+		UPDATE stream_outbox SET locked_until = NOW() + $1::float8 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT o.id
+			FROM stream_outbox o
+			WHERE o.status = 'PENDING'
+			AND o.next_attempt_at <= NOW()
+			AND (o.locked_until IS NULL OR o.locked_until < NOW())
+			AND ($2::text = '' OR o.camera_id::text = $2::text)
+			AND NOT EXISTS (
+				SELECT 1 FROM stream_outbox p
+				WHERE p.camera_id = o.camera_id AND p.status = 'PENDING' AND p.id < o.id
+			)
+			ORDER BY o.id ASC
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + outboxColumns
+
+	rows, err := r.db.Query(query, lease.Seconds(), cameraID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries, err := scanOutboxEntries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING tidak menjamin urutan
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+
+	return entries, nil
+}
+
+// MarkDelivered menandai operasi berhasil dikirim ke RTSPtoWeb
+func (r *outboxRepository) MarkDelivered(id int64) error {
+	query := `
+		UPDATE stream_outbox SET
+			status = 'DELIVERED',
+			attempts = attempts + 1,
+			last_error = NULL,
+			locked_until = NULL,
+			delivered_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Exec(query, id); err != nil {
+		return fmt.Errorf("failed to mark outbox entry delivered: %w", err)
+	}
+
+	return nil
+}
+
+// MarkRetry mencatat kegagalan dan menjadwalkan percobaan berikutnya
+func (r *outboxRepository) MarkRetry(id int64, lastError string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE stream_outbox SET
+			attempts = attempts + 1,
+			last_error = $1,
+			next_attempt_at = $2,
+			locked_until = NULL
+		WHERE id = $3
+	`
+
+	if _, err := r.db.Exec(query, lastError, nextAttemptAt, id); err != nil {
+		return fmt.Errorf("failed to reschedule outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed menandai operasi gagal permanen setelah batas percobaan habis
+func (r *outboxRepository) MarkFailed(id int64, lastError string) error {
+	query := `
+		UPDATE stream_outbox SET
+			status = 'FAILED',
+			attempts = attempts + 1,
+			last_error = $1,
+			locked_until = NULL
+		WHERE id = $2
+	`
+
+	if _, err := r.db.Exec(query, lastError, id); err != nil {
+		return fmt.Errorf("failed to mark outbox entry failed: %w", err)
+	}
+
+	return nil
+}
+
+// CountPending menghitung operasi camera yang belum terkirim
+func (r *outboxRepository) CountPending(cameraID string) (int, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM stream_outbox WHERE camera_id = $1 AND status = 'PENDING'"
+
+	if err := r.db.QueryRow(query, cameraID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count pending outbox entries: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetByCamera mengambil riwayat operasi stream camera, terbaru dulu
+func (r *outboxRepository) GetByCamera(cameraID string, limit int) ([]*models.StreamOutboxEntry, error) {
+	query := `
+		SELECT ` + outboxColumns + `
+		FROM stream_outbox
+		WHERE camera_id = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(query, cameraID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbox entries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanOutboxEntries(rows)
+}
+
+// DeleteDeliveredBefore menghapus operasi yang sudah terkirim sebelum waktu tertentu
+func (r *outboxRepository) DeleteDeliveredBefore(before time.Time) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM stream_outbox WHERE status = 'DELIVERED' AND delivered_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete delivered outbox entries: %w", err)
+	}
+
+	return result.RowsAffected()
+}
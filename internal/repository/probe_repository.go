@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"cctv-monitoring-backend/internal/models"
+)
+
+// ErrProbeNotFound dikembalikan jika camera belum pernah di-probe
+var ErrProbeNotFound = errors.New("camera has not been probed")
+
+// ProbeRepository adalah interface untuk operasi database hasil probe camera
+type ProbeRepository interface {
+	Upsert(probe *models.CameraProbe) error
+	GetByCamera(cameraID string) (*models.CameraProbe, error)
+	WithTx(tx *sql.Tx) ProbeRepository
+}
+
+type probeRepository struct {
+	db DBTX
+}
+
+// NewProbeRepository membuat instance baru dari ProbeRepository
+func NewProbeRepository(db *sql.DB) ProbeRepository {
+	return &probeRepository{db: db}
+}
+
+// WithTx mengembalikan ProbeRepository yang berjalan di dalam transaksi tx
+func (r *probeRepository) WithTx(tx *sql.Tx) ProbeRepository {
+	return &probeRepository{db: tx}
+}
+
+// Upsert menyimpan hasil probe, menggantikan hasil sebelumnya
+func (r *probeRepository) Upsert(probe *models.CameraProbe) error {
+	tracks, err := json.Marshal(probe.Tracks)
+	if err != nil {
+		return fmt.Errorf("failed to encode probe tracks: %w", err)
+	}
+
+	query := `
+		INSERT INTO camera_probes (
+			camera_id, success, error, server, session_name, video_codec, video_profile,
+			width, height, fps, audio_codec, audio_sample_rate, track_count, tracks,
+			sdp, duration_ms, probed_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		ON CONFLICT (camera_id) DO UPDATE SET
+			success = EXCLUDED.success,
+			error = EXCLUDED.error,
+			server = EXCLUDED.server,
+			session_name = EXCLUDED.session_name,
+			video_codec = EXCLUDED.video_codec,
+			video_profile = EXCLUDED.video_profile,
+			width = EXCLUDED.width,
+			height = EXCLUDED.height,
+			fps = EXCLUDED.fps,
+			audio_codec = EXCLUDED.audio_codec,
+			audio_sample_rate = EXCLUDED.audio_sample_rate,
+			track_count = EXCLUDED.track_count,
+			tracks = EXCLUDED.tracks,
+			sdp = EXCLUDED.sdp,
+			duration_ms = EXCLUDED.duration_ms,
+			probed_at = EXCLUDED.probed_at
+	`
+
+	_, err = r.db.Exec(
+		query,
+		probe.CameraID,
+		probe.Success,
+		probe.Error,
+		probe.Server,
+		probe.SessionName,
+		probe.VideoCodec,
+		probe.VideoProfile,
+		probe.Width,
+		probe.Height,
+		probe.FPS,
+		probe.AudioCodec,
+		probe.AudioSampleRate,
+		probe.TrackCount,
+		string(tracks),
+		probe.SDP,
+		probe.DurationMs,
+		probe.ProbedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to save camera probe: %w", err)
+	}
+
+	return nil
+}
+
+// GetByCamera mengambil hasil probe terakhir camera
+func (r *probeRepository) GetByCamera(cameraID string) (*models.CameraProbe, error) {
+	query := `
+		SELECT
+			camera_id, success, error, server, session_name, video_codec, video_profile,
+			width, height, fps, audio_codec, audio_sample_rate, track_count, tracks,
+			sdp, duration_ms, probed_at
+		FROM camera_probes
+		WHERE camera_id = $1
+	`
+
+	probe := &models.CameraProbe{}
+	var tracks []byte
+	err := r.db.QueryRow(query, cameraID).Scan(
+		&probe.CameraID,
+		&probe.Success,
+		&probe.Error,
+		&probe.Server,
+		&probe.SessionName,
+		&probe.VideoCodec,
+		&probe.VideoProfile,
+		&probe.Width,
+		&probe.Height,
+		&probe.FPS,
+		&probe.AudioCodec,
+		&probe.AudioSampleRate,
+		&probe.TrackCount,
+		&tracks,
+		&probe.SDP,
+		&probe.DurationMs,
+		&probe.ProbedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrProbeNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get camera probe: %w", err)
+	}
+
+	if err := json.Unmarshal(tracks, &probe.Tracks); err != nil {
+		return nil, fmt.Errorf("failed to decode probe tracks: %w", err)
+	}
+
+	return probe, nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+)
+
+// queryBuilder menyusun klausa WHERE dengan placeholder bernomor. Nilai dari
+// request selalu menjadi argumen query, hanya nama kolom dari whitelist yang
+// ditulis langsung ke SQL.
+type queryBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg menambahkan argumen dan mengembalikan placeholder-nya ($n)
+func (b *queryBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// where menambahkan kondisi yang digabung dengan AND
+func (b *queryBuilder) where(condition string) {
+	b.conditions = append(b.conditions, condition)
+}
+
+// whereClause mengembalikan "WHERE ..." atau string kosong tanpa kondisi
+func (b *queryBuilder) whereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// likePattern meng-escape wildcard LIKE agar input dicocokkan apa adanya
+func likePattern(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
+}
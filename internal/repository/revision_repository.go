@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"cctv-monitoring-backend/internal/models"
+)
+
+// ErrRevisionNotFound dikembalikan jika revision tidak ada untuk camera tersebut
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// RevisionRepository adalah interface untuk riwayat perubahan camera
+type RevisionRepository interface {
+	Create(revision *models.CameraRevision) error
+	GetByCamera(cameraID string, page, pageSize int) ([]*models.CameraRevision, *models.PaginationMeta, error)
+	GetByID(cameraID string, id int64) (*models.CameraRevision, error)
+	GetAfter(cameraID string, id int64) ([]*models.CameraRevision, error)
+	WithTx(tx *sql.Tx) RevisionRepository
+}
+
+type revisionRepository struct {
+	db DBTX
+}
+
+// NewRevisionRepository membuat instance baru dari RevisionRepository
+func NewRevisionRepository(db *sql.DB) RevisionRepository {
+	return &revisionRepository{db: db}
+}
+
+// WithTx mengembalikan RevisionRepository yang berjalan di dalam transaksi tx
+func (r *revisionRepository) WithTx(tx *sql.Tx) RevisionRepository {
+	return &revisionRepository{db: tx}
+}
+
+// revisionColumns adalah kolom yang dibaca scanRevision, username dari users
+const revisionColumns = `r.id, r.camera_id, r.version, r.user_id, u.username,
+			r.action, r.changes, r.reverted_to, r.created_at`
+
+// scanRevision membaca satu baris revisionColumns
+func scanRevision(row rowScanner) (*models.CameraRevision, error) {
+	revision := &models.CameraRevision{}
+	var changes []byte
+
+	err := row.Scan(
+		&revision.ID,
+		&revision.CameraID,
+		&revision.Version,
+		&revision.UserID,
+		&revision.Username,
+		&revision.Action,
+		&changes,
+		&revision.RevertedTo,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(changes, &revision.Changes); err != nil {
+		return nil, fmt.Errorf("failed to decode revision changes: %w", err)
+	}
+
+	return revision, nil
+}
+
+// scanRevisions membaca semua baris hasil query revisionColumns
+func scanRevisions(rows *sql.Rows) ([]*models.CameraRevision, error) {
+	revisions := []*models.CameraRevision{}
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// Create menyimpan satu revision camera
+func (r *revisionRepository) Create(revision *models.CameraRevision) error {
+	changes, err := json.Marshal(revision.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode revision changes: %w", err)
+	}
+
+	query := `
+		INSERT INTO camera_revisions (camera_id, version, user_id, action, changes, reverted_to)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	err = r.db.QueryRow(
+		query,
+		revision.CameraID,
+		revision.Version,
+		revision.UserID,
+		revision.Action,
+		changes,
+		revision.RevertedTo,
+	).Scan(&revision.ID, &revision.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create camera revision: %w", err)
+	}
+
+	return nil
+}
+
+// GetByCamera mengambil riwayat perubahan camera, yang terbaru lebih dulu
+func (r *revisionRepository) GetByCamera(cameraID string, page, pageSize int) ([]*models.CameraRevision, *models.PaginationMeta, error) {
+	var totalItems int64
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM camera_revisions WHERE camera_id = $1", cameraID).Scan(&totalItems); err != nil {
+		return nil, nil, fmt.Errorf("failed to count camera revisions: %w", err)
+	}
+
+	totalPages := int(totalItems) / pageSize
+	if int(totalItems)%pageSize > 0 {
+		totalPages++
+	}
+
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM camera_revisions r
+		LEFT JOIN users u ON u.id = r.user_id
+		WHERE r.camera_id = $1
+		ORDER BY r.id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(query, cameraID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get camera revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions, err := scanRevisions(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meta := &models.PaginationMeta{
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: totalItems,
+		TotalPages: totalPages,
+	}
+
+	return revisions, meta, nil
+}
+
+// GetByID mengambil satu revision milik camera
+func (r *revisionRepository) GetByID(cameraID string, id int64) (*models.CameraRevision, error) {
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM camera_revisions r
+		LEFT JOIN users u ON u.id = r.user_id
+		WHERE r.camera_id = $1 AND r.id = $2
+	`
+
+	revision, err := scanRevision(r.db.QueryRow(query, cameraID, id))
+
+	if err == sql.ErrNoRows {
+		return nil, ErrRevisionNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get camera revision: %w", err)
+	}
+
+	return revision, nil
+}
+
+// GetAfter mengambil revision camera setelah revision id, urut dari yang lama
+func (r *revisionRepository) GetAfter(cameraID string, id int64) ([]*models.CameraRevision, error) {
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM camera_revisions r
+		LEFT JOIN users u ON u.id = r.user_id
+		WHERE r.camera_id = $1 AND r.id > $2
+		ORDER BY r.id ASC
+	`
+
+	rows, err := r.db.Query(query, cameraID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get camera revisions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRevisions(rows)
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// DBTX dipenuhi oleh *sql.DB dan *sql.Tx, sehingga repository yang sama bisa
+// dipakai di luar maupun di dalam transaksi (lihat WithTx di tiap repository)
+type DBTX interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Transactor menjalankan beberapa operasi repository dalam satu transaksi database
+type Transactor interface {
+	WithinTx(fn func(tx *sql.Tx) error) error
+}
+
+type transactor struct {
+	db *sql.DB
+}
+
+// NewTransactor membuat instance baru dari Transactor
+func NewTransactor(db *sql.DB) Transactor {
+	return &transactor{db: db}
+}
+
+// WithinTx menjalankan fn di dalam transaksi. Transaksi di-commit jika fn
+// berhasil dan di-rollback jika fn mengembalikan error.
+func (t *transactor) WithinTx(fn func(tx *sql.Tx) error) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// inTx menjalankan fn dalam transaksi: memakai transaksi yang sedang berjalan
+// jika repository dibuat lewat WithTx, atau membuka transaksi baru
+func inTx(db DBTX, fn func(tx DBTX) error) error {
+	sqlDB, ok := db.(*sql.DB)
+	if !ok {
+		return fn(db)
+	}
+
+	return NewTransactor(sqlDB).WithinTx(func(tx *sql.Tx) error {
+		return fn(tx)
+	})
+}
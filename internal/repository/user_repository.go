@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"cctv-monitoring-backend/internal/models"
+
+	"github.com/lib/pq"
 )
 
 // UserRepository adalah interface untuk operasi database user
@@ -13,6 +15,7 @@ type UserRepository interface {
 	GetByUsername(username string) (*models.User, error)
 	GetByID(id string) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
+	UpdatePermissions(id string, permissions []string) error
 }
 
 type userRepository struct {
@@ -51,7 +54,7 @@ func (r *userRepository) Create(user *models.User) error {
 // GetByUsername mencari user berdasarkan username
 func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, role, is_active, created_at, updated_at
+		SELECT id, username, email, password_hash, role, permissions, is_active, created_at, updated_at
 		FROM users
 		WHERE username = $1
 	`
@@ -63,6 +66,7 @@ func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 		&user.Email,
 		&user.PasswordHash,
 		&user.Role,
+		pq.Array(&user.Permissions),
 		&user.IsActive,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -82,7 +86,7 @@ func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 // GetByID mencari user berdasarkan ID
 func (r *userRepository) GetByID(id string) (*models.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, role, is_active, created_at, updated_at
+		SELECT id, username, email, password_hash, role, permissions, is_active, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
@@ -94,6 +98,7 @@ func (r *userRepository) GetByID(id string) (*models.User, error) {
 		&user.Email,
 		&user.PasswordHash,
 		&user.Role,
+		pq.Array(&user.Permissions),
 		&user.IsActive,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -113,7 +118,7 @@ func (r *userRepository) GetByID(id string) (*models.User, error) {
 // GetByEmail mencari user berdasarkan email
 func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, role, is_active, created_at, updated_at
+		SELECT id, username, email, password_hash, role, permissions, is_active, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
@@ -125,6 +130,7 @@ func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 		&user.Email,
 		&user.PasswordHash,
 		&user.Role,
+		pq.Array(&user.Permissions),
 		&user.IsActive,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -140,3 +146,26 @@ func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 
 	return user, nil
 }
+
+// UpdatePermissions mengganti permissions user
+func (r *userRepository) UpdatePermissions(id string, permissions []string) error {
+	query := `
+		UPDATE users SET permissions = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	result, err := r.db.Exec(query, pq.Array(permissions), id)
+	if err != nil {
+		return fmt.Errorf("failed to update user permissions: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update user permissions: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
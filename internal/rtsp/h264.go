@@ -0,0 +1,238 @@
+package rtsp
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var errShortSPS = errors.New("rtsp: truncated H.264 SPS")
+
+// spsInfo adalah field SPS H.264 yang dipakai untuk identifikasi stream
+type spsInfo struct {
+	profile int
+	level   int
+	width   int
+	height  int
+	fps     float64
+}
+
+// decodeParameterSet men-decode satu parameter set dari sprop-parameter-sets
+// (base64, padding opsional) dan membuang emulation prevention bytes
+func decodeParameterSet(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		raw, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 00 00 03 -> 00 00
+	nal := make([]byte, 0, len(raw))
+	zeros := 0
+	for _, b := range raw {
+		if zeros >= 2 && b == 0x03 {
+			zeros = 0
+			continue
+		}
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		nal = append(nal, b)
+	}
+	return nal, nil
+}
+
+// parseH264SPS membaca resolusi dan timing dari NAL SPS (ITU-T H.264 7.3.2.1.1)
+func parseH264SPS(nal []byte) (info spsInfo, err error) {
+	if len(nal) < 4 || nal[0]&0x1f != 7 {
+		return info, errors.New("rtsp: not an H.264 SPS")
+	}
+
+	r := &bitReader{data: nal[1:]}
+	defer func() {
+		// bitReader panic saat data habis; diubah menjadi error
+		if recover() != nil {
+			err = errShortSPS
+		}
+	}()
+
+	info.profile = int(r.bits(8))
+	r.bits(8) // constraint flags
+	info.level = int(r.bits(8))
+	r.ue() // seq_parameter_set_id
+
+	chromaFormat := 1
+	separateColourPlane := false
+	switch info.profile {
+	case 100, 110, 122, 244, 44, 83, 86, 118, 128, 138, 139, 134, 135:
+		chromaFormat = int(r.ue())
+		if chromaFormat == 3 {
+			separateColourPlane = r.flag()
+		}
+		r.ue()   // bit_depth_luma_minus8
+		r.ue()   // bit_depth_chroma_minus8
+		r.flag() // qpprime_y_zero_transform_bypass_flag
+		if r.flag() {
+			lists := 8
+			if chromaFormat == 3 {
+				lists = 12
+			}
+			for i := 0; i < lists; i++ {
+				if !r.flag() {
+					continue
+				}
+				size := 16
+				if i >= 6 {
+					size = 64
+				}
+				skipScalingList(r, size)
+			}
+		}
+	}
+
+	r.ue() // log2_max_frame_num_minus4
+	switch r.ue() {
+	case 0:
+		r.ue() // log2_max_pic_order_cnt_lsb_minus4
+	case 1:
+		r.flag() // delta_pic_order_always_zero_flag
+		r.se()   // offset_for_non_ref_pic
+		r.se()   // offset_for_top_to_bottom_field
+		for n := r.ue(); n > 0; n-- {
+			r.se()
+		}
+	}
+
+	r.ue()   // max_num_ref_frames
+	r.flag() // gaps_in_frame_num_value_allowed_flag
+
+	widthMbs := int(r.ue()) + 1
+	heightMapUnits := int(r.ue()) + 1
+	frameMbsOnly := 0
+	if r.flag() {
+		frameMbsOnly = 1
+	} else {
+		r.flag() // mb_adaptive_frame_field_flag
+	}
+	r.flag() // direct_8x8_inference_flag
+
+	info.width = widthMbs * 16
+	info.height = (2 - frameMbsOnly) * heightMapUnits * 16
+
+	if r.flag() {
+		left, right, top, bottom := int(r.ue()), int(r.ue()), int(r.ue()), int(r.ue())
+
+		cropX, cropY := 1, 2-frameMbsOnly
+		if chromaFormat != 0 && !separateColourPlane {
+			if chromaFormat == 1 || chromaFormat == 2 {
+				cropX = 2
+			}
+			if chromaFormat == 1 {
+				cropY *= 2
+			}
+		}
+		info.width -= (left + right) * cropX
+		info.height -= (top + bottom) * cropY
+	}
+
+	if r.flag() {
+		info.fps = parseVUITiming(r)
+	}
+
+	return info, nil
+}
+
+// parseVUITiming membaca VUI sampai timing_info dan mengembalikan FPS
+func parseVUITiming(r *bitReader) float64 {
+	if r.flag() { // aspect_ratio_info_present_flag
+		if r.bits(8) == 255 { // Extended_SAR
+			r.bits(16)
+			r.bits(16)
+		}
+	}
+	if r.flag() { // overscan_info_present_flag
+		r.flag()
+	}
+	if r.flag() { // video_signal_type_present_flag
+		r.bits(3)
+		r.flag()
+		if r.flag() { // colour_description_present_flag
+			r.bits(24)
+		}
+	}
+	if r.flag() { // chroma_loc_info_present_flag
+		r.ue()
+		r.ue()
+	}
+	if !r.flag() { // timing_info_present_flag
+		return 0
+	}
+
+	unitsInTick := r.bits(32)
+	timeScale := r.bits(32)
+	if unitsInTick == 0 {
+		return 0
+	}
+	return float64(timeScale) / float64(2*unitsInTick)
+}
+
+func skipScalingList(r *bitReader, size int) {
+	last, next := 8, 8
+	for j := 0; j < size; j++ {
+		if next != 0 {
+			next = (last + int(r.se()) + 256) % 256
+		}
+		if next != 0 {
+			last = next
+		}
+	}
+}
+
+// bitReader membaca bit MSB-first dan Exp-Golomb dari RBSP
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) bits(n int) uint64 {
+	var v uint64
+	for i := 0; i < n; i++ {
+		if r.pos >= len(r.data)*8 {
+			panic(errShortSPS)
+		}
+		bit := (r.data[r.pos/8] >> (7 - uint(r.pos%8))) & 1
+		v = v<<1 | uint64(bit)
+		r.pos++
+	}
+	return v
+}
+
+func (r *bitReader) flag() bool {
+	return r.bits(1) == 1
+}
+
+// ue membaca unsigned Exp-Golomb
+func (r *bitReader) ue() uint64 {
+	zeros := 0
+	for !r.flag() {
+		zeros++
+		if zeros > 31 {
+			panic(errShortSPS)
+		}
+	}
+	return (1 << uint(zeros)) - 1 + r.bits(zeros)
+}
+
+// se membaca signed Exp-Golomb
+func (r *bitReader) se() int64 {
+	v := r.ue()
+	if v%2 == 1 {
+		return int64(v+1) / 2
+	}
+	return -int64(v / 2)
+}
@@ -0,0 +1,414 @@
+// Package rtsp berisi RTSP client minimal untuk mem-probe kamera: OPTIONS dan
+// DESCRIBE (dengan basic/digest auth), lalu parsing SDP untuk codec, profile,
+// resolusi dan FPS. Tidak ada SETUP/PLAY, sehingga tidak ada media yang ditarik.
+package rtsp
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error probe yang bisa dibedakan oleh caller
+var (
+	ErrUnauthorized = errors.New("rtsp: camera rejected credentials")
+	ErrUnreachable  = errors.New("rtsp: camera unreachable")
+	ErrTimeout      = errors.New("rtsp: camera did not respond in time")
+	ErrProtocol     = errors.New("rtsp: invalid response from camera")
+	ErrInvalidURL   = errors.New("rtsp: invalid camera URL")
+)
+
+const (
+	defaultPort      = "554"
+	defaultUserAgent = "cctv-monitoring-backend/1.0"
+	maxBodySize      = 1 << 20
+)
+
+// Prober menjalankan OPTIONS dan DESCRIBE ke kamera
+type Prober struct {
+	Timeout   time.Duration
+	UserAgent string
+}
+
+// NewProber membuat Prober dengan timeout untuk seluruh probe
+func NewProber(timeout time.Duration) *Prober {
+	return &Prober{
+		Timeout:   timeout,
+		UserAgent: defaultUserAgent,
+	}
+}
+
+// Result adalah hasil probe satu RTSP URL
+type Result struct {
+	Server  string   // Header Server dari kamera
+	Methods []string // Header Public dari OPTIONS
+	SDP     string   // Body DESCRIBE apa adanya
+	Session *Session // SDP yang sudah di-parse
+}
+
+// Probe menjalankan OPTIONS lalu DESCRIBE ke rawURL. Credential diambil dari
+// userinfo URL dan hanya dikirim jika kamera meminta (basic atau digest).
+func (p *Prober) Probe(ctx context.Context, rawURL string) (*Result, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// Error url.Parse memuat URL lengkap (termasuk password), jadi tidak diteruskan
+		return nil, fmt.Errorf("%w: malformed URL", ErrInvalidURL)
+	}
+	if u.Scheme != "rtsp" {
+		return nil, fmt.Errorf("%w: unsupported scheme %q (only rtsp:// can be probed)", ErrInvalidURL, u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("%w: missing host", ErrInvalidURL)
+	}
+
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+	addr := net.JoinHostPort(u.Hostname(), port)
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, wrapNetError(ctx, err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c := &client{
+		conn:      conn,
+		reader:    bufio.NewReader(conn),
+		userAgent: p.UserAgent,
+		user:      u.User,
+	}
+
+	// URL request tanpa credential
+	target := *u
+	target.User = nil
+	requestURL := target.String()
+
+	options, err := c.do(ctx, "OPTIONS", requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	describe, err := c.do(ctx, "DESCRIBE", requestURL, map[string]string{"Accept": "application/sdp"})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Server:  firstNonEmpty(describe.header.Get("Server"), options.header.Get("Server")),
+		Methods: splitList(options.header.Get("Public")),
+		SDP:     string(describe.body),
+	}
+
+	session, err := ParseSDP(result.SDP)
+	if err != nil {
+		return nil, err
+	}
+	result.Session = session
+
+	return result, nil
+}
+
+// response adalah satu response RTSP
+type response struct {
+	statusCode int
+	status     string
+	header     textproto.MIMEHeader
+	body       []byte
+}
+
+// client adalah satu koneksi RTSP dengan CSeq dan state auth
+type client struct {
+	conn      net.Conn
+	reader    *bufio.Reader
+	userAgent string
+	user      *url.Userinfo
+	cseq      int
+
+	// Challenge terakhir dari kamera, dipakai ulang untuk request berikutnya
+	auth *challenge
+}
+
+// do mengirim request dan mengulang sekali dengan Authorization jika kamera
+// membalas 401
+func (c *client) do(ctx context.Context, method, requestURL string, header map[string]string) (*response, error) {
+	resp, err := c.roundTrip(ctx, method, requestURL, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.statusCode == 401 {
+		if c.user == nil {
+			return nil, fmt.Errorf("%w: %s requires credentials", ErrUnauthorized, method)
+		}
+
+		ch, err := parseChallenge(resp.header.Values("WWW-Authenticate"))
+		if err != nil {
+			return nil, err
+		}
+		c.auth = ch
+
+		resp, err = c.roundTrip(ctx, method, requestURL, header)
+		if err != nil {
+			return nil, err
+		}
+		if resp.statusCode == 401 {
+			return nil, fmt.Errorf("%w: %s", ErrUnauthorized, resp.status)
+		}
+	}
+
+	if resp.statusCode != 200 {
+		return nil, fmt.Errorf("%w: %s returned %s", ErrProtocol, method, resp.status)
+	}
+
+	return resp, nil
+}
+
+// roundTrip menulis satu request dan membaca response-nya
+func (c *client) roundTrip(ctx context.Context, method, requestURL string, header map[string]string) (*response, error) {
+	c.cseq++
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s RTSP/1.0\r\n", method, requestURL)
+	fmt.Fprintf(&b, "CSeq: %d\r\n", c.cseq)
+	fmt.Fprintf(&b, "User-Agent: %s\r\n", c.userAgent)
+	for key, value := range header {
+		fmt.Fprintf(&b, "%s: %s\r\n", key, value)
+	}
+	if c.auth != nil {
+		fmt.Fprintf(&b, "Authorization: %s\r\n", c.auth.authorization(c.user, method, requestURL))
+	}
+	b.WriteString("\r\n")
+
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, wrapNetError(ctx, err)
+	}
+
+	return c.readResponse(ctx)
+}
+
+// readResponse membaca status line, header dan body (Content-Length)
+func (c *client) readResponse(ctx context.Context) (*response, error) {
+	tp := textproto.NewReader(c.reader)
+
+	line, err := tp.ReadLine()
+	if err != nil {
+		return nil, wrapNetError(ctx, err)
+	}
+
+	// RTSP/1.0 200 OK
+	parts := strings.SplitN(line, " ", 3)
+	if len(parts) < 2 || !strings.HasPrefix(parts[0], "RTSP/") {
+		return nil, fmt.Errorf("%w: unexpected status line %q", ErrProtocol, line)
+	}
+	code, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: unexpected status line %q", ErrProtocol, line)
+	}
+
+	header, err := tp.ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, wrapNetError(ctx, err)
+	}
+
+	resp := &response{
+		statusCode: code,
+		status:     strings.Join(parts[1:], " "),
+		header:     header,
+	}
+
+	if length := header.Get("Content-Length"); length != "" {
+		n, err := strconv.Atoi(length)
+		if err != nil || n < 0 || n > maxBodySize {
+			return nil, fmt.Errorf("%w: invalid Content-Length %q", ErrProtocol, length)
+		}
+		resp.body = make([]byte, n)
+		if _, err := io.ReadFull(c.reader, resp.body); err != nil {
+			return nil, wrapNetError(ctx, err)
+		}
+	}
+
+	return resp, nil
+}
+
+// challenge adalah isi header WWW-Authenticate
+type challenge struct {
+	scheme string // Basic atau Digest
+	realm  string
+	nonce  string
+	opaque string
+	qop    string
+	nc     int
+}
+
+// parseChallenge memilih Digest jika ditawarkan, jika tidak Basic
+func parseChallenge(values []string) (*challenge, error) {
+	var basic *challenge
+	for _, value := range values {
+		scheme, params, _ := strings.Cut(strings.TrimSpace(value), " ")
+		fields := parseAuthParams(params)
+
+		switch strings.ToLower(scheme) {
+		case "digest":
+			return &challenge{
+				scheme: "Digest",
+				realm:  fields["realm"],
+				nonce:  fields["nonce"],
+				opaque: fields["opaque"],
+				qop:    pickQop(fields["qop"]),
+			}, nil
+		case "basic":
+			basic = &challenge{scheme: "Basic", realm: fields["realm"]}
+		}
+	}
+
+	if basic == nil {
+		return nil, fmt.Errorf("%w: unsupported authentication %q", ErrUnauthorized, strings.Join(values, ", "))
+	}
+	return basic, nil
+}
+
+// authorization membuat header Authorization untuk satu request
+func (ch *challenge) authorization(user *url.Userinfo, method, uri string) string {
+	username := user.Username()
+	password, _ := user.Password()
+
+	if ch.scheme == "Basic" {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	}
+
+	ha1 := md5Hex(username + ":" + ch.realm + ":" + password)
+	ha2 := md5Hex(method + ":" + uri)
+
+	params := []string{
+		fmt.Sprintf(`username="%s"`, username),
+		fmt.Sprintf(`realm="%s"`, ch.realm),
+		fmt.Sprintf(`nonce="%s"`, ch.nonce),
+		fmt.Sprintf(`uri="%s"`, uri),
+	}
+
+	if ch.qop != "" {
+		ch.nc++
+		nc := fmt.Sprintf("%08x", ch.nc)
+		cnonce := randomHex(8)
+		resp := md5Hex(ha1 + ":" + ch.nonce + ":" + nc + ":" + cnonce + ":" + ch.qop + ":" + ha2)
+		params = append(params,
+			fmt.Sprintf(`response="%s"`, resp),
+			"qop="+ch.qop,
+			"nc="+nc,
+			fmt.Sprintf(`cnonce="%s"`, cnonce),
+		)
+	} else {
+		params = append(params, fmt.Sprintf(`response="%s"`, md5Hex(ha1+":"+ch.nonce+":"+ha2)))
+	}
+
+	if ch.opaque != "" {
+		params = append(params, fmt.Sprintf(`opaque="%s"`, ch.opaque))
+	}
+
+	return "Digest " + strings.Join(params, ", ")
+}
+
+// parseAuthParams membaca key="value", key=value dari header auth
+func parseAuthParams(s string) map[string]string {
+	fields := map[string]string{}
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ,")
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, s = rest[1:], ""
+			} else {
+				value, s = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, s, _ = strings.Cut(rest, ",")
+		}
+
+		fields[key] = strings.TrimSpace(value)
+	}
+	return fields
+}
+
+// pickQop memilih "auth" jika ditawarkan (auth-int tidak didukung)
+func pickQop(qop string) string {
+	for _, q := range splitList(qop) {
+		if q == "auth" {
+			return q
+		}
+	}
+	return ""
+}
+
+// wrapNetError mengubah error jaringan menjadi ErrTimeout atau ErrUnreachable
+func wrapNetError(ctx context.Context, err error) error {
+	var netErr net.Error
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: connection closed by camera", ErrProtocol)
+	}
+	return fmt.Errorf("%w: %v", ErrUnreachable, err)
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,504 @@
+package rtsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bitWriter menulis bit MSB-first dan Exp-Golomb, kebalikan dari bitReader
+type bitWriter struct {
+	data []byte
+	n    int
+}
+
+func (w *bitWriter) bits(v uint64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if w.n%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+		if (v>>uint(i))&1 == 1 {
+			w.data[len(w.data)-1] |= 1 << (7 - uint(w.n%8))
+		}
+		w.n++
+	}
+}
+
+func (w *bitWriter) flag(b bool) {
+	if b {
+		w.bits(1, 1)
+	} else {
+		w.bits(0, 1)
+	}
+}
+
+func (w *bitWriter) ue(v uint64) {
+	v++
+	size := 0
+	for x := v; x > 1; x >>= 1 {
+		size++
+	}
+	w.bits(0, size)
+	w.bits(v, size+1)
+}
+
+// testSPS membuat NAL SPS High@4.0 1920x1080 (1088 dengan cropping 8 baris)
+// dan timing 25 fps, dengan emulation prevention bytes
+func testSPS() []byte {
+	w := &bitWriter{}
+	w.bits(0x67, 8) // nal_unit_type 7
+	w.bits(100, 8)  // profile_idc High
+	w.bits(0, 8)    // constraint flags
+	w.bits(40, 8)   // level_idc 4.0
+	w.ue(0)         // seq_parameter_set_id
+	w.ue(1)         // chroma_format_idc 4:2:0
+	w.ue(0)         // bit_depth_luma_minus8
+	w.ue(0)         // bit_depth_chroma_minus8
+	w.flag(false)   // qpprime_y_zero_transform_bypass_flag
+	w.flag(false)   // seq_scaling_matrix_present_flag
+	w.ue(0)         // log2_max_frame_num_minus4
+	w.ue(0)         // pic_order_cnt_type
+	w.ue(2)         // log2_max_pic_order_cnt_lsb_minus4
+	w.ue(1)         // max_num_ref_frames
+	w.flag(false)   // gaps_in_frame_num_value_allowed_flag
+	w.ue(119)       // pic_width_in_mbs_minus1 -> 1920
+	w.ue(67)        // pic_height_in_map_units_minus1 -> 1088
+	w.flag(true)    // frame_mbs_only_flag
+	w.flag(true)    // direct_8x8_inference_flag
+	w.flag(true)    // frame_cropping_flag
+	w.ue(0)         // left
+	w.ue(0)         // right
+	w.ue(0)         // top
+	w.ue(4)         // bottom -> 1088 - 8
+	w.flag(true)    // vui_parameters_present_flag
+	w.flag(false)   // aspect_ratio_info_present_flag
+	w.flag(false)   // overscan_info_present_flag
+	w.flag(false)   // video_signal_type_present_flag
+	w.flag(false)   // chroma_loc_info_present_flag
+	w.flag(true)    // timing_info_present_flag
+	w.bits(1, 32)   // num_units_in_tick
+	w.bits(50, 32)  // time_scale -> 25 fps
+
+	// 00 00 0x (x <= 3) -> 00 00 03 0x
+	nal := make([]byte, 0, len(w.data)+4)
+	zeros := 0
+	for _, b := range w.data {
+		if zeros >= 2 && b <= 3 {
+			nal = append(nal, 3)
+			zeros = 0
+		}
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		nal = append(nal, b)
+	}
+	return nal
+}
+
+// testSDP adalah body DESCRIBE dengan track H.264 (SPS dari testSPS) dan AAC
+func testSDP() string {
+	sps := base64.StdEncoding.EncodeToString(testSPS())
+	return strings.Join([]string{
+		"v=0",
+		"o=- 0 0 IN IP4 127.0.0.1",
+		"s=Fake Camera",
+		"a=tool:fake-rtsp",
+		"t=0 0",
+		"m=video 0 RTP/AVP 96",
+		"a=rtpmap:96 H264/90000",
+		"a=fmtp:96 packetization-mode=1;profile-level-id=42e01f;sprop-parameter-sets=" + sps + ",aM48gA==",
+		"a=control:trackID=1",
+		"m=audio 0 RTP/AVP 97",
+		"a=rtpmap:97 MPEG4-GENERIC/16000/1",
+		"a=control:trackID=2",
+		"",
+	}, "\r\n")
+}
+
+// fakeCamera adalah RTSP server minimal: OPTIONS dijawab tanpa auth, DESCRIBE
+// meminta Digest auth (qop=auth) lalu mengirim SDP jika response digest benar
+type fakeCamera struct {
+	listener net.Listener
+	username string
+	password string
+	silent   bool // tidak pernah membalas, untuk menguji timeout
+
+	// Error dari goroutine server, dibaca setelah probe selesai
+	errs chan error
+}
+
+const (
+	fakeRealm = "Fake Camera"
+	fakeNonce = "dcd98b7102dd2f0e8b11d0f600bfb0c0"
+)
+
+func startFakeCamera(t *testing.T, username, password string, silent bool) *fakeCamera {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	cam := &fakeCamera{listener: listener, username: username, password: password, silent: silent, errs: make(chan error, 8)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go cam.serve(conn)
+		}
+	}()
+
+	return cam
+}
+
+// url mengembalikan URL kamera, dengan userinfo jika user diisi
+func (f *fakeCamera) url(user *string) string {
+	userinfo := ""
+	if user != nil {
+		userinfo = *user + "@"
+	}
+	return fmt.Sprintf("rtsp://%s%s/stream1", userinfo, f.listener.Addr())
+}
+
+func (f *fakeCamera) serve(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewReader(bufio.NewReader(conn))
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		header, err := tp.ReadMIMEHeader()
+		if err != nil {
+			return
+		}
+		if f.silent {
+			continue
+		}
+
+		method, uri, _ := strings.Cut(line, " ")
+		uri, _, _ = strings.Cut(uri, " ")
+		cseq := header.Get("CSeq")
+
+		switch method {
+		case "OPTIONS":
+			fmt.Fprintf(conn, "RTSP/1.0 200 OK\r\nCSeq: %s\r\nServer: FakeCam/1.0\r\nPublic: OPTIONS, DESCRIBE, SETUP, PLAY, TEARDOWN\r\n\r\n", cseq)
+		case "DESCRIBE":
+			if err := f.checkDigest(header.Get("Authorization"), method, uri); err != nil {
+				if header.Get("Authorization") != "" {
+					f.errs <- err
+				}
+				fmt.Fprintf(conn, "RTSP/1.0 401 Unauthorized\r\nCSeq: %s\r\nWWW-Authenticate: Basic realm=\"%s\"\r\nWWW-Authenticate: Digest realm=\"%s\", nonce=\"%s\", qop=\"auth,auth-int\", opaque=\"5ccc069c\"\r\n\r\n",
+					cseq, fakeRealm, fakeRealm, fakeNonce)
+				continue
+			}
+			sdp := testSDP()
+			fmt.Fprintf(conn, "RTSP/1.0 200 OK\r\nCSeq: %s\r\nContent-Type: application/sdp\r\nContent-Length: %d\r\n\r\n%s", cseq, len(sdp), sdp)
+		default:
+			fmt.Fprintf(conn, "RTSP/1.0 405 Method Not Allowed\r\nCSeq: %s\r\n\r\n", cseq)
+		}
+	}
+}
+
+// checkDigest memverifikasi header Authorization Digest (RFC 2617, qop=auth)
+func (f *fakeCamera) checkDigest(authorization, method, uri string) error {
+	scheme, params, _ := strings.Cut(authorization, " ")
+	if scheme != "Digest" {
+		return fmt.Errorf("expected Digest authorization, got %q", authorization)
+	}
+
+	fields := parseAuthParams(params)
+	if fields["realm"] != fakeRealm || fields["nonce"] != fakeNonce || fields["opaque"] != "5ccc069c" {
+		return fmt.Errorf("challenge not echoed: %v", fields)
+	}
+	if fields["uri"] != uri {
+		return fmt.Errorf("digest uri %q does not match request uri %q", fields["uri"], uri)
+	}
+	if fields["qop"] != "auth" || fields["nc"] == "" || fields["cnonce"] == "" {
+		return fmt.Errorf("missing qop parameters: %v", fields)
+	}
+
+	ha1 := md5Hex(fields["username"] + ":" + fakeRealm + ":" + f.password)
+	if fields["username"] != f.username {
+		ha1 = ""
+	}
+	ha2 := md5Hex(method + ":" + uri)
+	expected := md5Hex(ha1 + ":" + fakeNonce + ":" + fields["nc"] + ":" + fields["cnonce"] + ":auth:" + ha2)
+	if fields["response"] != expected {
+		return errors.New("digest response mismatch")
+	}
+	return nil
+}
+
+func TestProbeDigestAuth(t *testing.T) {
+	cam := startFakeCamera(t, "admin", "s3cret", false)
+	user := "admin:s3cret"
+
+	result, err := NewProber(2*time.Second).Probe(context.Background(), cam.url(&user))
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+
+	if result.Server != "FakeCam/1.0" {
+		t.Errorf("Server = %q, want FakeCam/1.0", result.Server)
+	}
+	if got := strings.Join(result.Methods, ","); got != "OPTIONS,DESCRIBE,SETUP,PLAY,TEARDOWN" {
+		t.Errorf("Methods = %q", got)
+	}
+	if result.Session.Name != "Fake Camera" || result.Session.Tool != "fake-rtsp" {
+		t.Errorf("Session name/tool = %q/%q", result.Session.Name, result.Session.Tool)
+	}
+
+	video := result.Session.Video()
+	if video == nil {
+		t.Fatal("no video track")
+	}
+	if video.Codec != "H264" || video.ClockRate != 90000 || video.Control != "trackID=1" {
+		t.Errorf("video = %s/%d control %q", video.Codec, video.ClockRate, video.Control)
+	}
+	// SPS menimpa profile-level-id (Baseline@3.1)
+	if video.Profile != "High@4" {
+		t.Errorf("Profile = %q, want High@4", video.Profile)
+	}
+	if video.Width != 1920 || video.Height != 1080 {
+		t.Errorf("resolution = %dx%d, want 1920x1080", video.Width, video.Height)
+	}
+	if video.FPS != 25 {
+		t.Errorf("FPS = %v, want 25", video.FPS)
+	}
+
+	audio := result.Session.Audio()
+	if audio == nil || audio.Codec != "AAC" || audio.ClockRate != 16000 || audio.Channels != 1 {
+		t.Errorf("audio = %+v", audio)
+	}
+
+	select {
+	case err := <-cam.errs:
+		t.Errorf("server rejected authorization: %v", err)
+	default:
+	}
+}
+
+func TestProbeUnauthorized(t *testing.T) {
+	cam := startFakeCamera(t, "admin", "s3cret", false)
+	wrong := "admin:wrong"
+
+	tests := []struct {
+		name string
+		user *string
+	}{
+		{"wrong password", &wrong},
+		{"no credentials", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewProber(2*time.Second).Probe(context.Background(), cam.url(tt.user))
+			if !errors.Is(err, ErrUnauthorized) {
+				t.Fatalf("err = %v, want ErrUnauthorized", err)
+			}
+			if strings.Contains(err.Error(), "wrong") {
+				t.Errorf("error leaks password: %v", err)
+			}
+		})
+	}
+}
+
+func TestProbeTimeout(t *testing.T) {
+	cam := startFakeCamera(t, "admin", "s3cret", true)
+
+	start := time.Now()
+	_, err := NewProber(200*time.Millisecond).Probe(context.Background(), cam.url(nil))
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("probe took %v, timeout not enforced", elapsed)
+	}
+}
+
+func TestProbeInvalidURL(t *testing.T) {
+	for _, rawURL := range []string{"http://camera/stream", "rtsp:///stream", "rtsp://a b:%zz@host"} {
+		_, err := NewProber(time.Second).Probe(context.Background(), rawURL)
+		if !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("Probe(%q) err = %v, want ErrInvalidURL", rawURL, err)
+		}
+		if err != nil && strings.Contains(err.Error(), "%zz") {
+			t.Errorf("Probe(%q) error leaks URL: %v", rawURL, err)
+		}
+	}
+}
+
+func TestParseSDP(t *testing.T) {
+	tests := []struct {
+		name    string
+		sdp     string
+		media   string
+		want    Track
+		wantErr error
+	}{
+		{
+			name:  "h264 profile-level-id without sprop",
+			sdp:   "v=0\r\ns=Cam\r\nm=video 0 RTP/AVP 96\r\na=rtpmap:96 H264/90000\r\na=fmtp:96 profile-level-id=42e01f\r\n",
+			media: "video",
+			want:  Track{Codec: "H264", ClockRate: 90000, Profile: "Baseline@3.1"},
+		},
+		{
+			name:  "fmtp before rtpmap with framesize and framerate",
+			sdp:   "v=0\nm=video 0 RTP/AVP 96\na=fmtp:96 profile-level-id=4d0028\na=rtpmap:96 H264/90000\na=framesize:96 1280-720\na=framerate:12.5\n",
+			media: "video",
+			want:  Track{Codec: "H264", ClockRate: 90000, Profile: "Main@4", Width: 1280, Height: 720, FPS: 12.5},
+		},
+		{
+			name:  "h265 profile and level",
+			sdp:   "v=0\r\nm=video 0 RTP/AVP 98\r\na=rtpmap:98 H265/90000\r\na=fmtp:98 profile-id=1;level-id=93\r\na=x-dimensions:2560,1440\r\n",
+			media: "video",
+			want:  Track{Codec: "H265", ClockRate: 90000, Profile: "Main@3.1", Width: 2560, Height: 1440},
+		},
+		{
+			name:  "hevc alias",
+			sdp:   "v=0\r\nm=video 0 RTP/AVP 98\r\na=rtpmap:98 HEVC/90000\r\n",
+			media: "video",
+			want:  Track{Codec: "H265", ClockRate: 90000},
+		},
+		{
+			name:  "static mjpeg payload with cliprect",
+			sdp:   "v=0\r\nm=video 0 RTP/AVP 26\r\na=cliprect:0,0,480,640\r\n",
+			media: "video",
+			want:  Track{Codec: "MJPEG", ClockRate: 90000, Width: 640, Height: 480},
+		},
+		{
+			name:  "static pcma audio",
+			sdp:   "v=0\r\nm=audio 0 RTP/AVP 8\r\n",
+			media: "audio",
+			want:  Track{Codec: "PCMA", ClockRate: 8000},
+		},
+		{
+			name:  "aac with channels",
+			sdp:   "v=0\r\nm=audio 0 RTP/AVP 97\r\na=rtpmap:97 mpeg4-generic/48000/2\r\n",
+			media: "audio",
+			want:  Track{Codec: "AAC", ClockRate: 48000, Channels: 2},
+		},
+		{
+			name:  "truncated sprop keeps profile-level-id",
+			sdp:   "v=0\r\nm=video 0 RTP/AVP 96\r\na=rtpmap:96 H264/90000\r\na=fmtp:96 profile-level-id=640028;sprop-parameter-sets=Z2QAKA==\r\n",
+			media: "video",
+			want:  Track{Codec: "H264", ClockRate: 90000, Profile: "High@4"},
+		},
+		{
+			name:  "invalid base64 sprop is ignored",
+			sdp:   "v=0\r\nm=video 0 RTP/AVP 96\r\na=rtpmap:96 H264/90000\r\na=fmtp:96 sprop-parameter-sets=!!!\r\n",
+			media: "video",
+			want:  Track{Codec: "H264", ClockRate: 90000},
+		},
+		{
+			name:    "no media tracks",
+			sdp:     "v=0\r\ns=Empty\r\na=tool:x\r\n",
+			wantErr: ErrProtocol,
+		},
+		{
+			name:    "garbage",
+			sdp:     "not an sdp",
+			wantErr: ErrProtocol,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := ParseSDP(tt.sdp)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSDP: %v", err)
+			}
+
+			track := session.firstTrack(tt.media)
+			if track == nil {
+				t.Fatalf("no %s track", tt.media)
+			}
+			got := *track
+			got.Media, got.Control, got.Payload, got.Parameters = "", "", 0, nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("track = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseH264SPS(t *testing.T) {
+	nal, err := decodeParameterSet(base64.StdEncoding.EncodeToString(testSPS()))
+	if err != nil {
+		t.Fatalf("decodeParameterSet: %v", err)
+	}
+
+	info, err := parseH264SPS(nal)
+	if err != nil {
+		t.Fatalf("parseH264SPS: %v", err)
+	}
+	want := spsInfo{profile: 100, level: 40, width: 1920, height: 1080, fps: 25}
+	if info != want {
+		t.Errorf("info = %+v, want %+v", info, want)
+	}
+
+	// Padding base64 opsional
+	unpadded := strings.TrimRight(base64.StdEncoding.EncodeToString(testSPS()), "=")
+	if raw, err := decodeParameterSet(unpadded); err != nil || string(raw) != string(nal) {
+		t.Errorf("unpadded sprop decoded to %x, %v", raw, err)
+	}
+}
+
+func TestParseH264SPSTruncated(t *testing.T) {
+	nal, err := decodeParameterSet(base64.StdEncoding.EncodeToString(testSPS()))
+	if err != nil {
+		t.Fatalf("decodeParameterSet: %v", err)
+	}
+
+	// Setiap potongan SPS harus menjadi error, bukan panic dari bitReader
+	for n := 4; n < len(nal); n++ {
+		if _, err := parseH264SPS(nal[:n]); !errors.Is(err, errShortSPS) {
+			t.Errorf("parseH264SPS(%d of %d bytes) err = %v, want errShortSPS", n, len(nal), err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		nal  []byte
+		want error
+	}{
+		{"empty", nil, nil},
+		{"shorter than header", []byte{0x67, 100}, nil},
+		{"not an SPS", []byte{0x68, 0xce, 0x3c, 0x80}, nil},
+		{"exp-golomb without terminator", []byte{0x67, 100, 0, 40, 0, 0, 0, 0, 0, 0}, errShortSPS},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseH264SPS(tt.nal)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
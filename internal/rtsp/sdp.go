@@ -0,0 +1,295 @@
+package rtsp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Session adalah ringkasan SDP dari DESCRIBE
+type Session struct {
+	Name   string  // s=
+	Tool   string  // a=tool:
+	Tracks []Track // Satu per m=
+}
+
+// Track adalah satu media (m=) di SDP
+type Track struct {
+	Media      string  // video, audio, application
+	Control    string  // a=control
+	Payload    int     // Payload type RTP
+	Codec      string  // H264, H265, MJPEG, AAC, PCMA, ...
+	ClockRate  int     // Dari rtpmap
+	Channels   int     // Audio channel dari rtpmap
+	Profile    string  // Profile video, misal "High@4.0"
+	Width      int     // 0 jika tidak diketahui
+	Height     int     // 0 jika tidak diketahui
+	FPS        float64 // 0 jika tidak diketahui
+	Parameters map[string]string
+}
+
+// Video mengembalikan track video pertama
+func (s *Session) Video() *Track {
+	return s.firstTrack("video")
+}
+
+// Audio mengembalikan track audio pertama
+func (s *Session) Audio() *Track {
+	return s.firstTrack("audio")
+}
+
+func (s *Session) firstTrack(media string) *Track {
+	for i := range s.Tracks {
+		if s.Tracks[i].Media == media {
+			return &s.Tracks[i]
+		}
+	}
+	return nil
+}
+
+// Payload type statis (RFC 3551) yang sering dipakai kamera tanpa rtpmap
+var staticPayloads = map[int]struct {
+	codec     string
+	clockRate int
+}{
+	0:  {"PCMU", 8000},
+	8:  {"PCMA", 8000},
+	14: {"MPA", 90000},
+	26: {"MJPEG", 90000},
+	32: {"MPV", 90000},
+}
+
+// Nama encoding rtpmap yang dinormalisasi
+var codecNames = map[string]string{
+	"H264":          "H264",
+	"H265":          "H265",
+	"HEVC":          "H265",
+	"JPEG":          "MJPEG",
+	"MP4V-ES":       "MPEG4",
+	"MPEG4-GENERIC": "AAC",
+	"MP4A-LATM":     "AAC",
+	"PCMA":          "PCMA",
+	"PCMU":          "PCMU",
+	"G726-32":       "G726",
+	"L16":           "L16",
+	"OPUS":          "OPUS",
+}
+
+// ParseSDP mem-parse body DESCRIBE (RFC 4566). Hanya atribut yang relevan untuk
+// identifikasi stream yang dibaca; atribut lain diabaikan.
+func ParseSDP(sdp string) (*Session, error) {
+	session := &Session{}
+	var track *Track
+	// rtpmap dan fmtp bisa muncul sebelum atau sesudah payload dipilih,
+	// jadi disimpan per payload lalu diterapkan setelah m= selesai
+	var rtpmaps, fmtps map[int]string
+
+	finish := func() {
+		if track == nil {
+			return
+		}
+		applyTrackAttributes(track, rtpmaps[track.Payload], fmtps[track.Payload])
+		session.Tracks = append(session.Tracks, *track)
+	}
+
+	for _, line := range strings.Split(sdp, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if len(line) < 2 || line[1] != '=' {
+			continue
+		}
+		value := line[2:]
+
+		switch line[0] {
+		case 's':
+			session.Name = strings.TrimSpace(value)
+		case 'm':
+			finish()
+			track, rtpmaps, fmtps = parseMediaLine(value), map[int]string{}, map[int]string{}
+		case 'a':
+			name, attr, _ := strings.Cut(value, ":")
+			if track == nil {
+				if name == "tool" {
+					session.Tool = strings.TrimSpace(attr)
+				}
+				continue
+			}
+			parseMediaAttribute(track, name, attr, rtpmaps, fmtps)
+		}
+	}
+	finish()
+
+	if len(session.Tracks) == 0 {
+		return nil, fmt.Errorf("%w: SDP has no media tracks", ErrProtocol)
+	}
+
+	return session, nil
+}
+
+// parseMediaLine membaca "video 0 RTP/AVP 96"
+func parseMediaLine(value string) *Track {
+	fields := strings.Fields(value)
+	track := &Track{Payload: -1, Parameters: map[string]string{}}
+	if len(fields) > 0 {
+		track.Media = fields[0]
+	}
+	if len(fields) > 3 {
+		// Kamera hampir selalu menawarkan satu payload per track
+		if pt, err := strconv.Atoi(fields[3]); err == nil {
+			track.Payload = pt
+		}
+	}
+	return track
+}
+
+// parseMediaAttribute membaca atribut a= di dalam satu m=
+func parseMediaAttribute(track *Track, name, attr string, rtpmaps, fmtps map[int]string) {
+	switch name {
+	case "control":
+		track.Control = strings.TrimSpace(attr)
+	case "rtpmap", "fmtp":
+		pt, rest, _ := strings.Cut(attr, " ")
+		n, err := strconv.Atoi(pt)
+		if err != nil {
+			return
+		}
+		if name == "rtpmap" {
+			rtpmaps[n] = strings.TrimSpace(rest)
+		} else {
+			fmtps[n] = strings.TrimSpace(rest)
+		}
+	case "framerate", "x-framerate":
+		if fps, err := strconv.ParseFloat(strings.TrimSpace(attr), 64); err == nil {
+			track.FPS = fps
+		}
+	case "framesize":
+		// a=framesize:96 1920-1080
+		_, size, _ := strings.Cut(attr, " ")
+		w, h, _ := strings.Cut(strings.TrimSpace(size), "-")
+		setDimensions(track, w, h)
+	case "x-dimensions":
+		// a=x-dimensions:1920,1080
+		w, h, _ := strings.Cut(attr, ",")
+		setDimensions(track, w, h)
+	case "cliprect":
+		// a=cliprect:0,0,1080,1920 (top,left,bottom,right)
+		parts := strings.Split(attr, ",")
+		if len(parts) == 4 {
+			setDimensions(track, parts[3], parts[2])
+		}
+	}
+}
+
+// applyTrackAttributes menerapkan rtpmap dan fmtp milik payload track
+func applyTrackAttributes(track *Track, rtpmap, fmtp string) {
+	if rtpmap != "" {
+		// H264/90000 atau MPEG4-GENERIC/16000/1
+		parts := strings.Split(rtpmap, "/")
+		track.Codec = strings.ToUpper(parts[0])
+		if name, ok := codecNames[track.Codec]; ok {
+			track.Codec = name
+		}
+		if len(parts) > 1 {
+			track.ClockRate, _ = strconv.Atoi(parts[1])
+		}
+		if len(parts) > 2 {
+			track.Channels, _ = strconv.Atoi(parts[2])
+		}
+	} else if static, ok := staticPayloads[track.Payload]; ok {
+		track.Codec = static.codec
+		track.ClockRate = static.clockRate
+	}
+
+	for _, param := range strings.Split(fmtp, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if ok {
+			track.Parameters[strings.ToLower(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	switch track.Codec {
+	case "H264":
+		applyH264Parameters(track)
+	case "H265":
+		applyH265Parameters(track)
+	}
+}
+
+// applyH264Parameters membaca profile-level-id dan SPS dari sprop-parameter-sets
+func applyH264Parameters(track *Track) {
+	if id := track.Parameters["profile-level-id"]; len(id) == 6 {
+		profile, err1 := strconv.ParseUint(id[0:2], 16, 8)
+		level, err2 := strconv.ParseUint(id[4:6], 16, 8)
+		if err1 == nil && err2 == nil {
+			track.Profile = h264ProfileName(int(profile), int(level))
+		}
+	}
+
+	sets := track.Parameters["sprop-parameter-sets"]
+	if sets == "" {
+		return
+	}
+	sps, err := decodeParameterSet(strings.Split(sets, ",")[0])
+	if err != nil {
+		return
+	}
+	info, err := parseH264SPS(sps)
+	if err != nil {
+		return
+	}
+
+	// SPS adalah sumber paling akurat, menimpa hint dari atribut lain
+	track.Profile = h264ProfileName(info.profile, info.level)
+	if info.width > 0 && info.height > 0 {
+		track.Width, track.Height = info.width, info.height
+	}
+	if info.fps > 0 && track.FPS == 0 {
+		track.FPS = info.fps
+	}
+}
+
+// applyH265Parameters membaca profile-id (RFC 7798)
+func applyH265Parameters(track *Track) {
+	profile := map[string]string{
+		"1": "Main",
+		"2": "Main 10",
+		"3": "Main Still Picture",
+		"4": "Range Extensions",
+	}[track.Parameters["profile-id"]]
+	if profile == "" {
+		return
+	}
+
+	// level-id = 30 x level (RFC 7798 7.1)
+	if level, err := strconv.Atoi(track.Parameters["level-id"]); err == nil && level > 0 {
+		profile = fmt.Sprintf("%s@%s", profile, strconv.FormatFloat(float64(level)/30, 'f', -1, 64))
+	}
+	track.Profile = profile
+}
+
+// h264ProfileName mengubah profile_idc dan level_idc menjadi "High@4.1"
+func h264ProfileName(profile, level int) string {
+	name, ok := map[int]string{
+		66:  "Baseline",
+		77:  "Main",
+		88:  "Extended",
+		100: "High",
+		110: "High 10",
+		122: "High 4:2:2",
+		244: "High 4:4:4",
+	}[profile]
+	if !ok {
+		name = fmt.Sprintf("Profile %d", profile)
+	}
+	if level == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s@%s", name, strconv.FormatFloat(float64(level)/10, 'f', -1, 64))
+}
+
+func setDimensions(track *Track, w, h string) {
+	width, err1 := strconv.Atoi(strings.TrimSpace(w))
+	height, err2 := strconv.Atoi(strings.TrimSpace(h))
+	if err1 == nil && err2 == nil && width > 0 && height > 0 {
+		track.Width, track.Height = width, height
+	}
+}
@@ -63,6 +63,7 @@ func (s *authService) Login(username, password, jwtSecret, jwtExpiration string)
 		user.ID,
 		user.Username,
 		user.Role,
+		user.Permissions,
 		jwtSecret,
 		utils.ParseDuration(jwtExpiration),
 	)
@@ -103,6 +104,7 @@ func (s *authService) Register(req *models.CreateUserRequest) (*models.User, err
 		Email:        req.Email,
 		PasswordHash: hashedPassword,
 		Role:         req.Role,
+		Permissions:  []string{},
 		IsActive:     true,
 	}
 
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/utils"
+)
+
+// Error validasi bulk operation
+var (
+	ErrInvalidBulkRequest = errors.New("invalid bulk request")
+	ErrBulkTooManyCameras = fmt.Errorf("bulk operation is limited to %d cameras", models.MaxBulkCameras)
+)
+
+// bulkDeliveryConcurrency adalah jumlah operasi stream bulk yang dikirim ke
+// RTSPtoWeb secara paralel
+const bulkDeliveryConcurrency = 8
+
+// Bulk menjalankan satu aksi ke banyak camera. Semua perubahan database dan
+// operasi stream di outbox disimpan dalam satu transaksi, lalu operasi stream
+// dikirim paralel ke RTSPtoWeb. ID yang tidak ditemukan dilaporkan per camera
+// tanpa membatalkan camera lain.
+func (s *cameraService) Bulk(ctx context.Context, req *models.BulkCameraRequest, userID string) (*models.BulkCameraReport, error) {
+	if err := normalizeBulkRequest(req); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBulkRequest, err)
+	}
+
+	// Building dan zone camera mengikuti nama zone tujuan
+	if req.Action == models.BulkActionMove && req.ZoneID != "" {
+		location := &models.Camera{}
+		if err := s.resolveLocation(location, req.ZoneID); err != nil {
+			if errors.Is(err, ErrZoneNotFound) {
+				return nil, fmt.Errorf("%w: zone_id: %v", ErrInvalidBulkRequest, err)
+			}
+			return nil, err
+		}
+		req.Building, req.Zone = location.Building.String, location.Zone.String
+	}
+
+	cameras, results, err := s.bulkTargets(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Operasi outbox per camera, kosong jika stream tidak berubah
+	operations := make([]string, len(cameras))
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		cameraRepo := s.cameraRepo.WithTx(tx)
+		activityRepo := s.activityRepo.WithTx(tx)
+
+		for i, camera := range cameras {
+			previous := *camera
+			before := models.NewCameraSnapshot(camera)
+			changed, operation, err := applyBulkAction(req, camera)
+			if err != nil {
+				return fmt.Errorf("camera %s: %w", camera.ID, err)
+			}
+			results[i].Changed = changed
+			if !changed {
+				continue
+			}
+
+			// Move dengan teks dicocokkan ulang ke zone berdasarkan nama
+			if req.Action == models.BulkActionMove && req.ZoneID == "" {
+				if err := s.resolveLocation(camera, ""); err != nil {
+					return err
+				}
+			}
+
+			if req.Action == models.BulkActionDelete {
+				if err := cameraRepo.Delete(camera.ID, userID); err != nil {
+					return fmt.Errorf("failed to delete camera: %w", err)
+				}
+				activity := models.NewCameraActivity(models.ActivityCameraDeleted, camera, userID, map[string]interface{}{"source": "bulk"})
+				if err := activityRepo.Create(activity); err != nil {
+					return err
+				}
+				// Delete menaikkan version camera
+				camera.IsActive = false
+				camera.Version++
+			} else if err := cameraRepo.Update(camera.ID, camera); err != nil {
+				return fmt.Errorf("failed to update camera: %w", err)
+			}
+
+			if err := recordRevision(tx, s.revisionRepo, camera, before, userID, models.RevisionActionBulk+":"+req.Action); err != nil {
+				return err
+			}
+
+			if operation != "" {
+				// REMOVE dicatat dengan stream_id dan node sebelum camera diubah
+				target := camera
+				if operation == models.OutboxOpRemove {
+					target = &previous
+				}
+				if err := s.outbox.Enqueue(tx, target, operation); err != nil {
+					return err
+				}
+				operations[i] = operation
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.deliverBulk(ctx, cameras, operations, results)
+
+	report := &models.BulkCameraReport{Action: req.Action, Results: results}
+	for _, result := range results {
+		report.Total++
+		if result.Success {
+			report.Succeeded++
+		} else {
+			report.Failed++
+		}
+		if result.Changed {
+			report.Changed++
+		}
+		if result.StreamSync != nil && result.StreamSync.Queued {
+			report.StreamsQueued++
+		}
+	}
+
+	return report, nil
+}
+
+// normalizeBulkRequest memvalidasi aksi, target dan parameter bulk operation.
+// ID duplikat dan tag kosong dibuang, status diubah ke huruf besar.
+func normalizeBulkRequest(req *models.BulkCameraRequest) error {
+	if !utils.ContainsString(models.BulkCameraActions, req.Action) {
+		return fmt.Errorf("action must be one of %s", strings.Join(models.BulkCameraActions, ", "))
+	}
+
+	switch {
+	case len(req.IDs) > 0 && req.Filter != nil:
+		return errors.New("ids and filter cannot be combined")
+	case req.Filter != nil:
+		if req.Filter.IsEmpty() {
+			return errors.New("filter must contain at least one condition")
+		}
+		if req.Filter.TagMatch != "" && req.Filter.TagMatch != models.TagMatchAny && req.Filter.TagMatch != models.TagMatchAll {
+			return errors.New("filter.tag_match must be any or all")
+		}
+		if req.Filter.CreatedBy != "" && !utils.IsUUID(req.Filter.CreatedBy) {
+			return errors.New("filter.created_by must be a user ID")
+		}
+		for i := range req.Filter.Statuses {
+			req.Filter.Statuses[i] = strings.ToUpper(req.Filter.Statuses[i])
+		}
+	case len(req.IDs) > 0:
+		ids := make([]string, 0, len(req.IDs))
+		for _, id := range req.IDs {
+			if !utils.IsUUID(id) {
+				return fmt.Errorf("invalid camera ID %q", id)
+			}
+			if !utils.ContainsString(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > models.MaxBulkCameras {
+			return ErrBulkTooManyCameras
+		}
+		req.IDs = ids
+	default:
+		return errors.New("ids or filter is required")
+	}
+
+	switch req.Action {
+	case models.BulkActionAddTags, models.BulkActionRemoveTags:
+		var tags []string
+		for _, tag := range req.Tags {
+			if tag = strings.TrimSpace(tag); tag != "" && !utils.ContainsString(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) == 0 {
+			return fmt.Errorf("tags is required for %s", req.Action)
+		}
+		req.Tags = tags
+	case models.BulkActionMove:
+		req.Zone = strings.TrimSpace(req.Zone)
+		req.Building = strings.TrimSpace(req.Building)
+		switch {
+		case req.ZoneID != "" && (req.Zone != "" || req.Building != ""):
+			return errors.New("zone_id cannot be combined with zone or building")
+		case req.ZoneID != "" && !utils.IsUUID(req.ZoneID):
+			return errors.New("zone_id must be a zone ID")
+		case req.ZoneID == "" && req.Zone == "" && req.Building == "":
+			return errors.New("zone_id, zone or building is required for move")
+		}
+	case models.BulkActionSetStatus:
+		req.Status = strings.ToUpper(req.Status)
+		if !utils.ContainsString(models.CameraStatuses, req.Status) {
+			return fmt.Errorf("status must be one of %s", strings.Join(models.CameraStatuses, ", "))
+		}
+	}
+
+	return nil
+}
+
+// bulkTargets mengambil camera target bulk operation. results berisi satu
+// entry per camera yang ditemukan (urutan sama dengan cameras) diikuti ID yang
+// tidak ditemukan.
+func (s *cameraService) bulkTargets(req *models.BulkCameraRequest) ([]*models.Camera, []models.BulkCameraResult, error) {
+	var cameras []*models.Camera
+	var err error
+
+	if req.Filter != nil {
+		// Satu baris ekstra untuk mendeteksi filter yang melebihi batas
+		cameras, err = s.cameraRepo.GetAllByFilter(req.Filter.CameraFilter(), models.MaxBulkCameras+1)
+	} else {
+		cameras, err = s.cameraRepo.GetByIDs(req.IDs)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(cameras) > models.MaxBulkCameras {
+		return nil, nil, ErrBulkTooManyCameras
+	}
+
+	results := make([]models.BulkCameraResult, 0, len(cameras))
+	found := make(map[string]bool, len(cameras))
+	for _, camera := range cameras {
+		found[camera.ID] = true
+		results = append(results, models.BulkCameraResult{ID: camera.ID, Name: camera.Name, Success: true})
+	}
+	for _, id := range req.IDs {
+		if !found[id] {
+			results = append(results, models.BulkCameraResult{ID: id, Error: ErrCameraNotFound.Error()})
+		}
+	}
+
+	return cameras, results, nil
+}
+
+// applyBulkAction mengubah camera sesuai aksi bulk. changed false jika camera
+// sudah dalam kondisi yang diminta; operation adalah operasi outbox stream.
+func applyBulkAction(req *models.BulkCameraRequest, camera *models.Camera) (changed bool, operation string, err error) {
+	hasStream := camera.StreamID.Valid && camera.StreamID.String != ""
+
+	switch req.Action {
+	case models.BulkActionStartStream:
+		if hasStream {
+			return false, "", nil
+		}
+		camera.StreamID = sql.NullString{String: camera.ID, Valid: true}
+		camera.MediaServerID = sql.NullString{}
+		// Status ditentukan stream monitor setelah RTSPtoWeb menghubungi kamera
+		camera.Status = models.CameraStatusUnknown
+		return true, models.OutboxOpAdd, nil
+
+	case models.BulkActionStopStream:
+		if !camera.StreamID.Valid {
+			return false, "", nil
+		}
+		camera.StreamID = sql.NullString{}
+		camera.MediaServerID = sql.NullString{}
+		camera.Status = models.CameraStatusOffline
+		return true, models.OutboxOpRemove, nil
+
+	case models.BulkActionAddTags:
+		tags := append([]string{}, camera.Tags...)
+		for _, tag := range req.Tags {
+			if !utils.ContainsString(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		changed = len(tags) != len(camera.Tags)
+		camera.Tags = tags
+		return changed, "", nil
+
+	case models.BulkActionRemoveTags:
+		tags := make([]string, 0, len(camera.Tags))
+		for _, tag := range camera.Tags {
+			if !utils.ContainsString(req.Tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		changed = len(tags) != len(camera.Tags)
+		camera.Tags = tags
+		return changed, "", nil
+
+	case models.BulkActionMove:
+		if req.ZoneID != "" && req.ZoneID != camera.ZoneID.String {
+			camera.ZoneID = sql.NullString{String: req.ZoneID, Valid: true}
+			changed = true
+		}
+		if req.Zone != "" && req.Zone != camera.Zone.String {
+			camera.Zone = sql.NullString{String: req.Zone, Valid: true}
+			changed = true
+		}
+		if req.Building != "" && req.Building != camera.Building.String {
+			camera.Building = sql.NullString{String: req.Building, Valid: true}
+			changed = true
+		}
+		return changed, "", nil
+
+	case models.BulkActionSetStatus:
+		if camera.Status == req.Status {
+			return false, "", nil
+		}
+		camera.Status = req.Status
+		return true, "", nil
+
+	case models.BulkActionDelete:
+		if camera.StreamID.Valid {
+			return true, models.OutboxOpRemove, nil
+		}
+		return true, "", nil
+	}
+
+	return false, "", errors.New("unsupported bulk action " + req.Action)
+}
+
+// deliverBulk mengirim operasi stream hasil bulk operation ke RTSPtoWeb secara
+// paralel. Yang gagal tetap di outbox dan dikirim ulang oleh worker.
+func (s *cameraService) deliverBulk(ctx context.Context, cameras []*models.Camera, operations []string, results []models.BulkCameraResult) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, bulkDeliveryConcurrency)
+
+	for i, camera := range cameras {
+		if operations[i] == "" {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, id string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			deliverErr := s.outbox.DeliverCamera(ctx, id)
+			result := &models.StreamSyncResult{
+				Action:  operations[i],
+				Applied: deliverErr == nil,
+			}
+			if deliverErr != nil {
+				log.Printf("Stream %s of camera %s queued for retry: %v", operations[i], id, deliverErr)
+				result.Error = deliverErr.Error()
+				result.Queued = true
+			}
+			results[i].StreamSync = result
+		}(i, camera.ID)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/utils"
+)
+
+// TestApplyBulkActionWritesKnownStatuses memastikan start/stop bulk hanya
+// menulis status yang diterima request camera
+func TestApplyBulkActionWritesKnownStatuses(t *testing.T) {
+	tests := []struct {
+		action    string
+		hasStream bool
+		want      string
+	}{
+		{models.BulkActionStartStream, false, models.CameraStatusUnknown},
+		{models.BulkActionStopStream, true, models.CameraStatusOffline},
+	}
+
+	for _, tt := range tests {
+		camera := &models.Camera{ID: "5f0c6a56-7c1e-4b43-9c55-2f7d2f6f1a10", Status: models.CameraStatusOnline}
+		camera.StreamID.String, camera.StreamID.Valid = camera.ID, tt.hasStream
+
+		changed, _, err := applyBulkAction(&models.BulkCameraRequest{Action: tt.action}, camera)
+		if err != nil || !changed {
+			t.Fatalf("%s: changed = %v, error = %v", tt.action, changed, err)
+		}
+		if camera.Status != tt.want || !utils.ContainsString(models.CameraStatuses, camera.Status) {
+			t.Errorf("%s: status = %q, want %q", tt.action, camera.Status, tt.want)
+		}
+	}
+}
+
+func TestNormalizeBulkRequestStatus(t *testing.T) {
+	ids := []string{"5f0c6a56-7c1e-4b43-9c55-2f7d2f6f1a10"}
+
+	for _, status := range models.CameraStatuses {
+		req := &models.BulkCameraRequest{Action: models.BulkActionSetStatus, IDs: ids, Status: status}
+		if err := normalizeBulkRequest(req); err != nil {
+			t.Errorf("set_status %q rejected: %v", status, err)
+		}
+	}
+
+	req := &models.BulkCameraRequest{Action: models.BulkActionSetStatus, IDs: ids, Status: "ready"}
+	if err := normalizeBulkRequest(req); err == nil {
+		t.Error("set_status READY accepted")
+	}
+
+	req = &models.BulkCameraRequest{Action: models.BulkActionSetStatus, IDs: []string{"cam-1"}, Status: models.CameraStatusOnline}
+	if err := normalizeBulkRequest(req); err == nil {
+		t.Error("invalid camera ID accepted")
+	}
+}
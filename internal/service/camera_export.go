@@ -0,0 +1,298 @@
+package service
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/repository"
+)
+
+// ErrUnsupportedExportFormat dikembalikan untuk format export yang tidak dikenal
+var ErrUnsupportedExportFormat = fmt.Errorf("export format must be %s, %s or %s",
+	models.CameraExportCSV, models.CameraExportGeoJSON, models.CameraExportKML)
+
+// cameraExportColumns adalah kolom CSV export. Credential RTSP tidak pernah
+// diexport; rtsp_url dan sub_rtsp_url disimpan tanpa userinfo.
+var cameraExportColumns = []string{
+	"id", "name", "description", "rtsp_url", "sub_rtsp_url",
+	"latitude", "longitude", "building", "zone", "zone_id", "ip_address", "port",
+	"manufacturer", "model", "resolution", "fps", "tags", "status", "last_seen",
+	"is_active", "stream_id", "media_server_id", "sync_status",
+	"on_demand", "audio", "debug", "insecure_skip_verify",
+	"created_by", "created_at", "updated_at",
+}
+
+// CameraExport adalah hasil query export yang belum ditulis. Write menulis
+// camera satu per satu langsung dari database lalu menutup query.
+type CameraExport struct {
+	Format      string
+	ContentType string
+	rows        *repository.CameraRows
+}
+
+// Filename mengembalikan nama file attachment, misalnya cameras-20240115-103000.csv
+func (e *CameraExport) Filename(now time.Time) string {
+	return fmt.Sprintf("cameras-%s.%s", now.UTC().Format("20060102-150405"), e.Format)
+}
+
+// cameraExportWriter menulis satu format export
+type cameraExportWriter interface {
+	begin() error
+	write(camera *models.Camera, subURL string) error
+	end() error
+}
+
+// Write menulis semua camera ke w dalam format export dan menutup query
+func (e *CameraExport) Write(w io.Writer) (err error) {
+	defer func() {
+		if closeErr := e.rows.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to close export query: %w", closeErr)
+		}
+	}()
+
+	bw := bufio.NewWriter(w)
+	var out cameraExportWriter
+	switch e.Format {
+	case models.CameraExportGeoJSON:
+		out = &geoJSONCameraWriter{w: bw}
+	case models.CameraExportKML:
+		out = &kmlCameraWriter{w: bw, enc: xml.NewEncoder(bw)}
+	default:
+		out = &csvCameraWriter{w: csv.NewWriter(bw)}
+	}
+
+	if err := out.begin(); err != nil {
+		return err
+	}
+	for e.rows.Next() {
+		camera, subURL, err := e.rows.Scan()
+		if err != nil {
+			return err
+		}
+		if err := out.write(camera, subURL); err != nil {
+			return err
+		}
+	}
+	if err := e.rows.Err(); err != nil {
+		return err
+	}
+	if err := out.end(); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// Export membuka query export camera dengan filter list camera. Pagination dan
+// fields diabaikan: semua camera yang cocok diexport.
+func (s *cameraService) Export(filter *models.CameraFilter, format string) (*CameraExport, error) {
+	contentType, ok := models.CameraExportContentTypes[format]
+	if !ok {
+		return nil, ErrUnsupportedExportFormat
+	}
+
+	rows, err := s.cameraRepo.Export(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CameraExport{Format: format, ContentType: contentType, rows: rows}, nil
+}
+
+// csvCameraWriter menulis semua metadata camera sebagai CSV
+type csvCameraWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvCameraWriter) begin() error {
+	return c.w.Write(cameraExportColumns)
+}
+
+func (c *csvCameraWriter) write(camera *models.Camera, subURL string) error {
+	port := ""
+	if camera.Port.Valid {
+		port = strconv.FormatInt(camera.Port.Int64, 10)
+	}
+	lastSeen := ""
+	if camera.LastSeen.Valid {
+		lastSeen = camera.LastSeen.Time.UTC().Format(time.RFC3339)
+	}
+
+	return c.w.Write([]string{
+		camera.ID,
+		camera.Name,
+		camera.Description.String,
+		camera.RTSPUrl,
+		subURL,
+		strconv.FormatFloat(camera.Latitude, 'f', -1, 64),
+		strconv.FormatFloat(camera.Longitude, 'f', -1, 64),
+		camera.Building.String,
+		camera.Zone.String,
+		camera.ZoneID.String,
+		camera.IPAddress.String,
+		port,
+		camera.Manufacturer.String,
+		camera.Model.String,
+		camera.Resolution.String,
+		strconv.Itoa(camera.FPS),
+		strings.Join(camera.Tags, ";"),
+		camera.Status,
+		lastSeen,
+		strconv.FormatBool(camera.IsActive),
+		camera.StreamID.String,
+		camera.MediaServerID.String,
+		camera.SyncStatus,
+		strconv.FormatBool(camera.StreamOptions.OnDemand),
+		strconv.FormatBool(camera.StreamOptions.Audio),
+		strconv.FormatBool(camera.StreamOptions.Debug),
+		strconv.FormatBool(camera.StreamOptions.InsecureSkipVerify),
+		camera.CreatedBy.String,
+		camera.CreatedAt.UTC().Format(time.RFC3339),
+		camera.UpdatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+func (c *csvCameraWriter) end() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// cameraExportProperties adalah properti titik camera di GeoJSON dan KML
+type cameraExportProperties struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Zone     string   `json:"zone"`
+	Building string   `json:"building"`
+	Status   string   `json:"status"`
+	Tags     []string `json:"tags"`
+}
+
+func newCameraExportProperties(camera *models.Camera) cameraExportProperties {
+	tags := camera.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return cameraExportProperties{
+		ID:       camera.ID,
+		Name:     camera.Name,
+		Zone:     camera.Zone.String,
+		Building: camera.Building.String,
+		Status:   camera.Status,
+		Tags:     tags,
+	}
+}
+
+// geoJSONCameraWriter menulis FeatureCollection berisi Point per camera.
+// Koordinat GeoJSON berurutan [longitude, latitude].
+type geoJSONCameraWriter struct {
+	w     *bufio.Writer
+	count int
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Geometry   geoJSONPoint           `json:"geometry"`
+	Properties cameraExportProperties `json:"properties"`
+}
+
+type geoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+func (g *geoJSONCameraWriter) begin() error {
+	_, err := g.w.WriteString(`{"type":"FeatureCollection","features":[`)
+	return err
+}
+
+func (g *geoJSONCameraWriter) write(camera *models.Camera, _ string) error {
+	data, err := json.Marshal(geoJSONFeature{
+		Type: "Feature",
+		ID:   camera.ID,
+		Geometry: geoJSONPoint{
+			Type:        "Point",
+			Coordinates: [2]float64{camera.Longitude, camera.Latitude},
+		},
+		Properties: newCameraExportProperties(camera),
+	})
+	if err != nil {
+		return err
+	}
+
+	if g.count > 0 {
+		if err := g.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	g.count++
+
+	_, err = g.w.Write(data)
+	return err
+}
+
+func (g *geoJSONCameraWriter) end() error {
+	_, err := g.w.WriteString("]}\n")
+	return err
+}
+
+// kmlCameraWriter menulis dokumen KML berisi Placemark per camera. Properti
+// camera ditulis sebagai ExtendedData, tags dipisah ";".
+type kmlCameraWriter struct {
+	w   *bufio.Writer
+	enc *xml.Encoder
+}
+
+type kmlPlacemark struct {
+	XMLName      xml.Name  `xml:"Placemark"`
+	ID           string    `xml:"id,attr"`
+	Name         string    `xml:"name"`
+	ExtendedData []kmlData `xml:"ExtendedData>Data"`
+	Point        string    `xml:"Point>coordinates"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+func (k *kmlCameraWriter) begin() error {
+	_, err := k.w.WriteString(xml.Header +
+		`<kml xmlns="http://www.opengis.net/kml/2.2"><Document><name>Cameras</name>` + "\n")
+	return err
+}
+
+func (k *kmlCameraWriter) write(camera *models.Camera, _ string) error {
+	props := newCameraExportProperties(camera)
+	err := k.enc.Encode(kmlPlacemark{
+		ID:   "camera-" + props.ID,
+		Name: props.Name,
+		ExtendedData: []kmlData{
+			{Name: "id", Value: props.ID},
+			{Name: "zone", Value: props.Zone},
+			{Name: "building", Value: props.Building},
+			{Name: "status", Value: props.Status},
+			{Name: "tags", Value: strings.Join(props.Tags, ";")},
+		},
+		Point: strconv.FormatFloat(camera.Longitude, 'f', -1, 64) + "," +
+			strconv.FormatFloat(camera.Latitude, 'f', -1, 64),
+	})
+	if err != nil {
+		return err
+	}
+
+	return k.w.WriteByte('\n')
+}
+
+func (k *kmlCameraWriter) end() error {
+	_, err := k.w.WriteString("</Document></kml>\n")
+	return err
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/utils"
+	"cctv-monitoring-backend/internal/validation"
+)
+
+// cameraImportColumns adalah kolom CSV bulk import. name dan rtsp_url wajib ada
+// di header, kolom lain opsional.
+var cameraImportColumns = []string{
+	"name", "description", "rtsp_url", "sub_rtsp_url", "rtsp_username", "rtsp_password",
+	"latitude", "longitude", "building", "zone", "zone_id", "ip_address", "port",
+	"manufacturer", "model", "resolution", "fps", "tags", "status",
+	"on_demand", "audio", "debug", "insecure_skip_verify",
+}
+
+// ParseCameraImportCSV membaca CSV bulk import. Baris pertama adalah header
+// dengan nama kolom sesuai field CreateCameraRequest; tags dipisah ";" dan
+// sub_rtsp_url menambahkan channel SUB.
+func ParseCameraImportCSV(data []byte) ([]models.CameraImportInput, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("invalid CSV: file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !utils.ContainsString(cameraImportColumns, name) {
+			return nil, fmt.Errorf("invalid CSV: unknown column %q", name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("invalid CSV: duplicate column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"name", "rtsp_url"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("invalid CSV: missing column %q", required)
+		}
+	}
+
+	var inputs []models.CameraImportInput
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if len(inputs) == models.MaxCameraImportRows {
+			return nil, fmt.Errorf("import is limited to %d cameras", models.MaxCameraImportRows)
+		}
+
+		line, _ := r.FieldPos(0)
+		inputs = append(inputs, parseCameraCSVRecord(line, record, columns))
+	}
+
+	return inputs, nil
+}
+
+// parseCameraCSVRecord mengubah satu baris CSV menjadi CreateCameraRequest
+func parseCameraCSVRecord(line int, record []string, columns map[string]int) models.CameraImportInput {
+	input := models.CameraImportInput{Row: line}
+	req := &input.Request
+
+	get := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	fail := func(name string, err error) {
+		input.Errors = append(input.Errors, fmt.Sprintf("%s: %v", name, err))
+	}
+
+	req.Name = get("name")
+	req.Description = get("description")
+	req.RTSPUrl = get("rtsp_url")
+	req.Building = get("building")
+	req.Zone = get("zone")
+	req.ZoneID = get("zone_id")
+	req.IPAddress = get("ip_address")
+	req.Manufacturer = get("manufacturer")
+	req.Model = get("model")
+	req.Resolution = get("resolution")
+	req.Status = strings.ToUpper(get("status"))
+
+	if sub := get("sub_rtsp_url"); sub != "" {
+		req.Channels = []models.CameraChannelRequest{
+			{RTSPUrl: req.RTSPUrl, Role: models.ChannelRoleMain},
+			{RTSPUrl: sub, Role: models.ChannelRoleSub},
+		}
+	}
+
+	if v := get("rtsp_username"); v != "" {
+		req.Username = &v
+	}
+	if v := get("rtsp_password"); v != "" {
+		req.Password = &v
+	}
+
+	for _, tag := range strings.Split(get("tags"), ";") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			req.Tags = append(req.Tags, tag)
+		}
+	}
+
+	for _, f := range []struct {
+		name string
+		dst  *float64
+	}{{"latitude", &req.Latitude}, {"longitude", &req.Longitude}} {
+		if v := get(f.name); v != "" {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				fail(f.name, fmt.Errorf("must be a number"))
+				continue
+			}
+			*f.dst = n
+		}
+	}
+
+	for _, f := range []struct {
+		name string
+		dst  *int
+	}{{"port", &req.Port}, {"fps", &req.FPS}} {
+		if v := get(f.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				fail(f.name, fmt.Errorf("must be an integer"))
+				continue
+			}
+			*f.dst = n
+		}
+	}
+
+	opts := &models.StreamOptionsRequest{}
+	for _, f := range []struct {
+		name string
+		dst  **bool
+	}{
+		{"on_demand", &opts.OnDemand},
+		{"audio", &opts.Audio},
+		{"debug", &opts.Debug},
+		{"insecure_skip_verify", &opts.InsecureSkipVerify},
+	} {
+		if v := get(f.name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				fail(f.name, fmt.Errorf("must be true or false"))
+				continue
+			}
+			*f.dst = &b
+		}
+	}
+	if *opts != (models.StreamOptionsRequest{}) {
+		req.StreamOptions = opts
+	}
+
+	return input
+}
+
+// ParseCameraImportJSON membaca array JSON CreateCameraRequest. Baris yang
+// tidak bisa di-decode dilaporkan per baris, bukan menggagalkan seluruh file.
+func ParseCameraImportJSON(data []byte) ([]models.CameraImportInput, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON: expected an array of cameras: %w", err)
+	}
+	if len(raw) > models.MaxCameraImportRows {
+		return nil, fmt.Errorf("import is limited to %d cameras", models.MaxCameraImportRows)
+	}
+
+	inputs := make([]models.CameraImportInput, len(raw))
+	for i, item := range raw {
+		inputs[i].Row = i + 1
+		if err := json.Unmarshal(item, &inputs[i].Request); err != nil {
+			inputs[i].Errors = append(inputs[i].Errors, fmt.Sprintf("invalid camera: %v", err))
+		}
+	}
+
+	return inputs, nil
+}
+
+// Import memvalidasi semua baris bulk import lalu, jika semua valid dan bukan
+// dry run, membuat semua camera dalam satu transaksi. Stream setiap camera
+// didaftarkan ke RTSPtoWeb setelah commit; yang gagal dikirim ulang oleh
+// outbox worker.
+func (s *cameraService) Import(ctx context.Context, inputs []models.CameraImportInput, userID string, dryRun bool) (*models.CameraImportReport, error) {
+	report := &models.CameraImportReport{
+		DryRun: dryRun,
+		Total:  len(inputs),
+		Rows:   make([]models.CameraImportRow, len(inputs)),
+	}
+
+	cameras := make([]*models.Camera, len(inputs))
+	channels := make([][]models.CameraChannel, len(inputs))
+	rowByURL := map[string]int{}
+
+	for i := range inputs {
+		input := &inputs[i]
+		row := &report.Rows[i]
+		row.Row = input.Row
+		row.Name = input.Request.Name
+		row.Errors = append(row.Errors, input.Errors...)
+
+		input.Request.Normalize()
+		row.Errors = append(row.Errors, validation.Struct(&input.Request).Strings()...)
+		if len(row.Errors) > 0 {
+			continue
+		}
+
+		camera, cameraChannels, err := s.newCamera(&input.Request, userID)
+		if err != nil {
+			row.Errors = append(row.Errors, err.Error())
+			continue
+		}
+		row.RTSPUrl = camera.RTSPUrl
+
+		// URL yang sama di dua baris hampir pasti salah salin
+		if first, ok := rowByURL[camera.RTSPUrl]; ok {
+			row.Errors = append(row.Errors, fmt.Sprintf("rtsp_url duplicates row %d", first))
+			continue
+		}
+		rowByURL[camera.RTSPUrl] = input.Row
+
+		cameras[i] = camera
+		channels[i] = cameraChannels
+	}
+
+	// Camera yang sudah terdaftar tidak dibuat ulang (import ulang file yang sama)
+	if len(rowByURL) > 0 {
+		urls := make([]string, 0, len(rowByURL))
+		for u := range rowByURL {
+			urls = append(urls, u)
+		}
+
+		existing, err := s.cameraRepo.GetIDsByRTSPUrls(urls)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, camera := range cameras {
+			if camera == nil {
+				continue
+			}
+			if id, ok := existing[camera.RTSPUrl]; ok {
+				report.Rows[i].Errors = append(report.Rows[i].Errors, fmt.Sprintf("rtsp_url is already used by camera %s", id))
+				cameras[i] = nil
+			}
+		}
+	}
+
+	for i := range report.Rows {
+		report.Rows[i].Valid = len(report.Rows[i].Errors) == 0
+		if report.Rows[i].Valid {
+			report.Valid++
+		} else {
+			report.Invalid++
+		}
+	}
+
+	if dryRun || report.Invalid > 0 || report.Total == 0 {
+		return report, nil
+	}
+
+	err := s.transactor.WithinTx(func(tx *sql.Tx) error {
+		for i, camera := range cameras {
+			if err := s.insertCamera(tx, camera, channels[i], userID, models.RevisionActionImport); err != nil {
+				return fmt.Errorf("row %d: %w", inputs[i].Row, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.Created = len(cameras)
+
+	for i, camera := range cameras {
+		row := &report.Rows[i]
+		row.CameraID = camera.ID
+
+		deliverErr := s.outbox.DeliverCamera(ctx, camera.ID)
+		row.StreamSync = &models.StreamSyncResult{
+			Action:  models.OutboxOpAdd,
+			Applied: deliverErr == nil,
+		}
+		if deliverErr != nil {
+			row.StreamSync.Error = deliverErr.Error()
+			row.StreamSync.Queued = true
+			report.StreamsQueued++
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"testing"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/validation"
+)
+
+func validImportRequest(status string) *models.CreateCameraRequest {
+	return &models.CreateCameraRequest{
+		Name:      "Lobby",
+		RTSPUrl:   "rtsp://10.0.0.1/live",
+		Latitude:  -6.2,
+		Longitude: 106.8,
+		Status:    status,
+	}
+}
+
+// TestImportRequestStatus memastikan import menerima semua status yang ditulis
+// sistem dan menolak nilai lain
+func TestImportRequestStatus(t *testing.T) {
+	for _, status := range append([]string{""}, models.CameraStatuses...) {
+		if errs := validation.Struct(validImportRequest(status)); len(errs) > 0 {
+			t.Errorf("status %q rejected: %v", status, errs)
+		}
+	}
+
+	errs := validation.Struct(validImportRequest("READY"))
+	if len(errs) != 1 || errs[0].Field != "status" || errs[0].Code != models.FieldErrInvalidChoice {
+		t.Errorf("status READY: errors = %v, want status error", errs)
+	}
+}
+
+func TestParseCameraImportCSV(t *testing.T) {
+	data := "\xef\xbb\xbfname,rtsp_url,sub_rtsp_url,latitude,longitude,tags,status\n" +
+		"Lobby,rtsp://10.0.0.1/main,rtsp://10.0.0.1/sub,-6.2,106.8,lobby;entrance,ONLINE\n"
+
+	inputs, err := ParseCameraImportCSV([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) != 1 {
+		t.Fatalf("parsed %d rows, want 1", len(inputs))
+	}
+
+	req := inputs[0].Request
+	if req.Name != "Lobby" || req.Status != models.CameraStatusOnline || len(req.Tags) != 2 {
+		t.Errorf("request = %+v", req)
+	}
+	if len(req.Channels) != 2 || req.Channels[1].Role != models.ChannelRoleSub {
+		t.Errorf("channels = %+v, want MAIN and SUB", req.Channels)
+	}
+}
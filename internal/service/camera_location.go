@@ -0,0 +1,53 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/utils"
+)
+
+// ErrZoneNotFound dikembalikan jika zone_id camera tidak ada
+var ErrZoneNotFound = errors.New("zone not found")
+
+// resolveLocation menautkan camera ke zone di hierarki lokasi. zoneID diisi:
+// building dan zone camera mengikuti nama zone tersebut. zoneID kosong: zone
+// dicari dari nama building dan zone camera; jika tidak ada yang cocok camera
+// tetap memakai teks tersebut tanpa zone_id.
+func (s *cameraService) resolveLocation(camera *models.Camera, zoneID string) error {
+	var zone *models.Zone
+	var err error
+
+	if zoneID != "" {
+		if !utils.IsUUID(zoneID) {
+			return ErrZoneNotFound
+		}
+		zone, err = s.locationRepo.GetZone(zoneID)
+		if errors.Is(err, ErrLocationNotFound) {
+			return ErrZoneNotFound
+		}
+	} else {
+		camera.ZoneID = sql.NullString{}
+		if !camera.Building.Valid || !camera.Zone.Valid {
+			return nil
+		}
+		zone, err = s.locationRepo.FindZone(camera.Building.String, camera.Zone.String)
+		if errors.Is(err, ErrLocationNotFound) {
+			return nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	building, err := s.locationRepo.GetBuilding(zone.BuildingID)
+	if err != nil {
+		return err
+	}
+
+	camera.ZoneID = sql.NullString{String: zone.ID, Valid: true}
+	camera.Building = sql.NullString{String: building.Name, Valid: true}
+	camera.Zone = sql.NullString{String: zone.Name, Valid: true}
+	return nil
+}
@@ -0,0 +1,485 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/validation"
+)
+
+// ErrInvalidCameraPatch dikembalikan jika body PATCH berisi key atau nilai yang
+// tidak valid
+var ErrInvalidCameraPatch = errors.New("invalid camera patch")
+
+// cameraPatchString adalah field string opsional camera beserta rule validasi
+// yang sama dengan tag di UpdateCameraRequest. null atau string kosong
+// menghapus nilainya.
+type cameraPatchString struct {
+	field func(*models.Camera) *sql.NullString
+	rules string
+}
+
+var cameraPatchStrings = map[string]cameraPatchString{
+	"description":  {func(c *models.Camera) *sql.NullString { return &c.Description }, ""},
+	"building":     {func(c *models.Camera) *sql.NullString { return &c.Building }, "max=255"},
+	"zone":         {func(c *models.Camera) *sql.NullString { return &c.Zone }, "max=255"},
+	"ip_address":   {func(c *models.Camera) *sql.NullString { return &c.IPAddress }, "omitempty,ip"},
+	"manufacturer": {func(c *models.Camera) *sql.NullString { return &c.Manufacturer }, "max=100"},
+	"model":        {func(c *models.Camera) *sql.NullString { return &c.Model }, "max=100"},
+	"resolution":   {func(c *models.Camera) *sql.NullString { return &c.Resolution }, "max=50"},
+}
+
+// invalidCameraPatch membungkus pelanggaran validasi dengan
+// ErrInvalidCameraPatch, keduanya bisa dicek dengan errors.Is/errors.As
+func invalidCameraPatch(errs validation.Errors) error {
+	return fmt.Errorf("%w: %w", ErrInvalidCameraPatch, errs)
+}
+
+// Patch menerapkan JSON Merge Patch ke camera, termasuk camera di trash.
+// is_active false sama dengan Delete, is_active true sama dengan Restore.
+// version > 0 (dari If-Match) harus sama dengan version camera saat ini.
+func (s *cameraService) Patch(ctx context.Context, id string, patch models.CameraPatch, version int, userID string) (*models.Camera, error) {
+	return s.patchCamera(ctx, id, patch, version, userID, nil)
+}
+
+// patchCamera menjalankan Patch. revertedTo diisi jika patch berasal dari
+// revert ke sebuah revision.
+func (s *cameraService) patchCamera(ctx context.Context, id string, patch models.CameraPatch, version int, userID string, revertedTo *int64) (*models.Camera, error) {
+	camera, err := s.cameraRepo.GetByIDWithInactive(id)
+	if err != nil {
+		return nil, fmt.Errorf("camera not found: %w", err)
+	}
+	if version > 0 && camera.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	action := models.RevisionActionPatch
+	if revertedTo != nil {
+		action = models.RevisionActionRevert
+	}
+
+	before, err := cameraSnapshot(s.channelRepo, camera)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *camera
+	streamChanged, err := s.applyCameraPatch(camera, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	hasStream := camera.StreamID.Valid && camera.StreamID.String != ""
+	operation := ""
+	activity := ""
+	switch {
+	case previous.IsActive && !camera.IsActive:
+		// Camera masuk trash, stream dihapus dari RTSPtoWeb
+		activity = models.ActivityCameraDeleted
+		camera.DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		camera.DeletedBy = sql.NullString{String: userID, Valid: userID != ""}
+		if hasStream {
+			operation = models.OutboxOpRemove
+		}
+	case !previous.IsActive && camera.IsActive:
+		// Stream camera yang dihapus sudah di-REMOVE, didaftarkan ulang
+		activity = models.ActivityCameraRestored
+		restoreCamera(camera)
+		if hasStream {
+			operation = models.OutboxOpAdd
+		}
+	case camera.IsActive && streamChanged && hasStream:
+		operation = models.OutboxOpEdit
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.cameraRepo.WithTx(tx).Update(id, camera); err != nil {
+			return err
+		}
+
+		// Channels hanya diisi jika ada perubahan channel
+		if camera.Channels != nil {
+			if err := s.channelRepo.WithTx(tx).ReplaceForCamera(id, camera.Channels); err != nil {
+				return fmt.Errorf("failed to update camera channels: %w", err)
+			}
+		}
+
+		revision := models.NewCameraRevision(camera, before, userID, action)
+		if revision != nil {
+			if revertedTo != nil {
+				revision.RevertedTo = sql.NullInt64{Int64: *revertedTo, Valid: true}
+			}
+			if err := s.revisionRepo.WithTx(tx).Create(revision); err != nil {
+				return err
+			}
+		}
+
+		if activity != "" {
+			entry := models.NewCameraActivity(activity, camera, userID, map[string]interface{}{"source": action})
+			if err := s.activityRepo.WithTx(tx).Create(entry); err != nil {
+				return err
+			}
+		}
+
+		switch operation {
+		case models.OutboxOpRemove:
+			// REMOVE dicatat dengan stream_id dan node sebelum patch
+			return s.outbox.Enqueue(tx, &previous, operation)
+		case models.OutboxOpAdd, models.OutboxOpEdit:
+			return s.outbox.Enqueue(tx, camera, operation)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var streamSync *models.StreamSyncResult
+	if operation != "" {
+		deliverErr := s.outbox.DeliverCamera(ctx, id)
+		streamSync = &models.StreamSyncResult{
+			Action:  operation,
+			Applied: deliverErr == nil,
+		}
+		if deliverErr != nil {
+			log.Printf("Stream %s of camera %s queued for retry: %v", operation, id, deliverErr)
+			streamSync.Error = deliverErr.Error()
+			streamSync.Queued = true
+		}
+	}
+
+	camera, err = s.cameraRepo.GetByIDWithInactive(id)
+	if err != nil {
+		return nil, fmt.Errorf("camera not found: %w", err)
+	}
+	camera.StreamSync = streamSync
+
+	// Enrich dengan stream URLs
+	s.enrichCameraWithStreamURLs(camera)
+
+	return camera, nil
+}
+
+// applyCameraPatch mengubah camera sesuai merge patch. streamChanged true jika
+// perubahan harus diteruskan ke stream RTSPtoWeb. Semua key yang tidak valid
+// dilaporkan sekaligus.
+func (s *cameraService) applyCameraPatch(camera *models.Camera, patch models.CameraPatch) (streamChanged bool, err error) {
+	var errs validation.Errors
+	// check menjalankan rules untuk nilai key, false jika ada pelanggaran
+	check := func(key string, value interface{}, rules string) bool {
+		keyErrs := validation.Var(key, value, rules)
+		errs = append(errs, keyErrs...)
+		return len(keyErrs) == 0
+	}
+	invalid := func(key, message string) {
+		errs.Add(key, models.FieldErrInvalidFormat, message)
+	}
+
+	var (
+		rtspURL     *string
+		channelReqs []models.CameraChannelRequest
+		credentials models.RTSPCredentialsRequest
+
+		// zoneID nil jika zone_id tidak ada di patch, "" jika null
+		zoneID          *string
+		locationChanged bool
+	)
+
+	// Urutan key tetap agar pesan error konsisten
+	keys := make([]string, 0, len(patch))
+	for key := range patch {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		raw := patch[key]
+		isNull := models.IsJSONNull(raw)
+
+		if patchString, ok := cameraPatchStrings[key]; ok {
+			var value string
+			if !isNull && json.Unmarshal(raw, &value) != nil {
+				invalid(key, "must be a string or null")
+				continue
+			}
+			value = strings.TrimSpace(value)
+			if !check(key, value, patchString.rules) {
+				continue
+			}
+			*patchString.field(camera) = sql.NullString{String: value, Valid: value != ""}
+			locationChanged = locationChanged || key == "building" || key == "zone"
+			continue
+		}
+
+		switch key {
+		case "name":
+			var name string
+			if !isNull && json.Unmarshal(raw, &name) != nil {
+				invalid(key, "must be a string")
+				continue
+			}
+			if !check(key, strings.TrimSpace(name), "required,max=255") {
+				continue
+			}
+			if name != camera.Name {
+				camera.Name = name
+				streamChanged = true
+			}
+
+		case "rtsp_url":
+			var value string
+			if !isNull && json.Unmarshal(raw, &value) != nil {
+				invalid(key, "must be a string")
+				continue
+			}
+			if !check(key, value, "required,rtsp_url") {
+				continue
+			}
+			rtspURL = &value
+
+		case "channels":
+			if !isNull && json.Unmarshal(raw, &channelReqs) != nil {
+				invalid(key, "must be an array of channels")
+				channelReqs = nil
+				continue
+			}
+			// Role tidak peka huruf besar/kecil seperti di Create dan Update
+			for i := range channelReqs {
+				channelReqs[i].Role = strings.ToUpper(channelReqs[i].Role)
+			}
+			if !check(key, channelReqs, "required,min=1") {
+				channelReqs = nil
+			}
+
+		case "zone_id":
+			value := ""
+			if !isNull && json.Unmarshal(raw, &value) != nil {
+				invalid(key, "must be a zone ID or null")
+				continue
+			}
+			if !check(key, value, "omitempty,uuid") {
+				continue
+			}
+			zoneID = &value
+
+		case "rtsp_username", "rtsp_password":
+			// null menghapus credential, sama seperti string kosong
+			value := ""
+			if !isNull && json.Unmarshal(raw, &value) != nil {
+				invalid(key, "must be a string or null")
+				continue
+			}
+			if key == "rtsp_username" {
+				credentials.Username = &value
+			} else {
+				credentials.Password = &value
+			}
+
+		case "latitude", "longitude":
+			var value float64
+			if isNull || json.Unmarshal(raw, &value) != nil {
+				invalid(key, "must be a number")
+				continue
+			}
+			if key == "latitude" {
+				if !check(key, value, "min=-90,max=90") {
+					continue
+				}
+				camera.Latitude = value
+			} else {
+				if !check(key, value, "min=-180,max=180") {
+					continue
+				}
+				camera.Longitude = value
+			}
+
+		case "port":
+			var port int
+			if !isNull && json.Unmarshal(raw, &port) != nil {
+				invalid(key, "must be an integer or null")
+				continue
+			}
+			if !isNull && !check(key, port, "min=1,max=65535") {
+				continue
+			}
+			camera.Port = sql.NullInt64{Int64: int64(port), Valid: !isNull}
+
+		case "fps":
+			// null = FPS tidak diketahui (0)
+			var fps int
+			if !isNull && json.Unmarshal(raw, &fps) != nil {
+				invalid(key, "must be an integer or null")
+				continue
+			}
+			if !check(key, fps, "min=0,max=120") {
+				continue
+			}
+			camera.FPS = fps
+
+		case "tags":
+			tags := []string{}
+			if !isNull && json.Unmarshal(raw, &tags) != nil {
+				invalid(key, "must be an array of strings or null")
+				continue
+			}
+			if !check(key, tags, "dive,required,max=100") {
+				continue
+			}
+			if tags == nil {
+				tags = []string{}
+			}
+			camera.Tags = tags
+
+		case "status":
+			var status string
+			if !isNull && json.Unmarshal(raw, &status) != nil {
+				invalid(key, "must be a string")
+				continue
+			}
+			status = strings.ToUpper(status)
+			if !check(key, status, "required,camera_status") {
+				continue
+			}
+			camera.Status = status
+
+		case "is_active":
+			var active bool
+			if isNull || json.Unmarshal(raw, &active) != nil {
+				invalid(key, "must be true or false")
+				continue
+			}
+			camera.IsActive = active
+
+		case "stream_options":
+			opts, err := patchStreamOptions(camera.StreamOptions, raw)
+			if err != nil {
+				invalid(key, err.Error())
+				continue
+			}
+			if opts != camera.StreamOptions {
+				camera.StreamOptions = opts
+				streamChanged = true
+			}
+
+		default:
+			errs.Add(key, models.FieldErrUnknownField, "unknown or read-only field")
+		}
+	}
+
+	if rtspURL != nil && channelReqs != nil {
+		errs.Add("rtsp_url", models.FieldErrInvalidChoice, "cannot be combined with channels")
+	}
+	if len(errs) > 0 {
+		return false, invalidCameraPatch(errs)
+	}
+
+	// zone_id null melepas camera dari zone tanpa mengubah building dan zone.
+	// building atau zone tanpa zone_id dicocokkan ulang ke zone berdasarkan nama.
+	switch {
+	case zoneID != nil && *zoneID == "":
+		camera.ZoneID = sql.NullString{}
+	case zoneID != nil || locationChanged:
+		id := ""
+		if zoneID != nil {
+			id = *zoneID
+		}
+		if err := s.resolveLocation(camera, id); err != nil {
+			if errors.Is(err, ErrZoneNotFound) {
+				errs.Add("zone_id", models.FieldErrNotFound, "does not exist")
+				return false, invalidCameraPatch(errs)
+			}
+			return false, err
+		}
+	}
+
+	// Credential dan URL dibandingkan setelah credential dipisahkan, karena
+	// URL tersimpan tidak berisi credential
+	var credentialsChanged bool
+	switch {
+	case channelReqs != nil:
+		channels, err := buildChannels(channelReqs, "")
+		if err != nil {
+			errs.Add("channels", models.FieldErrInvalidFormat, err.Error())
+			return false, invalidCameraPatch(errs)
+		}
+		current, err := cameraChannels(s.channelRepo, camera)
+		if err != nil {
+			return false, fmt.Errorf("failed to get camera channels: %w", err)
+		}
+		credentialsChanged, err = s.credentials.Seal(camera, channels, credentials)
+		if err != nil {
+			return false, err
+		}
+		streamChanged = streamChanged || channelsChanged(current, channels)
+		camera.Channels = channels
+
+	case rtspURL != nil:
+		channels, err := cameraChannels(s.channelRepo, camera)
+		if err != nil {
+			return false, fmt.Errorf("failed to get camera channels: %w", err)
+		}
+		previousURL := camera.RTSPUrl
+		mainChannel(channels).RTSPUrl = *rtspURL
+		credentialsChanged, err = s.credentials.Seal(camera, channels, credentials)
+		if err != nil {
+			return false, err
+		}
+		streamChanged = streamChanged || camera.RTSPUrl != previousURL
+
+	default:
+		credentialsChanged, err = s.credentials.Seal(camera, nil, credentials)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return streamChanged || credentialsChanged, nil
+}
+
+// patchStreamOptions menerapkan merge patch object stream_options. null pada
+// object atau salah satu key mengembalikan opsi ke default.
+func patchStreamOptions(opts models.StreamOptions, raw json.RawMessage) (models.StreamOptions, error) {
+	defaults := models.DefaultStreamOptions()
+	if models.IsJSONNull(raw) {
+		return defaults, nil
+	}
+
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &patch); err != nil {
+		return opts, errors.New("must be an object or null")
+	}
+
+	fields := map[string]struct {
+		dst *bool
+		def bool
+	}{
+		"on_demand":            {&opts.OnDemand, defaults.OnDemand},
+		"audio":                {&opts.Audio, defaults.Audio},
+		"debug":                {&opts.Debug, defaults.Debug},
+		"insecure_skip_verify": {&opts.InsecureSkipVerify, defaults.InsecureSkipVerify},
+	}
+
+	for key, value := range patch {
+		field, ok := fields[key]
+		if !ok {
+			return opts, fmt.Errorf("unknown option %q", key)
+		}
+		if models.IsJSONNull(value) {
+			*field.dst = field.def
+			continue
+		}
+		if err := json.Unmarshal(value, field.dst); err != nil {
+			return opts, fmt.Errorf("%s must be true, false or null", key)
+		}
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"cctv-monitoring-backend/internal/models"
+)
+
+// patchCamera mengembalikan camera tanpa stream dengan field opsional terisi
+func patchCamera() *models.Camera {
+	return &models.Camera{
+		ID:            "cam-1",
+		Name:          "Lobby",
+		RTSPUrl:       "rtsp://10.0.0.1/live",
+		Description:   sql.NullString{String: "Pintu utama", Valid: true},
+		Building:      sql.NullString{String: "A", Valid: true},
+		Port:          sql.NullInt64{Int64: 554, Valid: true},
+		FPS:           25,
+		Tags:          []string{"lobby"},
+		Status:        models.CameraStatusOnline,
+		IsActive:      true,
+		StreamOptions: models.StreamOptions{Audio: true},
+		Version:       3,
+	}
+}
+
+func newPatchService(t *testing.T, cameraRepo *fakeCameraRepo) *cameraService {
+	return &cameraService{
+		cameraRepo:  cameraRepo,
+		credentials: NewCredentialService(testCipher(t), nil, nil, nil, nil, nil),
+	}
+}
+
+func TestApplyCameraPatchNullClearsFields(t *testing.T) {
+	camera := patchCamera()
+	patch, err := models.ParseCameraPatch([]byte(`{
+		"description": null, "building": null, "port": null, "fps": null,
+		"tags": null, "stream_options": null
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	streamChanged, err := newPatchService(t, nil).applyCameraPatch(camera, patch)
+	if err != nil {
+		t.Fatalf("applyCameraPatch() error = %v", err)
+	}
+
+	if camera.Description.Valid || camera.Building.Valid || camera.Port.Valid {
+		t.Errorf("nullable fields not cleared: %+v %+v %+v", camera.Description, camera.Building, camera.Port)
+	}
+	if camera.FPS != 0 || camera.Tags == nil || len(camera.Tags) != 0 {
+		t.Errorf("fps = %d, tags = %#v, want 0 and empty", camera.FPS, camera.Tags)
+	}
+	if camera.StreamOptions != models.DefaultStreamOptions() || !streamChanged {
+		t.Errorf("stream_options = %+v, streamChanged = %v, want defaults and true", camera.StreamOptions, streamChanged)
+	}
+	// Key yang tidak ada di patch tidak berubah
+	if camera.Name != "Lobby" || camera.Status != models.CameraStatusOnline {
+		t.Errorf("untouched fields changed: name %q, status %q", camera.Name, camera.Status)
+	}
+}
+
+func TestApplyCameraPatchStreamOptionKey(t *testing.T) {
+	camera := patchCamera()
+	patch := models.CameraPatch{"stream_options": []byte(`{"audio": null, "debug": true}`)}
+
+	if _, err := newPatchService(t, nil).applyCameraPatch(camera, patch); err != nil {
+		t.Fatalf("applyCameraPatch() error = %v", err)
+	}
+
+	want := models.StreamOptions{Debug: true}
+	if camera.StreamOptions != want {
+		t.Errorf("stream_options = %+v, want %+v", camera.StreamOptions, want)
+	}
+}
+
+func TestApplyCameraPatchRejectsInvalidKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"null name", `{"name": null}`},
+		{"null latitude", `{"latitude": null}`},
+		{"null is_active", `{"is_active": null}`},
+		{"legacy status", `{"status": "READY"}`},
+		{"read-only field", `{"version": 4}`},
+		{"url and channels", `{"rtsp_url": "rtsp://10.0.0.2/live", "channels": [{"rtsp_url": "rtsp://10.0.0.2/live"}]}`},
+	}
+
+	for _, tt := range tests {
+		camera := patchCamera()
+		patch, err := models.ParseCameraPatch([]byte(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := newPatchService(t, nil).applyCameraPatch(camera, patch); !errors.Is(err, ErrInvalidCameraPatch) {
+			t.Errorf("%s: error = %v, want ErrInvalidCameraPatch", tt.name, err)
+		}
+	}
+}
+
+func TestApplyCameraPatchNormalizesStatus(t *testing.T) {
+	camera := patchCamera()
+	patch := models.CameraPatch{"status": []byte(`"error"`)}
+
+	if _, err := newPatchService(t, nil).applyCameraPatch(camera, patch); err != nil {
+		t.Fatalf("applyCameraPatch() error = %v", err)
+	}
+	if camera.Status != models.CameraStatusError {
+		t.Errorf("status = %q, want %q", camera.Status, models.CameraStatusError)
+	}
+}
+
+func TestPatchVersionConflict(t *testing.T) {
+	cameraRepo := &fakeCameraRepo{cameras: []*models.Camera{patchCamera()}}
+	patch := models.CameraPatch{"name": []byte(`"Lobby Timur"`)}
+
+	// Version basi ditolak sebelum patch diterapkan atau ditulis
+	_, err := newPatchService(t, cameraRepo).Patch(context.Background(), "cam-1", patch, 2, "user-1")
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("Patch() error = %v, want ErrVersionConflict", err)
+	}
+	if cameraRepo.cameras[0].Name != "Lobby" {
+		t.Errorf("camera changed on conflict: name %q", cameraRepo.cameras[0].Name)
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/repository"
+	"cctv-monitoring-backend/internal/rtsp"
+)
+
+// Custom errors untuk probe camera
+var (
+	ErrProbeNotFound      = repository.ErrProbeNotFound
+	ErrCameraUnreachable  = rtsp.ErrUnreachable
+	ErrCameraTimeout      = rtsp.ErrTimeout
+	ErrCameraUnauthorized = rtsp.ErrUnauthorized
+	ErrInvalidRTSPURL     = rtsp.ErrInvalidURL
+)
+
+// CameraProbeService menjalankan probe RTSP ke kamera dan menyimpan hasilnya
+type CameraProbeService interface {
+	Probe(ctx context.Context, cameraID string, apply bool) (*models.CameraProbe, error)
+	GetLatest(cameraID string) (*models.CameraProbe, error)
+}
+
+// RTSPProber adalah probe OPTIONS + DESCRIBE ke satu RTSP URL
+type RTSPProber interface {
+	Probe(ctx context.Context, rawURL string) (*rtsp.Result, error)
+}
+
+type cameraProbeService struct {
+	cameraRepo  repository.CameraRepository
+	probeRepo   repository.ProbeRepository
+	transactor  repository.Transactor
+	credentials CredentialService
+	prober      RTSPProber
+}
+
+// NewCameraProbeService membuat instance baru dari CameraProbeService
+func NewCameraProbeService(
+	cameraRepo repository.CameraRepository,
+	probeRepo repository.ProbeRepository,
+	transactor repository.Transactor,
+	credentials CredentialService,
+	prober RTSPProber,
+) CameraProbeService {
+	return &cameraProbeService{
+		cameraRepo:  cameraRepo,
+		probeRepo:   probeRepo,
+		transactor:  transactor,
+		credentials: credentials,
+		prober:      prober,
+	}
+}
+
+// Probe menjalankan OPTIONS + DESCRIBE ke rtsp_url camera dan menyimpan hasilnya.
+// Jika apply true, resolution, fps dan manufacturer camera diperbarui dari hasil
+// probe. Probe yang gagal tetap disimpan (success = false) lalu error dikembalikan.
+func (s *cameraProbeService) Probe(ctx context.Context, cameraID string, apply bool) (*models.CameraProbe, error) {
+	camera, err := s.cameraRepo.GetByID(cameraID)
+	if err != nil {
+		return nil, err
+	}
+
+	rtspURL, err := s.credentials.StreamURL(camera)
+	if err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	result, probeErr := s.prober.Probe(ctx, rtspURL)
+
+	probe := &models.CameraProbe{
+		CameraID:   camera.ID,
+		Success:    probeErr == nil,
+		Tracks:     []models.ProbeTrack{},
+		DurationMs: time.Since(started).Milliseconds(),
+		ProbedAt:   started,
+	}
+
+	if probeErr != nil {
+		probe.Error = probeErr.Error()
+		if err := s.probeRepo.Upsert(probe); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to probe camera: %w", probeErr)
+	}
+
+	fillProbe(probe, result)
+
+	if apply {
+		probe.Applied = applyProbe(camera, probe, result)
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if len(probe.Applied) > 0 {
+			if err := s.cameraRepo.WithTx(tx).Update(camera.ID, camera); err != nil {
+				return err
+			}
+		}
+		return s.probeRepo.WithTx(tx).Upsert(probe)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return probe, nil
+}
+
+// GetLatest mengambil hasil probe terakhir camera
+func (s *cameraProbeService) GetLatest(cameraID string) (*models.CameraProbe, error) {
+	if _, err := s.cameraRepo.GetByID(cameraID); err != nil {
+		return nil, err
+	}
+
+	return s.probeRepo.GetByCamera(cameraID)
+}
+
+// fillProbe menyalin hasil DESCRIBE ke CameraProbe
+func fillProbe(probe *models.CameraProbe, result *rtsp.Result) {
+	probe.Server = result.Server
+	probe.SessionName = result.Session.Name
+	probe.SDP = result.SDP
+	probe.TrackCount = len(result.Session.Tracks)
+
+	for _, track := range result.Session.Tracks {
+		probe.Tracks = append(probe.Tracks, models.ProbeTrack{
+			Media:     track.Media,
+			Codec:     track.Codec,
+			Profile:   track.Profile,
+			ClockRate: track.ClockRate,
+			Channels:  track.Channels,
+			Width:     track.Width,
+			Height:    track.Height,
+			FPS:       track.FPS,
+			Control:   track.Control,
+		})
+	}
+
+	if video := result.Session.Video(); video != nil {
+		probe.VideoCodec = video.Codec
+		probe.VideoProfile = video.Profile
+		probe.Width = video.Width
+		probe.Height = video.Height
+		probe.FPS = video.FPS
+	}
+
+	if audio := result.Session.Audio(); audio != nil {
+		probe.AudioCodec = audio.Codec
+		probe.AudioSampleRate = audio.ClockRate
+	}
+}
+
+// applyProbe memperbarui field camera yang bisa dideteksi dan mengembalikan
+// field yang berubah
+func applyProbe(camera *models.Camera, probe *models.CameraProbe, result *rtsp.Result) map[string]string {
+	applied := map[string]string{}
+
+	if probe.Width > 0 && probe.Height > 0 {
+		resolution := fmt.Sprintf("%dx%d", probe.Width, probe.Height)
+		if camera.Resolution.String != resolution {
+			camera.Resolution = sql.NullString{String: resolution, Valid: true}
+			applied["resolution"] = resolution
+		}
+	}
+
+	if fps := int(math.Round(probe.FPS)); fps > 0 && camera.FPS != fps {
+		camera.FPS = fps
+		applied["fps"] = strconv.Itoa(fps)
+	}
+
+	vendor := detectManufacturer(result.Server, result.Session.Name, result.Session.Tool)
+	if vendor != "" && !strings.EqualFold(camera.Manufacturer.String, vendor) {
+		camera.Manufacturer = sql.NullString{String: vendor, Valid: true}
+		applied["manufacturer"] = vendor
+	}
+
+	return applied
+}
+
+// Penanda vendor yang muncul di header Server atau nama session SDP
+var manufacturerHints = []struct {
+	hint   string
+	vendor string
+}{
+	{"hikvision", "Hikvision"},
+	{"dahua", "Dahua"},
+	{"axis", "Axis"},
+	{"uniview", "Uniview"},
+	{"hanwha", "Hanwha"},
+	{"wisenet", "Hanwha"},
+	{"bosch", "Bosch"},
+	{"vivotek", "Vivotek"},
+	{"reolink", "Reolink"},
+	{"amcrest", "Amcrest"},
+	{"foscam", "Foscam"},
+	{"tp-link", "TP-Link"},
+	{"ezviz", "EZVIZ"},
+	{"milesight", "Milesight"},
+}
+
+// detectManufacturer menebak vendor dari identitas server RTSP. Kosong jika
+// tidak ada penanda yang dikenal (model tidak bisa dideteksi dari DESCRIBE).
+func detectManufacturer(values ...string) string {
+	for _, value := range values {
+		value = strings.ToLower(value)
+		for _, h := range manufacturerHints {
+			if strings.Contains(value, h.hint) {
+				return h.vendor
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/repository"
+)
+
+// ErrRevisionNotFound dikembalikan jika revision tidak ada untuk camera tersebut
+var ErrRevisionNotFound = repository.ErrRevisionNotFound
+
+// cameraSnapshot mengambil snapshot camera sebelum diubah, termasuk channel.
+// Channel dimuat ke salinan agar camera tidak dianggap mengubah channel.
+func cameraSnapshot(channelRepo repository.ChannelRepository, camera *models.Camera) (models.CameraSnapshot, error) {
+	current := *camera
+	if _, err := cameraChannels(channelRepo, &current); err != nil {
+		return nil, fmt.Errorf("failed to get camera channels: %w", err)
+	}
+	return models.NewCameraSnapshot(&current), nil
+}
+
+// recordRevision mencatat perubahan camera dibanding snapshot before di dalam
+// tx. Tidak ada yang dicatat jika field revision tidak berubah.
+func recordRevision(tx *sql.Tx, revisionRepo repository.RevisionRepository, camera *models.Camera, before models.CameraSnapshot, userID, action string) error {
+	revision := models.NewCameraRevision(camera, before, userID, action)
+	if revision == nil {
+		return nil
+	}
+	return revisionRepo.WithTx(tx).Create(revision)
+}
+
+// GetHistory mengambil riwayat perubahan camera (termasuk camera di trash),
+// yang terbaru lebih dulu
+func (s *cameraService) GetHistory(id string, page, pageSize int) ([]*models.CameraRevision, *models.PaginationMeta, error) {
+	if _, err := s.cameraRepo.GetByIDWithInactive(id); err != nil {
+		return nil, nil, fmt.Errorf("camera not found: %w", err)
+	}
+
+	return s.revisionRepo.GetByCamera(id, page, pageSize)
+}
+
+// GetRevertPlan mengambil revision beserta perubahan yang terjadi jika camera
+// dikembalikan ke revision tersebut
+func (s *cameraService) GetRevertPlan(id string, revisionID int64) (*models.CameraRevertPlan, error) {
+	camera, err := s.cameraRepo.GetByIDWithInactive(id)
+	if err != nil {
+		return nil, fmt.Errorf("camera not found: %w", err)
+	}
+
+	return s.revertPlan(camera, revisionID)
+}
+
+// revertPlan menghitung plan revert camera ke state setelah revisionID
+func (s *cameraService) revertPlan(camera *models.Camera, revisionID int64) (*models.CameraRevertPlan, error) {
+	revision, err := s.revisionRepo.GetByID(camera.ID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	later, err := s.revisionRepo.GetAfter(camera.ID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := cameraSnapshot(s.channelRepo, camera)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewCameraRevertPlan(revision, later, current), nil
+}
+
+// Revert mengembalikan camera ke state setelah revision lewat merge patch,
+// sehingga validasi, sinkronisasi stream dan pencatatan revision sama dengan
+// PATCH. Password RTSP tidak ikut dikembalikan.
+func (s *cameraService) Revert(ctx context.Context, id string, revisionID int64, version int, userID string) (*models.Camera, error) {
+	camera, err := s.cameraRepo.GetByIDWithInactive(id)
+	if err != nil {
+		return nil, fmt.Errorf("camera not found: %w", err)
+	}
+	if version > 0 && camera.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	plan, err := s.revertPlan(camera, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	patch := plan.Patch()
+	if len(patch) == 0 {
+		s.enrichCameraWithStreamURLs(camera)
+		return camera, nil
+	}
+
+	// Plan dihitung dari version ini, perubahan di antaranya ditolak sebagai konflik
+	return s.patchCamera(ctx, id, patch, camera.Version, userID, &revisionID)
+}
@@ -1,57 +1,198 @@
 package service
 
 import (
+	"bytes"
 	"cctv-monitoring-backend/internal/models"
 	"cctv-monitoring-backend/internal/repository"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"time"
+)
+
+// Custom errors untuk camera service
+var (
+	ErrCameraNotFound   = repository.ErrCameraNotFound
+	ErrVersionConflict  = repository.ErrCameraVersionConflict
+	ErrStreamNotStarted = errors.New("stream has not been started")
+	ErrChannelNotFound  = errors.New("channel not found")
 )
 
 type CameraService interface {
-	Create(req *models.CreateCameraRequest, userID string) (*models.Camera, error)
+	Create(ctx context.Context, req *models.CreateCameraRequest, userID string) (*models.Camera, error)
 	GetByID(id string) (*models.Camera, error)
-	GetAll(page, pageSize int) ([]*models.Camera, *models.PaginationMeta, error)
-	Update(id string, req *models.UpdateCameraRequest) (*models.Camera, error)
-	Delete(id string) error
+	GetAll(filter *models.CameraFilter) ([]*models.Camera, *models.PaginationMeta, error)
+	GetByCursor(filter *models.CameraFilter) ([]*models.Camera, *models.CursorMeta, error)
+	Update(ctx context.Context, id string, req *models.UpdateCameraRequest, version int, userID string) (*models.Camera, error)
+	Patch(ctx context.Context, id string, patch models.CameraPatch, version int, userID string) (*models.Camera, error)
+	Delete(ctx context.Context, id, userID string) error
 	GetByZone(zone string) ([]*models.Camera, error)
 	GetNearby(lat, lng, radius float64) ([]*models.Camera, error)
-	StartStream(id string) (*models.Camera, error)
-	StopStream(id string) error
+	StartStream(ctx context.Context, id string) (*models.Camera, error)
+	StopStream(ctx context.Context, id string) (*models.Camera, error)
+	WebRTCOffer(ctx context.Context, id string, channel *int, sdpOffer string) (*models.WebRTCAnswerResponse, error)
+	OpenHLS(ctx context.Context, id string, channel *int, mediaPath, viewerToken string) (*MediaResponse, error)
+	OpenSnapshot(ctx context.Context, id string, channel *int) (*MediaResponse, error)
+	GetStreamDeliveries(id string) ([]*models.StreamOutboxEntry, error)
+	RevealCredentials(cameras ...*models.Camera) error
+	Import(ctx context.Context, inputs []models.CameraImportInput, userID string, dryRun bool) (*models.CameraImportReport, error)
+	Export(filter *models.CameraFilter, format string) (*CameraExport, error)
+	Bulk(ctx context.Context, req *models.BulkCameraRequest, userID string) (*models.BulkCameraReport, error)
+	GetTrash(page, pageSize int) ([]*models.Camera, *models.PaginationMeta, error)
+	Restore(ctx context.Context, id, userID string) (*models.Camera, error)
+	Purge(id, userID string) error
+	PurgeTrash() (int, error)
+	GetHistory(id string, page, pageSize int) ([]*models.CameraRevision, *models.PaginationMeta, error)
+	GetRevertPlan(id string, revisionID int64) (*models.CameraRevertPlan, error)
+	Revert(ctx context.Context, id string, revisionID int64, version int, userID string) (*models.Camera, error)
 }
 
 type cameraService struct {
-	cameraRepo  repository.CameraRepository
-	rtspService RTSPService
+	cameraRepo   repository.CameraRepository
+	channelRepo  repository.ChannelRepository
+	mediaServers MediaServerService
+	viewerTokens ViewerTokenService
+	outbox       StreamOutboxService
+	credentials  CredentialService
+	transactor   repository.Transactor
+	playback     PlaybackConfig
+	activityRepo repository.ActivityRepository
+	revisionRepo repository.RevisionRepository
+	locationRepo repository.LocationRepository
+	// trashRetention adalah lama camera di trash sebelum di-purge, 0 = tidak pernah
+	trashRetention time.Duration
 }
 
-func NewCameraService(cameraRepo repository.CameraRepository, rtspService RTSPService) CameraService {
+func NewCameraService(cameraRepo repository.CameraRepository, channelRepo repository.ChannelRepository, mediaServers MediaServerService, viewerTokens ViewerTokenService, outbox StreamOutboxService, credentials CredentialService, transactor repository.Transactor, playback PlaybackConfig, activityRepo repository.ActivityRepository, revisionRepo repository.RevisionRepository, locationRepo repository.LocationRepository, trashRetention time.Duration) CameraService {
 	return &cameraService{
-		cameraRepo:  cameraRepo,
-		rtspService: rtspService,
+		cameraRepo:     cameraRepo,
+		channelRepo:    channelRepo,
+		mediaServers:   mediaServers,
+		viewerTokens:   viewerTokens,
+		outbox:         outbox,
+		credentials:    credentials,
+		transactor:     transactor,
+		playback:       playback,
+		activityRepo:   activityRepo,
+		revisionRepo:   revisionRepo,
+		locationRepo:   locationRepo,
+		trashRetention: trashRetention,
+	}
+}
+
+// loadChannels mengisi Channels untuk cameras yang belum punya, dalam satu query
+func (s *cameraService) loadChannels(cameras []*models.Camera) {
+	ids := make([]string, 0, len(cameras))
+	for _, camera := range cameras {
+		if camera.Channels == nil {
+			ids = append(ids, camera.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	channelsByCamera, err := s.channelRepo.GetByCameraIDs(ids)
+	if err != nil {
+		log.Printf("Error loading camera channels: %v", err)
+		channelsByCamera = map[string][]models.CameraChannel{}
+	}
+
+	for _, camera := range cameras {
+		if camera.Channels != nil {
+			continue
+		}
+		if channels := channelsByCamera[camera.ID]; len(channels) > 0 {
+			camera.Channels = channels
+		} else {
+			camera.Channels = []models.CameraChannel{defaultChannel(camera.RTSPUrl)}
+		}
 	}
 }
 
 // enrichCameraWithStreamURLs menambahkan stream URLs ke camera
 func (s *cameraService) enrichCameraWithStreamURLs(camera *models.Camera) {
-	if camera.StreamID.Valid && camera.StreamID.String != "" {
-		streamID := camera.StreamID.String
-		camera.HLSUrl = s.rtspService.GetHLSURL(streamID)
-		camera.SnapshotUrl = s.rtspService.GetSnapshotURL(streamID)
-	}
+	s.enrichCamerasWithStreamURLs([]*models.Camera{camera})
 }
 
-// enrichCamerasWithStreamURLs menambahkan stream URLs ke array cameras
+// enrichCamerasWithStreamURLs menambahkan stream URLs per channel ke array cameras.
+// URL di level camera selalu menunjuk ke channel MAIN.
 func (s *cameraService) enrichCamerasWithStreamURLs(cameras []*models.Camera) {
+	s.loadChannels(cameras)
+
 	for _, camera := range cameras {
-		s.enrichCameraWithStreamURLs(camera)
+		if !camera.StreamID.Valid || camera.StreamID.String == "" {
+			continue
+		}
+
+		streamID := camera.StreamID.String
+
+		// Viewer token camera-scoped, berlaku untuk semua channel
+		token, err := s.viewerTokens.Mint(camera.ID, streamID)
+		if err != nil {
+			log.Printf("Error minting viewer token for camera %s: %v", camera.ID, err)
+		}
+
+		for i := range camera.Channels {
+			channel := &camera.Channels[i]
+			if s.playback.ProxyEnabled {
+				channel.HLSUrl = s.playback.proxyHLSURL(camera.ID, channel.Index)
+				channel.SnapshotUrl = s.playback.proxySnapshotURL(camera.ID, channel.Index)
+			} else if client, err := s.mediaServers.ClientForCamera(camera); err == nil {
+				// URL langsung ke public URL node tempat stream berjalan
+				channel.HLSUrl = client.GetHLSURL(streamID, channel.Index)
+				channel.SnapshotUrl = client.GetSnapshotURL(streamID, channel.Index)
+			}
+			channel.HLSUrl = withToken(channel.HLSUrl, token)
+			channel.SnapshotUrl = withToken(channel.SnapshotUrl, token)
+			channel.WebRTCUrl = webRTCSignalingURL(camera.ID, channel.Index)
+		}
+
+		if main := mainChannel(camera.Channels); main != nil {
+			camera.HLSUrl = main.HLSUrl
+			camera.SnapshotUrl = main.SnapshotUrl
+			camera.WebRTCUrl = main.WebRTCUrl
+		}
+	}
+}
+
+func (s *cameraService) Create(ctx context.Context, req *models.CreateCameraRequest, userID string) (*models.Camera, error) {
+	camera, channels, err := s.newCamera(req, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Camera, channels dan operasi ADD stream disimpan dalam satu transaksi
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		return s.insertCamera(tx, camera, channels, userID, models.RevisionActionCreate)
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	// Add stream to RTSPtoWeb
+	return s.dispatch(ctx, camera.ID, models.OutboxOpAdd)
 }
 
-func (s *cameraService) Create(req *models.CreateCameraRequest, userID string) (*models.Camera, error) {
+// newCamera membangun camera dan channels dari request create. Credential
+// sudah dipisahkan dari URL dan dienkripsi.
+func (s *cameraService) newCamera(req *models.CreateCameraRequest, userID string) (*models.Camera, []models.CameraChannel, error) {
+	channels, err := buildChannels(req.Channels, req.RTSPUrl)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// rtsp_url camera selalu sama dengan URL channel MAIN
+	rtspURL := mainChannel(channels).RTSPUrl
+
 	camera := &models.Camera{
 		Name:         req.Name,
 		Description:  sql.NullString{String: req.Description, Valid: req.Description != ""},
-		RTSPUrl:      req.RTSPUrl,
+		RTSPUrl:      rtspURL,
 		Latitude:     req.Latitude,
 		Longitude:    req.Longitude,
 		Building:     sql.NullString{String: req.Building, Valid: req.Building != ""},
@@ -66,33 +207,51 @@ func (s *cameraService) Create(req *models.CreateCameraRequest, userID string) (
 		Status:       req.Status,
 		IsActive:     true,
 		CreatedBy:    sql.NullString{String: userID, Valid: true},
+		SyncStatus:   models.SyncStatusSynced,
+
+		StreamOptions: req.StreamOptions.Apply(models.DefaultStreamOptions()),
 	}
 
-	// Create camera in database
-	if err := s.cameraRepo.Create(camera, userID); err != nil {
-		return nil, fmt.Errorf("failed to create camera: %w", err)
+	if err := s.resolveLocation(camera, req.ZoneID); err != nil {
+		return nil, nil, err
 	}
 
-	// Add stream to RTSPtoWeb
-	streamID, hlsURL, snapshotURL, err := s.rtspService.AddStream(
-		camera.ID,
-		camera.Name,
-		camera.RTSPUrl,
-	)
+	// Credential dipisahkan dari URL dan dienkripsi sebelum disimpan
+	if _, err := s.credentials.Seal(camera, channels, req.RTSPCredentialsRequest); err != nil {
+		return nil, nil, err
+	}
+
+	return camera, channels, nil
+}
 
-	if err == nil {
-		camera.StreamID = sql.NullString{String: streamID, Valid: true}
-		camera.HLSUrl = hlsURL
-		camera.SnapshotUrl = snapshotURL
+// insertCamera menyimpan camera baru beserta channels, mencatat revision
+// pertama dan operasi ADD stream ke outbox di dalam tx
+func (s *cameraService) insertCamera(tx *sql.Tx, camera *models.Camera, channels []models.CameraChannel, userID, action string) error {
+	cameraRepo := s.cameraRepo.WithTx(tx)
 
-		// Update camera dengan stream info
-		s.cameraRepo.Update(camera.ID, camera)
+	// Create camera in database
+	if err := cameraRepo.Create(camera, userID); err != nil {
+		return fmt.Errorf("failed to create camera: %w", err)
 	}
 
-	// Enrich dengan stream URLs
-	s.enrichCameraWithStreamURLs(camera)
+	// Simpan channels
+	if err := s.channelRepo.WithTx(tx).ReplaceForCamera(camera.ID, channels); err != nil {
+		return fmt.Errorf("failed to create camera channels: %w", err)
+	}
 
-	return camera, nil
+	// Stream ID RTSPtoWeb sama dengan ID camera
+	camera.StreamID = sql.NullString{String: camera.ID, Valid: true}
+	if err := cameraRepo.Update(camera.ID, camera); err != nil {
+		return fmt.Errorf("failed to update camera: %w", err)
+	}
+
+	created := *camera
+	created.Channels = channels
+	if err := recordRevision(tx, s.revisionRepo, &created, nil, userID, action); err != nil {
+		return err
+	}
+
+	return s.outbox.Enqueue(tx, camera, models.OutboxOpAdd)
 }
 
 func (s *cameraService) GetByID(id string) (*models.Camera, error) {
@@ -107,33 +266,101 @@ func (s *cameraService) GetByID(id string) (*models.Camera, error) {
 	return camera, nil
 }
 
-func (s *cameraService) GetAll(page, pageSize int) ([]*models.Camera, *models.PaginationMeta, error) {
-	cameras, meta, err := s.cameraRepo.GetAll(page, pageSize)
+func (s *cameraService) GetAll(filter *models.CameraFilter) ([]*models.Camera, *models.PaginationMeta, error) {
+	cameras, meta, err := s.cameraRepo.GetAll(filter)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get cameras: %w", err)
 	}
 
-	// Enrich semua cameras dengan stream URLs
-	s.enrichCamerasWithStreamURLs(cameras)
+	// Enrich semua cameras dengan stream URLs (dilewati jika fields tidak memintanya)
+	if filter.NeedsStreamURLs() {
+		s.enrichCamerasWithStreamURLs(cameras)
+	}
 
 	return cameras, meta, nil
 }
 
-func (s *cameraService) Update(id string, req *models.UpdateCameraRequest) (*models.Camera, error) {
+// GetByCursor mengambil camera dengan keyset pagination, untuk dashboard yang
+// polling list camera
+func (s *cameraService) GetByCursor(filter *models.CameraFilter) ([]*models.Camera, *models.CursorMeta, error) {
+	cameras, meta, err := s.cameraRepo.GetByCursor(filter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get cameras: %w", err)
+	}
+
+	if filter.NeedsStreamURLs() {
+		s.enrichCamerasWithStreamURLs(cameras)
+	}
+
+	return cameras, meta, nil
+}
+
+// Update mengubah field camera yang diisi di request. version > 0 (dari
+// If-Match) harus sama dengan version camera saat ini.
+func (s *cameraService) Update(ctx context.Context, id string, req *models.UpdateCameraRequest, version int, userID string) (*models.Camera, error) {
 	camera, err := s.cameraRepo.GetByID(id)
 	if err != nil {
 		return nil, fmt.Errorf("camera not found: %w", err)
 	}
+	if version > 0 && camera.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	before, err := cameraSnapshot(s.channelRepo, camera)
+	if err != nil {
+		return nil, err
+	}
+
+	// streamChanged menandai perubahan yang harus diteruskan ke RTSPtoWeb
+	streamChanged := false
 
 	// Update fields
-	if req.Name != "" {
+	if req.Name != "" && req.Name != camera.Name {
 		camera.Name = req.Name
+		streamChanged = true
 	}
 	if req.Description != "" {
 		camera.Description = sql.NullString{String: req.Description, Valid: true}
 	}
-	if req.RTSPUrl != "" {
-		camera.RTSPUrl = req.RTSPUrl
+	if len(req.Channels) > 0 {
+		channels, err := buildChannels(req.Channels, "")
+		if err != nil {
+			return nil, err
+		}
+		current, err := cameraChannels(s.channelRepo, camera)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get camera channels: %w", err)
+		}
+		credentialsChanged, err := s.credentials.Seal(camera, channels, req.RTSPCredentialsRequest)
+		if err != nil {
+			return nil, err
+		}
+		streamChanged = streamChanged || credentialsChanged || channelsChanged(current, channels)
+		camera.Channels = channels
+	} else if req.RTSPUrl != "" {
+		// rtsp_url ikut mengubah URL channel MAIN. Dibandingkan setelah
+		// credential dipisahkan, karena URL tersimpan tidak berisi credential.
+		channels, err := cameraChannels(s.channelRepo, camera)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get camera channels: %w", err)
+		}
+		previousURL := camera.RTSPUrl
+		mainChannel(channels).RTSPUrl = req.RTSPUrl
+		credentialsChanged, err := s.credentials.Seal(camera, channels, req.RTSPCredentialsRequest)
+		if err != nil {
+			return nil, err
+		}
+		streamChanged = streamChanged || credentialsChanged || camera.RTSPUrl != previousURL
+	} else {
+		credentialsChanged, err := s.credentials.Seal(camera, nil, req.RTSPCredentialsRequest)
+		if err != nil {
+			return nil, err
+		}
+		streamChanged = streamChanged || credentialsChanged
+	}
+	if opts := req.StreamOptions.Apply(camera.StreamOptions); opts != camera.StreamOptions {
+		camera.StreamOptions = opts
+		streamChanged = true
 	}
 	if req.Latitude != 0 {
 		camera.Latitude = req.Latitude
@@ -147,6 +374,11 @@ func (s *cameraService) Update(id string, req *models.UpdateCameraRequest) (*mod
 	if req.Zone != "" {
 		camera.Zone = sql.NullString{String: req.Zone, Valid: true}
 	}
+	if req.ZoneID != "" || req.Building != "" || req.Zone != "" {
+		if err := s.resolveLocation(camera, req.ZoneID); err != nil {
+			return nil, err
+		}
+	}
 	if req.IPAddress != "" {
 		camera.IPAddress = sql.NullString{String: req.IPAddress, Valid: true}
 	}
@@ -172,8 +404,38 @@ func (s *cameraService) Update(id string, req *models.UpdateCameraRequest) (*mod
 		camera.Status = req.Status
 	}
 
-	if err := s.cameraRepo.Update(id, camera); err != nil {
-		return nil, fmt.Errorf("failed to update camera: %w", err)
+	// Perubahan yang mempengaruhi stream yang sedang berjalan dicatat ke outbox
+	// dalam transaksi yang sama
+	enqueueEdit := streamChanged && camera.StreamID.Valid && camera.StreamID.String != ""
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.cameraRepo.WithTx(tx).Update(id, camera); err != nil {
+			return fmt.Errorf("failed to update camera: %w", err)
+		}
+
+		// Channels hanya diisi jika ada perubahan channel
+		if camera.Channels != nil {
+			if err := s.channelRepo.WithTx(tx).ReplaceForCamera(id, camera.Channels); err != nil {
+				return fmt.Errorf("failed to update camera channels: %w", err)
+			}
+		}
+
+		if err := recordRevision(tx, s.revisionRepo, camera, before, userID, models.RevisionActionUpdate); err != nil {
+			return err
+		}
+
+		if enqueueEdit {
+			return s.outbox.Enqueue(tx, camera, models.OutboxOpEdit)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Teruskan perubahan ke RTSPtoWeb jika stream sedang berjalan
+	if enqueueEdit {
+		return s.dispatch(ctx, id, models.OutboxOpEdit)
 	}
 
 	// Enrich dengan stream URLs
@@ -182,19 +444,47 @@ func (s *cameraService) Update(id string, req *models.UpdateCameraRequest) (*mod
 	return camera, nil
 }
 
-func (s *cameraService) Delete(id string) error {
+// Delete memindahkan camera ke trash dan menghapus stream-nya dari RTSPtoWeb.
+// Camera bisa di-restore sampai di-purge.
+func (s *cameraService) Delete(ctx context.Context, id, userID string) error {
 	camera, err := s.cameraRepo.GetByID(id)
 	if err != nil {
 		return fmt.Errorf("camera not found: %w", err)
 	}
 
-	// Stop stream jika ada
-	if camera.StreamID.Valid {
-		s.rtspService.RemoveStream(camera.StreamID.String)
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.cameraRepo.WithTx(tx).Delete(id, userID); err != nil {
+			return fmt.Errorf("failed to delete camera: %w", err)
+		}
+
+		activity := models.NewCameraActivity(models.ActivityCameraDeleted, camera, userID, nil)
+		if err := s.activityRepo.WithTx(tx).Create(activity); err != nil {
+			return err
+		}
+
+		// Delete menaikkan version camera
+		deleted := *camera
+		deleted.IsActive = false
+		deleted.Version++
+		if err := recordRevision(tx, s.revisionRepo, &deleted, models.NewCameraSnapshot(camera), userID, models.RevisionActionDelete); err != nil {
+			return err
+		}
+
+		// Stop stream jika ada
+		if camera.StreamID.Valid {
+			return s.outbox.Enqueue(tx, camera, models.OutboxOpRemove)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	if err := s.cameraRepo.Delete(id); err != nil {
-		return fmt.Errorf("failed to delete camera: %w", err)
+	// Gagal menghapus stream tidak menggagalkan delete, worker akan mencoba lagi
+	if camera.StreamID.Valid {
+		if err := s.outbox.DeliverCamera(ctx, id); err != nil {
+			log.Printf("Stream removal of deleted camera %s queued for retry: %v", id, err)
+		}
 	}
 
 	return nil
@@ -224,32 +514,89 @@ func (s *cameraService) GetNearby(lat, lng, radius float64) ([]*models.Camera, e
 	return cameras, nil
 }
 
-func (s *cameraService) StartStream(id string) (*models.Camera, error) {
+func (s *cameraService) StartStream(ctx context.Context, id string) (*models.Camera, error) {
 	camera, err := s.cameraRepo.GetByID(id)
 	if err != nil {
 		return nil, fmt.Errorf("camera not found: %w", err)
 	}
 
-	// Add stream ke RTSPtoWeb jika belum ada
-	if !camera.StreamID.Valid || camera.StreamID.String == "" {
-		streamID, hlsURL, snapshotURL, err := s.rtspService.AddStream(
-			camera.ID,
-			camera.Name,
-			camera.RTSPUrl,
-		)
+	// Stream sudah berjalan (atau ADD masih menunggu di outbox)
+	if camera.StreamID.Valid && camera.StreamID.String != "" {
+		s.enrichCameraWithStreamURLs(camera)
+		return camera, nil
+	}
 
-		if err != nil {
-			return nil, fmt.Errorf("failed to start stream: %w", err)
+	// Add stream ke RTSPtoWeb lewat outbox
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		camera.StreamID = sql.NullString{String: camera.ID, Valid: true}
+		camera.MediaServerID = sql.NullString{}
+		// Status ditentukan stream monitor setelah RTSPtoWeb menghubungi kamera
+		camera.Status = models.CameraStatusUnknown
+
+		if err := s.cameraRepo.WithTx(tx).Update(id, camera); err != nil {
+			return fmt.Errorf("failed to update camera: %w", err)
 		}
 
-		camera.StreamID = sql.NullString{String: streamID, Valid: true}
-		camera.HLSUrl = hlsURL
-		camera.SnapshotUrl = snapshotURL
-		camera.Status = "READY"
+		return s.outbox.Enqueue(tx, camera, models.OutboxOpAdd)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dispatch(ctx, id, models.OutboxOpAdd)
+}
 
-		if err := s.cameraRepo.Update(id, camera); err != nil {
-			return nil, fmt.Errorf("failed to update camera: %w", err)
+func (s *cameraService) StopStream(ctx context.Context, id string) (*models.Camera, error) {
+	camera, err := s.cameraRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("camera not found: %w", err)
+	}
+
+	if !camera.StreamID.Valid {
+		return camera, nil
+	}
+
+	// REMOVE dicatat dengan stream_id dan node saat ini, lalu camera dikosongkan
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.outbox.Enqueue(tx, camera, models.OutboxOpRemove); err != nil {
+			return err
+		}
+
+		camera.StreamID = sql.NullString{Valid: false}
+		camera.MediaServerID = sql.NullString{Valid: false}
+		camera.Status = models.CameraStatusOffline
+
+		if err := s.cameraRepo.WithTx(tx).Update(id, camera); err != nil {
+			return fmt.Errorf("failed to update camera: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.dispatch(ctx, id, models.OutboxOpRemove)
+}
+
+// dispatch langsung mengirim operasi outbox camera setelah transaksi commit dan
+// mengembalikan camera terbaru beserta hasil pengirimannya. Jika RTSPtoWeb gagal,
+// operasi tetap di outbox dan dikirim ulang oleh worker.
+func (s *cameraService) dispatch(ctx context.Context, id, operation string) (*models.Camera, error) {
+	deliverErr := s.outbox.DeliverCamera(ctx, id)
+
+	camera, err := s.cameraRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("camera not found: %w", err)
+	}
+
+	camera.StreamSync = &models.StreamSyncResult{
+		Action:  operation,
+		Applied: deliverErr == nil,
+	}
+	if deliverErr != nil {
+		log.Printf("Stream %s of camera %s queued for retry: %v", operation, id, deliverErr)
+		camera.StreamSync.Error = deliverErr.Error()
+		camera.StreamSync.Queued = camera.SyncStatus == models.SyncStatusPending
 	}
 
 	// Enrich dengan stream URLs
@@ -258,24 +605,172 @@ func (s *cameraService) StartStream(id string) (*models.Camera, error) {
 	return camera, nil
 }
 
-func (s *cameraService) StopStream(id string) error {
+// GetStreamDeliveries mengambil riwayat pengiriman operasi stream camera ke RTSPtoWeb
+func (s *cameraService) GetStreamDeliveries(id string) ([]*models.StreamOutboxEntry, error) {
+	if _, err := s.cameraRepo.GetByID(id); err != nil {
+		return nil, fmt.Errorf("camera not found: %w", err)
+	}
+
+	return s.outbox.GetByCamera(id)
+}
+
+// RevealCredentials mendekripsi password RTSP cameras agar rtsp_url di response
+// tidak disamarkan. Hanya untuk caller dengan permission camera:credentials.
+func (s *cameraService) RevealCredentials(cameras ...*models.Camera) error {
+	var firstErr error
+	for _, camera := range cameras {
+		// Camera yang gagal didekripsi tetap disamarkan, sisanya tetap ditampilkan
+		if err := s.credentials.Reveal(camera); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// webRTCSignalingURL adalah endpoint backend tempat browser mengirim SDP offer
+func webRTCSignalingURL(cameraID string, channel int) string {
+	return fmt.Sprintf("/api/v1/cameras/%s/webrtc?channel=%d", cameraID, channel)
+}
+
+// streamingChannel mengambil camera yang stream-nya sudah berjalan beserta channel
+// yang diminta (default channel MAIN)
+func (s *cameraService) streamingChannel(id string, channel *int) (*models.Camera, *models.CameraChannel, error) {
 	camera, err := s.cameraRepo.GetByID(id)
 	if err != nil {
-		return fmt.Errorf("camera not found: %w", err)
+		return nil, nil, ErrCameraNotFound
 	}
 
-	if camera.StreamID.Valid {
-		if err := s.rtspService.RemoveStream(camera.StreamID.String); err != nil {
-			return fmt.Errorf("failed to stop stream: %w", err)
-		}
+	if !camera.StreamID.Valid || camera.StreamID.String == "" {
+		return nil, nil, ErrStreamNotStarted
+	}
 
-		camera.StreamID = sql.NullString{Valid: false}
-		camera.Status = "OFFLINE"
+	channels, err := cameraChannels(s.channelRepo, camera)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get camera channels: %w", err)
+	}
 
-		if err := s.cameraRepo.Update(id, camera); err != nil {
-			return fmt.Errorf("failed to update camera: %w", err)
+	if channel == nil {
+		return camera, mainChannel(channels), nil
+	}
+
+	for i := range channels {
+		if channels[i].Index == *channel {
+			return camera, &channels[i], nil
 		}
 	}
 
-	return nil
+	return nil, nil, ErrChannelNotFound
+}
+
+// WebRTCOffer meneruskan SDP offer ke RTSPtoWeb untuk channel camera yang diminta
+// (default channel MAIN) dan mengembalikan SDP answer-nya
+func (s *cameraService) WebRTCOffer(ctx context.Context, id string, channel *int, sdpOffer string) (*models.WebRTCAnswerResponse, error) {
+	camera, target, err := s.streamingChannel(id, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.viewerTokens.Mint(camera.ID, camera.StreamID.String)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.mediaServers.ClientForCamera(camera)
+	if err != nil {
+		return nil, err
+	}
+
+	answer, err := client.WebRTCOffer(ctx, camera.StreamID.String, target.Index, sdpOffer, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to negotiate WebRTC: %w", err)
+	}
+
+	return &models.WebRTCAnswerResponse{
+		Type:    "answer",
+		SDP:     answer,
+		Channel: target.Index,
+	}, nil
+}
+
+// OpenHLS membuka playlist atau segment HLS dari RTSPtoWeb untuk proxy.
+// Playlist di-rewrite agar semua URI menunjuk kembali ke proxy backend,
+// segment diteruskan sebagai stream tanpa di-buffer. viewerToken (jika client
+// memakai viewer token) ikut ditempelkan ke URI hasil rewrite.
+func (s *cameraService) OpenHLS(ctx context.Context, id string, channel *int, mediaPath, viewerToken string) (*MediaResponse, error) {
+	mediaPath, err := cleanMediaPath(mediaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	camera, target, err := s.streamingChannel(id, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	upstreamToken, err := s.viewerTokens.Mint(camera.ID, camera.StreamID.String)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.mediaServers.ClientForCamera(camera)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.FetchMedia(ctx, camera.StreamID.String, target.Index, "hls/"+mediaPath, upstreamToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch HLS media: %w", err)
+	}
+
+	media := newMediaResponse(resp)
+	if resp.StatusCode != 200 || !isPlaylist(mediaPath, media.ContentType) {
+		return media, nil
+	}
+
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HLS playlist: %w", err)
+	}
+
+	upstreamPrefix := fmt.Sprintf("/stream/%s/channel/%d/hls/", camera.StreamID.String, target.Index)
+	proxyPrefix := s.playback.proxyBaseURL(camera.ID) + "/hls/"
+	query := fmt.Sprintf("channel=%d", target.Index)
+	if viewerToken != "" {
+		query += "&token=" + url.QueryEscape(viewerToken)
+	}
+
+	body = rewritePlaylist(body, upstreamPrefix, mediaPath, proxyPrefix, query)
+	media.Body = io.NopCloser(bytes.NewReader(body))
+	media.ContentLength = int64(len(body))
+	if media.ContentType == "" {
+		media.ContentType = "application/vnd.apple.mpegurl"
+	}
+
+	return media, nil
+}
+
+// OpenSnapshot membuka snapshot JPEG dari RTSPtoWeb untuk proxy
+func (s *cameraService) OpenSnapshot(ctx context.Context, id string, channel *int) (*MediaResponse, error) {
+	camera, target, err := s.streamingChannel(id, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	upstreamToken, err := s.viewerTokens.Mint(camera.ID, camera.StreamID.String)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.mediaServers.ClientForCamera(camera)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.FetchMedia(ctx, camera.StreamID.String, target.Index, "jpeg", upstreamToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch snapshot: %w", err)
+	}
+
+	return newMediaResponse(resp), nil
 }
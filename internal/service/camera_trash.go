@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/repository"
+)
+
+// ErrStreamRemovalPending dikembalikan jika camera belum bisa dihapus permanen
+var ErrStreamRemovalPending = repository.ErrStreamRemovalPending
+
+// trashPurgeBatch adalah jumlah camera yang di-purge per transaksi
+const trashPurgeBatch = 100
+
+// GetTrash mengambil camera di trash beserta waktu purge-nya
+func (s *cameraService) GetTrash(page, pageSize int) ([]*models.Camera, *models.PaginationMeta, error) {
+	cameras, meta, err := s.cameraRepo.GetTrash(page, pageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if s.trashRetention > 0 {
+		for _, camera := range cameras {
+			if camera.DeletedAt.Valid {
+				purgeAt := camera.DeletedAt.Time.Add(s.trashRetention)
+				camera.PurgeAt = &purgeAt
+			}
+		}
+	}
+
+	return cameras, meta, nil
+}
+
+// restoreCamera mengeluarkan camera dari trash. Stream yang sudah di-REMOVE
+// didaftarkan ulang, node dipilih ulang oleh outbox.
+func restoreCamera(camera *models.Camera) {
+	camera.IsActive = true
+	camera.DeletedAt = sql.NullTime{}
+	camera.DeletedBy = sql.NullString{}
+
+	if camera.StreamID.Valid && camera.StreamID.String != "" {
+		camera.MediaServerID = sql.NullString{}
+		// Status ditentukan stream monitor setelah RTSPtoWeb menghubungi kamera
+		camera.Status = models.CameraStatusUnknown
+	}
+}
+
+// Restore mengaktifkan kembali camera dari trash dan mendaftarkan ulang
+// stream-nya ke RTSPtoWeb
+func (s *cameraService) Restore(ctx context.Context, id, userID string) (*models.Camera, error) {
+	camera, err := s.cameraRepo.GetTrashedByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("camera not found: %w", err)
+	}
+
+	before := models.NewCameraSnapshot(camera)
+	restoreCamera(camera)
+	hasStream := camera.StreamID.Valid && camera.StreamID.String != ""
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.cameraRepo.WithTx(tx).Update(id, camera); err != nil {
+			return fmt.Errorf("failed to restore camera: %w", err)
+		}
+
+		activity := models.NewCameraActivity(models.ActivityCameraRestored, camera, userID, nil)
+		if err := s.activityRepo.WithTx(tx).Create(activity); err != nil {
+			return err
+		}
+
+		if err := recordRevision(tx, s.revisionRepo, camera, before, userID, models.RevisionActionRestore); err != nil {
+			return err
+		}
+
+		if hasStream {
+			return s.outbox.Enqueue(tx, camera, models.OutboxOpAdd)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if hasStream {
+		return s.dispatch(ctx, id, models.OutboxOpAdd)
+	}
+
+	camera, err = s.cameraRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("camera not found: %w", err)
+	}
+
+	// Enrich dengan stream URLs
+	s.enrichCameraWithStreamURLs(camera)
+
+	return camera, nil
+}
+
+// purgeActivity membuat activity log camera.purged. camera_id kosong karena
+// camera sudah tidak ada, ID dan data hapus disimpan di details.
+func purgeActivity(camera *models.Camera, userID, reason string) *models.ActivityLog {
+	details := map[string]interface{}{"reason": reason}
+	if camera.DeletedAt.Valid {
+		details["deleted_at"] = camera.DeletedAt.Time
+	}
+	if camera.DeletedBy.Valid {
+		details["deleted_by"] = camera.DeletedBy.String
+	}
+
+	activity := models.NewCameraActivity(models.ActivityCameraPurged, camera, userID, details)
+	activity.CameraID = ""
+	return activity
+}
+
+// Purge menghapus permanen camera di trash. Camera yang REMOVE stream-nya masih
+// menunggu di outbox ditolak dengan ErrStreamRemovalPending.
+func (s *cameraService) Purge(id, userID string) error {
+	camera, err := s.cameraRepo.GetTrashedByID(id)
+	if err != nil {
+		return fmt.Errorf("camera not found: %w", err)
+	}
+
+	return s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.cameraRepo.WithTx(tx).HardDelete(id); err != nil {
+			return err
+		}
+		return s.activityRepo.WithTx(tx).Create(purgeActivity(camera, userID, "manual"))
+	})
+}
+
+// PurgeTrash menghapus permanen camera yang sudah lebih lama dari retention di
+// trash dan mengembalikan jumlahnya. Camera dengan REMOVE stream yang masih
+// menunggu dilewati sampai run berikutnya.
+func (s *cameraService) PurgeTrash() (int, error) {
+	if s.trashRetention <= 0 {
+		return 0, nil
+	}
+
+	before := time.Now().Add(-s.trashRetention)
+	total := 0
+
+	for {
+		var purged []*models.Camera
+		err := s.transactor.WithinTx(func(tx *sql.Tx) error {
+			var err error
+			purged, err = s.cameraRepo.WithTx(tx).PurgeDeletedBefore(before, trashPurgeBatch)
+			if err != nil {
+				return err
+			}
+
+			activityRepo := s.activityRepo.WithTx(tx)
+			for _, camera := range purged {
+				if err := activityRepo.Create(purgeActivity(camera, "", "retention")); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+
+		total += len(purged)
+		for _, camera := range purged {
+			log.Printf("Purged camera %s (%s) from trash", camera.ID, camera.Name)
+		}
+
+		if len(purged) < trashPurgeBatch {
+			return total, nil
+		}
+	}
+}
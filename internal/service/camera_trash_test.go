@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"cctv-monitoring-backend/internal/models"
+)
+
+// trashedCamera mengembalikan camera di trash yang dihapus age lalu
+func trashedCamera(id string, age time.Duration, withStream bool) *models.Camera {
+	camera := &models.Camera{
+		ID:            id,
+		Name:          "Camera " + id,
+		Status:        models.CameraStatusOffline,
+		MediaServerID: sql.NullString{String: "server-1", Valid: true},
+		DeletedAt:     sql.NullTime{Time: time.Now().Add(-age), Valid: true},
+		DeletedBy:     sql.NullString{String: "user-1", Valid: true},
+	}
+	if withStream {
+		camera.StreamID = sql.NullString{String: id, Valid: true}
+	}
+	return camera
+}
+
+func newTrashService(cameraRepo *fakeCameraRepo, activityRepo *fakeActivityRepo, outbox *fakeOutbox, retention time.Duration) *cameraService {
+	return &cameraService{
+		cameraRepo:     cameraRepo,
+		channelRepo:    &fakeChannelRepo{},
+		viewerTokens:   NewViewerTokenService(false, "", time.Minute),
+		outbox:         outbox,
+		transactor:     fakeTransactor{},
+		activityRepo:   activityRepo,
+		revisionRepo:   &fakeRevisionRepo{},
+		playback:       PlaybackConfig{ProxyEnabled: true},
+		trashRetention: retention,
+	}
+}
+
+func TestRestoreReRegistersStream(t *testing.T) {
+	cameraRepo := &fakeCameraRepo{cameras: []*models.Camera{trashedCamera("cam-1", time.Hour, true)}}
+	activityRepo := &fakeActivityRepo{}
+	outbox := &fakeOutbox{}
+
+	camera, err := newTrashService(cameraRepo, activityRepo, outbox, 0).Restore(context.Background(), "cam-1", "user-2")
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	if !camera.IsActive || camera.DeletedAt.Valid || camera.DeletedBy.Valid {
+		t.Errorf("camera still in trash: active %v, deleted_at %v, deleted_by %v", camera.IsActive, camera.DeletedAt, camera.DeletedBy)
+	}
+	// Node dipilih ulang outbox, status ditentukan stream monitor
+	if camera.MediaServerID.Valid || camera.Status != models.CameraStatusUnknown {
+		t.Errorf("media_server_id = %v, status = %q, want empty and %q", camera.MediaServerID, camera.Status, models.CameraStatusUnknown)
+	}
+	if len(outbox.operations) != 1 || outbox.operations[0] != models.OutboxOpAdd+" cam-1" {
+		t.Errorf("outbox operations = %v, want [ADD cam-1]", outbox.operations)
+	}
+	if camera.StreamSync == nil || !camera.StreamSync.Applied {
+		t.Errorf("stream sync = %+v, want applied", camera.StreamSync)
+	}
+	if len(activityRepo.entries) != 1 || activityRepo.entries[0].Action != models.ActivityCameraRestored {
+		t.Errorf("activities = %+v, want one %s", activityRepo.entries, models.ActivityCameraRestored)
+	}
+}
+
+func TestRestoreWithoutStream(t *testing.T) {
+	cameraRepo := &fakeCameraRepo{cameras: []*models.Camera{trashedCamera("cam-1", time.Hour, false)}}
+	outbox := &fakeOutbox{}
+
+	camera, err := newTrashService(cameraRepo, &fakeActivityRepo{}, outbox, 0).Restore(context.Background(), "cam-1", "user-2")
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if len(outbox.operations) != 0 || camera.StreamSync != nil {
+		t.Errorf("outbox operations = %v, stream sync = %+v, want none", outbox.operations, camera.StreamSync)
+	}
+	if camera.Status != models.CameraStatusOffline {
+		t.Errorf("status = %q, want unchanged %q", camera.Status, models.CameraStatusOffline)
+	}
+}
+
+func TestRestoreActiveCameraNotFound(t *testing.T) {
+	camera := trashedCamera("cam-1", time.Hour, true)
+	camera.IsActive = true
+	cameraRepo := &fakeCameraRepo{cameras: []*models.Camera{camera}}
+
+	if _, err := newTrashService(cameraRepo, &fakeActivityRepo{}, &fakeOutbox{}, 0).Restore(context.Background(), "cam-1", ""); err == nil {
+		t.Fatal("Restore() of active camera succeeded")
+	}
+}
+
+func TestPurgeRecordsActivity(t *testing.T) {
+	cameraRepo := &fakeCameraRepo{cameras: []*models.Camera{trashedCamera("cam-1", time.Hour, true)}}
+	activityRepo := &fakeActivityRepo{}
+
+	if err := newTrashService(cameraRepo, activityRepo, &fakeOutbox{}, 0).Purge("cam-1", "user-2"); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+
+	if len(cameraRepo.cameras) != 0 {
+		t.Errorf("camera not deleted: %d left", len(cameraRepo.cameras))
+	}
+	if len(activityRepo.entries) != 1 {
+		t.Fatalf("activities = %d, want 1", len(activityRepo.entries))
+	}
+	activity := activityRepo.entries[0]
+	if activity.Action != models.ActivityCameraPurged || activity.CameraID != "" || activity.UserID != "user-2" {
+		t.Errorf("activity = %+v, want purged without camera_id by user-2", activity)
+	}
+	if activity.Details["reason"] != "manual" || activity.Details["deleted_by"] != "user-1" {
+		t.Errorf("activity details = %v", activity.Details)
+	}
+}
+
+func TestPurgePendingRemoval(t *testing.T) {
+	cameraRepo := &fakeCameraRepo{
+		cameras:        []*models.Camera{trashedCamera("cam-1", time.Hour, true)},
+		pendingRemoval: map[string]bool{"cam-1": true},
+	}
+	activityRepo := &fakeActivityRepo{}
+
+	err := newTrashService(cameraRepo, activityRepo, &fakeOutbox{}, 0).Purge("cam-1", "user-2")
+	if !errors.Is(err, ErrStreamRemovalPending) {
+		t.Fatalf("Purge() error = %v, want ErrStreamRemovalPending", err)
+	}
+	if len(activityRepo.entries) != 0 {
+		t.Errorf("activity recorded for failed purge: %+v", activityRepo.entries)
+	}
+}
+
+func TestPurgeTrashRetention(t *testing.T) {
+	cameras := []*models.Camera{trashedCamera("fresh", time.Hour, true), trashedCamera("pending", 48*time.Hour, true)}
+	for i := 0; i < trashPurgeBatch+1; i++ {
+		cameras = append(cameras, trashedCamera("old", 48*time.Hour, false))
+	}
+	cameraRepo := &fakeCameraRepo{cameras: cameras, pendingRemoval: map[string]bool{"pending": true}}
+	activityRepo := &fakeActivityRepo{}
+
+	// Lebih dari satu batch di-purge dalam satu run
+	purged, err := newTrashService(cameraRepo, activityRepo, &fakeOutbox{}, 24*time.Hour).PurgeTrash()
+	if err != nil {
+		t.Fatalf("PurgeTrash() error = %v", err)
+	}
+	if purged != trashPurgeBatch+1 || len(activityRepo.entries) != purged {
+		t.Errorf("purged = %d, activities = %d, want %d", purged, len(activityRepo.entries), trashPurgeBatch+1)
+	}
+	if activityRepo.entries[0].Details["reason"] != "retention" || activityRepo.entries[0].UserID != "" {
+		t.Errorf("activity = %+v, want system retention purge", activityRepo.entries[0])
+	}
+
+	remaining := map[string]bool{}
+	for _, camera := range cameraRepo.cameras {
+		remaining[camera.ID] = true
+	}
+	if len(remaining) != 2 || !remaining["fresh"] || !remaining["pending"] {
+		t.Errorf("remaining cameras = %v, want fresh and pending", remaining)
+	}
+}
+
+func TestPurgeTrashDisabled(t *testing.T) {
+	cameraRepo := &fakeCameraRepo{cameras: []*models.Camera{trashedCamera("cam-1", 365*24*time.Hour, false)}}
+
+	purged, err := newTrashService(cameraRepo, &fakeActivityRepo{}, &fakeOutbox{}, 0).PurgeTrash()
+	if err != nil || purged != 0 || len(cameraRepo.cameras) != 1 {
+		t.Errorf("PurgeTrash() = %d, %v, remaining %d, want nothing purged", purged, err, len(cameraRepo.cameras))
+	}
+}
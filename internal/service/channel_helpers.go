@@ -0,0 +1,126 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/repository"
+)
+
+// buildChannels mengubah channel dari request menjadi CameraChannel dengan index
+// berurutan. Jika request kosong, rtspURL dipakai sebagai channel utama.
+func buildChannels(reqs []models.CameraChannelRequest, rtspURL string) ([]models.CameraChannel, error) {
+	if len(reqs) == 0 {
+		if rtspURL == "" {
+			return nil, fmt.Errorf("camera requires an RTSP URL")
+		}
+		return []models.CameraChannel{defaultChannel(rtspURL)}, nil
+	}
+
+	channels := make([]models.CameraChannel, 0, len(reqs))
+	hasMain := false
+	for i, req := range reqs {
+		if req.RTSPUrl == "" {
+			return nil, fmt.Errorf("channel %d: rtsp_url is required", i)
+		}
+
+		role := strings.ToUpper(req.Role)
+		switch role {
+		case "":
+		case models.ChannelRoleMain:
+			if hasMain {
+				return nil, fmt.Errorf("channel %d: only one MAIN channel is allowed", i)
+			}
+			hasMain = true
+		case models.ChannelRoleSub:
+		default:
+			return nil, fmt.Errorf("channel %d: invalid role %q (must be MAIN or SUB)", i, req.Role)
+		}
+
+		channels = append(channels, models.CameraChannel{
+			Index:   i,
+			Label:   req.Label,
+			RTSPUrl: req.RTSPUrl,
+			Role:    role,
+		})
+	}
+
+	// Channel tanpa role: yang pertama jadi MAIN jika belum ada, sisanya SUB
+	for i := range channels {
+		if channels[i].Role == "" {
+			if !hasMain {
+				channels[i].Role = models.ChannelRoleMain
+				hasMain = true
+			} else {
+				channels[i].Role = models.ChannelRoleSub
+			}
+		}
+		if channels[i].Label == "" {
+			if channels[i].Role == models.ChannelRoleMain {
+				channels[i].Label = "Main"
+			} else {
+				channels[i].Label = fmt.Sprintf("Sub %d", i)
+			}
+		}
+	}
+
+	return channels, nil
+}
+
+// defaultChannel membuat channel utama 0 dari rtsp_url camera
+func defaultChannel(rtspURL string) models.CameraChannel {
+	return models.CameraChannel{
+		Index:   0,
+		Label:   "Main",
+		RTSPUrl: rtspURL,
+		Role:    models.ChannelRoleMain,
+	}
+}
+
+// mainChannel mengembalikan channel dengan role MAIN, atau channel pertama
+func mainChannel(channels []models.CameraChannel) *models.CameraChannel {
+	for i := range channels {
+		if channels[i].Role == models.ChannelRoleMain {
+			return &channels[i]
+		}
+	}
+	if len(channels) > 0 {
+		return &channels[0]
+	}
+	return nil
+}
+
+// cameraChannels mengambil channel camera dari database, dengan fallback ke
+// rtsp_url camera untuk data lama yang belum punya baris camera_channels
+func cameraChannels(channelRepo repository.ChannelRepository, camera *models.Camera) ([]models.CameraChannel, error) {
+	if camera.Channels != nil {
+		return camera.Channels, nil
+	}
+
+	channels, err := channelRepo.GetByCameraID(camera.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(channels) == 0 {
+		channels = []models.CameraChannel{defaultChannel(camera.RTSPUrl)}
+	}
+
+	camera.Channels = channels
+	return channels, nil
+}
+
+// channelsChanged mengecek apakah perubahan channel mempengaruhi stream di
+// RTSPtoWeb (index atau URL berubah). Label dan role hanya metadata backend.
+func channelsChanged(current, updated []models.CameraChannel) bool {
+	if len(current) != len(updated) {
+		return true
+	}
+	for i := range current {
+		if current[i].Index != updated[i].Index || current[i].RTSPUrl != updated[i].RTSPUrl {
+			return true
+		}
+	}
+	return false
+}
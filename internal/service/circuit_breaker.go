@@ -0,0 +1,109 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// Circuit breaker states
+const (
+	breakerClosed   = "CLOSED"
+	breakerOpen     = "OPEN"
+	breakerHalfOpen = "HALF_OPEN"
+)
+
+// circuitBreaker menghentikan request ke media server yang sedang down.
+// Setelah threshold kegagalan berturut-turut breaker terbuka dan semua request
+// langsung ditolak selama cooldown. Setelah cooldown satu request percobaan
+// diizinkan (half-open): sukses menutup breaker, gagal membukanya lagi.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     breakerClosed,
+	}
+}
+
+// Allow mengecek apakah request boleh dikirim
+func (b *circuitBreaker) Allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.probing = true
+		return true
+	case breakerHalfOpen:
+		// Hanya satu request percobaan dalam satu waktu
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Success mencatat request yang berhasil dan menutup breaker
+func (b *circuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = breakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure mencatat kegagalan; breaker terbuka setelah threshold tercapai
+// atau jika request percobaan (half-open) gagal
+func (b *circuitBreaker) Failure() {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+	b.probing = false
+}
+
+// Release melepas slot percobaan half-open tanpa mengubah state, dipakai jika
+// request dibatalkan caller sehingga kondisi server tidak diketahui
+func (b *circuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// State mengembalikan state breaker saat ini
+func (b *circuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
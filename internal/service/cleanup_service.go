@@ -9,17 +9,20 @@ import (
 
 // CleanupService handles periodic cleanup tasks
 type CleanupService struct {
-	tokenRepo repository.TokenRepository
+	tokenRepo     repository.TokenRepository
+	cameraService CameraService
 }
 
 // NewCleanupService creates a new cleanup service
-func NewCleanupService(tokenRepo repository.TokenRepository) *CleanupService {
+func NewCleanupService(tokenRepo repository.TokenRepository, cameraService CameraService) *CleanupService {
 	return &CleanupService{
-		tokenRepo: tokenRepo,
+		tokenRepo:     tokenRepo,
+		cameraService: cameraService,
 	}
 }
 
-// StartCleanupJob runs periodic cleanup of expired tokens
+// StartCleanupJob runs periodic cleanup of expired tokens and purges cameras
+// past the trash retention
 func (s *CleanupService) StartCleanupJob(interval time.Duration) {
 	ticker := time.NewTicker(interval)
 
@@ -29,6 +32,13 @@ func (s *CleanupService) StartCleanupJob(interval time.Duration) {
 			if err := s.tokenRepo.CleanupExpiredTokens(); err != nil {
 				log.Printf("Error cleaning up expired tokens: %v", err)
 			}
+
+			purged, err := s.cameraService.PurgeTrash()
+			if err != nil {
+				log.Printf("Error purging camera trash: %v", err)
+			} else if purged > 0 {
+				log.Printf("Purged %d cameras from trash", purged)
+			}
 		}
 	}()
 
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/repository"
+	"cctv-monitoring-backend/internal/utils"
+)
+
+// ErrCredentialConflict dikembalikan jika channel satu camera memakai credential berbeda
+var ErrCredentialConflict = errors.New("all channels of a camera must use the same RTSP credentials")
+
+// ErrUnparseableRTSPURL dikembalikan jika RTSP URL berisi credential tetapi tidak
+// bisa di-parse, sehingga credential tidak bisa dipisahkan dari URL
+var ErrUnparseableRTSPURL = errors.New("RTSP URL with credentials cannot be parsed")
+
+// CredentialService memisahkan credential RTSP dari URL camera, menyimpannya
+// terenkripsi, dan menyisipkannya kembali hanya saat URL dikirim ke RTSPtoWeb
+// atau dipakai untuk menghubungi kamera. Password node RTSPtoWeb disimpan
+// terenkripsi dengan keyring yang sama.
+type CredentialService interface {
+	Seal(camera *models.Camera, channels []models.CameraChannel, req models.RTSPCredentialsRequest) (bool, error)
+	StreamChannels(camera *models.Camera, channels []models.CameraChannel) ([]models.CameraChannel, error)
+	StreamURL(camera *models.Camera) (string, error)
+	Reveal(camera *models.Camera) error
+	SealMediaServer(server *models.MediaServer) error
+	RevealMediaServer(server *models.MediaServer) error
+	RotateAll() (*models.CredentialRotationReport, error)
+}
+
+type credentialService struct {
+	cipher          *utils.CredentialCipher
+	cameraRepo      repository.CameraRepository
+	channelRepo     repository.ChannelRepository
+	mediaServerRepo repository.MediaServerRepository
+	revisionRepo    repository.RevisionRepository
+	transactor      repository.Transactor
+}
+
+// NewCredentialService membuat instance baru dari CredentialService
+func NewCredentialService(cipher *utils.CredentialCipher, cameraRepo repository.CameraRepository, channelRepo repository.ChannelRepository, mediaServerRepo repository.MediaServerRepository, revisionRepo repository.RevisionRepository, transactor repository.Transactor) CredentialService {
+	return &credentialService{
+		cipher:          cipher,
+		cameraRepo:      cameraRepo,
+		channelRepo:     channelRepo,
+		mediaServerRepo: mediaServerRepo,
+		revisionRepo:    revisionRepo,
+		transactor:      transactor,
+	}
+}
+
+// Seal membuang userinfo dari URL channels (diubah di tempat) dan rtsp_url
+// camera, lalu menyimpan credential ke camera dengan password terenkripsi.
+// Field di req menggantikan credential dari URL. Tanpa credential di URL
+// maupun req, credential camera tidak diubah. Mengembalikan true jika
+// credential camera berubah.
+func (s *credentialService) Seal(camera *models.Camera, channels []models.CameraChannel, req models.RTSPCredentialsRequest) (bool, error) {
+	if err := checkRTSPURLs(camera, channels); err != nil {
+		return false, err
+	}
+
+	var username, password string
+	found := false
+
+	for i := range channels {
+		stripped, user, pass, ok := models.SplitRTSPCredentials(channels[i].RTSPUrl)
+		channels[i].RTSPUrl = stripped
+		if !ok {
+			continue
+		}
+		if found && (user != username || pass != password) {
+			return false, ErrCredentialConflict
+		}
+		username, password, found = user, pass, true
+	}
+
+	if main := mainChannel(channels); main != nil {
+		camera.RTSPUrl = main.RTSPUrl
+	} else {
+		camera.RTSPUrl, _, _, _ = models.SplitRTSPCredentials(camera.RTSPUrl)
+	}
+
+	if !found && req.Username == nil && req.Password == nil {
+		return false, nil
+	}
+
+	// Mulai dari credential saat ini agar field yang tidak diisi tetap
+	if !found {
+		current, err := s.password(camera)
+		if err != nil {
+			return false, err
+		}
+		username, password = camera.RTSPUsername.String, current
+	}
+	if req.Username != nil {
+		username = *req.Username
+	}
+	if req.Password != nil {
+		password = *req.Password
+	}
+
+	return s.setCredentials(camera, username, password)
+}
+
+// checkRTSPURLs menolak URL camera dan channel yang berisi credential tapi tidak
+// bisa di-parse, agar password tidak tersimpan di dalam URL
+func checkRTSPURLs(camera *models.Camera, channels []models.CameraChannel) error {
+	if len(channels) == 0 && models.HasUnparseableCredentials(camera.RTSPUrl) {
+		return fmt.Errorf("rtsp_url: %w", ErrUnparseableRTSPURL)
+	}
+	for i, channel := range channels {
+		if models.HasUnparseableCredentials(channel.RTSPUrl) {
+			return fmt.Errorf("channel %d: %w", i, ErrUnparseableRTSPURL)
+		}
+	}
+	return nil
+}
+
+// setCredentials menyimpan username dan password terenkripsi jika berbeda dari
+// credential camera saat ini. Username kosong menghapus credential.
+func (s *credentialService) setCredentials(camera *models.Camera, username, password string) (bool, error) {
+	current, err := s.password(camera)
+	if err != nil {
+		return false, err
+	}
+	if username == camera.RTSPUsername.String && password == current {
+		return false, nil
+	}
+
+	camera.RTSPUsername = sql.NullString{String: username, Valid: username != ""}
+	camera.RTSPPasswordEnc = sql.NullString{}
+
+	if username != "" && password != "" {
+		encrypted, err := s.cipher.Encrypt(password)
+		if err != nil {
+			return false, fmt.Errorf("failed to encrypt RTSP password: %w", err)
+		}
+		camera.RTSPPasswordEnc = sql.NullString{String: encrypted, Valid: true}
+	}
+
+	return true, nil
+}
+
+// password mendekripsi password camera, kosong jika tidak ada
+func (s *credentialService) password(camera *models.Camera) (string, error) {
+	if !camera.RTSPPasswordEnc.Valid {
+		return "", nil
+	}
+
+	password, err := s.cipher.Decrypt(camera.RTSPPasswordEnc.String)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt RTSP credentials of camera %s: %w", camera.ID, err)
+	}
+	return password, nil
+}
+
+// StreamChannels mengembalikan salinan channels dengan credential camera di URL,
+// untuk payload RTSPtoWeb. Hasilnya tidak boleh disimpan atau dikirim ke client.
+func (s *credentialService) StreamChannels(camera *models.Camera, channels []models.CameraChannel) ([]models.CameraChannel, error) {
+	password, err := s.password(camera)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.CameraChannel, len(channels))
+	for i, channel := range channels {
+		channel.RTSPUrl = models.WithRTSPCredentials(channel.RTSPUrl, camera.RTSPUsername.String, password)
+		result[i] = channel
+	}
+	return result, nil
+}
+
+// StreamURL mengembalikan rtsp_url camera lengkap dengan credential
+func (s *credentialService) StreamURL(camera *models.Camera) (string, error) {
+	password, err := s.password(camera)
+	if err != nil {
+		return "", err
+	}
+	return models.WithRTSPCredentials(camera.RTSPUrl, camera.RTSPUsername.String, password), nil
+}
+
+// Reveal mendekripsi password camera agar rtsp_url di response tidak disamarkan.
+// Hanya dipanggil untuk caller dengan permission camera:credentials.
+func (s *credentialService) Reveal(camera *models.Camera) error {
+	password, err := s.password(camera)
+	if err != nil {
+		return err
+	}
+	camera.RTSPPassword = password
+	return nil
+}
+
+// SealMediaServer mengenkripsi Password node ke PasswordEnc dengan key aktif.
+// Password kosong menghapus PasswordEnc.
+func (s *credentialService) SealMediaServer(server *models.MediaServer) error {
+	if server.Password == "" {
+		server.PasswordEnc = sql.NullString{}
+		return nil
+	}
+
+	encrypted, err := s.cipher.Encrypt(server.Password)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt media server password: %w", err)
+	}
+	server.PasswordEnc = sql.NullString{String: encrypted, Valid: true}
+	return nil
+}
+
+// RevealMediaServer mendekripsi PasswordEnc node ke Password
+func (s *credentialService) RevealMediaServer(server *models.MediaServer) error {
+	if !server.PasswordEnc.Valid {
+		return nil
+	}
+
+	password, err := s.cipher.Decrypt(server.PasswordEnc.String)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt password of media server %s: %w", server.Name, err)
+	}
+	server.Password = password
+	return nil
+}
+
+// RotateAll menyegel ulang credential semua camera dan password node RTSPtoWeb
+// yang belum memakai key aktif, termasuk data lama yang credential-nya masih di
+// dalam URL. URL yang dikirim ke RTSPtoWeb tidak berubah, sehingga stream
+// tidak perlu di-edit.
+func (s *credentialService) RotateAll() (*models.CredentialRotationReport, error) {
+	cameras, err := s.cameraRepo.GetForCredentialRotation(s.cipher.ActiveKeyID())
+	if err != nil {
+		return nil, err
+	}
+
+	servers, err := s.mediaServerRepo.GetForCredentialRotation(s.cipher.ActiveKeyID())
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.CredentialRotationReport{
+		ActiveKeyID: s.cipher.ActiveKeyID(),
+		Total:       len(cameras) + len(servers),
+	}
+
+	for _, camera := range cameras {
+		err := s.rotate(camera)
+		switch {
+		case errors.Is(err, ErrUnparseableRTSPURL):
+			// Tidak akan berhasil sampai URL diperbaiki, password lama di URL
+			// tidak bergantung pada key sehingga key lama tetap boleh dihapus
+			log.Printf("Skipping credential rotation of camera %s, update its RTSP URL: %v", camera.ID, err)
+			report.Skipped++
+		case err != nil:
+			log.Printf("Failed to rotate credentials of camera %s: %v", camera.ID, err)
+			report.Failed++
+		default:
+			report.Rotated++
+		}
+	}
+
+	for _, server := range servers {
+		if err := s.rotateMediaServer(server); err != nil {
+			log.Printf("Failed to rotate password of media server %s: %v", server.Name, err)
+			report.Failed++
+			continue
+		}
+		report.Rotated++
+	}
+
+	return report, nil
+}
+
+// rotateMediaServer mengenkripsi ulang password node dengan key aktif
+func (s *credentialService) rotateMediaServer(server *models.MediaServer) error {
+	if err := s.RevealMediaServer(server); err != nil {
+		return err
+	}
+	if err := s.SealMediaServer(server); err != nil {
+		return err
+	}
+	return s.mediaServerRepo.UpdatePassword(server.ID, server.PasswordEnc)
+}
+
+// rotate memindahkan credential dari URL ke kolom credential dan mengenkripsi
+// ulang password dengan key aktif. Perubahan camera dicatat sebagai revision
+// credential_rotation.
+func (s *credentialService) rotate(camera *models.Camera) error {
+	channels, err := cameraChannels(s.channelRepo, camera)
+	if err != nil {
+		return fmt.Errorf("failed to get camera channels: %w", err)
+	}
+	before := strippedSnapshot(camera)
+
+	// Credential di URL (data lama) menggantikan kolom credential
+	if _, err := s.Seal(camera, channels, models.RTSPCredentialsRequest{}); err != nil {
+		return err
+	}
+
+	if camera.RTSPPasswordEnc.Valid && utils.KeyID(camera.RTSPPasswordEnc.String) != s.cipher.ActiveKeyID() {
+		password, err := s.password(camera)
+		if err != nil {
+			return err
+		}
+		encrypted, err := s.cipher.Encrypt(password)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt RTSP password: %w", err)
+		}
+		camera.RTSPPasswordEnc = sql.NullString{String: encrypted, Valid: true}
+	}
+
+	return s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.cameraRepo.WithTx(tx).Update(camera.ID, camera); err != nil {
+			return err
+		}
+		if err := s.channelRepo.WithTx(tx).ReplaceForCamera(camera.ID, channels); err != nil {
+			return err
+		}
+		return recordRevision(tx, s.revisionRepo, camera, before, "", models.RevisionActionCredentialRotation)
+	})
+}
+
+// strippedSnapshot mengambil snapshot camera dengan userinfo dibuang dari URL
+// camera dan channel, agar password lama di URL tidak tersimpan di revision
+func strippedSnapshot(camera *models.Camera) models.CameraSnapshot {
+	current := *camera
+	current.RTSPUrl, _, _, _ = models.SplitRTSPCredentials(camera.RTSPUrl)
+	current.Channels = make([]models.CameraChannel, len(camera.Channels))
+	for i, channel := range camera.Channels {
+		channel.RTSPUrl, _, _, _ = models.SplitRTSPCredentials(channel.RTSPUrl)
+		current.Channels[i] = channel
+	}
+	return models.NewCameraSnapshot(&current)
+}
@@ -0,0 +1,464 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/repository"
+	"cctv-monitoring-backend/internal/utils"
+)
+
+// fakeCameraRepo menyimpan camera di memory. Method yang tidak di-override
+// memanggil interface nil dan panic, sehingga test gagal jika service memakai
+// method yang tidak diharapkan.
+type fakeCameraRepo struct {
+	repository.CameraRepository
+	cameras      []*models.Camera
+	statuses     map[string]statusUpdate
+	syncStatuses map[string]string
+	// pendingRemoval berisi camera yang REMOVE stream-nya masih di outbox
+	pendingRemoval map[string]bool
+}
+
+// statusUpdate adalah satu pemanggilan UpdateStatus
+type statusUpdate struct {
+	status   string
+	lastSeen *time.Time
+}
+
+func (r *fakeCameraRepo) GetByIDWithInactive(id string) (*models.Camera, error) {
+	for _, camera := range r.cameras {
+		if camera.ID == id {
+			copied := *camera
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeCameraRepo) find(id string, active bool) (*models.Camera, error) {
+	for _, camera := range r.cameras {
+		if camera.ID == id && camera.IsActive == active {
+			copied := *camera
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeCameraRepo) GetByID(id string) (*models.Camera, error) {
+	return r.find(id, true)
+}
+
+func (r *fakeCameraRepo) GetTrashedByID(id string) (*models.Camera, error) {
+	return r.find(id, false)
+}
+
+func (r *fakeCameraRepo) Update(id string, camera *models.Camera) error {
+	for i, stored := range r.cameras {
+		if stored.ID == id {
+			updated := *camera
+			updated.Version = stored.Version + 1
+			r.cameras[i] = &updated
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (r *fakeCameraRepo) HardDelete(id string) error {
+	if r.pendingRemoval[id] {
+		return repository.ErrStreamRemovalPending
+	}
+	for i, camera := range r.cameras {
+		if camera.ID == id && !camera.IsActive {
+			r.cameras = append(r.cameras[:i], r.cameras[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (r *fakeCameraRepo) PurgeDeletedBefore(before time.Time, limit int) ([]*models.Camera, error) {
+	purged := []*models.Camera{}
+	kept := []*models.Camera{}
+	for _, camera := range r.cameras {
+		if len(purged) < limit && camera.DeletedAt.Valid && camera.DeletedAt.Time.Before(before) && !r.pendingRemoval[camera.ID] {
+			purged = append(purged, camera)
+			continue
+		}
+		kept = append(kept, camera)
+	}
+	r.cameras = kept
+	return purged, nil
+}
+
+func (r *fakeCameraRepo) WithTx(tx *sql.Tx) repository.CameraRepository {
+	return r
+}
+
+func (r *fakeCameraRepo) GetWithStream() ([]*models.Camera, error) {
+	return r.cameras, nil
+}
+
+func (r *fakeCameraRepo) UpdateStatus(id, status string, lastSeen *time.Time) error {
+	if r.statuses == nil {
+		r.statuses = map[string]statusUpdate{}
+	}
+	r.statuses[id] = statusUpdate{status: status, lastSeen: lastSeen}
+	return nil
+}
+
+func (r *fakeCameraRepo) UpdateSyncStatus(id, status string, syncError sql.NullString) error {
+	if r.syncStatuses == nil {
+		r.syncStatuses = map[string]string{}
+	}
+	r.syncStatuses[id] = status
+	return nil
+}
+
+func (r *fakeCameraRepo) GetForCredentialRotation(activeKeyID string) ([]*models.Camera, error) {
+	return r.cameras, nil
+}
+
+func (r *fakeCameraRepo) GetByMediaServer(serverID string) ([]*models.Camera, error) {
+	cameras := []*models.Camera{}
+	for _, camera := range r.cameras {
+		if camera.MediaServerID.String == serverID {
+			cameras = append(cameras, camera)
+		}
+	}
+	return cameras, nil
+}
+
+func (r *fakeCameraRepo) UpdateMediaServer(id string, serverID sql.NullString) error {
+	for _, camera := range r.cameras {
+		if camera.ID == id {
+			camera.MediaServerID = serverID
+		}
+	}
+	return nil
+}
+
+// fakeChannelRepo menyimpan channel per camera di memory
+type fakeChannelRepo struct {
+	repository.ChannelRepository
+	channels map[string][]models.CameraChannel
+}
+
+func (r *fakeChannelRepo) GetByCameraIDs(cameraIDs []string) (map[string][]models.CameraChannel, error) {
+	result := map[string][]models.CameraChannel{}
+	for _, id := range cameraIDs {
+		if channels, ok := r.channels[id]; ok {
+			result[id] = channels
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeChannelRepo) ReplaceForCamera(cameraID string, channels []models.CameraChannel) error {
+	if r.channels == nil {
+		r.channels = map[string][]models.CameraChannel{}
+	}
+	r.channels[cameraID] = channels
+	return nil
+}
+
+func (r *fakeChannelRepo) WithTx(tx *sql.Tx) repository.ChannelRepository {
+	return r
+}
+
+// fakeTransactor menjalankan fn tanpa transaksi database
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithinTx(fn func(tx *sql.Tx) error) error {
+	return fn(nil)
+}
+
+// fakeActivityRepo mencatat activity log yang dibuat
+type fakeActivityRepo struct {
+	entries []*models.ActivityLog
+}
+
+func (r *fakeActivityRepo) Create(entry *models.ActivityLog) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *fakeActivityRepo) WithTx(tx *sql.Tx) repository.ActivityRepository {
+	return r
+}
+
+// fakeRevisionRepo menyimpan revision camera di memory
+type fakeRevisionRepo struct {
+	repository.RevisionRepository
+	revisions []*models.CameraRevision
+}
+
+func (r *fakeRevisionRepo) Create(revision *models.CameraRevision) error {
+	revision.ID = int64(len(r.revisions) + 1)
+	r.revisions = append(r.revisions, revision)
+	return nil
+}
+
+func (r *fakeRevisionRepo) WithTx(tx *sql.Tx) repository.RevisionRepository {
+	return r
+}
+
+// fakeLocationRepo menyimpan building dan zone di memory. SyncCameraNames
+// mengembalikan synced, perubahan camera yang disiapkan test.
+type fakeLocationRepo struct {
+	repository.LocationRepository
+	buildings []*models.Building
+	zones     []*models.Zone
+	synced    []models.CameraLocationSync
+}
+
+func (r *fakeLocationRepo) GetBuilding(id string) (*models.Building, error) {
+	for _, building := range r.buildings {
+		if building.ID == id {
+			copied := *building
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrLocationNotFound
+}
+
+func (r *fakeLocationRepo) UpdateBuilding(building *models.Building) error {
+	for i, stored := range r.buildings {
+		if stored.ID == building.ID {
+			updated := *building
+			r.buildings[i] = &updated
+			return nil
+		}
+	}
+	return repository.ErrLocationNotFound
+}
+
+func (r *fakeLocationRepo) GetZone(id string) (*models.Zone, error) {
+	for _, zone := range r.zones {
+		if zone.ID == id {
+			copied := *zone
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrLocationNotFound
+}
+
+func (r *fakeLocationRepo) FindZone(buildingName, zoneName string) (*models.Zone, error) {
+	for _, zone := range r.zones {
+		building, err := r.GetBuilding(zone.BuildingID)
+		if err == nil && strings.EqualFold(building.Name, buildingName) && strings.EqualFold(zone.Name, zoneName) {
+			copied := *zone
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrLocationNotFound
+}
+
+func (r *fakeLocationRepo) SyncCameraNames(buildingID string) ([]models.CameraLocationSync, error) {
+	return r.synced, nil
+}
+
+func (r *fakeLocationRepo) WithTx(tx *sql.Tx) repository.LocationRepository {
+	return r
+}
+
+// fakeOutbox mencatat operasi stream yang di-enqueue, pengiriman selalu berhasil
+type fakeOutbox struct {
+	StreamOutboxService
+	operations []string
+}
+
+func (o *fakeOutbox) Enqueue(tx *sql.Tx, camera *models.Camera, operation string) error {
+	o.operations = append(o.operations, operation+" "+camera.ID)
+	return nil
+}
+
+func (o *fakeOutbox) DeliverCamera(ctx context.Context, cameraID string) error {
+	return nil
+}
+
+// fakeMediaServerRepo menyimpan node di memory, StreamCount dihitung dari
+// camera di cameraRepo seperti query mediaServerSelect
+type fakeMediaServerRepo struct {
+	repository.MediaServerRepository
+	servers    []*models.MediaServer
+	cameraRepo *fakeCameraRepo
+}
+
+func (r *fakeMediaServerRepo) load(server *models.MediaServer) *models.MediaServer {
+	loaded := *server
+	loaded.Password = ""
+	loaded.StreamCount = 0
+	if r.cameraRepo != nil {
+		cameras, _ := r.cameraRepo.GetByMediaServer(server.ID)
+		loaded.StreamCount = len(cameras)
+	}
+	return &loaded
+}
+
+func (r *fakeMediaServerRepo) GetAll() ([]*models.MediaServer, error) {
+	servers := make([]*models.MediaServer, 0, len(r.servers))
+	for _, server := range r.servers {
+		servers = append(servers, r.load(server))
+	}
+	return servers, nil
+}
+
+func (r *fakeMediaServerRepo) GetByID(id string) (*models.MediaServer, error) {
+	for _, server := range r.servers {
+		if server.ID == id {
+			return r.load(server), nil
+		}
+	}
+	return nil, fmt.Errorf("media server not found")
+}
+
+func (r *fakeMediaServerRepo) Create(server *models.MediaServer) error {
+	server.ID = fmt.Sprintf("server-%d", len(r.servers)+1)
+	stored := *server
+	r.servers = append(r.servers, &stored)
+	return nil
+}
+
+func (r *fakeMediaServerRepo) Update(id string, server *models.MediaServer) error {
+	for i, stored := range r.servers {
+		if stored.ID == id {
+			updated := *server
+			updated.UpdatedAt = stored.UpdatedAt.Add(time.Second)
+			r.servers[i] = &updated
+		}
+	}
+	return nil
+}
+
+func (r *fakeMediaServerRepo) UpdateStatus(id, status string) error {
+	for _, server := range r.servers {
+		if server.ID == id {
+			server.Status = status
+		}
+	}
+	return nil
+}
+
+func (r *fakeMediaServerRepo) GetForCredentialRotation(activeKeyID string) ([]*models.MediaServer, error) {
+	servers := []*models.MediaServer{}
+	for _, server := range r.servers {
+		if server.PasswordEnc.Valid && utils.KeyID(server.PasswordEnc.String) != activeKeyID {
+			servers = append(servers, r.load(server))
+		}
+	}
+	return servers, nil
+}
+
+func (r *fakeMediaServerRepo) UpdatePassword(id string, passwordEnc sql.NullString) error {
+	for _, server := range r.servers {
+		if server.ID == id {
+			server.PasswordEnc = passwordEnc
+		}
+	}
+	return nil
+}
+
+// fakeOutboxRepo menyimpan stream outbox di memory dengan aturan ClaimDue yang
+// sama seperti query: hanya operasi PENDING tertua per camera yang jatuh tempo
+type fakeOutboxRepo struct {
+	repository.OutboxRepository
+	entries []*models.StreamOutboxEntry
+}
+
+func (r *fakeOutboxRepo) add(entry *models.StreamOutboxEntry) *models.StreamOutboxEntry {
+	entry.ID = int64(len(r.entries) + 1)
+	entry.Status = models.OutboxPending
+	r.entries = append(r.entries, entry)
+	return entry
+}
+
+func (r *fakeOutboxRepo) ClaimDue(cameraID string, limit int, lease time.Duration) ([]*models.StreamOutboxEntry, error) {
+	claimed := []*models.StreamOutboxEntry{}
+	seen := map[string]bool{}
+	for _, entry := range r.entries {
+		if entry.Status != models.OutboxPending || seen[entry.CameraID] {
+			continue
+		}
+		seen[entry.CameraID] = true
+		if entry.NextAttemptAt.After(time.Now()) || (cameraID != "" && entry.CameraID != cameraID) {
+			continue
+		}
+		if len(claimed) < limit {
+			copied := *entry
+			claimed = append(claimed, &copied)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *fakeOutboxRepo) get(id int64) *models.StreamOutboxEntry {
+	return r.entries[id-1]
+}
+
+func (r *fakeOutboxRepo) MarkDelivered(id int64) error {
+	entry := r.get(id)
+	entry.Status = models.OutboxDelivered
+	entry.Attempts++
+	entry.LastError = sql.NullString{}
+	return nil
+}
+
+func (r *fakeOutboxRepo) MarkRetry(id int64, lastError string, nextAttemptAt time.Time) error {
+	entry := r.get(id)
+	entry.Attempts++
+	entry.LastError = sql.NullString{String: lastError, Valid: true}
+	entry.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (r *fakeOutboxRepo) MarkFailed(id int64, lastError string) error {
+	entry := r.get(id)
+	entry.Status = models.OutboxFailed
+	entry.Attempts++
+	entry.LastError = sql.NullString{String: lastError, Valid: true}
+	return nil
+}
+
+func (r *fakeOutboxRepo) CountPending(cameraID string) (int, error) {
+	count := 0
+	for _, entry := range r.entries {
+		if entry.CameraID == cameraID && entry.Status == models.OutboxPending {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// fakeMediaServers mengembalikan client yang sama untuk semua camera dan node
+type fakeMediaServers struct {
+	MediaServerService
+	client RTSPService
+}
+
+func (f *fakeMediaServers) ClientForCamera(camera *models.Camera) (RTSPService, error) {
+	return f.client, nil
+}
+
+func (f *fakeMediaServers) ClientFor(serverID string) (RTSPService, error) {
+	return f.client, nil
+}
+
+// testCipher membuat CredentialCipher dengan satu key tetap
+func testCipher(t *testing.T) *utils.CredentialCipher {
+	t.Helper()
+	key := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	cipher, err := utils.NewCredentialCipher("test:" + key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cipher
+}
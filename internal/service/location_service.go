@@ -0,0 +1,449 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/repository"
+	"cctv-monitoring-backend/internal/validation"
+)
+
+// Custom errors untuk location service
+var (
+	ErrLocationNotFound = repository.ErrLocationNotFound
+	ErrLocationExists   = repository.ErrLocationExists
+	ErrLocationInUse    = errors.New("location still has child locations or active cameras")
+)
+
+// LocationService mengelola hierarki site > building > floor > zone tempat
+// camera dipasang
+type LocationService interface {
+	GetTree() (*models.LocationTree, error)
+
+	ListSites() ([]*models.Site, error)
+	GetSite(id string) (*models.Site, error)
+	CreateSite(req *models.CreateSiteRequest) (*models.Site, error)
+	UpdateSite(id string, req *models.UpdateSiteRequest) (*models.Site, error)
+	DeleteSite(id string) error
+
+	ListBuildings(siteID string) ([]*models.Building, error)
+	GetBuilding(id string) (*models.Building, error)
+	CreateBuilding(req *models.CreateBuildingRequest) (*models.Building, error)
+	UpdateBuilding(id string, req *models.UpdateBuildingRequest, userID string) (*models.Building, error)
+	DeleteBuilding(id string) error
+
+	ListFloors(buildingID string) ([]*models.Floor, error)
+	GetFloor(id string) (*models.Floor, error)
+	CreateFloor(req *models.CreateFloorRequest) (*models.Floor, error)
+	UpdateFloor(id string, req *models.UpdateFloorRequest) (*models.Floor, error)
+	DeleteFloor(id string) error
+
+	ListZones(buildingID, floorID string) ([]*models.Zone, error)
+	GetZone(id string) (*models.Zone, error)
+	CreateZone(req *models.CreateZoneRequest) (*models.Zone, error)
+	UpdateZone(id string, req *models.UpdateZoneRequest, userID string) (*models.Zone, error)
+	DeleteZone(id string) error
+}
+
+type locationService struct {
+	locationRepo repository.LocationRepository
+	revisionRepo repository.RevisionRepository
+	transactor   repository.Transactor
+}
+
+// NewLocationService membuat instance baru dari LocationService
+func NewLocationService(locationRepo repository.LocationRepository, revisionRepo repository.RevisionRepository, transactor repository.Transactor) LocationService {
+	return &locationService{
+		locationRepo: locationRepo,
+		revisionRepo: revisionRepo,
+		transactor:   transactor,
+	}
+}
+
+// GetTree menyusun seluruh hierarki lokasi beserta jumlah camera aktif dan
+// camera ONLINE di setiap level
+func (s *locationService) GetTree() (*models.LocationTree, error) {
+	sites, err := s.locationRepo.GetSites()
+	if err != nil {
+		return nil, err
+	}
+	buildings, err := s.locationRepo.GetBuildings("")
+	if err != nil {
+		return nil, err
+	}
+	floors, err := s.locationRepo.GetFloors("")
+	if err != nil {
+		return nil, err
+	}
+	zones, err := s.locationRepo.GetZones("", "")
+	if err != nil {
+		return nil, err
+	}
+	counts, unassigned, err := s.locationRepo.CountCamerasByZone()
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewLocationTree(sites, buildings, floors, zones, counts, unassigned), nil
+}
+
+// nullString mengubah string kosong menjadi NULL
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// applyGeometry mengganti geometry jika dikirim, null menghapusnya
+func applyGeometry(current *json.RawMessage, geometry json.RawMessage) {
+	if len(geometry) == 0 {
+		return
+	}
+	if models.IsJSONNull(geometry) {
+		*current = nil
+		return
+	}
+	*current = geometry
+}
+
+// parentError mengubah parent yang tidak ada menjadi validation error di field
+// yang mereferensikannya
+func parentError(err error, field string) error {
+	if errors.Is(err, ErrLocationNotFound) {
+		var errs validation.Errors
+		errs.Add(field, models.FieldErrNotFound, "does not exist")
+		return errs
+	}
+	return err
+}
+
+// ensureEmpty mengembalikan ErrLocationInUse jika masih ada baris table yang
+// menunjuk ke lokasi id lewat column
+func (s *locationService) ensureEmpty(id, table, column string) error {
+	count, err := s.locationRepo.CountChildren(table, column, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: %d %s", ErrLocationInUse, count, table)
+	}
+	return nil
+}
+
+// ListSites mengambil semua site
+func (s *locationService) ListSites() ([]*models.Site, error) {
+	return s.locationRepo.GetSites()
+}
+
+// GetSite mengambil site berdasarkan ID
+func (s *locationService) GetSite(id string) (*models.Site, error) {
+	return s.locationRepo.GetSite(id)
+}
+
+// CreateSite membuat site baru
+func (s *locationService) CreateSite(req *models.CreateSiteRequest) (*models.Site, error) {
+	site := &models.Site{
+		Name:    req.Name,
+		Address: nullString(req.Address),
+	}
+	applyGeometry(&site.Geometry, req.Geometry)
+
+	if err := s.locationRepo.CreateSite(site); err != nil {
+		return nil, err
+	}
+	return site, nil
+}
+
+// UpdateSite mengubah field site yang diisi di request
+func (s *locationService) UpdateSite(id string, req *models.UpdateSiteRequest) (*models.Site, error) {
+	site, err := s.locationRepo.GetSite(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		site.Name = req.Name
+	}
+	if req.Address != nil {
+		site.Address = nullString(*req.Address)
+	}
+	applyGeometry(&site.Geometry, req.Geometry)
+
+	if err := s.locationRepo.UpdateSite(site); err != nil {
+		return nil, err
+	}
+	return s.locationRepo.GetSite(id)
+}
+
+// DeleteSite menghapus site yang sudah tidak punya building
+func (s *locationService) DeleteSite(id string) error {
+	if _, err := s.locationRepo.GetSite(id); err != nil {
+		return err
+	}
+	if err := s.ensureEmpty(id, "buildings", "site_id"); err != nil {
+		return err
+	}
+	return s.locationRepo.DeleteSite(id)
+}
+
+// ListBuildings mengambil building, difilter site jika siteID diisi
+func (s *locationService) ListBuildings(siteID string) ([]*models.Building, error) {
+	return s.locationRepo.GetBuildings(siteID)
+}
+
+// GetBuilding mengambil building berdasarkan ID
+func (s *locationService) GetBuilding(id string) (*models.Building, error) {
+	return s.locationRepo.GetBuilding(id)
+}
+
+// CreateBuilding membuat building baru di site
+func (s *locationService) CreateBuilding(req *models.CreateBuildingRequest) (*models.Building, error) {
+	if _, err := s.locationRepo.GetSite(req.SiteID); err != nil {
+		return nil, parentError(err, "site_id")
+	}
+
+	building := &models.Building{
+		SiteID:  req.SiteID,
+		Name:    req.Name,
+		Address: nullString(req.Address),
+	}
+	applyGeometry(&building.Geometry, req.Geometry)
+
+	if err := s.locationRepo.CreateBuilding(building); err != nil {
+		return nil, err
+	}
+	return building, nil
+}
+
+// UpdateBuilding mengubah field building yang diisi di request. Nama baru
+// ikut disalin ke kolom building camera di dalamnya.
+func (s *locationService) UpdateBuilding(id string, req *models.UpdateBuildingRequest, userID string) (*models.Building, error) {
+	building, err := s.locationRepo.GetBuilding(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SiteID != "" && req.SiteID != building.SiteID {
+		if _, err := s.locationRepo.GetSite(req.SiteID); err != nil {
+			return nil, parentError(err, "site_id")
+		}
+		building.SiteID = req.SiteID
+	}
+	if req.Name != "" {
+		building.Name = req.Name
+	}
+	if req.Address != nil {
+		building.Address = nullString(*req.Address)
+	}
+	applyGeometry(&building.Geometry, req.Geometry)
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		locationRepo := s.locationRepo.WithTx(tx)
+		if err := locationRepo.UpdateBuilding(building); err != nil {
+			return err
+		}
+		return s.syncCameraNames(tx, id, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.locationRepo.GetBuilding(id)
+}
+
+// syncCameraNames menyalin nama building dan zone ke camera di building di
+// dalam tx, dengan revision location_sync untuk setiap camera yang berubah
+func (s *locationService) syncCameraNames(tx *sql.Tx, buildingID, userID string) error {
+	synced, err := s.locationRepo.WithTx(tx).SyncCameraNames(buildingID)
+	if err != nil {
+		return err
+	}
+
+	for _, camera := range synced {
+		before := models.NewCameraSnapshot(camera.Before)
+		if err := recordRevision(tx, s.revisionRepo, camera.After, before, userID, models.RevisionActionLocationSync); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteBuilding menghapus building yang sudah tidak punya floor dan zone
+func (s *locationService) DeleteBuilding(id string) error {
+	if _, err := s.locationRepo.GetBuilding(id); err != nil {
+		return err
+	}
+	if err := s.ensureEmpty(id, "floors", "building_id"); err != nil {
+		return err
+	}
+	if err := s.ensureEmpty(id, "zones", "building_id"); err != nil {
+		return err
+	}
+	return s.locationRepo.DeleteBuilding(id)
+}
+
+// ListFloors mengambil floor, difilter building jika buildingID diisi
+func (s *locationService) ListFloors(buildingID string) ([]*models.Floor, error) {
+	return s.locationRepo.GetFloors(buildingID)
+}
+
+// GetFloor mengambil floor berdasarkan ID
+func (s *locationService) GetFloor(id string) (*models.Floor, error) {
+	return s.locationRepo.GetFloor(id)
+}
+
+// CreateFloor membuat floor baru di building
+func (s *locationService) CreateFloor(req *models.CreateFloorRequest) (*models.Floor, error) {
+	if _, err := s.locationRepo.GetBuilding(req.BuildingID); err != nil {
+		return nil, parentError(err, "building_id")
+	}
+
+	floor := &models.Floor{
+		BuildingID: req.BuildingID,
+		Name:       req.Name,
+		Level:      req.Level,
+	}
+	applyGeometry(&floor.Geometry, req.Geometry)
+
+	if err := s.locationRepo.CreateFloor(floor); err != nil {
+		return nil, err
+	}
+	return floor, nil
+}
+
+// UpdateFloor mengubah field floor yang diisi di request
+func (s *locationService) UpdateFloor(id string, req *models.UpdateFloorRequest) (*models.Floor, error) {
+	floor, err := s.locationRepo.GetFloor(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		floor.Name = req.Name
+	}
+	if req.Level != nil {
+		floor.Level = *req.Level
+	}
+	applyGeometry(&floor.Geometry, req.Geometry)
+
+	if err := s.locationRepo.UpdateFloor(floor); err != nil {
+		return nil, err
+	}
+	return s.locationRepo.GetFloor(id)
+}
+
+// DeleteFloor menghapus floor yang sudah tidak punya zone
+func (s *locationService) DeleteFloor(id string) error {
+	if _, err := s.locationRepo.GetFloor(id); err != nil {
+		return err
+	}
+	if err := s.ensureEmpty(id, "zones", "floor_id"); err != nil {
+		return err
+	}
+	return s.locationRepo.DeleteFloor(id)
+}
+
+// ListZones mengambil zone, difilter building dan/atau floor jika diisi
+func (s *locationService) ListZones(buildingID, floorID string) ([]*models.Zone, error) {
+	return s.locationRepo.GetZones(buildingID, floorID)
+}
+
+// GetZone mengambil zone berdasarkan ID
+func (s *locationService) GetZone(id string) (*models.Zone, error) {
+	return s.locationRepo.GetZone(id)
+}
+
+// placeZone memindahkan zone ke floorID (building ikut floor) atau ke
+// buildingID tanpa floor. Floor harus berada di building yang diminta.
+func (s *locationService) placeZone(zone *models.Zone, buildingID, floorID string) error {
+	if floorID != "" {
+		floor, err := s.locationRepo.GetFloor(floorID)
+		if err != nil {
+			return parentError(err, "floor_id")
+		}
+		if buildingID != "" && buildingID != floor.BuildingID {
+			var errs validation.Errors
+			errs.Add("floor_id", models.FieldErrInvalidChoice, "must be a floor of building_id")
+			return errs
+		}
+		zone.BuildingID = floor.BuildingID
+		zone.FloorID = sql.NullString{String: floor.ID, Valid: true}
+		return nil
+	}
+
+	if _, err := s.locationRepo.GetBuilding(buildingID); err != nil {
+		return parentError(err, "building_id")
+	}
+	zone.BuildingID = buildingID
+	zone.FloorID = sql.NullString{}
+	return nil
+}
+
+// CreateZone membuat zone baru di building atau floor
+func (s *locationService) CreateZone(req *models.CreateZoneRequest) (*models.Zone, error) {
+	zone := &models.Zone{Name: req.Name}
+	if err := s.placeZone(zone, req.BuildingID, req.FloorID); err != nil {
+		return nil, err
+	}
+	applyGeometry(&zone.Geometry, req.Geometry)
+
+	if err := s.locationRepo.CreateZone(zone); err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+// UpdateZone mengubah field zone yang diisi di request. Nama dan building
+// baru ikut disalin ke kolom building dan zone camera di dalamnya.
+func (s *locationService) UpdateZone(id string, req *models.UpdateZoneRequest, userID string) (*models.Zone, error) {
+	zone, err := s.locationRepo.GetZone(id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case req.FloorID != nil:
+		buildingID := req.BuildingID
+		if buildingID == "" {
+			buildingID = zone.BuildingID
+		}
+		if err := s.placeZone(zone, buildingID, *req.FloorID); err != nil {
+			return nil, err
+		}
+	case req.BuildingID != "" && req.BuildingID != zone.BuildingID:
+		// Floor lama milik building lama, zone pindah tanpa floor
+		if err := s.placeZone(zone, req.BuildingID, ""); err != nil {
+			return nil, err
+		}
+	}
+	if req.Name != "" {
+		zone.Name = req.Name
+	}
+	applyGeometry(&zone.Geometry, req.Geometry)
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		locationRepo := s.locationRepo.WithTx(tx)
+		if err := locationRepo.UpdateZone(zone); err != nil {
+			return err
+		}
+		return s.syncCameraNames(tx, zone.BuildingID, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.locationRepo.GetZone(id)
+}
+
+// DeleteZone menghapus zone yang sudah tidak punya camera aktif. Camera di
+// trash kehilangan zone_id, kolom building dan zone-nya tetap.
+func (s *locationService) DeleteZone(id string) error {
+	if _, err := s.locationRepo.GetZone(id); err != nil {
+		return err
+	}
+	if err := s.ensureEmpty(id, "cameras", "zone_id"); err != nil {
+		return err
+	}
+	return s.locationRepo.DeleteZone(id)
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"cctv-monitoring-backend/internal/models"
+)
+
+const (
+	testBuildingID = "6a1f0c7e-2b4d-4e8a-9c3f-5d7b1a2e4c6f"
+	testZoneID     = "9e3b5d7f-1a2c-4e6b-8d0f-3c5a7e9b1d2f"
+)
+
+func newLocationRepo() *fakeLocationRepo {
+	return &fakeLocationRepo{
+		buildings: []*models.Building{{ID: testBuildingID, SiteID: "site-1", Name: "Gedung A"}},
+		zones:     []*models.Zone{{ID: testZoneID, BuildingID: testBuildingID, Name: "Lobby"}},
+	}
+}
+
+func TestResolveLocationByZoneID(t *testing.T) {
+	s := &cameraService{locationRepo: newLocationRepo()}
+	camera := &models.Camera{Building: sql.NullString{String: "lama", Valid: true}}
+
+	if err := s.resolveLocation(camera, testZoneID); err != nil {
+		t.Fatalf("resolveLocation() error = %v", err)
+	}
+	// Nama building dan zone mengikuti entity zone
+	if camera.ZoneID.String != testZoneID || camera.Building.String != "Gedung A" || camera.Zone.String != "Lobby" {
+		t.Errorf("camera location = %v, %q, %q", camera.ZoneID, camera.Building.String, camera.Zone.String)
+	}
+}
+
+func TestResolveLocationByNames(t *testing.T) {
+	s := &cameraService{locationRepo: newLocationRepo()}
+	camera := &models.Camera{
+		Building: sql.NullString{String: "gedung a", Valid: true},
+		Zone:     sql.NullString{String: "LOBBY", Valid: true},
+	}
+
+	if err := s.resolveLocation(camera, ""); err != nil {
+		t.Fatalf("resolveLocation() error = %v", err)
+	}
+	if camera.ZoneID.String != testZoneID || camera.Building.String != "Gedung A" || camera.Zone.String != "Lobby" {
+		t.Errorf("camera location = %v, %q, %q", camera.ZoneID, camera.Building.String, camera.Zone.String)
+	}
+}
+
+func TestResolveLocationKeepsUnknownNames(t *testing.T) {
+	s := &cameraService{locationRepo: newLocationRepo()}
+	camera := &models.Camera{
+		Building: sql.NullString{String: "Gedung B", Valid: true},
+		Zone:     sql.NullString{String: "Lobby", Valid: true},
+		ZoneID:   sql.NullString{String: testZoneID, Valid: true},
+	}
+
+	if err := s.resolveLocation(camera, ""); err != nil {
+		t.Fatalf("resolveLocation() error = %v", err)
+	}
+	// Teks tetap dipakai, zone_id lama dilepas
+	if camera.ZoneID.Valid || camera.Building.String != "Gedung B" {
+		t.Errorf("camera location = %v, %q", camera.ZoneID, camera.Building.String)
+	}
+}
+
+func TestResolveLocationUnknownZone(t *testing.T) {
+	s := &cameraService{locationRepo: newLocationRepo()}
+
+	for _, zoneID := range []string{"zone-1", "0b5f3c1e-9a4d-4c2b-8f7e-1d2c3b4a5f6e"} {
+		if err := s.resolveLocation(&models.Camera{}, zoneID); !errors.Is(err, ErrZoneNotFound) {
+			t.Errorf("resolveLocation(%q) error = %v, want ErrZoneNotFound", zoneID, err)
+		}
+	}
+}
+
+func TestUpdateBuildingRecordsLocationSync(t *testing.T) {
+	before := &models.Camera{ID: "cam-1", Name: "Lobby", Building: sql.NullString{String: "Gedung A", Valid: true}, IsActive: true, Version: 3}
+	after := *before
+	after.Building = sql.NullString{String: "Gedung Utama", Valid: true}
+	after.Version = 4
+
+	locationRepo := newLocationRepo()
+	locationRepo.synced = []models.CameraLocationSync{{Before: before, After: &after}}
+	revisionRepo := &fakeRevisionRepo{}
+	s := NewLocationService(locationRepo, revisionRepo, fakeTransactor{})
+
+	building, err := s.UpdateBuilding(testBuildingID, &models.UpdateBuildingRequest{Name: "Gedung Utama"}, "user-1")
+	if err != nil {
+		t.Fatalf("UpdateBuilding() error = %v", err)
+	}
+	if building.Name != "Gedung Utama" {
+		t.Errorf("building name = %q", building.Name)
+	}
+
+	if len(revisionRepo.revisions) != 1 {
+		t.Fatalf("revisions = %d, want 1", len(revisionRepo.revisions))
+	}
+	revision := revisionRepo.revisions[0]
+	if revision.Action != models.RevisionActionLocationSync || revision.Version != 4 || revision.UserID.String != "user-1" {
+		t.Errorf("revision = %+v", revision)
+	}
+	if _, ok := revision.Changes["building"]; !ok || len(revision.Changes) != 1 {
+		t.Errorf("changes = %v, want building only", revision.Changes)
+	}
+}
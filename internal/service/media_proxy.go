@@ -0,0 +1,135 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// PlaybackConfig mengatur bentuk URL playback yang dikembalikan ke client
+type PlaybackConfig struct {
+	// ProxyEnabled true berarti semua URL HLS/JPEG melewati proxy backend,
+	// sehingga RTSPtoWeb tidak perlu bisa diakses publik
+	ProxyEnabled bool
+	// APIBaseURL adalah base URL publik backend, kosong berarti path relatif
+	APIBaseURL string
+}
+
+// MediaResponse adalah response media dari RTSPtoWeb yang siap diteruskan ke client
+type MediaResponse struct {
+	StatusCode    int
+	ContentType   string
+	CacheControl  string
+	ContentLength int64 // -1 jika tidak diketahui
+	Body          io.ReadCloser
+}
+
+// uriAttrPattern mencocokkan atribut URI="..." di tag playlist (EXT-X-MAP, EXT-X-KEY, dll)
+var uriAttrPattern = regexp.MustCompile(`URI="([^"]*)"`)
+
+// proxyBaseURL adalah prefix URL proxy media untuk camera
+func (c PlaybackConfig) proxyBaseURL(cameraID string) string {
+	return fmt.Sprintf("%s/api/v1/cameras/%s", strings.TrimRight(c.APIBaseURL, "/"), cameraID)
+}
+
+// proxyHLSURL adalah URL playlist HLS lewat proxy backend
+func (c PlaybackConfig) proxyHLSURL(cameraID string, channel int) string {
+	return fmt.Sprintf("%s/hls/live/index.m3u8?channel=%d", c.proxyBaseURL(cameraID), channel)
+}
+
+// proxySnapshotURL adalah URL snapshot JPEG lewat proxy backend
+func (c PlaybackConfig) proxySnapshotURL(cameraID string, channel int) string {
+	return fmt.Sprintf("%s/snapshot?channel=%d", c.proxyBaseURL(cameraID), channel)
+}
+
+// withToken menambahkan viewer token ke URL playback
+func withToken(playbackURL, token string) string {
+	if token == "" || playbackURL == "" {
+		return playbackURL
+	}
+
+	separator := "?"
+	if strings.Contains(playbackURL, "?") {
+		separator = "&"
+	}
+	return playbackURL + separator + "token=" + url.QueryEscape(token)
+}
+
+// cleanMediaPath menormalkan path media dari client dan menolak path traversal
+func cleanMediaPath(mediaPath string) (string, error) {
+	cleaned := path.Clean("/" + mediaPath)
+	if cleaned == "/" || strings.Contains(mediaPath, "..") {
+		return "", fmt.Errorf("invalid media path")
+	}
+	return strings.TrimPrefix(cleaned, "/"), nil
+}
+
+// newMediaResponse membungkus response RTSPtoWeb tanpa membaca body-nya
+func newMediaResponse(resp *http.Response) *MediaResponse {
+	return &MediaResponse{
+		StatusCode:    resp.StatusCode,
+		ContentType:   resp.Header.Get("Content-Type"),
+		CacheControl:  resp.Header.Get("Cache-Control"),
+		ContentLength: resp.ContentLength,
+		Body:          resp.Body,
+	}
+}
+
+// isPlaylist mengecek apakah response adalah playlist HLS yang perlu di-rewrite
+func isPlaylist(mediaPath, contentType string) bool {
+	return strings.HasSuffix(mediaPath, ".m3u8") || strings.Contains(strings.ToLower(contentType), "mpegurl")
+}
+
+// rewritePlaylist mengubah semua URI segment/playlist di dalam playlist HLS agar
+// menunjuk kembali ke proxy backend. upstreamPrefix adalah path HLS di RTSPtoWeb
+// (/stream/{id}/channel/{ch}/hls/), playlistPath adalah path playlist relatif
+// terhadap prefix tersebut.
+func rewritePlaylist(body []byte, upstreamPrefix, playlistPath, proxyPrefix, query string) []byte {
+	base := &url.URL{Path: upstreamPrefix + playlistPath}
+
+	rewrite := func(uri string) string {
+		ref, err := url.Parse(uri)
+		if err != nil {
+			return uri
+		}
+
+		resolved := base.ResolveReference(ref)
+		if !strings.HasPrefix(resolved.Path, upstreamPrefix) {
+			return uri
+		}
+
+		rewritten := proxyPrefix + strings.TrimPrefix(resolved.Path, upstreamPrefix)
+		if query != "" {
+			rewritten += "?" + query
+		}
+		return rewritten
+	}
+
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			out.WriteString(line)
+		case strings.HasPrefix(trimmed, "#"):
+			out.WriteString(uriAttrPattern.ReplaceAllStringFunc(line, func(attr string) string {
+				uri := uriAttrPattern.FindStringSubmatch(attr)[1]
+				return `URI="` + rewrite(uri) + `"`
+			}))
+		default:
+			out.WriteString(rewrite(trimmed))
+		}
+		out.WriteByte('\n')
+	}
+
+	return out.Bytes()
+}
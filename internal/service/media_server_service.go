@@ -0,0 +1,476 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/repository"
+)
+
+// Custom errors untuk media server service
+var (
+	ErrMediaServerNotFound = errors.New("media server not found")
+	ErrNoMediaServer       = errors.New("no healthy media server with free capacity")
+)
+
+// MediaServerService mengelola registry node RTSPtoWeb: client per node,
+// placement stream camera, health check dan failover. Password node disimpan
+// terenkripsi dengan CredentialCipher.
+type MediaServerService interface {
+	EnsureDefault(apiURL, publicURL, username, password string) error
+	List() ([]*models.MediaServer, error)
+	GetByID(id string) (*models.MediaServer, error)
+	Create(req *models.CreateMediaServerRequest) (*models.MediaServer, error)
+	Update(id string, req *models.UpdateMediaServerRequest) (*models.MediaServer, error)
+	Delete(id string) error
+	ClientFor(serverID string) (RTSPService, error)
+	ClientForCamera(camera *models.Camera) (RTSPService, error)
+	Place() (*models.MediaServer, error)
+	CheckAll()
+	StartHealthJob(interval time.Duration, failoverThreshold int)
+}
+
+type mediaServerService struct {
+	serverRepo  repository.MediaServerRepository
+	cameraRepo  repository.CameraRepository
+	channelRepo repository.ChannelRepository
+	credentials CredentialService
+	clientOpts  RTSPClientOptions
+
+	mu                sync.RWMutex
+	servers           map[string]*models.MediaServer
+	clients           map[string]RTSPService
+	failures          map[string]int
+	failoverThreshold int
+}
+
+// NewMediaServerService membuat instance baru dari MediaServerService
+func NewMediaServerService(serverRepo repository.MediaServerRepository, cameraRepo repository.CameraRepository, channelRepo repository.ChannelRepository, credentials CredentialService, clientOpts RTSPClientOptions) MediaServerService {
+	return &mediaServerService{
+		serverRepo:        serverRepo,
+		cameraRepo:        cameraRepo,
+		channelRepo:       channelRepo,
+		credentials:       credentials,
+		clientOpts:        clientOpts,
+		servers:           map[string]*models.MediaServer{},
+		clients:           map[string]RTSPService{},
+		failures:          map[string]int{},
+		failoverThreshold: 3,
+	}
+}
+
+// refresh memuat ulang registry node dari database ke memory
+func (s *mediaServerService) refresh() error {
+	servers, err := s.serverRepo.GetAll()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clients := make(map[string]RTSPService, len(servers))
+	byID := make(map[string]*models.MediaServer, len(servers))
+	for _, server := range servers {
+		byID[server.ID] = server
+
+		// Client lama dipakai ulang jika konfigurasi node tidak berubah
+		if old, ok := s.servers[server.ID]; ok && old.UpdatedAt.Equal(server.UpdatedAt) {
+			if client, ok := s.clients[server.ID]; ok {
+				clients[server.ID] = client
+				continue
+			}
+		}
+		// Node yang password-nya tidak bisa didekripsi (key dihapus dari
+		// CREDENTIAL_KEYS) tidak mendapat client
+		if err := s.credentials.RevealMediaServer(server); err != nil {
+			log.Printf("Error loading media server %s: %v", server.Name, err)
+			continue
+		}
+		clients[server.ID] = NewRTSPService(server.APIURL, server.PublicURL, server.Username, server.Password, s.clientOpts)
+	}
+
+	s.servers = byID
+	s.clients = clients
+
+	return nil
+}
+
+// EnsureDefault mendaftarkan node dari konfigurasi environment jika registry masih
+// kosong, lalu meng-assign camera lama yang sudah punya stream ke node tersebut
+func (s *mediaServerService) EnsureDefault(apiURL, publicURL, username, password string) error {
+	if err := s.refresh(); err != nil {
+		return fmt.Errorf("failed to load media servers: %w", err)
+	}
+
+	s.mu.RLock()
+	empty := len(s.servers) == 0
+	s.mu.RUnlock()
+
+	if empty {
+		server := &models.MediaServer{
+			Name:       "default",
+			APIURL:     apiURL,
+			PublicURL:  publicURL,
+			Username:   username,
+			Password:   password,
+			MaxStreams: 0,
+			Status:     models.MediaServerUnknown,
+			IsActive:   true,
+		}
+		if err := s.credentials.SealMediaServer(server); err != nil {
+			return err
+		}
+		if err := s.serverRepo.Create(server); err != nil {
+			return err
+		}
+
+		assigned, err := s.serverRepo.AssignUnplacedCameras(server.ID)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("✓ Registered default media server %s (%d cameras assigned)", apiURL, assigned)
+	}
+
+	return s.refresh()
+}
+
+// List mengambil semua node beserta jumlah stream-nya
+func (s *mediaServerService) List() ([]*models.MediaServer, error) {
+	servers, err := s.serverRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media servers: %w", err)
+	}
+	return servers, nil
+}
+
+// GetByID mengambil satu node
+func (s *mediaServerService) GetByID(id string) (*models.MediaServer, error) {
+	server, err := s.serverRepo.GetByID(id)
+	if err != nil {
+		return nil, ErrMediaServerNotFound
+	}
+	return server, nil
+}
+
+// Create mendaftarkan node baru
+func (s *mediaServerService) Create(req *models.CreateMediaServerRequest) (*models.MediaServer, error) {
+	server := &models.MediaServer{
+		Name:       req.Name,
+		APIURL:     req.APIURL,
+		PublicURL:  req.PublicURL,
+		Username:   req.Username,
+		Password:   req.Password,
+		MaxStreams: req.MaxStreams,
+		Status:     models.MediaServerUnknown,
+		IsActive:   true,
+	}
+
+	if server.PublicURL == "" {
+		server.PublicURL = server.APIURL
+	}
+
+	if err := s.credentials.SealMediaServer(server); err != nil {
+		return nil, err
+	}
+	if err := s.serverRepo.Create(server); err != nil {
+		return nil, err
+	}
+
+	if err := s.refresh(); err != nil {
+		log.Printf("Error refreshing media servers: %v", err)
+	}
+
+	return server, nil
+}
+
+// Update mengupdate konfigurasi node
+func (s *mediaServerService) Update(id string, req *models.UpdateMediaServerRequest) (*models.MediaServer, error) {
+	server, err := s.serverRepo.GetByID(id)
+	if err != nil {
+		return nil, ErrMediaServerNotFound
+	}
+
+	// Password lama didekripsi agar disimpan ulang dengan key aktif jika
+	// tidak diganti
+	if err := s.credentials.RevealMediaServer(server); err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		server.Name = req.Name
+	}
+	if req.APIURL != "" {
+		server.APIURL = req.APIURL
+	}
+	if req.PublicURL != "" {
+		server.PublicURL = req.PublicURL
+	}
+	if req.Username != "" {
+		server.Username = req.Username
+	}
+	if req.Password != "" {
+		server.Password = req.Password
+	}
+	if req.MaxStreams != nil {
+		server.MaxStreams = *req.MaxStreams
+	}
+	if req.IsActive != nil {
+		server.IsActive = *req.IsActive
+	}
+
+	if err := s.credentials.SealMediaServer(server); err != nil {
+		return nil, err
+	}
+	if err := s.serverRepo.Update(id, server); err != nil {
+		return nil, err
+	}
+
+	if err := s.refresh(); err != nil {
+		log.Printf("Error refreshing media servers: %v", err)
+	}
+
+	return s.serverRepo.GetByID(id)
+}
+
+// Delete menghapus node. Node yang masih punya stream tidak bisa dihapus,
+// nonaktifkan dulu agar stream dipindahkan oleh failover.
+func (s *mediaServerService) Delete(id string) error {
+	server, err := s.serverRepo.GetByID(id)
+	if err != nil {
+		return ErrMediaServerNotFound
+	}
+
+	if server.StreamCount > 0 {
+		return fmt.Errorf("media server still has %d streams assigned", server.StreamCount)
+	}
+
+	if err := s.serverRepo.Delete(id); err != nil {
+		return err
+	}
+
+	return s.refresh()
+}
+
+// ClientFor mengembalikan client RTSPtoWeb untuk node tertentu
+func (s *mediaServerService) ClientFor(serverID string) (RTSPService, error) {
+	s.mu.RLock()
+	client, ok := s.clients[serverID]
+	s.mu.RUnlock()
+
+	if ok {
+		return client, nil
+	}
+
+	// Node mungkin baru ditambahkan oleh instance lain
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if client, ok := s.clients[serverID]; ok {
+		return client, nil
+	}
+
+	return nil, ErrMediaServerNotFound
+}
+
+// ClientForCamera mengembalikan client untuk node tempat stream camera berjalan
+func (s *mediaServerService) ClientForCamera(camera *models.Camera) (RTSPService, error) {
+	if !camera.MediaServerID.Valid || camera.MediaServerID.String == "" {
+		return nil, ErrNoMediaServer
+	}
+	return s.ClientFor(camera.MediaServerID.String)
+}
+
+// Place memilih node aktif dan sehat dengan beban relatif paling kecil untuk stream baru
+func (s *mediaServerService) Place() (*models.MediaServer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var best *models.MediaServer
+	bestLoad := 0.0
+	for _, server := range s.servers {
+		if !server.IsActive || server.Status == models.MediaServerOffline || !server.HasCapacity() {
+			continue
+		}
+
+		load := float64(server.StreamCount)
+		if server.MaxStreams > 0 {
+			load = float64(server.StreamCount) / float64(server.MaxStreams)
+		}
+
+		if best == nil || load < bestLoad {
+			best = server
+			bestLoad = load
+		}
+	}
+
+	if best == nil {
+		return nil, ErrNoMediaServer
+	}
+
+	// Hitung langsung agar placement berikutnya sebelum refresh tetap seimbang
+	best.StreamCount++
+
+	placed := *best
+	return &placed, nil
+}
+
+// StartHealthJob runs periodic media server health checks and failover
+func (s *mediaServerService) StartHealthJob(interval time.Duration, failoverThreshold int) {
+	if failoverThreshold > 0 {
+		s.failoverThreshold = failoverThreshold
+	}
+
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		for range ticker.C {
+			s.CheckAll()
+		}
+	}()
+
+	log.Printf("✓ Media server health job started (interval: %v, failover after %d failures)", interval, s.failoverThreshold)
+}
+
+// CheckAll mengecek setiap node aktif. Node yang gagal merespon sebanyak
+// failoverThreshold kali berturut-turut ditandai OFFLINE dan camera-nya
+// dipindahkan ke node lain yang sehat.
+func (s *mediaServerService) CheckAll() {
+	if err := s.refresh(); err != nil {
+		log.Printf("Error loading media servers: %v", err)
+		return
+	}
+
+	s.mu.RLock()
+	servers := make([]*models.MediaServer, 0, len(s.servers))
+	for _, server := range s.servers {
+		servers = append(servers, server)
+	}
+	s.mu.RUnlock()
+
+	for _, server := range servers {
+		if !server.IsActive {
+			// Node nonaktif tidak dicek, tapi stream-nya tetap dipindahkan
+			s.failover(server)
+			continue
+		}
+
+		client, err := s.ClientFor(server.ID)
+		if err != nil {
+			continue
+		}
+
+		if _, err := client.ListStreams(context.Background()); err != nil {
+			s.mu.Lock()
+			s.failures[server.ID]++
+			failures := s.failures[server.ID]
+			s.mu.Unlock()
+
+			log.Printf("Media server %s (%s) health check failed (%d/%d): %v", server.Name, server.APIURL, failures, s.failoverThreshold, err)
+
+			if failures >= s.failoverThreshold {
+				s.setStatus(server, models.MediaServerOffline)
+				s.failover(server)
+			}
+			continue
+		}
+
+		s.mu.Lock()
+		s.failures[server.ID] = 0
+		s.mu.Unlock()
+
+		s.setStatus(server, models.MediaServerOnline)
+	}
+}
+
+// setStatus menyimpan status node ke database dan memory
+func (s *mediaServerService) setStatus(server *models.MediaServer, status string) {
+	if server.Status != status {
+		log.Printf("Media server %s status changed: %s -> %s", server.Name, server.Status, status)
+	}
+
+	s.mu.Lock()
+	server.Status = status
+	s.mu.Unlock()
+
+	if err := s.serverRepo.UpdateStatus(server.ID, status); err != nil {
+		log.Printf("Error updating media server %s status: %v", server.ID, err)
+	}
+}
+
+// failover memindahkan semua stream camera dari node ke node sehat lainnya.
+// Camera yang gagal dipindah akan dicoba lagi pada health check berikutnya.
+func (s *mediaServerService) failover(server *models.MediaServer) {
+	cameras, err := s.cameraRepo.GetByMediaServer(server.ID)
+	if err != nil {
+		log.Printf("Error loading cameras for failover from %s: %v", server.Name, err)
+		return
+	}
+
+	for _, camera := range cameras {
+		target, err := s.Place()
+		if err != nil {
+			log.Printf("Failover of camera %s from %s failed: %v", camera.ID, server.Name, err)
+			return
+		}
+
+		if err := s.moveCamera(camera, target); err != nil {
+			log.Printf("Failover of camera %s to %s failed: %v", camera.ID, target.Name, err)
+			s.mu.Lock()
+			if placed, ok := s.servers[target.ID]; ok {
+				placed.StreamCount--
+			}
+			s.mu.Unlock()
+			continue
+		}
+
+		log.Printf("Camera %s (%s) moved from media server %s to %s", camera.ID, camera.Name, server.Name, target.Name)
+	}
+}
+
+// moveCamera mendaftarkan stream camera di node tujuan dan menyimpan assignment baru
+func (s *mediaServerService) moveCamera(camera *models.Camera, target *models.MediaServer) error {
+	client, err := s.ClientFor(target.ID)
+	if err != nil {
+		return err
+	}
+
+	channels, err := cameraChannels(s.channelRepo, camera)
+	if err != nil {
+		return fmt.Errorf("failed to get camera channels: %w", err)
+	}
+
+	channels, err = s.credentials.StreamChannels(camera, channels)
+	if err != nil {
+		return err
+	}
+
+	// Stream yang sudah ada di node tujuan (sisa failover sebelumnya) tetap dipakai
+	if _, _, _, err := client.AddStream(context.Background(), camera.StreamID.String, camera.Name, channels, camera.StreamOptions); err != nil && !errors.Is(err, ErrStreamExists) {
+		return err
+	}
+
+	if err := s.cameraRepo.UpdateMediaServer(camera.ID, sql.NullString{String: target.ID, Valid: true}); err != nil {
+		return err
+	}
+
+	// Stream di node baru sudah memakai konfigurasi camera terbaru
+	if camera.SyncStatus == models.SyncStatusOutOfSync {
+		if err := s.cameraRepo.UpdateSyncStatus(camera.ID, models.SyncStatusSynced, sql.NullString{}); err != nil {
+			log.Printf("Error saving sync status of camera %s: %v", camera.ID, err)
+		}
+	}
+
+	return nil
+}
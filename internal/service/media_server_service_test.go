@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"cctv-monitoring-backend/internal/models"
+)
+
+// fakeNode adalah RTSPtoWeb palsu yang mencatat stream yang didaftarkan.
+// Request tanpa basic auth admin:secret ditolak.
+type fakeNode struct {
+	*httptest.Server
+	mu    sync.Mutex
+	added []string
+}
+
+func newFakeNode(t *testing.T) *fakeNode {
+	t.Helper()
+	node := &fakeNode{}
+	node.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.URL.Path == "/streams":
+			w.Write([]byte(`{"status":1,"payload":{}}`))
+		case strings.HasSuffix(r.URL.Path, "/add"):
+			node.mu.Lock()
+			node.added = append(node.added, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/stream/"), "/add"))
+			node.mu.Unlock()
+			w.Write([]byte(`{"status":1,"payload":"success"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(node.Close)
+	return node
+}
+
+func (n *fakeNode) streams() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.added...)
+}
+
+// newTestMediaServers membuat service dengan node yang password-nya disegel
+// seperti saat didaftarkan lewat API
+func newTestMediaServers(t *testing.T, cameraRepo *fakeCameraRepo, servers ...*models.MediaServer) (*mediaServerService, *fakeMediaServerRepo) {
+	t.Helper()
+	serverRepo := &fakeMediaServerRepo{cameraRepo: cameraRepo}
+	credentials := NewCredentialService(testCipher(t), cameraRepo, nil, serverRepo, nil, nil)
+	service := NewMediaServerService(serverRepo, cameraRepo, nil, credentials, testClientOptions).(*mediaServerService)
+
+	for _, server := range servers {
+		if server.Password == "" {
+			server.Username, server.Password = "admin", "secret"
+		}
+		if err := credentials.SealMediaServer(server); err != nil {
+			t.Fatal(err)
+		}
+		stored := *server
+		serverRepo.servers = append(serverRepo.servers, &stored)
+	}
+	if err := service.refresh(); err != nil {
+		t.Fatal(err)
+	}
+	return service, serverRepo
+}
+
+func testCamera(id, serverID string) *models.Camera {
+	return &models.Camera{
+		ID:            id,
+		Name:          "Camera " + id,
+		StreamID:      sql.NullString{String: id, Valid: true},
+		MediaServerID: sql.NullString{String: serverID, Valid: serverID != ""},
+		Channels:      []models.CameraChannel{{Index: 0, RTSPUrl: "rtsp://10.0.0.1/" + id}},
+	}
+}
+
+func TestPlaceLeastLoaded(t *testing.T) {
+	cameraRepo := &fakeCameraRepo{}
+	for i, serverID := range []string{"a", "a", "a", "b", "b", "full", "full"} {
+		cameraRepo.cameras = append(cameraRepo.cameras, testCamera(string(rune('0'+i)), serverID))
+	}
+
+	service, _ := newTestMediaServers(t, cameraRepo,
+		&models.MediaServer{ID: "a", Name: "a", MaxStreams: 4, Status: models.MediaServerOnline, IsActive: true},
+		&models.MediaServer{ID: "b", Name: "b", MaxStreams: 10, Status: models.MediaServerOnline, IsActive: true},
+		&models.MediaServer{ID: "full", Name: "full", MaxStreams: 2, Status: models.MediaServerOnline, IsActive: true},
+		&models.MediaServer{ID: "offline", Name: "offline", Status: models.MediaServerOffline, IsActive: true},
+		&models.MediaServer{ID: "inactive", Name: "inactive", IsActive: false},
+	)
+
+	// Beban a 3/4, b 2/10: b dipilih sampai bebannya melewati a, node
+	// penuh, offline dan nonaktif tidak pernah dipilih
+	var placed []string
+	for i := 0; i < 8; i++ {
+		server, err := service.Place()
+		if err != nil {
+			t.Fatalf("Place() #%d error: %v", i, err)
+		}
+		placed = append(placed, server.ID)
+	}
+
+	// b 8/10 > a 3/4, lalu a penuh sehingga kembali ke b
+	want := "b b b b b b a b"
+	if got := strings.Join(placed, " "); got != want {
+		t.Errorf("placements = %q, want %q", got, want)
+	}
+}
+
+func TestPlaceNoCapacity(t *testing.T) {
+	cameraRepo := &fakeCameraRepo{cameras: []*models.Camera{testCamera("1", "a")}}
+	service, _ := newTestMediaServers(t, cameraRepo,
+		&models.MediaServer{ID: "a", Name: "a", MaxStreams: 1, Status: models.MediaServerOnline, IsActive: true},
+		&models.MediaServer{ID: "down", Name: "down", Status: models.MediaServerOffline, IsActive: true},
+	)
+
+	if server, err := service.Place(); !errors.Is(err, ErrNoMediaServer) {
+		t.Fatalf("Place() = %v, %v, want ErrNoMediaServer", server, err)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	down := newFakeNode(t)
+	down.Close()
+	healthy := newFakeNode(t)
+
+	cameraRepo := &fakeCameraRepo{cameras: []*models.Camera{testCamera("cam-1", "down"), testCamera("cam-2", "down")}}
+	service, serverRepo := newTestMediaServers(t, cameraRepo,
+		&models.MediaServer{ID: "down", Name: "down", APIURL: down.URL, Status: models.MediaServerOnline, IsActive: true},
+		&models.MediaServer{ID: "healthy", Name: "healthy", APIURL: healthy.URL, Status: models.MediaServerOnline, IsActive: true},
+	)
+	service.failoverThreshold = 2
+
+	// Kegagalan pertama belum memindahkan stream
+	service.CheckAll()
+	if got := cameraRepo.cameras[0].MediaServerID.String; got != "down" {
+		t.Fatalf("camera moved to %q after one failed check, want it to stay on down", got)
+	}
+
+	service.CheckAll()
+	for _, camera := range cameraRepo.cameras {
+		if camera.MediaServerID.String != "healthy" {
+			t.Errorf("camera %s on %q after failover, want healthy", camera.ID, camera.MediaServerID.String)
+		}
+	}
+
+	if got := strings.Join(healthy.streams(), ","); got != "cam-1,cam-2" {
+		t.Errorf("streams added to healthy node = %q, want cam-1,cam-2", got)
+	}
+
+	statuses := map[string]string{}
+	for _, server := range serverRepo.servers {
+		statuses[server.ID] = server.Status
+	}
+	if statuses["down"] != models.MediaServerOffline || statuses["healthy"] != models.MediaServerOnline {
+		t.Errorf("statuses = %v, want down OFFLINE and healthy ONLINE", statuses)
+	}
+}
+
+func TestFailoverInactiveServer(t *testing.T) {
+	healthy := newFakeNode(t)
+	cameraRepo := &fakeCameraRepo{cameras: []*models.Camera{testCamera("cam-1", "retired")}}
+	service, _ := newTestMediaServers(t, cameraRepo,
+		&models.MediaServer{ID: "retired", Name: "retired", APIURL: "http://127.0.0.1:1", IsActive: false},
+		&models.MediaServer{ID: "healthy", Name: "healthy", APIURL: healthy.URL, Status: models.MediaServerOnline, IsActive: true},
+	)
+
+	// Node nonaktif langsung dikosongkan tanpa menunggu threshold
+	service.CheckAll()
+	if got := cameraRepo.cameras[0].MediaServerID.String; got != "healthy" {
+		t.Errorf("camera on %q, want healthy", got)
+	}
+}
+
+func TestMediaServerPasswordEncrypted(t *testing.T) {
+	service, serverRepo := newTestMediaServers(t, &fakeCameraRepo{})
+
+	server, err := service.Create(&models.CreateMediaServerRequest{
+		Name: "node-2", APIURL: "http://node-2:8083", Username: "admin", Password: "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored := serverRepo.servers[0]
+	if !stored.PasswordEnc.Valid || strings.Contains(stored.PasswordEnc.String, "secret") {
+		t.Fatalf("stored password_enc = %+v, want ciphertext", stored.PasswordEnc)
+	}
+
+	loaded, _ := serverRepo.GetByID(server.ID)
+	if err := service.credentials.RevealMediaServer(loaded); err != nil || loaded.Password != "secret" {
+		t.Errorf("revealed password = %q, %v, want secret", loaded.Password, err)
+	}
+}
+
+func TestUpdateMediaServer(t *testing.T) {
+	node := newFakeNode(t)
+	service, serverRepo := newTestMediaServers(t, &fakeCameraRepo{},
+		&models.MediaServer{ID: "a", Name: "a", APIURL: node.URL, MaxStreams: 50, IsActive: true},
+	)
+
+	zero := 0
+	if _, err := service.Update("a", &models.UpdateMediaServerRequest{MaxStreams: &zero}); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := serverRepo.servers[0]
+	if stored.MaxStreams != 0 {
+		t.Errorf("max_streams = %d, want 0 (unlimited)", stored.MaxStreams)
+	}
+
+	// Password yang tidak dikirim tetap sama dan node masih bisa dihubungi
+	client, err := service.ClientFor("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ListStreams(context.Background()); err != nil {
+		t.Errorf("ListStreams() with the kept password: %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/repository"
+)
+
+// ReconcileService menyamakan daftar stream di setiap node RTSPtoWeb dengan tabel cameras
+type ReconcileService interface {
+	Reconcile(ctx context.Context, dryRun bool) (*models.ReconcileReport, error)
+}
+
+type reconcileService struct {
+	cameraRepo   repository.CameraRepository
+	channelRepo  repository.ChannelRepository
+	mediaServers MediaServerService
+	credentials  CredentialService
+}
+
+// NewReconcileService membuat instance baru dari ReconcileService
+func NewReconcileService(cameraRepo repository.CameraRepository, channelRepo repository.ChannelRepository, mediaServers MediaServerService, credentials CredentialService) ReconcileService {
+	return &reconcileService{
+		cameraRepo:   cameraRepo,
+		channelRepo:  channelRepo,
+		mediaServers: mediaServers,
+		credentials:  credentials,
+	}
+}
+
+// Reconcile menambahkan kembali stream yang hilang dari node tempat camera
+// di-assign dan menghapus stream yatim yang tidak dimiliki camera manapun di
+// node tersebut. Dengan dryRun=true hanya perbedaannya yang dilaporkan.
+func (s *reconcileService) Reconcile(ctx context.Context, dryRun bool) (*models.ReconcileReport, error) {
+	servers, err := s.mediaServers.List()
+	if err != nil {
+		return nil, err
+	}
+
+	cameras, err := s.cameraRepo.GetWithStream()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cameras: %w", err)
+	}
+
+	report := &models.ReconcileReport{
+		DryRun:             dryRun,
+		TotalCameras:       len(cameras),
+		Missing:            []models.ReconcileItem{},
+		Orphans:            []models.ReconcileItem{},
+		OutOfSync:          []models.ReconcileItem{},
+		UnreachableServers: []string{},
+	}
+
+	// Kelompokkan camera berdasarkan node. Camera tanpa node diperlakukan sebagai
+	// stream yang hilang dan akan ditempatkan ulang.
+	camerasByServer := map[string][]*models.Camera{}
+	for _, camera := range cameras {
+		// Operasi camera masih menunggu di outbox, biarkan outbox worker yang mengirim
+		if camera.SyncStatus == models.SyncStatusPending {
+			report.Pending++
+			if !camera.MediaServerID.Valid {
+				continue
+			}
+		} else if !camera.MediaServerID.Valid {
+			s.addMissing(ctx, report, camera, "", dryRun)
+			continue
+		}
+		camerasByServer[camera.MediaServerID.String] = append(camerasByServer[camera.MediaServerID.String], camera)
+	}
+
+	for _, server := range servers {
+		if !server.IsActive {
+			continue
+		}
+
+		client, err := s.mediaServers.ClientFor(server.ID)
+		if err != nil {
+			report.UnreachableServers = append(report.UnreachableServers, server.Name)
+			continue
+		}
+
+		streams, err := client.ListStreams(ctx)
+		if err != nil {
+			log.Printf("Stream reconcile: media server %s unreachable: %v", server.Name, err)
+			report.UnreachableServers = append(report.UnreachableServers, server.Name)
+			continue
+		}
+		report.TotalStreams += len(streams)
+
+		// Stream yang dimiliki camera tapi tidak ada di node
+		owned := map[string]bool{}
+		for _, camera := range camerasByServer[server.ID] {
+			owned[camera.StreamID.String] = true
+			if camera.SyncStatus == models.SyncStatusPending {
+				continue
+			}
+
+			if _, ok := streams[camera.StreamID.String]; ok {
+				if camera.SyncStatus == models.SyncStatusOutOfSync {
+					s.editOutOfSync(ctx, report, client, camera, server.ID, dryRun)
+					continue
+				}
+				report.InSync++
+				continue
+			}
+
+			s.addMissing(ctx, report, camera, server.ID, dryRun)
+		}
+
+		// Stream di node yang tidak dimiliki camera manapun
+		for streamID, stream := range streams {
+			if owned[streamID] {
+				continue
+			}
+
+			item := models.ReconcileItem{
+				StreamID:      streamID,
+				MediaServerID: server.ID,
+				Name:          stream.Name,
+				Action:        models.ReconcileActionRemove,
+			}
+
+			if !dryRun {
+				if err := client.RemoveStream(ctx, streamID); err != nil && !errors.Is(err, ErrStreamNotFound) {
+					item.Error = err.Error()
+					report.FailedActions++
+				} else {
+					item.Applied = true
+				}
+			}
+
+			report.Orphans = append(report.Orphans, item)
+		}
+	}
+
+	log.Printf("Stream reconcile (dry_run=%v): %d in sync, %d missing, %d orphans, %d out of sync, %d pending, %d failed, %d servers unreachable",
+		dryRun, report.InSync, len(report.Missing), len(report.Orphans), len(report.OutOfSync), report.Pending, report.FailedActions, len(report.UnreachableServers))
+
+	return report, nil
+}
+
+// addMissing mencatat stream camera yang hilang dan (jika bukan dry run)
+// mendaftarkannya kembali. serverID kosong berarti camera ditempatkan ke node baru.
+func (s *reconcileService) addMissing(ctx context.Context, report *models.ReconcileReport, camera *models.Camera, serverID string, dryRun bool) {
+	item := models.ReconcileItem{
+		StreamID:      camera.StreamID.String,
+		CameraID:      camera.ID,
+		MediaServerID: serverID,
+		Name:          camera.Name,
+		Action:        models.ReconcileActionAdd,
+	}
+
+	if !dryRun {
+		if placedID, err := s.addStream(ctx, camera, serverID); err != nil {
+			item.Error = err.Error()
+			report.FailedActions++
+		} else {
+			item.MediaServerID = placedID
+			item.Applied = true
+		}
+	}
+
+	report.Missing = append(report.Missing, item)
+}
+
+// editOutOfSync mengirim ulang konfigurasi camera yang sebelumnya ditolak RTSPtoWeb
+func (s *reconcileService) editOutOfSync(ctx context.Context, report *models.ReconcileReport, client RTSPService, camera *models.Camera, serverID string, dryRun bool) {
+	item := models.ReconcileItem{
+		StreamID:      camera.StreamID.String,
+		CameraID:      camera.ID,
+		MediaServerID: serverID,
+		Name:          camera.Name,
+		Action:        models.ReconcileActionEdit,
+	}
+
+	if !dryRun {
+		channels, err := cameraChannels(s.channelRepo, camera)
+		if err == nil {
+			channels, err = s.credentials.StreamChannels(camera, channels)
+		}
+		if err == nil {
+			err = client.EditStream(ctx, camera.StreamID.String, camera.Name, channels, camera.StreamOptions)
+		}
+
+		if err != nil {
+			item.Error = err.Error()
+			report.FailedActions++
+			s.saveSyncStatus(camera.ID, models.SyncStatusOutOfSync, err)
+		} else {
+			item.Applied = true
+			s.saveSyncStatus(camera.ID, models.SyncStatusSynced, nil)
+		}
+	}
+
+	report.OutOfSync = append(report.OutOfSync, item)
+}
+
+// saveSyncStatus menyimpan status sinkronisasi camera, error hanya di-log
+func (s *reconcileService) saveSyncStatus(cameraID, status string, syncErr error) {
+	syncError := sql.NullString{}
+	if syncErr != nil {
+		syncError = sql.NullString{String: syncErr.Error(), Valid: true}
+	}
+
+	if err := s.cameraRepo.UpdateSyncStatus(cameraID, status, syncError); err != nil {
+		log.Printf("Error saving sync status of camera %s: %v", cameraID, err)
+	}
+}
+
+// addStream mendaftarkan ulang stream camera beserta semua channel-nya
+func (s *reconcileService) addStream(ctx context.Context, camera *models.Camera, serverID string) (string, error) {
+	if serverID == "" {
+		server, err := s.mediaServers.Place()
+		if err != nil {
+			return "", err
+		}
+		serverID = server.ID
+	}
+
+	client, err := s.mediaServers.ClientFor(serverID)
+	if err != nil {
+		return "", err
+	}
+
+	channels, err := cameraChannels(s.channelRepo, camera)
+	if err != nil {
+		return "", fmt.Errorf("failed to get camera channels: %w", err)
+	}
+
+	channels, err = s.credentials.StreamChannels(camera, channels)
+	if err != nil {
+		return "", err
+	}
+
+	// Stream yang ternyata sudah ada (mis. ditambahkan di antara list dan add) dianggap berhasil
+	if _, _, _, err := client.AddStream(ctx, camera.StreamID.String, camera.Name, channels, camera.StreamOptions); err != nil && !errors.Is(err, ErrStreamExists) {
+		return "", err
+	}
+
+	if !camera.MediaServerID.Valid || camera.MediaServerID.String != serverID {
+		if err := s.cameraRepo.UpdateMediaServer(camera.ID, sql.NullString{String: serverID, Valid: true}); err != nil {
+			return "", err
+		}
+	}
+
+	// Stream baru didaftarkan dengan konfigurasi terbaru
+	if camera.SyncStatus == models.SyncStatusOutOfSync {
+		s.saveSyncStatus(camera.ID, models.SyncStatusSynced, nil)
+	}
+
+	return serverID, nil
+}
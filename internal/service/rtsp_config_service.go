@@ -0,0 +1,597 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/repository"
+)
+
+// defaultRTSPPort dipakai jika RTSP URL tidak menyebut port
+const defaultRTSPPort = 554
+
+// ErrMediaServerRequired dikembalikan Diff jika node tidak bisa dipilih otomatis
+var ErrMediaServerRequired = errors.New("media_server_id is required when more than one media server is active")
+
+// RTSPConfigService mengelola config.json RTSPtoWeb: import stream dari config
+// yang sudah berjalan ke tabel cameras, dan sebaliknya generate config dari
+// tabel cameras untuk deploy node secara deklaratif
+type RTSPConfigService interface {
+	Import(config *models.RTSPtoWebConfig, opts models.ConfigImportOptions) (*models.ConfigImportReport, error)
+	Generate(mediaServerID string) (*models.RTSPtoWebConfig, error)
+	Diff(ctx context.Context, mediaServerID string) (*models.ConfigDiff, error)
+}
+
+// RTSPConfigDefaults adalah nilai blok "server" untuk config yang di-generate
+type RTSPConfigDefaults struct {
+	HTTPLogin    string // Dipakai jika node tidak punya username sendiri
+	HTTPPassword string
+	HTTPDemo     bool // Halaman demo RTSPtoWeb, sebaiknya mati di production
+	TokenEnabled bool
+	TokenBackend string // URL token backend lengkap dengan secret
+}
+
+type rtspConfigService struct {
+	cameraRepo   repository.CameraRepository
+	channelRepo  repository.ChannelRepository
+	mediaServers MediaServerService
+	credentials  CredentialService
+	transactor   repository.Transactor
+	revisionRepo repository.RevisionRepository
+	defaults     RTSPConfigDefaults
+}
+
+// NewRTSPConfigService membuat instance baru dari RTSPConfigService
+func NewRTSPConfigService(cameraRepo repository.CameraRepository, channelRepo repository.ChannelRepository, mediaServers MediaServerService, credentials CredentialService, transactor repository.Transactor, revisionRepo repository.RevisionRepository, defaults RTSPConfigDefaults) RTSPConfigService {
+	return &rtspConfigService{
+		cameraRepo:   cameraRepo,
+		channelRepo:  channelRepo,
+		mediaServers: mediaServers,
+		credentials:  credentials,
+		transactor:   transactor,
+		revisionRepo: revisionRepo,
+		defaults:     defaults,
+	}
+}
+
+// ParseRTSPtoWebConfig membaca isi config.json RTSPtoWeb
+func ParseRTSPtoWebConfig(data []byte) (*models.RTSPtoWebConfig, error) {
+	var config models.RTSPtoWebConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid RTSPtoWeb config: %w", err)
+	}
+
+	if config.Streams == nil {
+		return nil, fmt.Errorf("invalid RTSPtoWeb config: missing streams")
+	}
+
+	return &config, nil
+}
+
+// Import membuat camera untuk setiap stream di config. Stream ID (UUID) dari
+// config disimpan sebagai stream_id, sehingga stream yang sudah berjalan di
+// RTSPtoWeb langsung dimiliki camera tanpa didaftarkan ulang.
+func (s *rtspConfigService) Import(config *models.RTSPtoWebConfig, opts models.ConfigImportOptions) (*models.ConfigImportReport, error) {
+	switch opts.Mode {
+	case "":
+		opts.Mode = models.ConfigImportSkip
+	case models.ConfigImportSkip, models.ConfigImportUpdate:
+	default:
+		return nil, fmt.Errorf("invalid import mode %q (must be %s or %s)", opts.Mode, models.ConfigImportSkip, models.ConfigImportUpdate)
+	}
+
+	if opts.MediaServerID != "" {
+		if _, err := s.mediaServers.GetByID(opts.MediaServerID); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &models.ConfigImportReport{
+		DryRun:       opts.DryRun,
+		Mode:         opts.Mode,
+		TotalStreams: len(config.Streams),
+		Items:        []models.ConfigImportItem{},
+	}
+
+	// Urutkan agar report stabil
+	streamIDs := make([]string, 0, len(config.Streams))
+	for streamID := range config.Streams {
+		streamIDs = append(streamIDs, streamID)
+	}
+	sort.Strings(streamIDs)
+
+	for _, streamID := range streamIDs {
+		item := s.importStream(streamID, config.Streams[streamID], config.ChannelDefaults, opts)
+
+		switch item.Action {
+		case models.ConfigImportActionCreate:
+			report.Created++
+		case models.ConfigImportActionUpdate:
+			report.Updated++
+		case models.ConfigImportActionSkip:
+			report.Skipped++
+		case models.ConfigImportActionError:
+			report.Failed++
+		}
+		if item.NeedsLocation {
+			report.NeedsLocation++
+		}
+
+		report.Items = append(report.Items, item)
+	}
+
+	log.Printf("RTSPtoWeb config import (dry_run=%v, mode=%s): %d created, %d updated, %d skipped, %d failed, %d need location",
+		opts.DryRun, opts.Mode, report.Created, report.Updated, report.Skipped, report.Failed, report.NeedsLocation)
+
+	return report, nil
+}
+
+// importStream membuat atau mengupdate camera untuk satu stream config
+func (s *rtspConfigService) importStream(streamID string, stream models.RTSPtoWebStream, defaults models.RTSPtoWebChannelDefaults, opts models.ConfigImportOptions) models.ConfigImportItem {
+	item := models.ConfigImportItem{
+		StreamID: streamID,
+		Name:     stream.Name,
+		Channels: len(stream.Channels),
+	}
+
+	fail := func(err error) models.ConfigImportItem {
+		item.Action = models.ConfigImportActionError
+		item.Message = err.Error()
+		return item
+	}
+
+	if streamID == "" {
+		return fail(fmt.Errorf("stream id is empty"))
+	}
+	if stream.Name == "" {
+		item.Name = streamID
+	}
+
+	channels, err := configChannels(stream.Channels)
+	if err != nil {
+		return fail(err)
+	}
+	rtspURL := mainChannel(channels).RTSPUrl
+	streamOptions := configStreamOptions(stream.Channels[strconv.Itoa(mainChannel(channels).Index)], defaults)
+
+	host, port, err := parseRTSPHost(rtspURL)
+	if err != nil {
+		return fail(err)
+	}
+	item.IPAddress = host
+	item.Port = port
+
+	existing, err := s.cameraRepo.GetByStreamID(streamID)
+	if err != nil && !errors.Is(err, repository.ErrCameraNotFound) {
+		return fail(err)
+	}
+
+	if existing != nil {
+		item.CameraID = existing.ID
+		item.NeedsLocation = existing.Latitude == 0 && existing.Longitude == 0
+
+		switch {
+		case !existing.IsActive:
+			item.Action = models.ConfigImportActionSkip
+			item.Message = "camera with this stream id was deleted"
+			return item
+		case opts.Mode == models.ConfigImportSkip:
+			item.Action = models.ConfigImportActionSkip
+			item.Message = "camera already exists"
+			return item
+		}
+
+		current, err := cameraChannels(s.channelRepo, existing)
+		if err != nil {
+			return fail(fmt.Errorf("failed to get camera channels: %w", err))
+		}
+		before := models.NewCameraSnapshot(existing)
+
+		// Credential di URL config dipindahkan ke kolom credential camera
+		credentialsChanged, err := s.credentials.Seal(existing, channels, models.RTSPCredentialsRequest{})
+		if err != nil {
+			return fail(err)
+		}
+		if existing.Name == item.Name && !channelsChanged(current, channels) && !credentialsChanged && existing.StreamOptions == streamOptions &&
+			(opts.MediaServerID == "" || existing.MediaServerID.String == opts.MediaServerID) {
+			item.Action = models.ConfigImportActionSkip
+			item.Message = "camera is up to date"
+			return item
+		}
+
+		item.Action = models.ConfigImportActionUpdate
+		if opts.DryRun {
+			return item
+		}
+
+		existing.Name = item.Name
+		existing.IPAddress = sql.NullString{String: host, Valid: true}
+		existing.Port = sql.NullInt64{Int64: int64(port), Valid: true}
+		existing.StreamOptions = streamOptions
+		if opts.MediaServerID != "" {
+			existing.MediaServerID = sql.NullString{String: opts.MediaServerID, Valid: true}
+		}
+
+		err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+			if err := s.cameraRepo.WithTx(tx).Update(existing.ID, existing); err != nil {
+				return err
+			}
+			existing.Channels = channels
+			if err := recordRevision(tx, s.revisionRepo, existing, before, opts.UserID, models.RevisionActionConfigImport); err != nil {
+				return err
+			}
+			return s.channelRepo.WithTx(tx).ReplaceForCamera(existing.ID, channels)
+		})
+		if err != nil {
+			return fail(err)
+		}
+
+		item.Applied = true
+		return item
+	}
+
+	// Config tidak menyimpan lokasi, koordinat diisi placeholder dan ditandai
+	item.Action = models.ConfigImportActionCreate
+	item.NeedsLocation = true
+
+	camera := &models.Camera{
+		Name:          item.Name,
+		RTSPUrl:       rtspURL,
+		StreamID:      sql.NullString{String: streamID, Valid: true},
+		IPAddress:     sql.NullString{String: host, Valid: true},
+		Port:          sql.NullInt64{Int64: int64(port), Valid: true},
+		Tags:          []string{models.TagNeedsLocation},
+		Status:        models.CameraStatusUnknown,
+		IsActive:      true,
+		CreatedBy:     sql.NullString{String: opts.UserID, Valid: true},
+		MediaServerID: sql.NullString{String: opts.MediaServerID, Valid: opts.MediaServerID != ""},
+		SyncStatus:    models.SyncStatusSynced,
+		StreamOptions: streamOptions,
+	}
+	if _, err := s.credentials.Seal(camera, channels, models.RTSPCredentialsRequest{}); err != nil {
+		return fail(err)
+	}
+	if opts.DryRun {
+		return item
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.cameraRepo.WithTx(tx).Create(camera, opts.UserID); err != nil {
+			return err
+		}
+		camera.Channels = channels
+		if err := recordRevision(tx, s.revisionRepo, camera, nil, opts.UserID, models.RevisionActionConfigImport); err != nil {
+			return err
+		}
+		return s.channelRepo.WithTx(tx).ReplaceForCamera(camera.ID, channels)
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	item.CameraID = camera.ID
+	item.Applied = true
+	return item
+}
+
+// Generate membuat config.json RTSPtoWeb lengkap dari tabel cameras: blok server,
+// channel_defaults dan setiap camera aktif yang stream-nya di-start. Jika
+// mediaServerID diisi, hanya camera yang ditempatkan di node tersebut.
+func (s *rtspConfigService) Generate(mediaServerID string) (*models.RTSPtoWebConfig, error) {
+	config := &models.RTSPtoWebConfig{
+		// Channel selalu menyebut on_demand sendiri. RTSPtoWeb menggabungkan
+		// channel_defaults ke setiap channel, sehingga default true tidak bisa
+		// dimatikan per channel.
+		ChannelDefaults: models.RTSPtoWebChannelDefaults{OnDemand: false},
+		Server:          s.serverConfig(nil),
+		Streams:         map[string]models.RTSPtoWebStream{},
+	}
+
+	var cameras []*models.Camera
+	if mediaServerID != "" {
+		server, err := s.mediaServers.GetByID(mediaServerID)
+		if err != nil {
+			return nil, err
+		}
+		config.Server = s.serverConfig(server)
+
+		cameras, err = s.cameraRepo.GetByMediaServer(server.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cameras: %w", err)
+		}
+	} else {
+		var err error
+		cameras, err = s.cameraRepo.GetWithStream()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cameras: %w", err)
+		}
+	}
+
+	for _, camera := range cameras {
+		channels, err := cameraChannels(s.channelRepo, camera)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get channels of camera %s: %w", camera.ID, err)
+		}
+
+		channels, err = s.credentials.StreamChannels(camera, channels)
+		if err != nil {
+			return nil, err
+		}
+
+		config.Streams[camera.StreamID.String] = configStream(camera, channels)
+	}
+
+	return config, nil
+}
+
+// Diff membandingkan stream dari database dengan stream yang sedang berjalan di
+// node. RTSPtoWeb tidak mengekspos blok server lewat API, sehingga hanya stream
+// yang dibandingkan. mediaServerID boleh kosong jika hanya ada satu node.
+func (s *rtspConfigService) Diff(ctx context.Context, mediaServerID string) (*models.ConfigDiff, error) {
+	server, err := s.resolveServer(mediaServerID)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := s.Generate(server.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.mediaServers.ClientFor(server.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	running, err := client.ListStreams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.ConfigDiff{
+		MediaServerID:   server.ID,
+		MediaServerName: server.Name,
+		DatabaseStreams: len(config.Streams),
+		NodeStreams:     len(running),
+		Items:           []models.ConfigDiffItem{},
+	}
+
+	for streamID, stream := range config.Streams {
+		node, ok := running[streamID]
+		if !ok {
+			diff.Items = append(diff.Items, models.ConfigDiffItem{
+				StreamID: streamID,
+				Name:     stream.Name,
+				Type:     models.ConfigDiffAdded,
+			})
+			continue
+		}
+
+		if changes := streamChanges(stream, node); len(changes) > 0 {
+			diff.Items = append(diff.Items, models.ConfigDiffItem{
+				StreamID: streamID,
+				Name:     stream.Name,
+				Type:     models.ConfigDiffChanged,
+				Changes:  changes,
+			})
+		}
+	}
+
+	for streamID, node := range running {
+		if _, ok := config.Streams[streamID]; !ok {
+			diff.Items = append(diff.Items, models.ConfigDiffItem{
+				StreamID: streamID,
+				Name:     node.Name,
+				Type:     models.ConfigDiffRemoved,
+			})
+		}
+	}
+
+	sort.Slice(diff.Items, func(i, j int) bool { return diff.Items[i].StreamID < diff.Items[j].StreamID })
+	diff.InSync = len(diff.Items) == 0
+
+	return diff, nil
+}
+
+// resolveServer mengambil node untuk diff. Tanpa ID, node dipilih otomatis jika
+// hanya ada satu node aktif.
+func (s *rtspConfigService) resolveServer(mediaServerID string) (*models.MediaServer, error) {
+	if mediaServerID != "" {
+		return s.mediaServers.GetByID(mediaServerID)
+	}
+
+	servers, err := s.mediaServers.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var active []*models.MediaServer
+	for _, server := range servers {
+		if server.IsActive {
+			active = append(active, server)
+		}
+	}
+
+	if len(active) != 1 {
+		return nil, ErrMediaServerRequired
+	}
+
+	return active[0], nil
+}
+
+// serverConfig membuat blok "server". Jika server diisi, login dan port HTTP
+// diambil dari node tersebut.
+func (s *rtspConfigService) serverConfig(server *models.MediaServer) models.RTSPtoWebServerConfig {
+	config := models.RTSPtoWebServerConfig{
+		HTTPDemo:     s.defaults.HTTPDemo,
+		HTTPLogin:    s.defaults.HTTPLogin,
+		HTTPPassword: s.defaults.HTTPPassword,
+		HTTPPort:     ":8083",
+		HTTPSPort:    ":443",
+		ICEServers:   []string{},
+		LogLevel:     "info",
+		Token: models.RTSPtoWebTokenConfig{
+			Backend: s.defaults.TokenBackend,
+			Enable:  s.defaults.TokenEnabled,
+		},
+	}
+
+	if server == nil {
+		return config
+	}
+
+	if server.Username != "" {
+		config.HTTPLogin = server.Username
+		config.HTTPPassword = server.Password
+	}
+
+	if u, err := url.Parse(server.APIURL); err == nil && u.Port() != "" {
+		config.HTTPPort = ":" + u.Port()
+	}
+
+	return config
+}
+
+// configStream mengubah camera dan channel-nya menjadi stream config.json
+func configStream(camera *models.Camera, channels []models.CameraChannel) models.RTSPtoWebStream {
+	return models.RTSPtoWebStream{
+		Name:     camera.Name,
+		Channels: streamChannels(channels, camera.StreamOptions),
+	}
+}
+
+// streamChanges mencatat perbedaan stream database dengan stream di node. URL
+// tidak ditampilkan karena berisi credential camera.
+func streamChanges(stream models.RTSPtoWebStream, node models.MediaStream) []string {
+	var changes []string
+
+	if stream.Name != node.Name {
+		changes = append(changes, fmt.Sprintf("name: %q -> %q", node.Name, stream.Name))
+	}
+
+	keys := make([]string, 0, len(stream.Channels))
+	for key := range stream.Channels {
+		keys = append(keys, key)
+	}
+	for key := range node.Channels {
+		if _, ok := stream.Channels[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		want, inDB := stream.Channels[key]
+		have, onNode := node.Channels[key]
+
+		switch {
+		case !onNode:
+			changes = append(changes, fmt.Sprintf("channel %s: missing on node", key))
+		case !inDB:
+			changes = append(changes, fmt.Sprintf("channel %s: not in database", key))
+		default:
+			if want.URL != have.URL {
+				changes = append(changes, fmt.Sprintf("channel %s: url changed", key))
+			}
+			if want.OnDemand != have.OnDemand {
+				changes = append(changes, fmt.Sprintf("channel %s: on_demand %v -> %v", key, have.OnDemand, want.OnDemand))
+			}
+			if want.Audio != have.Audio {
+				changes = append(changes, fmt.Sprintf("channel %s: audio %v -> %v", key, have.Audio, want.Audio))
+			}
+			if want.Debug != have.Debug {
+				changes = append(changes, fmt.Sprintf("channel %s: debug %v -> %v", key, have.Debug, want.Debug))
+			}
+			if want.InsecureSkipVerify != have.InsecureSkipVerify {
+				changes = append(changes, fmt.Sprintf("channel %s: insecure_skip_verify %v -> %v", key, have.InsecureSkipVerify, want.InsecureSkipVerify))
+			}
+		}
+	}
+
+	return changes
+}
+
+// configChannels mengubah channel config.json menjadi CameraChannel. Index
+// channel harus sama dengan key di config karena dipakai di URL RTSPtoWeb.
+func configChannels(configChannels map[string]models.RTSPtoWebChannel) ([]models.CameraChannel, error) {
+	if len(configChannels) == 0 {
+		return nil, fmt.Errorf("stream has no channels")
+	}
+
+	indexes := make([]int, 0, len(configChannels))
+	for key := range configChannels {
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("invalid channel key %q", key)
+		}
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	reqs := make([]models.CameraChannelRequest, 0, len(indexes))
+	for _, index := range indexes {
+		reqs = append(reqs, models.CameraChannelRequest{
+			RTSPUrl: configChannels[strconv.Itoa(index)].URL,
+		})
+	}
+
+	channels, err := buildChannels(reqs, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range channels {
+		channels[i].Index = indexes[i]
+	}
+
+	return channels, nil
+}
+
+// configStreamOptions mengambil opsi stream dari channel config. Seperti RTSPtoWeb,
+// channel_defaults yang true berlaku walaupun channel menyebut false.
+func configStreamOptions(channel models.RTSPtoWebChannel, defaults models.RTSPtoWebChannelDefaults) models.StreamOptions {
+	return models.StreamOptions{
+		OnDemand:           channel.OnDemand || defaults.OnDemand,
+		Audio:              channel.Audio,
+		Debug:              channel.Debug,
+		InsecureSkipVerify: channel.InsecureSkipVerify,
+	}
+}
+
+// parseRTSPHost mengambil host dan port dari RTSP URL
+func parseRTSPHost(rawURL string) (string, int, error) {
+	// Error url.Parse tidak diteruskan karena memuat URL beserta password
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid RTSP URL: malformed URL")
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return "", 0, fmt.Errorf("invalid RTSP URL: missing host")
+	}
+
+	port := defaultRTSPPort
+	if p := u.Port(); p != "" {
+		port, err = strconv.Atoi(p)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid RTSP URL port %q", p)
+		}
+	}
+
+	// Hostname tetap disimpan, tapi alamat IP dinormalisasi
+	if ip := net.ParseIP(host); ip != nil {
+		host = ip.String()
+	}
+
+	return host, port, nil
+}
@@ -2,17 +2,97 @@ package service
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"cctv-monitoring-backend/internal/models"
+)
+
+// Typed errors dari RTSPtoWeb. Error yang dikembalikan RTSPService selalu
+// *RTSPError yang membungkus salah satu sentinel ini, cek dengan errors.Is.
+var (
+	ErrStreamNotFound    = errors.New("stream not found on media server")
+	ErrStreamExists      = errors.New("stream already exists on media server")
+	ErrMediaUnauthorized = errors.New("media server rejected credentials")
+	ErrMediaRejected     = errors.New("media server rejected request")
+	ErrMediaUnavailable  = errors.New("media server unavailable")
+	ErrMediaTimeout      = errors.New("media server request timed out")
+	ErrCircuitOpen       = errors.New("circuit breaker open")
 )
 
+// maxRTSPResponseSize membatasi body response API (bukan media) yang dibaca ke memory
+const maxRTSPResponseSize = 10 << 20
+
+// RTSPError adalah error dari satu operasi RTSPtoWeb API
+type RTSPError struct {
+	Op         string // operasi, mis. "add stream"
+	StatusCode int    // HTTP status dari RTSPtoWeb, 0 jika tidak ada response
+	Kind       error  // salah satu sentinel error di atas
+	Message    string // pesan error dari payload RTSPtoWeb
+	Err        error  // error transport asli (optional)
+}
+
+func (e *RTSPError) Error() string {
+	msg := fmt.Sprintf("RTSPtoWeb %s: %v", e.Op, e.Kind)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" (status %d)", e.StatusCode)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	} else if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *RTSPError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// RTSPClientOptions mengatur timeout, retry dan circuit breaker client RTSPtoWeb
+type RTSPClientOptions struct {
+	Timeout          time.Duration // timeout per request (per percobaan)
+	MaxRetries       int           // retry tambahan untuk operasi idempotent
+	RetryBackoff     time.Duration // backoff awal, naik dua kali lipat setiap retry
+	BreakerThreshold int           // kegagalan berturut-turut sebelum breaker terbuka, 0 = nonaktif
+	BreakerCooldown  time.Duration // lama breaker terbuka sebelum request percobaan
+}
+
+// DefaultRTSPClientOptions mengembalikan opsi default client RTSPtoWeb
+func DefaultRTSPClientOptions() RTSPClientOptions {
+	return RTSPClientOptions{
+		Timeout:          10 * time.Second,
+		MaxRetries:       2,
+		RetryBackoff:     200 * time.Millisecond,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
 type RTSPService interface {
-	AddStream(cameraID, name, rtspURL string) (streamID, hlsURL, snapshotURL string, err error)
-	RemoveStream(streamID string) error
-	GetStreamStatus(streamID string) (string, error)
-	GetHLSURL(streamID string) string      // NEW
-	GetSnapshotURL(streamID string) string // NEW
+	AddStream(ctx context.Context, cameraID, name string, channels []models.CameraChannel, opts models.StreamOptions) (streamID, hlsURL, snapshotURL string, err error)
+	EditStream(ctx context.Context, streamID, name string, channels []models.CameraChannel, opts models.StreamOptions) error
+	RemoveStream(ctx context.Context, streamID string) error
+	GetStreamStatus(ctx context.Context, streamID string) (string, error)
+	ListStreams(ctx context.Context) (map[string]models.MediaStream, error)
+	GetHLSURL(streamID string, channel int) string      // NEW
+	GetSnapshotURL(streamID string, channel int) string // NEW
+	WebRTCOffer(ctx context.Context, streamID string, channel int, sdpOffer, token string) (string, error)
+	FetchMedia(ctx context.Context, streamID string, channel int, mediaPath, token string) (*http.Response, error)
 }
 
 type rtspService struct {
@@ -20,113 +100,461 @@ type rtspService struct {
 	publicBaseURL string
 	username      string
 	password      string
+	opts          RTSPClientOptions
 	httpClient    *http.Client
+	breaker       *circuitBreaker
 }
 
-func NewRTSPService(apiURL, publicBaseURL, username, password string) RTSPService {
+func NewRTSPService(apiURL, publicBaseURL, username, password string, opts RTSPClientOptions) RTSPService {
+	defaults := DefaultRTSPClientOptions()
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaults.Timeout
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = defaults.RetryBackoff
+	}
+	if opts.BreakerCooldown <= 0 {
+		opts.BreakerCooldown = defaults.BreakerCooldown
+	}
+
+	// Tidak memakai http.Client.Timeout karena segment HLS di-stream ke client;
+	// request API dibatasi lewat context, media lewat ResponseHeaderTimeout.
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   opts.Timeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ResponseHeaderTimeout: opts.Timeout,
+		MaxIdleConnsPerHost:   16,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
 	return &rtspService{
 		apiURL:        apiURL,
 		publicBaseURL: publicBaseURL,
 		username:      username,
 		password:      password,
-		httpClient:    &http.Client{},
+		opts:          opts,
+		httpClient:    &http.Client{Transport: transport},
+		breaker:       newCircuitBreaker(opts.BreakerThreshold, opts.BreakerCooldown),
+	}
+}
+
+// rtspRequest adalah satu panggilan API RTSPtoWeb
+type rtspRequest struct {
+	op          string
+	method      string
+	url         string
+	body        []byte
+	contentType string
+	idempotent  bool // boleh di-retry
+}
+
+// do mengirim request ke RTSPtoWeb dengan circuit breaker, timeout per percobaan
+// dan retry dengan exponential backoff untuk operasi idempotent. Body response
+// sukses (2xx) dikembalikan utuh.
+func (s *rtspService) do(ctx context.Context, r rtspRequest) ([]byte, error) {
+	if !s.breaker.Allow() {
+		return nil, &RTSPError{Op: r.op, Kind: ErrMediaUnavailable, Err: ErrCircuitOpen}
+	}
+
+	attempts := 1
+	if r.idempotent {
+		attempts += s.opts.MaxRetries
+	}
+
+	var body []byte
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if waitErr := s.backoff(ctx, attempt); waitErr != nil {
+				break
+			}
+		}
+
+		body, err = s.attempt(ctx, r)
+		if err == nil || !isTransient(err) {
+			break
+		}
+	}
+
+	s.record(ctx, err)
+
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// attempt mengirim satu percobaan request dengan timeout per call
+func (s *rtspService) attempt(ctx context.Context, r rtspRequest) ([]byte, error) {
+	callCtx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+
+	var reqBody io.Reader
+	if r.body != nil {
+		reqBody = bytes.NewReader(r.body)
+	}
+
+	req, err := http.NewRequestWithContext(callCtx, r.method, r.url, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if r.contentType != "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+	req.SetBasicAuth(s.username, s.password)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, transportError(ctx, r.op, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRTSPResponseSize))
+	if err != nil {
+		return nil, transportError(ctx, r.op, err)
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return body, nil
+	}
+
+	return nil, statusError(r.op, resp.StatusCode, body)
+}
+
+// backoff menunggu sebelum retry ke-attempt (exponential dengan jitter)
+func (s *rtspService) backoff(ctx context.Context, attempt int) error {
+	delay := s.opts.RetryBackoff << (attempt - 1)
+	delay += time.Duration(rand.Int63n(int64(s.opts.RetryBackoff)/2 + 1))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// record mencatat hasil request ke circuit breaker. Hanya error yang menunjukkan
+// server down (timeout, koneksi gagal, 5xx) yang dihitung sebagai kegagalan.
+func (s *rtspService) record(ctx context.Context, err error) {
+	switch {
+	case err == nil:
+		s.breaker.Success()
+	case ctx.Err() != nil:
+		// Dibatalkan caller, kondisi server tidak diketahui
+		s.breaker.Release()
+	case isTransient(err):
+		s.breaker.Failure()
+	default:
+		// Server merespon (mis. 404/401), berarti server hidup
+		s.breaker.Success()
 	}
 }
 
-// GetHLSURL generates HLS URL for a stream
-func (s *rtspService) GetHLSURL(streamID string) string {
+// isTransient mengecek apakah error layak di-retry dan dihitung oleh circuit breaker
+func isTransient(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	return errors.Is(err, ErrMediaUnavailable) || errors.Is(err, ErrMediaTimeout)
+}
+
+// transportError memetakan error jaringan ke RTSPError
+func transportError(ctx context.Context, op string, err error) error {
+	// Context milik caller habis/dibatalkan: bukan kesalahan media server
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return &RTSPError{Op: op, Kind: ctxErr, Err: err}
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &RTSPError{Op: op, Kind: ErrMediaTimeout, Err: err}
+	}
+
+	return &RTSPError{Op: op, Kind: ErrMediaUnavailable, Err: err}
+}
+
+// statusError memetakan response error RTSPtoWeb ke RTSPError. RTSPtoWeb
+// mengembalikan sebagian besar error sebagai HTTP 500 dengan pesan di payload,
+// jadi pesannya ikut diperiksa.
+func statusError(op string, statusCode int, body []byte) error {
+	var result struct {
+		Payload interface{} `json:"payload"`
+	}
+	message := strings.TrimSpace(string(body))
+	if err := json.Unmarshal(body, &result); err == nil {
+		if payload, ok := result.Payload.(string); ok {
+			message = payload
+		}
+	}
+	if len(message) > 200 {
+		message = message[:200]
+	}
+
+	lower := strings.ToLower(message)
+	var kind error
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		kind = ErrMediaUnauthorized
+	case statusCode == http.StatusNotFound || strings.Contains(lower, "not found"):
+		kind = ErrStreamNotFound
+	case strings.Contains(lower, "already exists"):
+		kind = ErrStreamExists
+	case statusCode >= 500:
+		kind = ErrMediaUnavailable
+	default:
+		kind = ErrMediaRejected
+	}
+
+	return &RTSPError{Op: op, StatusCode: statusCode, Kind: kind, Message: message}
+}
+
+// GetHLSURL generates HLS URL for a stream channel
+func (s *rtspService) GetHLSURL(streamID string, channel int) string {
 	if streamID == "" {
 		return ""
 	}
-	return fmt.Sprintf("%s/stream/%s/channel/0/hls/live/index.m3u8", s.publicBaseURL, streamID)
+	return fmt.Sprintf("%s/stream/%s/channel/%d/hls/live/index.m3u8", s.publicBaseURL, streamID, channel)
 }
 
-// GetSnapshotURL generates snapshot URL for a stream
-func (s *rtspService) GetSnapshotURL(streamID string) string {
+// GetSnapshotURL generates snapshot URL for a stream channel
+func (s *rtspService) GetSnapshotURL(streamID string, channel int) string {
 	if streamID == "" {
 		return ""
 	}
-	return fmt.Sprintf("%s/stream/%s/channel/0/jpeg", s.publicBaseURL, streamID)
+	return fmt.Sprintf("%s/stream/%s/channel/%d/jpeg", s.publicBaseURL, streamID, channel)
 }
 
-func (s *rtspService) AddStream(cameraID, name, rtspURL string) (streamID, hlsURL, snapshotURL string, err error) {
-	payload := map[string]interface{}{
-		"name": name,
-		"channels": map[string]interface{}{
-			"0": map[string]interface{}{
-				"url": rtspURL,
-			},
-		},
+// AddStream mendaftarkan camera ke RTSPtoWeb. URL yang dikembalikan adalah URL channel pertama. Tidak di-retry karena bukan
+// operasi idempotent (percobaan kedua akan gagal dengan ErrStreamExists).
+func (s *rtspService) AddStream(ctx context.Context, cameraID, name string, channels []models.CameraChannel, opts models.StreamOptions) (streamID, hlsURL, snapshotURL string, err error) {
+	jsonData, err := streamPayload(name, channels, opts)
+	if err != nil {
+		return "", "", "", err
 	}
 
-	jsonData, err := json.Marshal(payload)
+	_, err = s.do(ctx, rtspRequest{
+		op:          "add stream",
+		method:      http.MethodPost,
+		url:         fmt.Sprintf("%s/stream/%s/add", s.apiURL, cameraID),
+		body:        jsonData,
+		contentType: "application/json",
+	})
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to marshal payload: %w", err)
+		return "", "", "", err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/stream/%s/add", s.apiURL, cameraID), bytes.NewBuffer(jsonData))
+	streamID = cameraID
+	hlsURL = s.GetHLSURL(streamID, channels[0].Index)
+	snapshotURL = s.GetSnapshotURL(streamID, channels[0].Index)
+
+	return streamID, hlsURL, snapshotURL, nil
+}
+
+// EditStream mengganti nama, channel dan opsi stream yang sudah terdaftar di
+// RTSPtoWeb. RTSPtoWeb langsung memakai URL baru tanpa perlu remove dan add ulang.
+func (s *rtspService) EditStream(ctx context.Context, streamID, name string, channels []models.CameraChannel, opts models.StreamOptions) error {
+	jsonData, err := streamPayload(name, channels, opts)
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to create request: %w", err)
+		return err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(s.username, s.password)
+	_, err = s.do(ctx, rtspRequest{
+		op:          "edit stream",
+		method:      http.MethodPost,
+		url:         fmt.Sprintf("%s/stream/%s/edit", s.apiURL, streamID),
+		body:        jsonData,
+		contentType: "application/json",
+		idempotent:  true,
+	})
+	return err
+}
 
-	resp, err := s.httpClient.Do(req)
+// streamPayload membuat body add/edit stream, setiap channel dikirim dengan key
+// index-nya dan opsi stream camera
+func streamPayload(name string, channels []models.CameraChannel, opts models.StreamOptions) ([]byte, error) {
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("stream requires at least one channel")
+	}
+
+	payload := models.RTSPtoWebStream{
+		Name:     name,
+		Channels: streamChannels(channels, opts),
+	}
+
+	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return "", "", "", fmt.Errorf("RTSPtoWeb API returned status: %d", resp.StatusCode)
+	return jsonData, nil
+}
+
+// streamChannels mengubah channel camera menjadi channel RTSPtoWeb. Opsi stream
+// berlaku untuk semua channel camera.
+func streamChannels(channels []models.CameraChannel, opts models.StreamOptions) map[string]models.RTSPtoWebChannel {
+	result := make(map[string]models.RTSPtoWebChannel, len(channels))
+	for _, channel := range channels {
+		result[strconv.Itoa(channel.Index)] = models.RTSPtoWebChannel{
+			URL:                channel.RTSPUrl,
+			OnDemand:           opts.OnDemand,
+			Debug:              opts.Debug,
+			Audio:              opts.Audio,
+			InsecureSkipVerify: opts.InsecureSkipVerify,
+		}
 	}
+	return result
+}
 
-	streamID = cameraID
-	hlsURL = s.GetHLSURL(streamID)
-	snapshotURL = s.GetSnapshotURL(streamID)
+func (s *rtspService) RemoveStream(ctx context.Context, streamID string) error {
+	_, err := s.do(ctx, rtspRequest{
+		op:         "remove stream",
+		method:     http.MethodDelete,
+		url:        fmt.Sprintf("%s/stream/%s/delete", s.apiURL, streamID),
+		idempotent: true,
+	})
+	return err
+}
 
-	return streamID, hlsURL, snapshotURL, nil
+// GetStreamStatus mengembalikan status camera dari status channel stream di
+// RTSPtoWeb: ONLINE jika ada channel yang sedang menerima video, UNKNOWN jika
+// channel on-demand belum ditonton (RTSPtoWeb belum menghubungi kamera), dan
+// OFFLINE jika stream tidak terdaftar atau kamera tidak bisa dihubungi.
+// Error hanya dikembalikan jika RTSPtoWeb tidak bisa dihubungi.
+func (s *rtspService) GetStreamStatus(ctx context.Context, streamID string) (string, error) {
+	body, err := s.do(ctx, rtspRequest{
+		op:         "stream info",
+		method:     http.MethodGet,
+		url:        fmt.Sprintf("%s/stream/%s/info", s.apiURL, streamID),
+		idempotent: true,
+	})
+
+	switch {
+	case errors.Is(err, ErrStreamNotFound):
+		return models.CameraStatusOffline, nil
+	case err != nil:
+		return "", err
+	}
+
+	var result struct {
+		Payload models.MediaStream `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return streamStatus(result.Payload), nil
+}
+
+// streamStatus menentukan status camera dari channel stream RTSPtoWeb
+func streamStatus(stream models.MediaStream) string {
+	status := models.CameraStatusOffline
+	for _, channel := range stream.Channels {
+		if channel.Status == models.MediaChannelOnline {
+			return models.CameraStatusOnline
+		}
+		if channel.OnDemand {
+			status = models.CameraStatusUnknown
+		}
+	}
+	return status
 }
 
-func (s *rtspService) RemoveStream(streamID string) error {
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/stream/%s/delete", s.apiURL, streamID), nil)
+// ListStreams mengambil semua stream yang terdaftar di RTSPtoWeb, key-nya stream UUID
+func (s *rtspService) ListStreams(ctx context.Context) (map[string]models.MediaStream, error) {
+	body, err := s.do(ctx, rtspRequest{
+		op:         "list streams",
+		method:     http.MethodGet,
+		url:        fmt.Sprintf("%s/streams", s.apiURL),
+		idempotent: true,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.SetBasicAuth(s.username, s.password)
+	var result struct {
+		Status  int                           `json:"status"`
+		Payload map[string]models.MediaStream `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
 
-	resp, err := s.httpClient.Do(req)
+	if result.Payload == nil {
+		result.Payload = map[string]models.MediaStream{}
+	}
+
+	return result.Payload, nil
+}
+
+// mediaURL membuat URL endpoint playback RTSPtoWeb (lewat API URL internal).
+// token diisi jika token backend RTSPtoWeb diaktifkan.
+func (s *rtspService) mediaURL(streamID string, channel int, mediaPath, token string) string {
+	mediaURL := fmt.Sprintf("%s/stream/%s/channel/%d/%s", s.apiURL, streamID, channel, mediaPath)
+	if token != "" {
+		mediaURL += "?token=" + url.QueryEscape(token)
+	}
+	return mediaURL
+}
+
+// WebRTCOffer meneruskan SDP offer dari browser ke RTSPtoWeb dan mengembalikan SDP answer.
+// RTSPtoWeb menerima dan mengembalikan SDP dalam bentuk base64.
+func (s *rtspService) WebRTCOffer(ctx context.Context, streamID string, channel int, sdpOffer, token string) (string, error) {
+	form := url.Values{}
+	form.Set("data", base64.StdEncoding.EncodeToString([]byte(sdpOffer)))
+
+	body, err := s.do(ctx, rtspRequest{
+		op:          "webrtc offer",
+		method:      http.MethodPost,
+		url:         s.mediaURL(streamID, channel, "webrtc", token),
+		body:        []byte(form.Encode()),
+		contentType: "application/x-www-form-urlencoded",
+	})
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return "", err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("RTSPtoWeb API returned status: %d", resp.StatusCode)
+	answer, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(body)))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode SDP answer: %w", err)
 	}
 
-	return nil
+	return string(answer), nil
 }
 
-func (s *rtspService) GetStreamStatus(streamID string) (string, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/stream/%s/info", s.apiURL, streamID), nil)
+// FetchMedia membuka request GET ke media RTSPtoWeb (HLS playlist/segment atau JPEG)
+// lewat API URL internal. Status HTTP dari RTSPtoWeb diteruskan apa adanya,
+// hanya kegagalan jaringan yang dikembalikan sebagai error. Caller wajib menutup resp.Body.
+func (s *rtspService) FetchMedia(ctx context.Context, streamID string, channel int, mediaPath, token string) (*http.Response, error) {
+	if !s.breaker.Allow() {
+		return nil, &RTSPError{Op: "fetch media", Kind: ErrMediaUnavailable, Err: ErrCircuitOpen}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.mediaURL(streamID, channel, mediaPath, token), nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		s.breaker.Release()
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.SetBasicAuth(s.username, s.password)
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusOK {
-		return "READY", nil
+		err = transportError(ctx, "fetch media", err)
+		s.record(ctx, err)
+		return nil, err
 	}
 
-	return "OFFLINE", nil
+	s.breaker.Success()
+	return resp, nil
 }
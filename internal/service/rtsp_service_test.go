@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cctv-monitoring-backend/internal/models"
+)
+
+// testClientOptions mematikan retry dan breaker agar test tidak menunggu backoff
+var testClientOptions = RTSPClientOptions{Timeout: time.Second}
+
+// fakeRTSPtoWeb melayani /stream/{id}/info dengan body per stream ID. Stream
+// yang tidak ada dijawab seperti RTSPtoWeb: 500 dengan pesan error.
+func fakeRTSPtoWeb(t *testing.T, infos map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/stream/"), "/info")
+		info, ok := infos[id]
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"status":0,"payload":"stream not found"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"status":1,"payload":%s}`, info)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetStreamStatus(t *testing.T) {
+	server := fakeRTSPtoWeb(t, map[string]string{
+		"live":      `{"name":"Lobby","channels":{"0":{"url":"rtsp://cam/main","status":1}}}`,
+		"dead":      `{"name":"Lobby","channels":{"0":{"url":"rtsp://cam/main"}}}`,
+		"on-demand": `{"name":"Lobby","channels":{"0":{"url":"rtsp://cam/main","on_demand":true}}}`,
+		"sub-live":  `{"name":"Lobby","channels":{"0":{"url":"rtsp://cam/main","on_demand":true},"1":{"url":"rtsp://cam/sub","status":1}}}`,
+	})
+	rtsp := NewRTSPService(server.URL, server.URL, "admin", "secret", testClientOptions)
+
+	tests := []struct {
+		streamID string
+		want     string
+	}{
+		{"live", models.CameraStatusOnline},
+		{"dead", models.CameraStatusOffline},
+		{"on-demand", models.CameraStatusUnknown},
+		{"sub-live", models.CameraStatusOnline},
+		{"missing", models.CameraStatusOffline},
+	}
+
+	for _, tt := range tests {
+		got, err := rtsp.GetStreamStatus(context.Background(), tt.streamID)
+		if err != nil {
+			t.Errorf("GetStreamStatus(%q) error: %v", tt.streamID, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("GetStreamStatus(%q) = %q, want %q", tt.streamID, got, tt.want)
+		}
+	}
+}
+
+func TestGetStreamStatusUnreachable(t *testing.T) {
+	server := fakeRTSPtoWeb(t, nil)
+	server.Close()
+
+	rtsp := NewRTSPService(server.URL, server.URL, "admin", "secret", testClientOptions)
+	if status, err := rtsp.GetStreamStatus(context.Background(), "live"); err == nil {
+		t.Fatalf("GetStreamStatus() = %q, want error for unreachable RTSPtoWeb", status)
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/url"
+	"time"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/repository"
+)
+
+// cameraDialTimeout adalah batas waktu koneksi TCP ke kamera saat status
+// channel on-demand tidak bisa dibaca dari RTSPtoWeb
+const cameraDialTimeout = 3 * time.Second
+
+// StreamMonitorService memantau status stream semua camera aktif secara berkala
+// dan menyimpan perubahan status serta last_seen ke database
+type StreamMonitorService struct {
+	cameraRepo   repository.CameraRepository
+	mediaServers MediaServerService
+
+	// reachable mengecek kamera bisa dihubungi langsung, diganti di test
+	reachable func(rtspURL string) bool
+}
+
+// NewStreamMonitorService creates a new stream monitor service
+func NewStreamMonitorService(cameraRepo repository.CameraRepository, mediaServers MediaServerService) *StreamMonitorService {
+	return &StreamMonitorService{
+		cameraRepo:   cameraRepo,
+		mediaServers: mediaServers,
+		reachable:    dialCamera,
+	}
+}
+
+// StartMonitorJob runs periodic stream health checks
+func (s *StreamMonitorService) StartMonitorJob(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		for range ticker.C {
+			s.CheckAll()
+		}
+	}()
+
+	log.Printf("✓ Stream monitor job started (interval: %v)", interval)
+}
+
+// CheckAll mengecek status stream setiap camera aktif yang punya stream_id.
+// last_seen hanya diperbarui jika camera ONLINE.
+func (s *StreamMonitorService) CheckAll() {
+	cameras, err := s.cameraRepo.GetWithStream()
+	if err != nil {
+		log.Printf("Error loading cameras for stream monitor: %v", err)
+		return
+	}
+
+	ctx := context.Background()
+	for _, camera := range cameras {
+		// Stream belum terdaftar di node manapun (ADD masih di outbox)
+		if camera.SyncStatus == models.SyncStatusPending && !camera.MediaServerID.Valid {
+			continue
+		}
+
+		now := time.Now()
+		var lastSeen *time.Time
+
+		var status string
+		client, err := s.mediaServers.ClientForCamera(camera)
+		if err == nil {
+			status, err = client.GetStreamStatus(ctx, camera.StreamID.String)
+		}
+
+		if err != nil {
+			// RTSPtoWeb tidak bisa dihubungi, stream tidak bisa ditonton
+			log.Printf("Error checking stream %s: %v", camera.StreamID.String, err)
+			status = models.CameraStatusError
+		} else if status == models.CameraStatusUnknown {
+			// Channel on-demand baru dihubungkan RTSPtoWeb saat ditonton,
+			// kamera dicek langsung
+			status = models.CameraStatusOffline
+			if s.reachable(camera.RTSPUrl) {
+				status = models.CameraStatusOnline
+			}
+		}
+
+		if status == models.CameraStatusOnline {
+			lastSeen = &now
+		}
+
+		// Tidak ada perubahan status dan camera tidak terlihat, tidak perlu write
+		if status == camera.Status && lastSeen == nil {
+			continue
+		}
+
+		if status != camera.Status {
+			log.Printf("Camera %s (%s) status changed: %s -> %s", camera.ID, camera.Name, camera.Status, status)
+		}
+
+		if err := s.cameraRepo.UpdateStatus(camera.ID, status, lastSeen); err != nil {
+			log.Printf("Error updating status for camera %s: %v", camera.ID, err)
+		}
+	}
+}
+
+// dialCamera membuka koneksi TCP ke host RTSP kamera (port default 554)
+func dialCamera(rtspURL string) bool {
+	u, err := url.Parse(rtspURL)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "554"
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(u.Hostname(), port), cameraDialTimeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
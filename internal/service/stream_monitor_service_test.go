@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/utils"
+)
+
+// fakeStreamStatus mengembalikan status per stream ID, stream tanpa status
+// dianggap RTSPtoWeb tidak bisa dihubungi
+type fakeStreamStatus struct {
+	RTSPService
+	statuses map[string]string
+}
+
+func (f *fakeStreamStatus) GetStreamStatus(ctx context.Context, streamID string) (string, error) {
+	status, ok := f.statuses[streamID]
+	if !ok {
+		return "", errors.New("connection refused")
+	}
+	return status, nil
+}
+
+func TestStreamMonitorCheckAll(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    string
+		stream     string // Status dari RTSPtoWeb, kosong berarti error
+		reachable  bool
+		want       string
+		wantSeen   bool
+		wantUpdate bool
+	}{
+		{name: "channel live", current: models.CameraStatusUnknown, stream: models.CameraStatusOnline, want: models.CameraStatusOnline, wantSeen: true, wantUpdate: true},
+		{name: "still live refreshes last_seen", current: models.CameraStatusOnline, stream: models.CameraStatusOnline, want: models.CameraStatusOnline, wantSeen: true, wantUpdate: true},
+		{name: "camera dead", current: models.CameraStatusOnline, stream: models.CameraStatusOffline, want: models.CameraStatusOffline, wantUpdate: true},
+		{name: "still dead", current: models.CameraStatusOffline, stream: models.CameraStatusOffline},
+		{name: "on-demand reachable", current: models.CameraStatusOffline, stream: models.CameraStatusUnknown, reachable: true, want: models.CameraStatusOnline, wantSeen: true, wantUpdate: true},
+		{name: "on-demand unreachable", current: models.CameraStatusOnline, stream: models.CameraStatusUnknown, want: models.CameraStatusOffline, wantUpdate: true},
+		{name: "media server down", current: models.CameraStatusOnline, want: models.CameraStatusError, wantUpdate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			camera := &models.Camera{ID: "cam-1", Name: tt.name, RTSPUrl: "rtsp://10.0.0.1/live", Status: tt.current}
+			camera.StreamID.String, camera.StreamID.Valid = "stream-1", true
+
+			repo := &fakeCameraRepo{cameras: []*models.Camera{camera}}
+			rtsp := &fakeStreamStatus{statuses: map[string]string{}}
+			if tt.stream != "" {
+				rtsp.statuses["stream-1"] = tt.stream
+			}
+
+			monitor := NewStreamMonitorService(repo, &fakeMediaServers{client: rtsp})
+			monitor.reachable = func(rtspURL string) bool {
+				if rtspURL != camera.RTSPUrl {
+					t.Errorf("reachable(%q), want camera URL", rtspURL)
+				}
+				return tt.reachable
+			}
+			monitor.CheckAll()
+
+			update, ok := repo.statuses[camera.ID]
+			if ok != tt.wantUpdate {
+				t.Fatalf("updated = %v, want %v (%+v)", ok, tt.wantUpdate, update)
+			}
+			if !ok {
+				return
+			}
+			if update.status != tt.want {
+				t.Errorf("status = %q, want %q", update.status, tt.want)
+			}
+			if (update.lastSeen != nil) != tt.wantSeen {
+				t.Errorf("last_seen updated = %v, want %v", update.lastSeen != nil, tt.wantSeen)
+			}
+		})
+	}
+}
+
+// TestStreamMonitorWritesKnownStatuses memastikan monitor hanya menulis status
+// yang diterima request camera
+func TestStreamMonitorWritesKnownStatuses(t *testing.T) {
+	streams := []string{models.CameraStatusOnline, models.CameraStatusOffline, models.CameraStatusUnknown, ""}
+
+	for _, stream := range streams {
+		camera := &models.Camera{ID: "cam-1", RTSPUrl: "rtsp://10.0.0.1/live", Status: "READY"}
+		camera.StreamID.String, camera.StreamID.Valid = "stream-1", true
+
+		repo := &fakeCameraRepo{cameras: []*models.Camera{camera}}
+		rtsp := &fakeStreamStatus{statuses: map[string]string{}}
+		if stream != "" {
+			rtsp.statuses["stream-1"] = stream
+		}
+
+		monitor := NewStreamMonitorService(repo, &fakeMediaServers{client: rtsp})
+		monitor.reachable = func(string) bool { return false }
+		monitor.CheckAll()
+
+		status := repo.statuses[camera.ID].status
+		if !utils.ContainsString(models.CameraStatuses, status) {
+			t.Errorf("stream %q: monitor wrote status %q, want one of %v", stream, status, models.CameraStatuses)
+		}
+	}
+}
+
+func TestDialCamera(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rtspURL := "rtsp://admin:secret@" + listener.Addr().String() + "/live"
+
+	if !dialCamera(rtspURL) {
+		t.Error("dialCamera() = false for a listening camera")
+	}
+
+	listener.Close()
+	if dialCamera(rtspURL) {
+		t.Error("dialCamera() = true after the camera stopped listening")
+	}
+	if dialCamera("rtsp:///live") {
+		t.Error("dialCamera() = true for a URL without host")
+	}
+}
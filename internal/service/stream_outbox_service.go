@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/repository"
+)
+
+const (
+	// outboxLease adalah lama operasi dikunci satu worker. Harus lebih lama dari
+	// satu pengiriman (timeout x retry client RTSPtoWeb); jika worker crash,
+	// operasi akan diambil lagi setelah lease habis.
+	outboxLease = 2 * time.Minute
+
+	outboxBatchSize    = 50
+	outboxBaseBackoff  = 5 * time.Second
+	outboxMaxBackoff   = 5 * time.Minute
+	outboxRetention    = 7 * 24 * time.Hour
+	outboxHistoryLimit = 50
+)
+
+// ErrDeliveryPending dikembalikan DeliverCamera jika masih ada operasi camera yang
+// menunggu jadwal retry, sehingga operasi baru belum bisa dikirim
+var ErrDeliveryPending = errors.New("stream operation queued behind a pending retry")
+
+// StreamOutboxService mencatat operasi stream RTSPtoWeb dalam transaksi yang sama
+// dengan perubahan camera, lalu mengirimnya dengan retry sampai berhasil
+type StreamOutboxService interface {
+	Enqueue(tx *sql.Tx, camera *models.Camera, operation string) error
+	DeliverCamera(ctx context.Context, cameraID string) error
+	ProcessPending(ctx context.Context) (int, error)
+	GetByCamera(cameraID string) ([]*models.StreamOutboxEntry, error)
+	StartWorker(interval time.Duration)
+}
+
+type streamOutboxService struct {
+	outboxRepo   repository.OutboxRepository
+	cameraRepo   repository.CameraRepository
+	channelRepo  repository.ChannelRepository
+	mediaServers MediaServerService
+	credentials  CredentialService
+	maxAttempts  int
+}
+
+// NewStreamOutboxService membuat instance baru dari StreamOutboxService
+func NewStreamOutboxService(outboxRepo repository.OutboxRepository, cameraRepo repository.CameraRepository, channelRepo repository.ChannelRepository, mediaServers MediaServerService, credentials CredentialService, maxAttempts int) StreamOutboxService {
+	if maxAttempts <= 0 {
+		maxAttempts = 10
+	}
+
+	return &streamOutboxService{
+		outboxRepo:   outboxRepo,
+		cameraRepo:   cameraRepo,
+		channelRepo:  channelRepo,
+		mediaServers: mediaServers,
+		credentials:  credentials,
+		maxAttempts:  maxAttempts,
+	}
+}
+
+// Enqueue mencatat operasi stream camera di dalam transaksi tx dan menandai
+// camera PENDING. stream_id dan media_server_id camera saat ini ikut disimpan
+// agar REMOVE tetap tahu stream mana yang harus dihapus.
+func (s *streamOutboxService) Enqueue(tx *sql.Tx, camera *models.Camera, operation string) error {
+	entry := &models.StreamOutboxEntry{
+		CameraID:      camera.ID,
+		Operation:     operation,
+		StreamID:      camera.StreamID,
+		MediaServerID: camera.MediaServerID,
+	}
+
+	if err := s.outboxRepo.WithTx(tx).Enqueue(entry); err != nil {
+		return err
+	}
+
+	camera.SyncStatus = models.SyncStatusPending
+	return s.cameraRepo.WithTx(tx).UpdateSyncStatus(camera.ID, camera.SyncStatus, camera.SyncError)
+}
+
+// DeliverCamera langsung mengirim operasi camera yang sudah jatuh tempo (dipanggil
+// setelah commit agar user mendapat hasilnya). Operasi yang gagal tetap di outbox
+// dan dicoba lagi oleh worker.
+func (s *streamOutboxService) DeliverCamera(ctx context.Context, cameraID string) error {
+	for {
+		entries, err := s.outboxRepo.ClaimDue(cameraID, 1, outboxLease)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			break
+		}
+
+		if err := s.process(ctx, entries[0]); err != nil {
+			return err
+		}
+	}
+
+	// Operasi yang lebih dulu masih menunggu backoff (atau sedang dikirim worker)
+	pending, err := s.outboxRepo.CountPending(cameraID)
+	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		return ErrDeliveryPending
+	}
+
+	return nil
+}
+
+// ProcessPending mengirim semua operasi yang sudah jatuh tempo, mengembalikan
+// jumlah operasi yang berhasil dikirim
+func (s *streamOutboxService) ProcessPending(ctx context.Context) (int, error) {
+	delivered := 0
+	for {
+		entries, err := s.outboxRepo.ClaimDue("", outboxBatchSize, outboxLease)
+		if err != nil {
+			return delivered, err
+		}
+		if len(entries) == 0 {
+			return delivered, nil
+		}
+
+		for _, entry := range entries {
+			if err := s.process(ctx, entry); err == nil {
+				delivered++
+			}
+		}
+	}
+}
+
+// GetByCamera mengambil riwayat pengiriman operasi stream camera
+func (s *streamOutboxService) GetByCamera(cameraID string) ([]*models.StreamOutboxEntry, error) {
+	return s.outboxRepo.GetByCamera(cameraID, outboxHistoryLimit)
+}
+
+// StartWorker runs periodic delivery of pending outbox entries
+func (s *streamOutboxService) StartWorker(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		lastPrune := time.Now()
+		for range ticker.C {
+			if _, err := s.ProcessPending(context.Background()); err != nil {
+				log.Printf("Error processing stream outbox: %v", err)
+			}
+
+			// Riwayat yang sudah terkirim cukup disimpan beberapa hari
+			if time.Since(lastPrune) >= time.Hour {
+				lastPrune = time.Now()
+				if _, err := s.outboxRepo.DeleteDeliveredBefore(time.Now().Add(-outboxRetention)); err != nil {
+					log.Printf("Error pruning stream outbox: %v", err)
+				}
+			}
+		}
+	}()
+
+	log.Printf("✓ Stream outbox worker started (interval: %v, max attempts: %d)", interval, s.maxAttempts)
+}
+
+// process mengirim satu operasi dan menyimpan hasilnya ke outbox serta sync_status camera
+func (s *streamOutboxService) process(ctx context.Context, entry *models.StreamOutboxEntry) error {
+	deliverErr := s.deliver(ctx, entry)
+
+	if deliverErr == nil {
+		if err := s.outboxRepo.MarkDelivered(entry.ID); err != nil {
+			return err
+		}
+
+		// Camera baru dianggap sinkron jika tidak ada operasi lain yang menunggu
+		pending, err := s.outboxRepo.CountPending(entry.CameraID)
+		if err == nil && pending == 0 {
+			s.saveSyncStatus(entry.CameraID, models.SyncStatusSynced, nil)
+		}
+		return nil
+	}
+
+	log.Printf("Stream outbox %s for camera %s failed (attempt %d/%d): %v",
+		entry.Operation, entry.CameraID, entry.Attempts+1, s.maxAttempts, deliverErr)
+
+	if entry.Attempts+1 >= s.maxAttempts {
+		if err := s.outboxRepo.MarkFailed(entry.ID, deliverErr.Error()); err != nil {
+			log.Printf("Error marking outbox entry %d failed: %v", entry.ID, err)
+		}
+		// Diserahkan ke reconcile
+		s.saveSyncStatus(entry.CameraID, models.SyncStatusOutOfSync, deliverErr)
+		return deliverErr
+	}
+
+	if err := s.outboxRepo.MarkRetry(entry.ID, deliverErr.Error(), time.Now().Add(outboxBackoff(entry.Attempts))); err != nil {
+		log.Printf("Error rescheduling outbox entry %d: %v", entry.ID, err)
+	}
+	s.saveSyncStatus(entry.CameraID, models.SyncStatusPending, deliverErr)
+
+	return deliverErr
+}
+
+// outboxBackoff menghitung jeda sebelum percobaan berikutnya (exponential, dibatasi)
+func outboxBackoff(attempts int) time.Duration {
+	delay := outboxBaseBackoff
+	for i := 0; i < attempts && delay < outboxMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > outboxMaxBackoff {
+		delay = outboxMaxBackoff
+	}
+	return delay
+}
+
+// saveSyncStatus menyimpan status sinkronisasi camera, error hanya di-log
+func (s *streamOutboxService) saveSyncStatus(cameraID, status string, syncErr error) {
+	syncError := sql.NullString{}
+	if syncErr != nil {
+		syncError = sql.NullString{String: syncErr.Error(), Valid: true}
+	}
+
+	if err := s.cameraRepo.UpdateSyncStatus(cameraID, status, syncError); err != nil {
+		log.Printf("Error saving sync status of camera %s: %v", cameraID, err)
+	}
+}
+
+// deliver menjalankan operasi di RTSPtoWeb. Semua operasi dibuat idempotent
+// karena satu operasi bisa terkirim lebih dari sekali (mis. worker crash
+// setelah RTSPtoWeb menerima tapi sebelum outbox ditandai DELIVERED).
+func (s *streamOutboxService) deliver(ctx context.Context, entry *models.StreamOutboxEntry) error {
+	switch entry.Operation {
+	case models.OutboxOpAdd:
+		return s.deliverAdd(ctx, entry)
+	case models.OutboxOpEdit:
+		return s.deliverEdit(ctx, entry)
+	case models.OutboxOpRemove:
+		return s.deliverRemove(ctx, entry)
+	default:
+		return fmt.Errorf("unknown outbox operation %q", entry.Operation)
+	}
+}
+
+// deliverAdd mendaftarkan stream camera dengan konfigurasi terbaru dari database
+func (s *streamOutboxService) deliverAdd(ctx context.Context, entry *models.StreamOutboxEntry) error {
+	camera, err := s.cameraRepo.GetByID(entry.CameraID)
+	if errors.Is(err, repository.ErrCameraNotFound) {
+		// Camera sudah dihapus, REMOVE berikutnya yang membersihkan
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Stream sudah dihentikan sebelum ADD sempat dikirim
+	if !camera.StreamID.Valid || camera.StreamID.String == "" {
+		return nil
+	}
+
+	channels, err := s.streamChannels(camera)
+	if err != nil {
+		return err
+	}
+
+	serverID := camera.MediaServerID.String
+	if !camera.MediaServerID.Valid {
+		server, err := s.mediaServers.Place()
+		if err != nil {
+			return err
+		}
+		serverID = server.ID
+	}
+
+	client, err := s.mediaServers.ClientFor(serverID)
+	if err != nil {
+		return err
+	}
+
+	_, _, _, err = client.AddStream(ctx, camera.StreamID.String, camera.Name, channels, camera.StreamOptions)
+	if errors.Is(err, ErrStreamExists) {
+		// Pengiriman ulang: pastikan stream memakai konfigurasi terbaru
+		err = client.EditStream(ctx, camera.StreamID.String, camera.Name, channels, camera.StreamOptions)
+	}
+	if err != nil {
+		return err
+	}
+
+	if camera.MediaServerID.String != serverID || !camera.MediaServerID.Valid {
+		return s.cameraRepo.UpdateMediaServer(camera.ID, sql.NullString{String: serverID, Valid: true})
+	}
+
+	return nil
+}
+
+// deliverEdit mengirim nama dan channel terbaru ke stream camera
+func (s *streamOutboxService) deliverEdit(ctx context.Context, entry *models.StreamOutboxEntry) error {
+	camera, err := s.cameraRepo.GetByID(entry.CameraID)
+	if errors.Is(err, repository.ErrCameraNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Stream sudah dihentikan, atau belum ditempatkan (ADD yang gagal akan
+	// ditangani reconcile dengan konfigurasi terbaru)
+	if !camera.StreamID.Valid || !camera.MediaServerID.Valid {
+		return nil
+	}
+
+	channels, err := s.streamChannels(camera)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.mediaServers.ClientForCamera(camera)
+	if err != nil {
+		return err
+	}
+
+	err = client.EditStream(ctx, camera.StreamID.String, camera.Name, channels, camera.StreamOptions)
+	if errors.Is(err, ErrStreamNotFound) {
+		// Stream hilang dari node (mis. RTSPtoWeb restart tanpa config), daftarkan ulang
+		_, _, _, err = client.AddStream(ctx, camera.StreamID.String, camera.Name, channels, camera.StreamOptions)
+	}
+
+	return err
+}
+
+// streamChannels mengembalikan channel camera lengkap dengan credential RTSP
+func (s *streamOutboxService) streamChannels(camera *models.Camera) ([]models.CameraChannel, error) {
+	channels, err := cameraChannels(s.channelRepo, camera)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get camera channels: %w", err)
+	}
+	return s.credentials.StreamChannels(camera, channels)
+}
+
+// deliverRemove menghapus stream dari node tempat stream terakhir didaftarkan
+func (s *streamOutboxService) deliverRemove(ctx context.Context, entry *models.StreamOutboxEntry) error {
+	// Stream belum pernah ditempatkan ke node, tidak ada yang dihapus
+	if !entry.StreamID.Valid || !entry.MediaServerID.Valid {
+		return nil
+	}
+
+	client, err := s.mediaServers.ClientFor(entry.MediaServerID.String)
+	if errors.Is(err, ErrMediaServerNotFound) {
+		// Node sudah dihapus dari registry
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = client.RemoveStream(ctx, entry.StreamID.String)
+	if errors.Is(err, ErrStreamNotFound) {
+		return nil
+	}
+
+	return err
+}
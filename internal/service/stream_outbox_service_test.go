@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"cctv-monitoring-backend/internal/models"
+)
+
+// fakeStreamRemover menjawab RemoveStream dengan error berikutnya dari errs,
+// nil jika errs sudah habis
+type fakeStreamRemover struct {
+	RTSPService
+	errs    []error
+	removed []string
+}
+
+func (f *fakeStreamRemover) RemoveStream(ctx context.Context, streamID string) error {
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return err
+	}
+	f.removed = append(f.removed, streamID)
+	return nil
+}
+
+func newTestOutbox(client RTSPService, maxAttempts int) (*streamOutboxService, *fakeOutboxRepo, *fakeCameraRepo) {
+	outboxRepo := &fakeOutboxRepo{}
+	cameraRepo := &fakeCameraRepo{}
+	outbox := NewStreamOutboxService(outboxRepo, cameraRepo, nil, &fakeMediaServers{client: client}, nil, maxAttempts)
+	return outbox.(*streamOutboxService), outboxRepo, cameraRepo
+}
+
+func removeEntry(cameraID string) *models.StreamOutboxEntry {
+	return &models.StreamOutboxEntry{
+		CameraID:      cameraID,
+		Operation:     models.OutboxOpRemove,
+		StreamID:      sql.NullString{String: "stream-" + cameraID, Valid: true},
+		MediaServerID: sql.NullString{String: "a", Valid: true},
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 5 * time.Second},
+		{1, 10 * time.Second},
+		{3, 40 * time.Second},
+		{5, 160 * time.Second},
+		{6, outboxMaxBackoff},
+		{100, outboxMaxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := outboxBackoff(tt.attempts); got != tt.want {
+			t.Errorf("outboxBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestOutboxRetryUntilDelivered(t *testing.T) {
+	ctx := context.Background()
+	rtsp := &fakeStreamRemover{errs: []error{ErrMediaUnavailable, ErrMediaUnavailable}}
+	outbox, outboxRepo, cameraRepo := newTestOutbox(rtsp, 5)
+	entry := outboxRepo.add(removeEntry("cam-1"))
+
+	// Percobaan pertama gagal: dijadwalkan ulang dengan backoff awal
+	before := time.Now()
+	if err := outbox.DeliverCamera(ctx, "cam-1"); !errors.Is(err, ErrMediaUnavailable) {
+		t.Fatalf("DeliverCamera() error = %v, want ErrMediaUnavailable", err)
+	}
+	if entry.Status != models.OutboxPending || entry.Attempts != 1 || !entry.LastError.Valid {
+		t.Fatalf("after first failure: status %s, attempts %d, last_error %v", entry.Status, entry.Attempts, entry.LastError)
+	}
+	if delay := entry.NextAttemptAt.Sub(before); delay < outboxBaseBackoff || delay > outboxBaseBackoff+time.Second {
+		t.Errorf("next attempt in %v, want about %v", delay, outboxBaseBackoff)
+	}
+	if got := cameraRepo.syncStatuses["cam-1"]; got != models.SyncStatusPending {
+		t.Errorf("sync status = %q, want %q", got, models.SyncStatusPending)
+	}
+
+	// Selama backoff operasi tidak dikirim ulang
+	if err := outbox.DeliverCamera(ctx, "cam-1"); !errors.Is(err, ErrDeliveryPending) {
+		t.Fatalf("DeliverCamera() during backoff error = %v, want ErrDeliveryPending", err)
+	}
+	if entry.Attempts != 1 {
+		t.Fatalf("attempts during backoff = %d, want 1", entry.Attempts)
+	}
+
+	// Percobaan kedua gagal: backoff naik dua kali lipat
+	entry.NextAttemptAt = time.Now()
+	before = time.Now()
+	if delivered, _ := outbox.ProcessPending(ctx); delivered != 0 {
+		t.Fatalf("ProcessPending() delivered %d, want 0", delivered)
+	}
+	if delay := entry.NextAttemptAt.Sub(before); delay < 2*outboxBaseBackoff || delay > 2*outboxBaseBackoff+time.Second {
+		t.Errorf("next attempt in %v, want about %v", delay, 2*outboxBaseBackoff)
+	}
+
+	// Percobaan ketiga berhasil
+	entry.NextAttemptAt = time.Now()
+	if delivered, err := outbox.ProcessPending(ctx); err != nil || delivered != 1 {
+		t.Fatalf("ProcessPending() = %d, %v, want 1 delivered", delivered, err)
+	}
+	if entry.Status != models.OutboxDelivered || entry.Attempts != 3 {
+		t.Errorf("status %s after %d attempts, want %s after 3", entry.Status, entry.Attempts, models.OutboxDelivered)
+	}
+	if len(rtsp.removed) != 1 || rtsp.removed[0] != "stream-cam-1" {
+		t.Errorf("removed streams = %v, want [stream-cam-1]", rtsp.removed)
+	}
+	if got := cameraRepo.syncStatuses["cam-1"]; got != models.SyncStatusSynced {
+		t.Errorf("sync status = %q, want %q", got, models.SyncStatusSynced)
+	}
+}
+
+func TestOutboxGivesUpAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	rtsp := &fakeStreamRemover{errs: []error{ErrMediaUnavailable, ErrMediaUnavailable}}
+	outbox, outboxRepo, cameraRepo := newTestOutbox(rtsp, 2)
+	entry := outboxRepo.add(removeEntry("cam-1"))
+
+	outbox.ProcessPending(ctx)
+	entry.NextAttemptAt = time.Now()
+	outbox.ProcessPending(ctx)
+
+	if entry.Status != models.OutboxFailed || entry.Attempts != 2 {
+		t.Fatalf("status %s after %d attempts, want %s after 2", entry.Status, entry.Attempts, models.OutboxFailed)
+	}
+	if got := cameraRepo.syncStatuses["cam-1"]; got != models.SyncStatusOutOfSync {
+		t.Errorf("sync status = %q, want %q", got, models.SyncStatusOutOfSync)
+	}
+
+	// Operasi yang gagal permanen tidak diambil lagi
+	entry.NextAttemptAt = time.Now()
+	if delivered, _ := outbox.ProcessPending(ctx); delivered != 0 || entry.Attempts != 2 {
+		t.Errorf("failed entry retried: delivered %d, attempts %d", delivered, entry.Attempts)
+	}
+}
+
+func TestOutboxKeepsCameraOrder(t *testing.T) {
+	ctx := context.Background()
+	rtsp := &fakeStreamRemover{errs: []error{ErrMediaUnavailable}}
+	outbox, outboxRepo, _ := newTestOutbox(rtsp, 5)
+	first := outboxRepo.add(removeEntry("cam-1"))
+	second := outboxRepo.add(removeEntry("cam-1"))
+	other := outboxRepo.add(removeEntry("cam-2"))
+
+	// Operasi kedua cam-1 menunggu yang pertama, camera lain tetap jalan
+	if delivered, _ := outbox.ProcessPending(ctx); delivered != 1 {
+		t.Fatalf("ProcessPending() delivered %d, want 1", delivered)
+	}
+	if first.Status != models.OutboxPending || second.Status != models.OutboxPending || second.Attempts != 0 {
+		t.Fatalf("cam-1 operations sent out of order: first %s, second %s (%d attempts)", first.Status, second.Status, second.Attempts)
+	}
+	if other.Status != models.OutboxDelivered {
+		t.Errorf("cam-2 operation status = %s, want %s", other.Status, models.OutboxDelivered)
+	}
+
+	first.NextAttemptAt = time.Now()
+	if delivered, _ := outbox.ProcessPending(ctx); delivered != 2 {
+		t.Fatalf("ProcessPending() delivered %d, want 2", delivered)
+	}
+	if first.Status != models.OutboxDelivered || second.Status != models.OutboxDelivered {
+		t.Errorf("statuses = %s, %s, want both %s", first.Status, second.Status, models.OutboxDelivered)
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/utils"
+)
+
+// Custom errors untuk viewer token
+var (
+	ErrViewerTokenInvalid = errors.New("viewer token is invalid or expired")
+	ErrViewerTokenScope   = errors.New("viewer token is not valid for this stream")
+)
+
+// ViewerTokenService membuat dan memvalidasi viewer token berumur pendek yang
+// ditempelkan ke URL HLS/snapshot, dan dipakai RTSPtoWeb lewat token backend
+type ViewerTokenService interface {
+	Enabled() bool
+	Mint(cameraID, streamID string) (string, error)
+	VerifyForCamera(token, cameraID string) (*utils.ViewerClaims, error)
+	Authorize(req *models.MediaAuthorizeRequest) error
+}
+
+type viewerTokenService struct {
+	enabled bool
+	secret  string
+	ttl     time.Duration
+}
+
+// NewViewerTokenService membuat instance baru dari ViewerTokenService
+func NewViewerTokenService(enabled bool, secret string, ttl time.Duration) ViewerTokenService {
+	return &viewerTokenService{
+		enabled: enabled,
+		secret:  secret,
+		ttl:     ttl,
+	}
+}
+
+// Enabled mengembalikan true jika viewer token diaktifkan
+func (s *viewerTokenService) Enabled() bool {
+	return s.enabled
+}
+
+// Mint membuat viewer token untuk stream satu camera, kosong jika fitur nonaktif
+func (s *viewerTokenService) Mint(cameraID, streamID string) (string, error) {
+	if !s.enabled {
+		return "", nil
+	}
+
+	token, err := utils.GenerateViewerToken(cameraID, streamID, s.secret, s.ttl)
+	if err != nil {
+		return "", fmt.Errorf("failed to mint viewer token: %w", err)
+	}
+
+	return token, nil
+}
+
+// VerifyForCamera memvalidasi viewer token untuk camera tertentu
+func (s *viewerTokenService) VerifyForCamera(token, cameraID string) (*utils.ViewerClaims, error) {
+	claims, err := utils.ValidateViewerToken(token, s.secret)
+	if err != nil {
+		return nil, ErrViewerTokenInvalid
+	}
+
+	if claims.CameraID != cameraID {
+		return nil, ErrViewerTokenScope
+	}
+
+	return claims, nil
+}
+
+// Authorize memvalidasi request dari token backend RTSPtoWeb. Token berlaku untuk
+// semua channel dari stream camera yang tercantum di token.
+func (s *viewerTokenService) Authorize(req *models.MediaAuthorizeRequest) error {
+	if req.Token == "" {
+		return ErrViewerTokenInvalid
+	}
+
+	claims, err := utils.ValidateViewerToken(req.Token, s.secret)
+	if err != nil {
+		return ErrViewerTokenInvalid
+	}
+
+	if claims.StreamID != req.Stream {
+		return ErrViewerTokenScope
+	}
+
+	return nil
+}
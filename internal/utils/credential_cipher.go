@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownCredentialKey dikembalikan jika ciphertext dienkripsi dengan key yang
+// sudah tidak ada di keyring
+var ErrUnknownCredentialKey = errors.New("credential encrypted with unknown key")
+
+// CredentialCipher mengenkripsi credential dengan AES-GCM. Ciphertext diawali ID
+// key ("<key-id>:<base64>") sehingga key lama tetap bisa mendekripsi setelah
+// key baru ditambahkan di depan keyring.
+type CredentialCipher struct {
+	activeID string
+	keys     map[string]cipher.AEAD
+}
+
+// NewCredentialCipher membaca keyring "id:base64key[,id:base64key...]". Key
+// pertama dipakai untuk enkripsi, sisanya hanya untuk dekripsi data lama. Key
+// harus 16, 24 atau 32 byte (AES-128/192/256).
+func NewCredentialCipher(keyring string) (*CredentialCipher, error) {
+	c := &CredentialCipher{keys: map[string]cipher.AEAD{}}
+
+	for _, entry := range strings.Split(keyring, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid credential key %q: expected <id>:<base64 key>", id)
+		}
+		if _, exists := c.keys[id]; exists {
+			return nil, fmt.Errorf("duplicate credential key id %q", id)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credential key %q: %w", id, err)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credential key %q: %w", id, err)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credential key %q: %w", id, err)
+		}
+
+		c.keys[id] = aead
+		if c.activeID == "" {
+			c.activeID = id
+		}
+	}
+
+	if c.activeID == "" {
+		return nil, fmt.Errorf("credential keyring is empty")
+	}
+
+	return c, nil
+}
+
+// ActiveKeyID mengembalikan ID key yang dipakai untuk enkripsi
+func (c *CredentialCipher) ActiveKeyID() string {
+	return c.activeID
+}
+
+// Encrypt mengenkripsi plaintext dengan key aktif
+func (c *CredentialCipher) Encrypt(plaintext string) (string, error) {
+	aead := c.keys[c.activeID]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return c.activeID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt mendekripsi ciphertext hasil Encrypt dengan key sesuai ID-nya
+func (c *CredentialCipher) Decrypt(ciphertext string) (string, error) {
+	id, encoded, ok := strings.Cut(ciphertext, ":")
+	if !ok {
+		return "", fmt.Errorf("invalid credential ciphertext")
+	}
+
+	aead, ok := c.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownCredentialKey, id)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("invalid credential ciphertext")
+	}
+
+	nonce, data := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt credential: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// KeyID mengembalikan ID key yang dipakai ciphertext
+func KeyID(ciphertext string) string {
+	id, _, _ := strings.Cut(ciphertext, ":")
+	return id
+}
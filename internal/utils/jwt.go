@@ -14,16 +14,20 @@ type JWTClaims struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+
+	// Permission eksplisit saat login, berubah setelah login ulang
+	Permissions []string `json:"permissions,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // GenerateToken membuat JWT token baru
-func GenerateToken(userID, username, role, secret string, expiration time.Duration) (string, error) {
+func GenerateToken(userID, username, role string, permissions []string, secret string, expiration time.Duration) (string, error) {
 	// Buat claims
 	claims := JWTClaims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
+		UserID:      userID,
+		Username:    username,
+		Role:        role,
+		Permissions: permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -81,3 +85,57 @@ func ParseDuration(duration string) time.Duration {
 	}
 	return d
 }
+
+// ViewerTokenAudience membedakan viewer token dari token login
+const ViewerTokenAudience = "media-viewer"
+
+// ViewerClaims adalah claims untuk viewer token, berlaku untuk stream satu camera
+type ViewerClaims struct {
+	CameraID string `json:"camera_id"`
+	StreamID string `json:"stream_id"`
+	jwt.RegisteredClaims
+}
+
+// GenerateViewerToken membuat viewer token berumur pendek untuk satu camera
+func GenerateViewerToken(cameraID, streamID, secret string, expiration time.Duration) (string, error) {
+	claims := ViewerClaims{
+		CameraID: cameraID,
+		StreamID: streamID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{ViewerTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	tokenString, err := token.SignedString([]byte(secret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign viewer token: %w", err)
+	}
+
+	return tokenString, nil
+}
+
+// ValidateViewerToken memvalidasi viewer token dan return claims
+func ValidateViewerToken(tokenString, secret string) (*ViewerClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ViewerClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(secret), nil
+	}, jwt.WithAudience(ViewerTokenAudience))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse viewer token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*ViewerClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid viewer token claims")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,21 @@
+package utils
+
+import "regexp"
+
+// uuidPattern untuk validasi parameter ID sebelum dipakai di query
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// IsUUID mengecek s berformat UUID
+func IsUUID(s string) bool {
+	return uuidPattern.MatchString(s)
+}
+
+// ContainsString mengecek value ada di values
+func ContainsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,344 @@
+// Package validation memvalidasi struct request berdasarkan tag `validate` dan
+// mengembalikan semua pelanggaran sekaligus sebagai models.FieldError.
+//
+// Rule dipisah koma dan dijalankan berurutan, berhenti di pelanggaran pertama
+// per field:
+//
+//	required            nilai tidak boleh kosong (zero value)
+//	required_without=F  wajib jika field F kosong
+//	omitempty           rule berikutnya dilewati jika nilai kosong
+//	min=N, max=N        batas angka, atau panjang string/slice
+//	oneof=A B C         nilai harus salah satu dari daftar
+//	camera_status       salah satu models.CameraStatuses
+//	email, ip, uuid     format string
+//	geojson             GeoJSON geometry (json.RawMessage), null diterima
+//	rtsp_url            URL rtsp:// atau rtsps:// dengan host
+//	username            huruf, angka, titik, garis bawah dan strip
+//	dive                rule berikutnya dijalankan untuk setiap elemen slice
+//
+// Nama field diambil dari tag json. Struct, pointer ke struct dan slice of
+// struct divalidasi rekursif (misalnya channels[1].rtsp_url).
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cctv-monitoring-backend/internal/models"
+	"cctv-monitoring-backend/internal/utils"
+)
+
+// usernamePattern adalah karakter yang boleh dipakai di username
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// geoJSONTypes adalah type GeoJSON geometry yang berisi coordinates
+var geoJSONTypes = []string{"Point", "MultiPoint", "LineString", "MultiLineString", "Polygon", "MultiPolygon"}
+
+// Errors adalah daftar pelanggaran validasi, kosong jika valid
+type Errors []models.FieldError
+
+// Add menambahkan satu pelanggaran
+func (e *Errors) Add(field, code, message string) {
+	*e = append(*e, models.FieldError{Field: field, Code: code, Message: message})
+}
+
+// Strings mengembalikan setiap pelanggaran sebagai "field: message"
+func (e Errors) Strings() []string {
+	result := make([]string, len(e))
+	for i, err := range e {
+		result[i] = err.Field + ": " + err.Message
+	}
+	return result
+}
+
+// Error menggabungkan semua pelanggaran menjadi satu pesan
+func (e Errors) Error() string {
+	return strings.Join(e.Strings(), "; ")
+}
+
+// Struct memvalidasi v (struct atau pointer ke struct) sesuai tag validate
+func Struct(v interface{}) Errors {
+	var errs Errors
+	validateStruct(reflect.Indirect(reflect.ValueOf(v)), "", &errs)
+	return errs
+}
+
+// Var memvalidasi satu nilai dengan rules berformat sama seperti tag validate,
+// misalnya nilai key JSON Merge Patch. Struct di dalam value ikut divalidasi
+// seperti field di Struct. required_without tidak bisa dipakai karena tidak
+// ada struct parent.
+func Var(field string, value interface{}, rules string) Errors {
+	var errs Errors
+	v := reflect.ValueOf(value)
+	if rules == "" || validateValue(reflect.Value{}, v, field, strings.Split(rules, ","), &errs) {
+		validateNested(v, field, &errs)
+	}
+	return errs
+}
+
+// validateStruct memvalidasi semua field struct, prefix adalah path parent
+func validateStruct(value reflect.Value, prefix string, errs *Errors) {
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		// Struct embedded (misalnya RTSPCredentialsRequest) digabung ke parent
+		if field.Anonymous {
+			validateStruct(reflect.Indirect(value.Field(i)), prefix, errs)
+			continue
+		}
+
+		name := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		rules := field.Tag.Get("validate")
+		if rules != "" && !validateValue(value, value.Field(i), name, strings.Split(rules, ","), errs) {
+			continue
+		}
+		validateNested(value.Field(i), name, errs)
+	}
+}
+
+// validateNested memvalidasi struct di dalam field (pointer atau slice)
+func validateNested(value reflect.Value, name string, errs *Errors) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			validateNested(value.Elem(), name, errs)
+		}
+	case reflect.Struct:
+		validateStruct(value, name, errs)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Struct {
+			for i := 0; i < value.Len(); i++ {
+				validateStruct(value.Index(i), fmt.Sprintf("%s[%d]", name, i), errs)
+			}
+		}
+	}
+}
+
+// validateValue menjalankan rules untuk satu nilai. false jika ada pelanggaran.
+func validateValue(parent, value reflect.Value, name string, rules []string, errs *Errors) bool {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			// Pointer nil = field tidak dikirim, hanya required yang berlaku
+			for _, rule := range rules {
+				if rule == "required" {
+					errs.Add(name, models.FieldErrRequired, "is required")
+					return false
+				}
+			}
+			return true
+		}
+		value = value.Elem()
+	}
+
+	for i, rule := range rules {
+		key, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		switch key {
+		case "omitempty":
+			if value.IsZero() {
+				return true
+			}
+			continue
+		case "dive":
+			ok := true
+			for j := 0; j < value.Len(); j++ {
+				if !validateValue(parent, value.Index(j), fmt.Sprintf("%s[%d]", name, j), rules[i+1:], errs) {
+					ok = false
+				}
+			}
+			return ok
+		case "required_without":
+			if value.IsZero() && reflectFieldByJSON(parent, param).IsZero() {
+				errs.Add(name, models.FieldErrRequired, fmt.Sprintf("is required when %s is empty", param))
+				return false
+			}
+			continue
+		}
+
+		if code, message := checkRule(key, param, value); code != "" {
+			errs.Add(name, code, message)
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkRule menjalankan satu rule, mengembalikan code dan pesan pelanggaran
+func checkRule(key, param string, value reflect.Value) (code, message string) {
+	switch key {
+	case "required":
+		if value.IsZero() {
+			return models.FieldErrRequired, "is required"
+		}
+
+	case "min", "max":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			panic(fmt.Sprintf("validation: invalid %s=%s", key, param))
+		}
+		n, unit := measure(value)
+		switch {
+		case key == "min" && n < limit && unit != "":
+			return models.FieldErrTooShort, fmt.Sprintf("must be at least %s %s", param, unit)
+		case key == "min" && n < limit:
+			return models.FieldErrOutOfRange, fmt.Sprintf("must be at least %s", param)
+		case key == "max" && n > limit && unit != "":
+			return models.FieldErrTooLong, fmt.Sprintf("must be at most %s %s", param, unit)
+		case key == "max" && n > limit:
+			return models.FieldErrOutOfRange, fmt.Sprintf("must be at most %s", param)
+		}
+
+	case "oneof":
+		options := strings.Fields(param)
+		s := fmt.Sprint(value.Interface())
+		for _, option := range options {
+			if s == option {
+				return "", ""
+			}
+		}
+		return models.FieldErrInvalidChoice, "must be one of " + strings.Join(options, ", ")
+
+	case "camera_status":
+		if !utils.ContainsString(models.CameraStatuses, value.String()) {
+			return models.FieldErrInvalidChoice, "must be one of " + strings.Join(models.CameraStatuses, ", ")
+		}
+
+	case "email":
+		s := value.String()
+		address, err := mail.ParseAddress(s)
+		if err != nil || address.Address != s {
+			return models.FieldErrInvalidFormat, "must be a valid email address"
+		}
+
+	case "ip":
+		if net.ParseIP(value.String()) == nil {
+			return models.FieldErrInvalidFormat, "must be a valid IPv4 or IPv6 address"
+		}
+
+	case "uuid":
+		if !utils.IsUUID(value.String()) {
+			return models.FieldErrInvalidFormat, "must be a valid ID"
+		}
+
+	case "geojson":
+		if !validGeoJSON(value.Bytes()) {
+			return models.FieldErrInvalidFormat, "must be a GeoJSON geometry object"
+		}
+
+	case "rtsp_url":
+		// Error url.Parse tidak diteruskan karena memuat URL beserta password
+		u, err := url.Parse(value.String())
+		if err != nil || (u.Scheme != "rtsp" && u.Scheme != "rtsps") || u.Hostname() == "" {
+			return models.FieldErrInvalidFormat, "must be an rtsp:// or rtsps:// URL with a host"
+		}
+
+	case "username":
+		if !usernamePattern.MatchString(value.String()) {
+			return models.FieldErrInvalidFormat, "may only contain letters, digits, '.', '_' and '-'"
+		}
+
+	default:
+		panic("validation: unknown rule " + key)
+	}
+
+	return "", ""
+}
+
+// validGeoJSON mengecek apakah data adalah object GeoJSON geometry. Struktur
+// coordinates tidak diperiksa lebih dalam selain harus berupa array.
+func validGeoJSON(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return true
+	}
+
+	var geometry struct {
+		Type        string            `json:"type"`
+		Coordinates json.RawMessage   `json:"coordinates"`
+		Geometries  []json.RawMessage `json:"geometries"`
+	}
+	if err := json.Unmarshal(data, &geometry); err != nil {
+		return false
+	}
+
+	if geometry.Type == "GeometryCollection" {
+		for _, child := range geometry.Geometries {
+			if bytes.Equal(bytes.TrimSpace(child), []byte("null")) || !validGeoJSON(child) {
+				return false
+			}
+		}
+		return geometry.Geometries != nil
+	}
+
+	for _, t := range geoJSONTypes {
+		if geometry.Type == t {
+			return bytes.HasPrefix(bytes.TrimSpace(geometry.Coordinates), []byte("["))
+		}
+	}
+	return false
+}
+
+// measure mengembalikan nilai angka, atau panjang dan satuannya untuk string
+// ("characters long") dan slice ("items"). unit kosong untuk angka.
+func measure(value reflect.Value) (n float64, unit string) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(len([]rune(value.String()))), "characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), "items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), ""
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), ""
+	}
+	panic("validation: min/max is not supported for " + value.Kind().String())
+}
+
+// jsonName mengembalikan nama field di tag json, atau nama field Go
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// reflectFieldByJSON mencari field struct berdasarkan nama json
+func reflectFieldByJSON(parent reflect.Value, name string) reflect.Value {
+	if !parent.IsValid() {
+		panic("validation: required_without needs a parent struct")
+	}
+	typ := parent.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		if jsonName(typ.Field(i)) == name {
+			return parent.Field(i)
+		}
+	}
+	panic("validation: unknown field " + name)
+}
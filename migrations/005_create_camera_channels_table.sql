@@ -0,0 +1,28 @@
+-- Migration: Create camera channels table
+-- File: migrations/005_create_camera_channels_table.sql
+
+-- Create camera_channels table (main stream / sub stream per camera)
+CREATE TABLE IF NOT EXISTS camera_channels (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    camera_id UUID NOT NULL REFERENCES cameras(id) ON DELETE CASCADE,
+    channel_index INTEGER NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    rtsp_url TEXT NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'MAIN',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (camera_id, channel_index)
+);
+
+-- Create indexes
+CREATE INDEX idx_camera_channels_camera_id ON camera_channels(camera_id);
+
+-- Existing cameras get their rtsp_url as main channel 0
+INSERT INTO camera_channels (camera_id, channel_index, label, rtsp_url, role)
+SELECT c.id, 0, 'Main', c.rtsp_url, 'MAIN'
+FROM cameras c
+WHERE NOT EXISTS (SELECT 1 FROM camera_channels cc WHERE cc.camera_id = c.id);
+
+COMMENT ON TABLE camera_channels IS 'Tabel untuk menyimpan channel stream per kamera';
+COMMENT ON COLUMN camera_channels.channel_index IS 'Key channel di RTSPtoWeb ("0", "1", ...)';
+COMMENT ON COLUMN camera_channels.role IS 'Role: MAIN (resolusi tinggi), SUB (bitrate rendah)';
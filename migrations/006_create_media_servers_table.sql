@@ -0,0 +1,31 @@
+-- Migration: Create media servers table
+-- File: migrations/006_create_media_servers_table.sql
+
+-- Create media_servers table (registry node RTSPtoWeb)
+CREATE TABLE IF NOT EXISTS media_servers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) UNIQUE NOT NULL,
+    api_url TEXT NOT NULL,
+    public_url TEXT NOT NULL,
+    username VARCHAR(100),
+    password_enc TEXT,
+    max_streams INTEGER NOT NULL DEFAULT 100,
+    status VARCHAR(20) NOT NULL DEFAULT 'UNKNOWN',
+    last_checked TIMESTAMPTZ,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Node tempat stream camera didaftarkan
+ALTER TABLE cameras ADD COLUMN IF NOT EXISTS media_server_id UUID REFERENCES media_servers(id) ON DELETE SET NULL;
+
+-- Create indexes
+CREATE INDEX idx_media_servers_is_active ON media_servers(is_active);
+CREATE INDEX idx_cameras_media_server_id ON cameras(media_server_id);
+
+COMMENT ON TABLE media_servers IS 'Tabel untuk menyimpan node media server RTSPtoWeb';
+COMMENT ON COLUMN media_servers.password_enc IS 'Password RTSPtoWeb terenkripsi: <key-id>:<base64(nonce || ciphertext)>';
+COMMENT ON COLUMN media_servers.max_streams IS 'Kapasitas maksimal stream di node';
+COMMENT ON COLUMN media_servers.status IS 'Status: ONLINE, OFFLINE, UNKNOWN';
+COMMENT ON COLUMN cameras.media_server_id IS 'Node RTSPtoWeb tempat stream camera berjalan';
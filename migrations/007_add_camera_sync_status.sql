@@ -0,0 +1,12 @@
+-- Migration: Add stream sync status to cameras
+-- File: migrations/007_add_camera_sync_status.sql
+
+-- Status sinkronisasi konfigurasi camera dengan stream di RTSPtoWeb
+ALTER TABLE cameras ADD COLUMN IF NOT EXISTS sync_status VARCHAR(20) NOT NULL DEFAULT 'SYNCED';
+ALTER TABLE cameras ADD COLUMN IF NOT EXISTS sync_error TEXT;
+
+-- Create indexes
+CREATE INDEX idx_cameras_sync_status ON cameras(sync_status);
+
+COMMENT ON COLUMN cameras.sync_status IS 'Status: SYNCED, OUT_OF_SYNC';
+COMMENT ON COLUMN cameras.sync_error IS 'Error terakhir dari RTSPtoWeb saat perubahan stream ditolak';
@@ -0,0 +1,28 @@
+-- Migration: Create stream outbox table
+-- File: migrations/008_create_stream_outbox_table.sql
+
+-- Create stream_outbox table (operasi RTSPtoWeb yang menunggu dikirim)
+CREATE TABLE IF NOT EXISTS stream_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    camera_id UUID NOT NULL REFERENCES cameras(id) ON DELETE CASCADE,
+    operation VARCHAR(20) NOT NULL,
+    stream_id VARCHAR(255),
+    media_server_id UUID,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ
+);
+
+-- Create indexes
+CREATE INDEX idx_stream_outbox_pending ON stream_outbox(next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX idx_stream_outbox_camera_id ON stream_outbox(camera_id, id);
+
+COMMENT ON TABLE stream_outbox IS 'Outbox operasi stream RTSPtoWeb, ditulis dalam transaksi yang sama dengan perubahan camera';
+COMMENT ON COLUMN stream_outbox.operation IS 'Operasi: ADD, EDIT, REMOVE';
+COMMENT ON COLUMN stream_outbox.status IS 'Status: PENDING, DELIVERED, FAILED';
+COMMENT ON COLUMN stream_outbox.locked_until IS 'Lease worker yang sedang mengirim operasi ini';
+COMMENT ON COLUMN cameras.sync_status IS 'Status: SYNCED, PENDING, OUT_OF_SYNC';
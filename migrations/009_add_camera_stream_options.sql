@@ -0,0 +1,13 @@
+-- Migration: Add RTSPtoWeb stream options to cameras
+-- File: migrations/009_add_camera_stream_options.sql
+
+-- Opsi channel RTSPtoWeb per camera (dikirim di payload add/edit stream)
+ALTER TABLE cameras ADD COLUMN IF NOT EXISTS on_demand BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE cameras ADD COLUMN IF NOT EXISTS audio BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE cameras ADD COLUMN IF NOT EXISTS debug BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE cameras ADD COLUMN IF NOT EXISTS insecure_skip_verify BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN cameras.on_demand IS 'true = stream hanya ditarik saat ada viewer, false = selalu aktif';
+COMMENT ON COLUMN cameras.audio IS 'Teruskan track audio kamera';
+COMMENT ON COLUMN cameras.debug IS 'Log RTSP verbose di RTSPtoWeb';
+COMMENT ON COLUMN cameras.insecure_skip_verify IS 'Lewati verifikasi sertifikat TLS untuk rtsps://';
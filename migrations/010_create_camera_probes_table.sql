@@ -0,0 +1,26 @@
+-- Migration: Create camera probes table
+-- File: migrations/010_create_camera_probes_table.sql
+
+-- Create camera_probes table (hasil probe RTSP terakhir per camera)
+CREATE TABLE IF NOT EXISTS camera_probes (
+    camera_id UUID PRIMARY KEY REFERENCES cameras(id) ON DELETE CASCADE,
+    success BOOLEAN NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    server VARCHAR(255) NOT NULL DEFAULT '',
+    session_name VARCHAR(255) NOT NULL DEFAULT '',
+    video_codec VARCHAR(50) NOT NULL DEFAULT '',
+    video_profile VARCHAR(50) NOT NULL DEFAULT '',
+    width INTEGER NOT NULL DEFAULT 0,
+    height INTEGER NOT NULL DEFAULT 0,
+    fps DOUBLE PRECISION NOT NULL DEFAULT 0,
+    audio_codec VARCHAR(50) NOT NULL DEFAULT '',
+    audio_sample_rate INTEGER NOT NULL DEFAULT 0,
+    track_count INTEGER NOT NULL DEFAULT 0,
+    tracks JSONB NOT NULL DEFAULT '[]',
+    sdp TEXT NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    probed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE camera_probes IS 'Hasil OPTIONS + DESCRIBE terakhir ke rtsp_url camera';
+COMMENT ON COLUMN camera_probes.tracks IS 'Media track dari SDP (codec, profile, resolusi, fps)';
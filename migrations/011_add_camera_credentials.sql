@@ -0,0 +1,16 @@
+-- Migration: Split RTSP credentials out of camera URLs
+-- File: migrations/011_add_camera_credentials.sql
+
+-- Credential RTSP camera, password dienkripsi AES-GCM oleh backend
+ALTER TABLE cameras ADD COLUMN IF NOT EXISTS rtsp_username VARCHAR(255);
+ALTER TABLE cameras ADD COLUMN IF NOT EXISTS rtsp_password_enc TEXT;
+
+-- Permission eksplisit user di luar role
+ALTER TABLE users ADD COLUMN IF NOT EXISTS permissions TEXT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN cameras.rtsp_username IS 'Username RTSP, tidak lagi disimpan di rtsp_url';
+COMMENT ON COLUMN cameras.rtsp_password_enc IS 'Password RTSP terenkripsi: <key-id>:<base64(nonce || ciphertext)>';
+COMMENT ON COLUMN users.permissions IS 'Permission eksplisit, misal camera:credentials';
+
+-- URL lama yang masih berisi user:password@ dipisahkan dan dienkripsi oleh
+-- backend saat startup (butuh CREDENTIAL_KEYS), atau dengan: cctvctl rotate-credentials
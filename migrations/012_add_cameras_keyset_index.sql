@@ -0,0 +1,6 @@
+-- Migration: Index for keyset (cursor) pagination of the camera list
+-- File: migrations/012_add_cameras_keyset_index.sql
+
+-- GET /cameras?cursor= mengurutkan dan melanjutkan halaman dengan (created_at, id).
+-- Index btree bisa dipindai dua arah, sehingga cukup satu untuk ASC dan DESC.
+CREATE INDEX IF NOT EXISTS idx_cameras_created_at_id ON cameras(created_at, id);
@@ -0,0 +1,7 @@
+-- Migration: Add version column for optimistic concurrency on cameras
+-- File: migrations/013_add_camera_version.sql
+
+-- version naik setiap camera diubah lewat API dan dikirim sebagai ETag.
+-- Update dengan If-Match (atau versi yang sudah basi) ditolak jika version
+-- sudah berubah, sehingga dua operator tidak saling menimpa perubahan.
+ALTER TABLE cameras ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
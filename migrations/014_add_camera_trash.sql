@@ -0,0 +1,23 @@
+-- Migration: Trash bin for soft-deleted cameras
+-- File: migrations/014_add_camera_trash.sql
+
+-- Camera yang dihapus tetap di tabel (is_active = false) sampai di-restore,
+-- di-hard delete, atau di-purge setelah CAMERA_TRASH_RETENTION_DAYS.
+ALTER TABLE cameras ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE cameras ADD COLUMN IF NOT EXISTS deleted_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+-- Camera yang dihapus sebelum migration ini: waktu hapus = update terakhir
+UPDATE cameras SET deleted_at = updated_at WHERE is_active = false AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_cameras_deleted_at ON cameras(deleted_at) WHERE is_active = false;
+
+-- Log activity (camera.deleted, camera.restored, camera.purged) tetap ada
+-- setelah camera di-purge; camera_id menjadi NULL, ID ada di details
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'activity_logs_camera_id_fkey' AND confdeltype = 'c') THEN
+        ALTER TABLE activity_logs DROP CONSTRAINT activity_logs_camera_id_fkey;
+        ALTER TABLE activity_logs ADD CONSTRAINT activity_logs_camera_id_fkey
+            FOREIGN KEY (camera_id) REFERENCES cameras(id) ON DELETE SET NULL;
+    END IF;
+END $$;
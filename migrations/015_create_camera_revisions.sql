@@ -0,0 +1,19 @@
+-- Migration: Create camera revisions table
+-- File: migrations/015_create_camera_revisions.sql
+
+-- Setiap perubahan camera (create, update, patch, delete, restore, bulk, import,
+-- revert) dicatat per field: changes = {"field": {"before": ..., "after": ...}}.
+-- version adalah version camera setelah perubahan. Password RTSP tidak
+-- disimpan, hanya has_rtsp_password.
+CREATE TABLE IF NOT EXISTS camera_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    camera_id UUID NOT NULL REFERENCES cameras(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(50) NOT NULL,
+    changes JSONB NOT NULL,
+    reverted_to BIGINT REFERENCES camera_revisions(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_camera_revisions_camera_id ON camera_revisions(camera_id, id);
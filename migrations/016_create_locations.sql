@@ -0,0 +1,104 @@
+-- Migration: Create site / building / floor / zone hierarchy
+-- File: migrations/016_create_locations.sql
+
+-- Lokasi camera sebagai entity: site > building > floor > zone. Floor opsional,
+-- zone tanpa floor langsung berada di bawah building. geometry berisi GeoJSON
+-- geometry (misalnya Polygon denah), address hanya untuk site dan building.
+CREATE TABLE IF NOT EXISTS sites (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    address TEXT,
+    geometry JSONB,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS buildings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    site_id UUID NOT NULL REFERENCES sites(id),
+    name VARCHAR(255) NOT NULL,
+    address TEXT,
+    geometry JSONB,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS floors (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    building_id UUID NOT NULL REFERENCES buildings(id),
+    name VARCHAR(100) NOT NULL,
+    level INTEGER NOT NULL DEFAULT 0,
+    geometry JSONB,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS zones (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    building_id UUID NOT NULL REFERENCES buildings(id),
+    floor_id UUID REFERENCES floors(id),
+    name VARCHAR(255) NOT NULL,
+    geometry JSONB,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Nama unik per parent tanpa membedakan huruf besar/kecil
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sites_name ON sites(LOWER(name));
+CREATE UNIQUE INDEX IF NOT EXISTS idx_buildings_site_name ON buildings(site_id, LOWER(name));
+CREATE UNIQUE INDEX IF NOT EXISTS idx_floors_building_name ON floors(building_id, LOWER(name));
+CREATE UNIQUE INDEX IF NOT EXISTS idx_zones_building_name ON zones(building_id, LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_zones_floor_id ON zones(floor_id);
+
+-- Kolom building dan zone camera tetap ada dan diisi nama building/zone dari
+-- zone_id, agar filter, sort dan export yang memakai teks tetap berjalan
+ALTER TABLE cameras ADD COLUMN IF NOT EXISTS zone_id UUID REFERENCES zones(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_cameras_zone_id ON cameras(zone_id);
+
+-- Data lama: setiap building/zone teks yang berbeda (tanpa membedakan huruf
+-- besar/kecil dan spasi) menjadi satu entity di bawah "Default Site". Ejaan
+-- yang paling banyak dipakai menjadi nama entity. Camera yang hanya punya salah
+-- satunya masuk ke building atau zone "Unassigned". Hanya dijalankan jika belum
+-- ada site sama sekali.
+DO $$
+DECLARE
+    default_site UUID;
+BEGIN
+    IF EXISTS (SELECT 1 FROM sites) THEN
+        RETURN;
+    END IF;
+
+    CREATE TEMP TABLE camera_locations ON COMMIT DROP AS
+    SELECT
+        id,
+        COALESCE(NULLIF(regexp_replace(TRIM(building), '\s+', ' ', 'g'), ''), 'Unassigned') AS building,
+        COALESCE(NULLIF(regexp_replace(TRIM(zone), '\s+', ' ', 'g'), ''), 'Unassigned') AS zone
+    FROM cameras
+    WHERE NULLIF(TRIM(building), '') IS NOT NULL OR NULLIF(TRIM(zone), '') IS NOT NULL;
+
+    IF NOT EXISTS (SELECT 1 FROM camera_locations) THEN
+        RETURN;
+    END IF;
+
+    INSERT INTO sites (name) VALUES ('Default Site') RETURNING id INTO default_site;
+
+    INSERT INTO buildings (site_id, name)
+    SELECT DISTINCT ON (LOWER(building)) default_site, building
+    FROM camera_locations
+    GROUP BY building
+    ORDER BY LOWER(building), COUNT(*) DESC, building;
+
+    INSERT INTO zones (building_id, name)
+    SELECT DISTINCT ON (b.id, LOWER(l.zone)) b.id, l.zone
+    FROM camera_locations l
+    JOIN buildings b ON LOWER(b.name) = LOWER(l.building)
+    GROUP BY b.id, l.zone
+    ORDER BY b.id, LOWER(l.zone), COUNT(*) DESC, l.zone;
+
+    UPDATE cameras c
+    SET zone_id = z.id, building = b.name, zone = z.name
+    FROM camera_locations l
+    JOIN buildings b ON LOWER(b.name) = LOWER(l.building)
+    JOIN zones z ON z.building_id = b.id AND LOWER(z.name) = LOWER(l.zone)
+    WHERE c.id = l.id;
+END $$;